@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"net/http"
+	"strings"
+)
+
+const (
+	apiKeyHeader        = "X-Api-Key"
+	apiKeyParam         = "apikey"
+	adminTokenHeader    = "X-Admin-Token"
+	apiKeysUsagePath    = "/api/admin/apikeys/usage"
+	bearerAuthorization = "Bearer "
+)
+
+var errAdminTokenRequired = apierrors.New(apierrors.Unauthorized, "admin token required")
+
+type clientContextKey struct{}
+
+func readApiKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); len(key) > 0 {
+		return key
+	}
+	return readApiKeyParam(r)
+}
+
+func readApiKeyParam(r *http.Request) string {
+	for name, values := range r.URL.Query() {
+		if strings.ToLower(name) == apiKeyParam && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// resolveClient returns the anonymous client identified by ip if the request has no api key
+func (s *httpServer) resolveClient(r *http.Request) (*client, error) {
+	if c, ok := r.Context().Value(clientContextKey{}).(*client); ok {
+		return c, nil
+	}
+	ip := s.ipResolver.resolve(r)
+	key := readApiKey(r)
+	if len(key) == 0 || s.apiKeys == nil {
+		return s.limiter.newClient(ip, s.ipResolver.limiterKey(ip), nil), nil
+	}
+	apiKey, err := s.apiKeys.Client(key)
+	if err != nil {
+		return nil, apierrors.Wrap(apierrors.Unauthorized, err)
+	}
+	return s.limiter.newClient(ip, "", apiKey), nil
+}
+
+func withClient(r *http.Request, c *client) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), clientContextKey{}, c))
+}
+
+func (s *httpServer) recordApiKeyRequest(c *client, rejected bool) {
+	if c.apiKey == nil || s.apiKeys == nil {
+		return
+	}
+	s.apiKeys.RecordRequest(c.apiKey, rejected)
+}
+
+func (s *httpServer) readPaginatorParams(r *http.Request) (uint64, *string, error) {
+	if c, ok := r.Context().Value(clientContextKey{}).(*client); ok && c.maxLimit > 0 {
+		return ReadPaginatorParamsWithMaxLimit(r.Form, c.maxLimit)
+	}
+	return ReadPaginatorParams(r.Form)
+}
+
+func (s *httpServer) checkAdminToken(r *http.Request) bool {
+	if len(s.adminToken) == 0 {
+		return false
+	}
+	token := r.Header.Get(adminTokenHeader)
+	if len(token) == 0 {
+		if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, bearerAuthorization) {
+			token = strings.TrimPrefix(authorization, bearerAuthorization)
+		}
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// @Tags Admin
+// @Id ApiKeysUsage
+// @Param X-Admin-Token header string true "admin token"
+// @Success 200 {object} api.Response{result=[]apikeys.Usage}
+// @Failure 401 "Admin token is missing or wrong"
+// @Failure 404 "Api keys are not enabled"
+// @Router /Admin/ApiKeys/Usage [get]
+func (s *httpServer) apiKeysUsage(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil || len(s.adminToken) == 0 {
+		s.apiHandler.ServeHTTP(w, r)
+		return
+	}
+	if !s.checkAdminToken(r) {
+		s.writeRejectedRequest(w, errAdminTokenRequired)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	WriteResponse(w, s.apiKeys.Usage(), nil, s.logger)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	batchPath           = "/api/batch"
+	maxBatchSize        = 20
+	maxBatchRequestSize = 1 << 16
+)
+
+type batchResponseRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newBatchResponseRecorder() *batchResponseRecorder {
+	return &batchResponseRecorder{
+		header:     make(http.Header),
+		statusCode: http.StatusOK,
+	}
+}
+
+func (r *batchResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchResponseRecorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+func (r *batchResponseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+}
+
+func (r *batchResponseRecorder) responsePage() ResponsePage {
+	if strings.HasPrefix(r.header.Get("Content-Type"), "application/json") {
+		var page struct {
+			Result            json.RawMessage `json:"result,omitempty"`
+			ContinuationToken *string         `json:"continuationToken,omitempty"`
+			Error             *RespError      `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal(r.body.Bytes(), &page); err == nil {
+			res := ResponsePage{
+				ContinuationToken: page.ContinuationToken,
+				Error:             page.Error,
+			}
+			if len(page.Result) > 0 {
+				res.Result = page.Result
+			}
+			return res
+		}
+	}
+	if r.statusCode != http.StatusOK {
+		return getErrorMsgResponse(http.StatusText(r.statusCode))
+	}
+	return ResponsePage{
+		Result: r.body.String(),
+	}
+}
+
+// @Tags Batch
+// @Id Batch
+// @Summary Executes up to 20 API GET requests concurrently, every request counts against the client request limit
+// @Accept json
+// @Param paths body []string true "relative API paths, e.g. /Epoch/Last"
+// @Success 200 {array} api.ResponsePage
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Router /Batch [post]
+func (s *httpServer) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeRejectedRequest(w, apierrors.New(apierrors.InvalidArgument, "POST method required"))
+		return
+	}
+	id := s.pm.Start("batch", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	var paths []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchRequestSize)).Decode(&paths); err != nil {
+		s.writeRejectedRequest(w, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "unable to parse batch request")))
+		return
+	}
+	if len(paths) == 0 || len(paths) > maxBatchSize {
+		s.writeRejectedRequest(w, apierrors.Errorf(apierrors.InvalidArgument, "batch size must be from 1 to %d", maxBatchSize))
+		return
+	}
+	subRequests := make([]*http.Request, len(paths))
+	for i, path := range paths {
+		subRequest, err := s.newBatchSubRequest(r, path)
+		if err != nil {
+			s.writeRejectedRequest(w, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrapf(err, "wrong path %v", path)))
+			return
+		}
+		subRequests[i] = subRequest
+	}
+
+	res := make([]ResponsePage, len(subRequests))
+	wg := sync.WaitGroup{}
+	wg.Add(len(subRequests))
+	for i, subRequest := range subRequests {
+		go func(i int, subRequest *http.Request) {
+			defer wg.Done()
+			recorder := newBatchResponseRecorder()
+			s.apiHandler.ServeHTTP(recorder, subRequest)
+			res[i] = recorder.responsePage()
+		}(i, subRequest)
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to write batch response: %v", err))
+	}
+}
+
+func (s *httpServer) newBatchSubRequest(r *http.Request, path string) (*http.Request, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasPrefix(strings.ToLower(path), "/api/") {
+		path = "/api" + path
+	}
+	subRequest, err := http.NewRequestWithContext(r.Context(), http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Sub-requests of v2 batches are v2 ones, v1 batches may include v2 paths
+	if path, ok := trimApiV2Prefix(subRequest.URL.Path); ok || getApiVersion(r.Context()) == apiV2 {
+		if subRequest, err = withApiVersion(subRequest, apiV2, path); err != nil {
+			return nil, err
+		}
+	}
+	switch strings.ToLower(subRequest.URL.Path) {
+	case batchPath, wsPath, epochEventsPath:
+		return nil, errors.New("path is not allowed in batch")
+	}
+	subRequest.Header = r.Header.Clone()
+	subRequest.Header.Del("Content-Type")
+	subRequest.Header.Del("Content-Length")
+	// Conditional and negotiation headers target the batch response rather than sub-responses
+	subRequest.Header.Del("If-None-Match")
+	subRequest.Header.Del("Accept")
+	_, ownFormatParam := ownFormatParamPaths[strings.ToLower(subRequest.URL.Path)]
+	if query := subRequest.URL.Query(); len(query.Get(formatParam)) > 0 && !ownFormatParam {
+		query.Del(formatParam)
+		subRequest.URL.RawQuery = query.Encode()
+	}
+	if key := readApiKeyParam(r); len(key) > 0 && len(subRequest.Header.Get(apiKeyHeader)) == 0 {
+		subRequest.Header.Set(apiKeyHeader, key)
+	}
+	subRequest.RemoteAddr = r.RemoteAddr
+	subRequest.RequestURI = subRequest.URL.RequestURI()
+	return subRequest, nil
+}
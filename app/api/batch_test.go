@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBatchTestServer(t *testing.T, reqLimit int) *httpServer {
+	ipResolver, err := newIpResolver(config.ClientIpConfig{})
+	require.Nil(t, err)
+	s := &httpServer{
+		logger: log.New(),
+		pm:     monitoring.NewEmptyPerformanceMonitor(),
+		limiter: &reqLimiter{
+			queue:               make(chan struct{}, maxBatchSize),
+			adjacentDataQueue:   make(chan struct{}, 1),
+			timeout:             time.Second,
+			reqCountsByClientId: cache.New(reqLimitWindow, time.Minute),
+			reqLimit:            reqLimit,
+		},
+		ipResolver: ipResolver,
+	}
+	router := mux.NewRouter().PathPrefix("/api").Subrouter()
+	router.Path("/epoch/last").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteResponse(w, map[string]int{"epoch": 2}, nil, s.logger)
+	})
+	router.Path("/identity/{address}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, apierrors.New(apierrors.NotFound, "no data found"), s.logger)
+	})
+	s.apiHandler = s.requestFilter(router)
+	return s
+}
+
+type batchTestItem struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RespError      `json:"error"`
+}
+
+func serveBatch(s *httpServer, method, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, batchPath, strings.NewReader(body))
+	r.RemoteAddr = "10.0.0.1:1234"
+	w := httptest.NewRecorder()
+	s.batch(w, r)
+	return w
+}
+
+func Test_batch_itemErrors(t *testing.T) {
+	s := newBatchTestServer(t, 0)
+
+	w := serveBatch(s, http.MethodPost, `["/Epoch/Last", "api/Identity/0x01", "/Unknown"]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var items []batchTestItem
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Len(t, items, 3)
+	require.JSONEq(t, `{"epoch":2}`, string(items[0].Result))
+	require.Nil(t, items[0].Error)
+	require.Empty(t, items[1].Result)
+	require.Equal(t, apierrors.NotFound, items[1].Error.Code)
+	require.Equal(t, "no data found", items[1].Error.Message)
+	require.Equal(t, http.StatusText(http.StatusNotFound), items[2].Error.Message)
+
+	w = serveBatch(s, http.MethodPost, `["/v2/Epochs/Last"]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.JSONEq(t, `{"epoch":2}`, string(items[0].Result))
+
+	w = serveBatch(s, http.MethodPost, `["/v2/Epoch/Last"]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "use /api/v2/Epochs/Last")
+}
+
+func Test_batch_requestErrors(t *testing.T) {
+	s := newBatchTestServer(t, 0)
+
+	w := serveBatch(s, http.MethodGet, `["/Epoch/Last"]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"invalid_argument"`)
+
+	w = serveBatch(s, http.MethodPost, `{"path":"/Epoch/Last"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveBatch(s, http.MethodPost, `[]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	paths := make([]string, maxBatchSize+1)
+	for i := range paths {
+		paths[i] = "/Epoch/Last"
+	}
+	body, err := json.Marshal(paths)
+	require.Nil(t, err)
+	w = serveBatch(s, http.MethodPost, string(body))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "batch size must be from 1 to 20")
+
+	body, err = json.Marshal(paths[:maxBatchSize])
+	require.Nil(t, err)
+	w = serveBatch(s, http.MethodPost, string(body))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = serveBatch(s, http.MethodPost, `["/Epoch/Last", "/Batch"]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "path is not allowed in batch")
+
+	w = serveBatch(s, http.MethodPost, `["/Epoch/Last", "/ws"]`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	s.legacyErrors = true
+	w = serveBatch(s, http.MethodPost, `[]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "batch size must be from 1 to 20")
+}
+
+func Test_batch_limiter(t *testing.T) {
+	s := newBatchTestServer(t, 3)
+
+	// Every item is charged to the client limit
+	w := serveBatch(s, http.MethodPost, `["/Epoch/Last", "/Epoch/Last", "/Epoch/Last", "/Epoch/Last"]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var items []batchTestItem
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	var results, rateLimited int
+	for _, item := range items {
+		if item.Error == nil {
+			results++
+			continue
+		}
+		require.Equal(t, apierrors.RateLimited, item.Error.Code)
+		rateLimited++
+	}
+	require.Equal(t, 3, results)
+	require.Equal(t, 1, rateLimited)
+	require.Len(t, s.limiter.queue, 0)
+
+	w = serveBatch(s, http.MethodPost, `["/Epoch/Last"]`)
+	items = nil
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Equal(t, apierrors.RateLimited, items[0].Error.Code)
+
+	// Other clients have their own limits
+	r := httptest.NewRequest(http.MethodPost, batchPath, strings.NewReader(`["/Epoch/Last"]`))
+	r.RemoteAddr = "10.0.0.2:1234"
+	w = httptest.NewRecorder()
+	s.batch(w, r)
+	items = nil
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Nil(t, items[0].Error)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const ipv6AggregationPrefix = 64
+
+// ipResolver resolves the client address taking into account forwarding headers set by trusted proxies only
+type ipResolver struct {
+	trustedProxies []*net.IPNet
+	aggregateIPv6  bool
+}
+
+func newIpResolver(conf config.ClientIpConfig) (*ipResolver, error) {
+	resolver := &ipResolver{
+		aggregateIPv6: conf.AggregateIPv6,
+	}
+	for _, proxy := range conf.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %v", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			resolver.trustedProxies = append(resolver.trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %v", proxy)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, ipNet)
+	}
+	return resolver, nil
+}
+
+// resolve walks forwarding hops from right to left while they are trusted proxies and returns the first untrusted one
+func (resolver *ipResolver) resolve(r *http.Request) string {
+	remoteIp := parseHop(r.RemoteAddr)
+	if remoteIp == nil {
+		return r.RemoteAddr
+	}
+	if !resolver.isTrusted(remoteIp) {
+		return remoteIp.String()
+	}
+	hops := forwardedHops(r.Header)
+	if len(hops) == 0 {
+		hops = xForwardedForHops(r.Header)
+	}
+	if len(hops) == 0 {
+		if realIp := parseHop(r.Header.Get("X-Real-IP")); realIp != nil {
+			return realIp.String()
+		}
+		return remoteIp.String()
+	}
+	res := remoteIp
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHop(hops[i])
+		if ip == nil {
+			break
+		}
+		res = ip
+		if !resolver.isTrusted(ip) {
+			break
+		}
+	}
+	return res.String()
+}
+
+func (resolver *ipResolver) isTrusted(ip net.IP) bool {
+	for _, proxy := range resolver.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// limiterKey returns the key the client request limit is counted by, IPv6 addresses are optionally aggregated by /64 networks
+func (resolver *ipResolver) limiterKey(ip string) string {
+	if !resolver.aggregateIPv6 {
+		return ip
+	}
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil || parsedIp.To4() != nil {
+		return ip
+	}
+	ipNet := &net.IPNet{
+		IP:   parsedIp.Mask(net.CIDRMask(ipv6AggregationPrefix, 8*net.IPv6len)),
+		Mask: net.CIDRMask(ipv6AggregationPrefix, 8*net.IPv6len),
+	}
+	return ipNet.String()
+}
+
+// forwardedHops returns "for" parameters of the RFC 7239 Forwarded headers
+func forwardedHops(header http.Header) []string {
+	var res []string
+	for _, value := range header.Values("Forwarded") {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				name, value, ok := cutString(strings.TrimSpace(pair), "=")
+				if !ok || strings.ToLower(name) != "for" {
+					continue
+				}
+				res = append(res, value)
+			}
+		}
+	}
+	return res
+}
+
+func xForwardedForHops(header http.Header) []string {
+	var res []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		res = append(res, strings.Split(value, ",")...)
+	}
+	return res
+}
+
+// parseHop parses an address which may be quoted, bracketed and followed by a port or an IPv6 zone
+func parseHop(hop string) net.IP {
+	hop = strings.Trim(strings.TrimSpace(hop), `"`)
+	if ip := parseIp(hop); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return parseIp(host)
+	}
+	return parseIp(strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]"))
+}
+
+func parseIp(value string) net.IP {
+	if i := strings.IndexByte(value, '%'); i >= 0 {
+		value = value[:i]
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
+
+func cutString(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func Test_ipResolver_resolve(t *testing.T) {
+	resolver, err := newIpResolver(config.ClientIpConfig{
+		TrustedProxies: []string{"10.0.0.0/8", "2001:db8:ffff::1"},
+	})
+	require.Nil(t, err)
+
+	newRequest := func(remoteAddr string, header map[string]string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, "/api/block/last", nil)
+		r.RemoteAddr = remoteAddr
+		for name, value := range header {
+			r.Header.Set(name, value)
+		}
+		return r
+	}
+
+	// Headers of untrusted senders are ignored
+	require.Equal(t, "203.0.113.5", resolver.resolve(newRequest("203.0.113.5:1234", map[string]string{
+		"X-Forwarded-For": "1.1.1.1",
+	})))
+	require.Equal(t, "2001:db8::1", resolver.resolve(newRequest("[2001:db8::1]:1234", nil)))
+
+	// The rightmost untrusted hop is the client
+	require.Equal(t, "198.51.100.7", resolver.resolve(newRequest("10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "1.1.1.1, 198.51.100.7, 10.0.0.2",
+	})))
+	require.Equal(t, "10.0.0.2", resolver.resolve(newRequest("10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "unknown, 10.0.0.2",
+	})))
+	require.Equal(t, "2001:db8:cafe::17", resolver.resolve(newRequest("[2001:db8:ffff::1]:443", map[string]string{
+		"Forwarded":       `for=192.0.2.43, for="[2001:db8:cafe::17]:4711";proto=https`,
+		"X-Forwarded-For": "1.1.1.1",
+	})))
+	require.Equal(t, "192.0.2.60", resolver.resolve(newRequest("10.0.0.1:1234", map[string]string{
+		"X-Real-IP": "192.0.2.60",
+	})))
+}
+
+func Test_ipResolver_limiterKey(t *testing.T) {
+	resolver, err := newIpResolver(config.ClientIpConfig{AggregateIPv6: true})
+	require.Nil(t, err)
+	require.Equal(t, "2001:db8:1:2::/64", resolver.limiterKey("2001:db8:1:2:3:4:5:6"))
+	require.Equal(t, "192.0.2.1", resolver.limiterKey("192.0.2.1"))
+
+	_, err = newIpResolver(config.ClientIpConfig{TrustedProxies: []string{"wrong"}})
+	require.NotNil(t, err)
+}
@@ -1,14 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/pkg/errors"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 )
 
 // Response mock  type for swagger
@@ -23,8 +24,13 @@ type ResponsePage struct {
 	Error             *RespError  `json:"error,omitempty"`
 } // @Name ResponsePage
 
+var errRequestTimeout = apierrors.New(apierrors.Timeout, "request timeout")
+
 type RespError struct {
 	Message string `json:"message"`
+	// Code is one of not_found, invalid_argument, unauthorized, rate_limited, upstream_unavailable, timeout, conflict, internal
+	Code    apierrors.Code         `json:"code,omitempty" swaggertype:"string"`
+	Details map[string]interface{} `json:"details,omitempty"`
 } // @Name Error
 
 func WriteErrorResponse(w http.ResponseWriter, err error, logger log.Logger) {
@@ -36,14 +42,57 @@ func WriteResponse(w http.ResponseWriter, result interface{}, err error, logger
 }
 
 func WriteResponsePage(w http.ResponseWriter, result interface{}, continuationToken *string, err error, logger log.Logger) {
+	if err != nil {
+		if isRequestTimeout(w, err) {
+			err = errRequestTimeout
+		}
+		limitCacheClass(w, volatileData)
+		w.Header().Set("Content-Type", "application/json")
+		writeErrorStatus(w, err)
+	} else if written, err := writeListResponse(w, result, continuationToken); written {
+		if err != nil {
+			logger.Error(fmt.Sprintf("Unable to write API response: %v", err))
+		}
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(getResponse(result, continuationToken, err))
+	err = json.NewEncoder(w).Encode(getVersionedResponse(w, result, continuationToken, err))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Unable to write API response: %v", err))
 		return
 	}
 }
 
+// isRequestTimeout checks whether the error is caused by the expired request deadline, e.g. the db statement canceled
+// by the deadline is reported by the driver with its own error
+func isRequestTimeout(w http.ResponseWriter, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	aw, ok := w.(*apiResponseWriter)
+	return ok && aw.ctx != nil && aw.ctx.Err() == context.DeadlineExceeded
+}
+
+// writeErrorStatus writes the status of the error code unless the handler has already written the status
+// or legacy errors are enabled, legacy errors are returned with 200 status
+func writeErrorStatus(w http.ResponseWriter, err error) {
+	aw, ok := w.(*apiResponseWriter)
+	if !ok || aw.legacyErrors || aw.wroteHeader {
+		return
+	}
+	aw.WriteHeader(apierrors.HttpStatus(apierrors.CodeOf(err)))
+}
+
+// writeRejectedRequest writes the error of the request rejected before it reaches the api response writer,
+// the status of the error code is written unless legacy errors are enabled
+func (s *httpServer) writeRejectedRequest(w http.ResponseWriter, err error) {
+	if !s.legacyErrors {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(apierrors.HttpStatus(apierrors.CodeOf(err)))
+	}
+	WriteErrorResponse(w, err, s.logger)
+}
+
 func getResponse(result interface{}, continuationToken *string, err error) ResponsePage {
 	if err != nil {
 		return getErrorResponse(err)
@@ -55,7 +104,10 @@ func getResponse(result interface{}, continuationToken *string, err error) Respo
 }
 
 func getErrorResponse(err error) ResponsePage {
-	return getErrorMsgResponse(err.Error())
+	res := getErrorMsgResponse(err.Error())
+	res.Error.Code = apierrors.CodeOf(err)
+	res.Error.Details = apierrors.DetailsOf(err)
+	return res
 }
 
 func getErrorMsgResponse(errMsg string) ResponsePage {
@@ -69,12 +121,18 @@ func getErrorMsgResponse(errMsg string) ResponsePage {
 func ReadUint(vars map[string]string, name string) (uint64, error) {
 	value, err := strconv.ParseUint(vars[name], 10, 64)
 	if err != nil {
-		return 0, errors.New(fmt.Sprintf("wrong value %s=%v", name, vars[name]))
+		return 0, apierrors.Errorf(apierrors.InvalidArgument, "wrong value %s=%v", name, vars[name])
 	}
 	return value, nil
 }
 
+const defaultMaxLimit = 100
+
 func ReadPaginatorParams(params url.Values) (uint64, *string, error) {
+	return ReadPaginatorParamsWithMaxLimit(params, defaultMaxLimit)
+}
+
+func ReadPaginatorParamsWithMaxLimit(params url.Values, maxLimit uint64) (uint64, *string, error) {
 	var continuationToken *string
 	if v := params.Get("continuationtoken"); len(v) > 0 {
 		continuationToken = &v
@@ -83,8 +141,9 @@ func ReadPaginatorParams(params url.Values) (uint64, *string, error) {
 	if err != nil {
 		return 0, nil, err
 	}
-	if count > 100 {
-		return 0, nil, errors.Errorf("too big value limit=%d", count)
+	if count > maxLimit {
+		return 0, nil, apierrors.Errorf(apierrors.InvalidArgument, "too big value limit=%d", count).
+			WithDetails(map[string]interface{}{"maxLimit": maxLimit})
 	}
 	return count, continuationToken, nil
 }
@@ -92,22 +151,11 @@ func ReadPaginatorParams(params url.Values) (uint64, *string, error) {
 func ReadUintUrlValue(params url.Values, name string) (uint64, error) {
 	value, err := strconv.ParseUint(params.Get(name), 10, 64)
 	if err != nil {
-		return 0, errors.New(fmt.Sprintf("wrong value %s=%v", name, params.Get(name)))
+		return 0, apierrors.Errorf(apierrors.InvalidArgument, "wrong value %s=%v", name, params.Get(name))
 	}
 	return value, nil
 }
 
-func GetIP(r *http.Request) string {
-	header := r.Header.Get("X-Forwarded-For")
-	if len(header) > 0 {
-		return strings.Split(header, ", ")[0]
-	}
-	if strings.Contains(r.RemoteAddr, ":") {
-		return strings.Split(r.RemoteAddr, ":")[0]
-	}
-	return r.RemoteAddr
-}
-
 func WriteTextPlainResponse(w http.ResponseWriter, result string, err error, logger log.Logger) {
 	var bytes []byte
 	if err != nil {
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/exports"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var errExportRowLimit = errors.New("export row limit reached")
+
+// exportRoute pages through the accessor method backing the route, vars are the route path variables validated
+// as path variables of the api routes with the same names
+type exportRoute struct {
+	pattern  []string
+	varNames []string
+	itemType reflect.Type
+	filters  []queryParam
+	export   func(ctx context.Context, accessor db.Accessor, vars []string, filters map[string]string, pageSize uint64, w db.RowWriter) error
+}
+
+var (
+	exportStatesFilter     = queryParam{name: "states", list: true, validate: identityStatesParam.validate}
+	exportPrevStatesFilter = queryParam{name: "prevstates", list: true, validate: identityStatesParam.validate}
+)
+
+var exportRoutes = []exportRoute{
+	newExportRoute("/Address/{address}/Txs", types.TransactionSummary{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		return streamPages(func(continuationToken *string) (*string, error) {
+			return accessor.StreamIdentityTxs(ctx, vars[0], pageSize, continuationToken, w)
+		})
+	}),
+	newExportRoute("/Epoch/{epoch}/Txs", types.TransactionSummary{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		epoch, _ := strconv.ParseUint(vars[0], 10, 64)
+		return streamPages(func(continuationToken *string) (*string, error) {
+			return accessor.StreamEpochTxs(ctx, epoch, pageSize, continuationToken, w)
+		})
+	}),
+	newExportRoute("/Block/{block}/Txs", types.TransactionSummary{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		height, err := strconv.ParseUint(vars[0], 10, 64)
+		return streamPages(func(continuationToken *string) (*string, error) {
+			if err != nil {
+				return accessor.StreamBlockTxsByHash(ctx, vars[0], pageSize, continuationToken, w)
+			}
+			return accessor.StreamBlockTxsByHeight(ctx, height, pageSize, continuationToken, w)
+		})
+	}),
+	newExportRoute("/Epoch/{epoch}/Identities", types.EpochIdentity{}, []queryParam{exportStatesFilter, exportPrevStatesFilter}, func(ctx context.Context, accessor db.Accessor, vars []string, filters map[string]string, pageSize uint64, w db.RowWriter) error {
+		epoch, _ := strconv.ParseUint(vars[0], 10, 64)
+		prevStates, states := convertStates([]string{filters["prevstates"]}), convertStates([]string{filters["states"]})
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.EpochIdentities(ctx, epoch, prevStates, states, pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Epoch/{epoch}/IdentityRewards", types.Rewards{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		epoch, _ := strconv.ParseUint(vars[0], 10, 64)
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.EpochIdentitiesRewards(ctx, epoch, pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Epoch/{epoch}/Invites", types.Invite{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		epoch, _ := strconv.ParseUint(vars[0], 10, 64)
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.EpochInvites(ctx, epoch, pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Epoch/{epoch}/Flips", types.FlipSummary{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		epoch, _ := strconv.ParseUint(vars[0], 10, 64)
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.EpochFlips(ctx, epoch, pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Address/{address}/Balance/Changes", types.BalanceUpdate{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.AddressBalanceUpdates(ctx, vars[0], pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Identity/{address}/Rewards", types.Reward{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.IdentityRewards(ctx, vars[0], pageSize, continuationToken)
+		})
+	}),
+	newExportRoute("/Contract/{address}/BalanceUpdates", types.ContractTxBalanceUpdate{}, nil, func(ctx context.Context, accessor db.Accessor, vars []string, _ map[string]string, pageSize uint64, w db.RowWriter) error {
+		return writePages(w, func(continuationToken *string) (interface{}, *string, error) {
+			return accessor.ContractTxBalanceUpdates(ctx, vars[0], pageSize, continuationToken)
+		})
+	}),
+}
+
+func newExportRoute(
+	path string,
+	item interface{},
+	filters []queryParam,
+	export func(ctx context.Context, accessor db.Accessor, vars []string, filters map[string]string, pageSize uint64, w db.RowWriter) error,
+) exportRoute {
+	pattern := splitPath(strings.ToLower(path))
+	var varNames []string
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, "{") {
+			varNames = append(varNames, strings.Trim(segment, "{}"))
+			pattern[i] = "*"
+		}
+	}
+	return exportRoute{
+		pattern:  pattern,
+		varNames: varNames,
+		itemType: reflect.TypeOf(item),
+		filters:  filters,
+		export:   export,
+	}
+}
+
+func streamPages(stream func(continuationToken *string) (*string, error)) error {
+	var continuationToken *string
+	for {
+		nextContinuationToken, err := stream(continuationToken)
+		if err != nil || nextContinuationToken == nil {
+			return err
+		}
+		continuationToken = nextContinuationToken
+	}
+}
+
+func writePages(w db.RowWriter, load func(continuationToken *string) (interface{}, *string, error)) error {
+	return streamPages(func(continuationToken *string) (*string, error) {
+		items, nextContinuationToken, err := load(continuationToken)
+		if err != nil {
+			return nil, err
+		}
+		v := reflect.ValueOf(items)
+		for i := 0; i < v.Len(); i++ {
+			if err := w(v.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return nextContinuationToken, nil
+	})
+}
+
+type exportRunner struct {
+	accessor db.Accessor
+	pageSize uint64
+}
+
+// NewExportRunner reads exported rows with the accessor directly as export pages are too large to be cached
+func NewExportRunner(accessor db.Accessor, pageSize int) exports.Runner {
+	return &exportRunner{
+		accessor: accessor,
+		pageSize: uint64(pageSize),
+	}
+}
+
+func (runner *exportRunner) Validate(spec exports.Spec) error {
+	_, err := runner.resolve(spec)
+	return err
+}
+
+// resolvedExport is the export route matching the spec with the normalized path variables and filters
+type resolvedExport struct {
+	route   exportRoute
+	vars    []string
+	filters map[string]string
+	format  responseFormat
+}
+
+func (runner *exportRunner) resolve(spec exports.Spec) (resolvedExport, error) {
+	var res resolvedExport
+	switch strings.ToLower(spec.Format) {
+	case "csv":
+		res.format = csvFormat
+	case "ndjson", "":
+		res.format = ndjsonFormat
+	default:
+		return resolvedExport{}, apierrors.Errorf(apierrors.InvalidArgument, "unsupported format %v", spec.Format)
+	}
+	segments := splitPath(strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(spec.Route), "/"), "api/"))
+	for _, route := range exportRoutes {
+		if !matchPathPattern(route.pattern, segments) {
+			continue
+		}
+		res.route = route
+		for i, patternSegment := range route.pattern {
+			if patternSegment != "*" {
+				continue
+			}
+			name := route.varNames[len(res.vars)]
+			value, err := pathVarValidators[name](segments[i])
+			if err != nil {
+				return resolvedExport{}, newValidationError(name, segments[i], err)
+			}
+			res.vars = append(res.vars, value)
+		}
+		res.filters = make(map[string]string, len(spec.Filters))
+		for name, value := range spec.Filters {
+			filter, ok := findQueryParam(route.filters, strings.ToLower(name))
+			if !ok {
+				return resolvedExport{}, apierrors.Errorf(apierrors.InvalidArgument, "unsupported filter %v", name)
+			}
+			normalized, err := validateQueryParam(filter, []string{value})
+			if err != nil {
+				return resolvedExport{}, err
+			}
+			res.filters[filter.name] = strings.Join(normalized, ",")
+		}
+		return res, nil
+	}
+	return resolvedExport{}, apierrors.Errorf(apierrors.InvalidArgument, "route %v cannot be exported", spec.Route)
+}
+
+func (runner *exportRunner) Run(ctx context.Context, spec exports.Spec, w io.Writer, maxRows uint64) (uint64, bool, error) {
+	export, err := runner.resolve(spec)
+	if err != nil {
+		return 0, false, err
+	}
+	encoder, err := newRowEncoder(w, export.format, export.route.itemType, apiV1)
+	if err != nil {
+		return 0, false, err
+	}
+	var rows uint64
+	err = export.route.export(ctx, runner.accessor, export.vars, export.filters, runner.pageSize, func(item interface{}) error {
+		if rows == maxRows {
+			return errExportRowLimit
+		}
+		rows++
+		return encoder.encode(item)
+	})
+	truncated := err == errExportRowLimit
+	if truncated {
+		err = nil
+	}
+	if err != nil {
+		return rows, false, err
+	}
+	return rows, truncated, encoder.flush()
+}
+
+func findQueryParam(params []queryParam, name string) (queryParam, bool) {
+	for _, param := range params {
+		if param.name == name {
+			return param, true
+		}
+	}
+	return queryParam{}, false
+}
+
+type exportJob struct {
+	exports.Job
+	DownloadUrl string `json:"downloadUrl,omitempty"`
+}
+
+func newExportJob(job exports.Job) exportJob {
+	res := exportJob{Job: job}
+	if job.Status == exports.Completed {
+		res.DownloadUrl = fmt.Sprintf("/api/Exports/%s/Download", job.Id)
+	}
+	return res
+}
+
+// exportOwner is the requester exports are bound to, i.e. the api key client or the ip of anonymous clients
+func exportOwner(r *http.Request) string {
+	if c, _ := r.Context().Value(clientContextKey{}).(*client); c != nil {
+		return c.id
+	}
+	return ""
+}
+
+// @Tags Exports
+// @Id CreateExport
+// @Param spec body exports.Spec true "route, filters and format (csv or ndjson) of the export"
+// @Success 202 {object} api.Response{result=api.exportJob}
+// @Failure 400 "Bad request"
+// @Failure 429 "Export quota exceeded or too many exports in progress"
+// @Failure 503 "Service unavailable"
+// @Router /Exports [post]
+func (s *httpServer) createExport(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("createExport", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	var spec exports.Spec
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&spec); err != nil {
+		WriteErrorResponse(w, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "invalid export spec")), s.logger)
+		return
+	}
+	var jobsPerDay int
+	if c, _ := r.Context().Value(clientContextKey{}).(*client); c != nil && c.apiKey != nil {
+		jobsPerDay = c.apiKey.Tier.ExportsPerDay
+	}
+	job, err := s.exports.Create(exportOwner(r), jobsPerDay, spec)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/Exports/%s", job.Id))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	WriteResponse(w, newExportJob(job), nil, s.logger)
+}
+
+// @Tags Exports
+// @Id Export
+// @Param id path string true "export id"
+// @Success 200 {object} api.Response{result=api.exportJob}
+// @Failure 404 "Export not found"
+// @Router /Exports/{id} [get]
+func (s *httpServer) export(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("export", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	job, err := s.exports.Job(exportOwner(r), mux.Vars(r)["id"])
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	WriteResponse(w, newExportJob(job), nil, s.logger)
+}
+
+// @Tags Exports
+// @Id DownloadExport
+// @Param id path string true "export id"
+// @Success 200 {file} file "gzip compressed csv or ndjson"
+// @Failure 404 "Export not found"
+// @Failure 409 "Export is not completed"
+// @Router /Exports/{id}/Download [get]
+func (s *httpServer) downloadExport(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("downloadExport", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	file, job, err := s.exports.Open(exportOwner(r), mux.Vars(r)["id"])
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	defer file.Close()
+	extension := "ndjson"
+	if strings.ToLower(job.Spec.Format) == "csv" {
+		extension = "csv"
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=export-%s.%s.gz", job.Id, extension))
+	http.ServeContent(w, r, "", *job.CompletedAt, file)
+}
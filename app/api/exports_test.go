@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/exports"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testExportManager struct {
+	exports.Manager
+}
+
+func (m *testExportManager) Create(owner string, jobsPerDay int, spec exports.Spec) (exports.Job, error) {
+	return exports.Job{Id: "1", Status: exports.Pending}, nil
+}
+
+func Test_exportRunner_resolve(t *testing.T) {
+	runner := &exportRunner{}
+
+	res, err := runner.resolve(exports.Spec{
+		Route:   "/api/Epoch/10/Identities",
+		Filters: map[string]string{"States": "verified,HUMAN", "prevStates": ""},
+		Format:  "csv",
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"10"}, res.vars)
+	require.Equal(t, map[string]string{"states": "Verified,Human", "prevstates": ""}, res.filters)
+	require.Equal(t, csvFormat, res.format)
+
+	res, err = runner.resolve(exports.Spec{Route: "/Address/0X0000000000000000000000000000000000000ABC/Txs"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"0x0000000000000000000000000000000000000abc"}, res.vars)
+	require.Equal(t, ndjsonFormat, res.format)
+
+	for _, spec := range []exports.Spec{
+		{Route: "/Epoch/x/Txs"},
+		{Route: "/Address/0x01/Txs"},
+		{Route: "/Block/0x01/Txs"},
+		{Route: "/Epoch/10/Identities", Filters: map[string]string{"states": "Verified,Unknown"}},
+		{Route: "/Epoch/10/Invites", Filters: map[string]string{"states": "Verified"}},
+		{Route: "/Epoch/10/Txs", Format: "xml"},
+		{Route: "/Epoch/10/Coins"},
+	} {
+		_, err := runner.resolve(spec)
+		require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err), spec.Route)
+	}
+}
+
+func Test_httpServer_createExport(t *testing.T) {
+	s := &httpServer{
+		pm:      monitoring.NewEmptyPerformanceMonitor(),
+		logger:  log.New(),
+		exports: &testExportManager{},
+	}
+	w := httptest.NewRecorder()
+	s.createExport(w, httptest.NewRequest(http.MethodPost, "/api/Exports", strings.NewReader(`{"route":"/Epoch/10/Txs"}`)))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, "application/json", w.Result().Header.Get("Content-Type"))
+	require.Equal(t, "/api/Exports/1", w.Result().Header.Get("Location"))
+}
@@ -0,0 +1,442 @@
+package api
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/shopspring/decimal"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type responseFormat int
+
+const (
+	jsonFormat responseFormat = iota
+	csvFormat
+	ndjsonFormat
+)
+
+const (
+	formatParam             = "format"
+	csvContentType          = "text/csv"
+	ndjsonContentType       = "application/x-ndjson"
+	continuationTokenHeader = "X-Continuation-Token"
+)
+
+// continuationRecord is the last NDJSON row of every page, unlike headers it is read by any client,
+// the token is null for the last page
+type continuationRecord struct {
+	ContinuationToken *string `json:"continuationToken"`
+}
+
+// ownFormatParamPaths are routes having their own format param values, e.g. /CirculatingSupply?format=short
+var ownFormatParamPaths = map[string]struct{}{
+	"/api/circulatingsupply": {},
+}
+
+// readResponseFormat prefers the format param to the Accept header, list results are written as JSON by default
+func readResponseFormat(r *http.Request) (responseFormat, error) {
+	_, ownFormatParam := ownFormatParamPaths[strings.ToLower(r.URL.Path)]
+	if format := r.Form.Get(formatParam); len(format) > 0 && !ownFormatParam {
+		switch strings.ToLower(format) {
+		case "json":
+			return jsonFormat, nil
+		case "csv":
+			return csvFormat, nil
+		case "ndjson":
+			return ndjsonFormat, nil
+		}
+		return jsonFormat, apierrors.Errorf(apierrors.InvalidArgument, "unsupported format %v", format)
+	}
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := cutString(strings.TrimSpace(mediaRange), ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/json":
+			return jsonFormat, nil
+		case csvContentType:
+			return csvFormat, nil
+		case ndjsonContentType, "application/ndjson":
+			return ndjsonFormat, nil
+		}
+	}
+	return jsonFormat, nil
+}
+
+func getResponseFormat(w http.ResponseWriter) responseFormat {
+	if aw, ok := w.(*apiResponseWriter); ok {
+		return aw.format
+	}
+	return jsonFormat
+}
+
+// writeListResponse writes slice results in the negotiated format, it returns false if the result is to be written as JSON
+func writeListResponse(w http.ResponseWriter, result interface{}, continuationToken *string) (bool, error) {
+	format := getResponseFormat(w)
+	if format == jsonFormat || result == nil || reflect.TypeOf(result).Kind() != reflect.Slice {
+		return false, nil
+	}
+	if continuationToken != nil {
+		w.Header().Set(continuationTokenHeader, *continuationToken)
+	}
+	items := reflect.ValueOf(result)
+	if format == csvFormat {
+		w.Header().Set("Content-Type", csvContentType)
+	} else {
+		w.Header().Set("Content-Type", ndjsonContentType)
+	}
+	markDeprecatedFields(w, result)
+	version := getResponseVersion(w)
+	encoder, err := newRowEncoder(w, format, items.Type().Elem(), version)
+	if err != nil {
+		return true, err
+	}
+	// CSV columns of v2 omit deprecated fields on their own
+	project := format == ndjsonFormat && version == apiV2
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i).Interface()
+		if project {
+			item = withoutDeprecatedFields(item)
+		}
+		if err := encoder.encode(item); err != nil {
+			return true, err
+		}
+	}
+	if format == ndjsonFormat {
+		if err := encoder.encode(continuationRecord{continuationToken}); err != nil {
+			return true, err
+		}
+	}
+	return true, encoder.flush()
+}
+
+// rowEncoder writes list items one by one
+type rowEncoder interface {
+	encode(item interface{}) error
+	flush() error
+}
+
+func newRowEncoder(w io.Writer, format responseFormat, itemType reflect.Type, version apiVersion) (rowEncoder, error) {
+	if format != csvFormat {
+		return &ndjsonRowEncoder{json.NewEncoder(w)}, nil
+	}
+	encoder := &csvRowEncoder{
+		writer:  csv.NewWriter(w),
+		columns: getCsvColumns(itemType, version),
+	}
+	encoder.record = make([]string, len(encoder.columns))
+	for i, column := range encoder.columns {
+		encoder.record[i] = column.name
+	}
+	return encoder, encoder.writer.Write(encoder.record)
+}
+
+type ndjsonRowEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonRowEncoder) encode(item interface{}) error {
+	return e.encoder.Encode(item)
+}
+
+func (e *ndjsonRowEncoder) flush() error {
+	return nil
+}
+
+type csvRowEncoder struct {
+	writer  *csv.Writer
+	columns []csvColumn
+	record  []string
+}
+
+func (e *csvRowEncoder) encode(item interface{}) error {
+	v := reflect.ValueOf(item)
+	for i, column := range e.columns {
+		e.record[i] = column.value(v)
+	}
+	return e.writer.Write(e.record)
+}
+
+func (e *csvRowEncoder) flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// streamListResponse writes NDJSON rows as soon as they are read, the continuation token is known after the last row only
+// so it is written as the final continuationRecord row. Other formats are written by writeResponsePage once the page is loaded
+func (s *httpServer) streamListResponse(
+	w http.ResponseWriter,
+	stream func(w db.RowWriter) (*string, error),
+	load func() (interface{}, *string, error),
+) {
+	if getResponseFormat(w) != ndjsonFormat {
+		resp, nextContinuationToken, err := load()
+		WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+		return
+	}
+	var started bool
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	project := getResponseVersion(w) == apiV2
+	nextContinuationToken, err := stream(func(item interface{}) error {
+		if !started {
+			markDeprecatedFields(w, item)
+			w.Header().Set("Content-Type", ndjsonContentType)
+			started = true
+		}
+		if project {
+			item = withoutDeprecatedFields(item)
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			WriteErrorResponse(w, err, s.logger)
+			return
+		}
+		// The response is already sent partially, clients detect the truncation by the missing final row
+		s.logger.Error(fmt.Sprintf("Unable to stream API response: %v", err))
+		return
+	}
+	if !started {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.WriteHeader(http.StatusOK)
+	}
+	if err := encoder.Encode(continuationRecord{nextContinuationToken}); err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to stream API response: %v", err))
+	}
+}
+
+type csvColumn struct {
+	name  string
+	value func(item reflect.Value) string
+}
+
+// csvColumnsKey keys the columns by the API version since v2 columns omit deprecated fields
+type csvColumnsKey struct {
+	itemType reflect.Type
+	version  apiVersion
+}
+
+var csvColumnsByType sync.Map
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	decimalType       = reflect.TypeOf(decimal.Decimal{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// getCsvColumns flattens nested structs into dot separated columns derived from the type rather than values,
+// so all pages of a list share the same header
+func getCsvColumns(t reflect.Type, version apiVersion) []csvColumn {
+	key := csvColumnsKey{t, version}
+	if columns, ok := csvColumnsByType.Load(key); ok {
+		return columns.([]csvColumn)
+	}
+	var columns []csvColumn
+	if indirectType(t).Kind() == reflect.Struct && !isCsvLeaf(t) {
+		columns = structCsvColumns(t, "", version, func(item reflect.Value) (reflect.Value, bool) {
+			return item, true
+		})
+	} else {
+		columns = []csvColumn{{name: "value", value: formatCsvValue}}
+	}
+	csvColumnsByType.Store(key, columns)
+	return columns
+}
+
+func structCsvColumns(t reflect.Type, prefix string, version apiVersion, get func(item reflect.Value) (reflect.Value, bool)) []csvColumn {
+	var res []csvColumn
+	structType := indirectType(t)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue
+		}
+		name, _ := jsonFieldName(field)
+		if name == "-" || version == apiV2 && isDeprecatedField(field) {
+			continue
+		}
+		index := i
+		getField := func(item reflect.Value) (reflect.Value, bool) {
+			v, ok := get(item)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			v, ok = indirectValue(v)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			return v.Field(index), true
+		}
+		fieldType := field.Type
+		if field.Anonymous && len(name) == 0 && indirectType(fieldType).Kind() == reflect.Struct {
+			res = append(res, structCsvColumns(fieldType, prefix, version, getField)...)
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		name = prefix + name
+		switch {
+		case indirectType(fieldType).Kind() == reflect.Struct && !isCsvLeaf(fieldType):
+			res = append(res, structCsvColumns(fieldType, name+".", version, getField)...)
+		case fieldType.Kind() == reflect.Slice && indirectType(fieldType.Elem()).Kind() == reflect.Struct && pivotKey(fieldType.Elem()) >= 0:
+			res = append(res, pivotCsvColumns(fieldType.Elem(), name+".", version, getField)...)
+		default:
+			res = append(res, csvColumn{
+				name: name,
+				value: func(item reflect.Value) string {
+					v, ok := getField(item)
+					if !ok {
+						return ""
+					}
+					return formatCsvValue(v)
+				},
+			})
+		}
+	}
+	return res
+}
+
+// pivotKey returns the index of the string field enumerating item kinds, e.g. types.Reward.Type
+func pivotKey(t reflect.Type) int {
+	structType := indirectType(t)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if name, _ := jsonFieldName(field); name == "type" && field.Type.Kind() == reflect.String && len(field.Tag.Get("enums")) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// pivotCsvColumns turns a list of typed items into a column group per enumerated type, e.g. rewards.Validation.balance,
+// omitempty fields duplicate the parent item and are skipped
+func pivotCsvColumns(t reflect.Type, prefix string, version apiVersion, get func(item reflect.Value) (reflect.Value, bool)) []csvColumn {
+	structType := indirectType(t)
+	key := pivotKey(t)
+	var res []csvColumn
+	for _, kind := range strings.Split(structType.Field(key).Tag.Get("enums"), ",") {
+		if len(kind) == 0 {
+			continue
+		}
+		kind := kind
+		getItem := func(item reflect.Value) (reflect.Value, bool) {
+			v, ok := get(item)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			for i := 0; i < v.Len(); i++ {
+				element, ok := indirectValue(v.Index(i))
+				if ok && element.Field(key).String() == kind {
+					return element, true
+				}
+			}
+			return reflect.Value{}, false
+		}
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			name, omitEmpty := jsonFieldName(field)
+			if i == key || omitEmpty || name == "-" || len(field.PkgPath) > 0 || version == apiV2 && isDeprecatedField(field) {
+				continue
+			}
+			if len(name) == 0 {
+				name = field.Name
+			}
+			index := i
+			res = append(res, csvColumn{
+				name: prefix + kind + "." + name,
+				value: func(item reflect.Value) string {
+					element, ok := getItem(item)
+					if !ok {
+						return ""
+					}
+					return formatCsvValue(element.Field(index))
+				},
+			})
+		}
+	}
+	return res
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name, options, _ := cutString(tag, ",")
+	return name, strings.Contains(options, "omitempty")
+}
+
+func isCsvLeaf(t reflect.Type) bool {
+	t = indirectType(t)
+	return t == timeType || t == decimalType || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+func formatCsvValue(v reflect.Value) string {
+	v, ok := indirectValue(v)
+	if !ok {
+		return ""
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case decimal.Decimal:
+		return value.String()
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.String:
+		return v.String()
+	}
+	// Lists, maps and values of custom encoding stay JSON encoded within the cell
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	var str string
+	if json.Unmarshal(b, &str) == nil {
+		return str
+	}
+	return string(b)
+}
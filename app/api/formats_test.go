@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_readResponseFormat(t *testing.T) {
+	newRequest := func(format, accept string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/epoch/1/txs?format="+format, nil)
+		r.Header.Set("Accept", accept)
+		r.ParseForm()
+		return r
+	}
+	format, err := readResponseFormat(newRequest("", ""))
+	require.Nil(t, err)
+	require.Equal(t, jsonFormat, format)
+
+	format, err = readResponseFormat(newRequest("", "text/html, text/csv;q=0.9"))
+	require.Nil(t, err)
+	require.Equal(t, csvFormat, format)
+
+	format, err = readResponseFormat(newRequest("NDJSON", "text/csv"))
+	require.Nil(t, err)
+	require.Equal(t, ndjsonFormat, format)
+
+	_, err = readResponseFormat(newRequest("xml", ""))
+	require.NotNil(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/circulatingsupply?format=short", nil)
+	r.ParseForm()
+	format, err = readResponseFormat(r)
+	require.Nil(t, err)
+	require.Equal(t, jsonFormat, format)
+}
+
+func Test_writeListResponse_csv(t *testing.T) {
+	timestamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	amount := decimal.New(15, -1)
+	txs := []types.TransactionSummary{
+		{
+			Hash:      "0x01",
+			Type:      "SendTx",
+			Timestamp: &timestamp,
+			Amount:    &amount,
+			Data:      &types.OnlineStatusTxSpecificData{BecomeOnline: true},
+			TxReceipt: &types.TxReceipt{Success: true, GasUsed: 10, Method: "send"},
+		},
+		{
+			Hash: "0x02",
+		},
+	}
+	w := httptest.NewRecorder()
+	token := "5"
+	WriteResponsePage(&apiResponseWriter{ResponseWriter: w, format: csvFormat, stream: true}, txs, &token, nil, log.New())
+
+	require.Equal(t, csvContentType, w.Header().Get("Content-Type"))
+	require.Equal(t, "5", w.Header().Get(continuationTokenHeader))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.Nil(t, err)
+	require.Len(t, records, 3)
+	row := func(i int) map[string]string {
+		res := make(map[string]string)
+		for j, name := range records[0] {
+			res[name] = records[i][j]
+		}
+		return res
+	}
+	require.Equal(t, "0x01", row(1)["hash"])
+	require.Equal(t, "2020-01-01T00:00:00Z", row(1)["timestamp"])
+	require.Equal(t, "1.5", row(1)["amount"])
+	require.Contains(t, row(1)["data"], `"becomeOnline":true`)
+	require.Equal(t, "true", row(1)["txReceipt.success"])
+	require.Equal(t, "10", row(1)["txReceipt.gasUsed"])
+	require.Equal(t, "send", row(1)["txReceipt.method"])
+	require.Contains(t, records[0], "txReceipt.actionResult.inputAction.method")
+	require.Equal(t, "", row(2)["txReceipt.success"])
+	require.Equal(t, "", row(2)["amount"])
+	require.Contains(t, records[0], "transfer")
+
+	w = httptest.NewRecorder()
+	WriteResponsePage(&apiResponseWriter{ResponseWriter: w, format: csvFormat, version: apiV2, stream: true}, txs, &token, nil, log.New())
+	records, err = csv.NewReader(w.Body).ReadAll()
+	require.Nil(t, err)
+	require.NotContains(t, records[0], "transfer")
+	require.Equal(t, "0x01", row(1)["hash"])
+
+	w = httptest.NewRecorder()
+	rewards := []types.Rewards{
+		{
+			Address: "0x03",
+			Rewards: []types.Reward{
+				{Type: "Validation", Balance: decimal.New(1, 0), Stake: decimal.New(2, 0)},
+				{Type: "Flips", Balance: decimal.New(3, 0)},
+			},
+		},
+	}
+	WriteResponsePage(&apiResponseWriter{ResponseWriter: w, format: csvFormat, stream: true}, rewards, nil, nil, log.New())
+	records, err = csv.NewReader(w.Body).ReadAll()
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "1", row(1)["rewards.Validation.balance"])
+	require.Equal(t, "2", row(1)["rewards.Validation.stake"])
+	require.Equal(t, "3", row(1)["rewards.Flips.balance"])
+	require.Equal(t, "", row(1)["rewards.Invitations.balance"])
+	require.NotContains(t, records[0], "rewards.Validation.address")
+}
+
+func Test_streamListResponse(t *testing.T) {
+	s := &httpServer{logger: log.New()}
+	stream := func(rows []string, nextContinuationToken *string, err error) *http.Response {
+		w := httptest.NewRecorder()
+		s.streamListResponse(&apiResponseWriter{ResponseWriter: w, format: ndjsonFormat, stream: true}, func(rw db.RowWriter) (*string, error) {
+			for _, row := range rows {
+				if err := rw(row); err != nil {
+					return nil, err
+				}
+			}
+			return nextContinuationToken, err
+		}, func() (interface{}, *string, error) {
+			panic("page must be streamed")
+		})
+		return w.Result()
+	}
+
+	token := "10"
+	resp := stream([]string{"a", "b"}, &token, nil)
+	body, _ := io.ReadAll(resp.Body)
+	require.Equal(t, ndjsonContentType, resp.Header.Get("Content-Type"))
+	require.Equal(t, "\"a\"\n\"b\"\n{\"continuationToken\":\"10\"}\n", string(body))
+
+	resp = stream([]string{"a"}, nil, nil)
+	body, _ = io.ReadAll(resp.Body)
+	require.Equal(t, "\"a\"\n{\"continuationToken\":null}\n", string(body))
+
+	resp = stream(nil, nil, errors.New("timeout"))
+	body, _ = io.ReadAll(resp.Body)
+	require.True(t, strings.Contains(string(body), "timeout"))
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/graphql"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+const maxGraphQLRequestSize = 1 << 16
+
+// graphqlCostMeter charges every db call of a GraphQL query as a separate request to the client request limit
+type graphqlCostMeter struct {
+	limiter *reqLimiter
+	client  *client
+	maxCost int
+}
+
+func (m *graphqlCostMeter) Charge(complexity int) error {
+	if m.maxCost > 0 && complexity > m.maxCost {
+		return apierrors.Errorf(apierrors.InvalidArgument, "query complexity %d exceeds limit %d", complexity, m.maxCost)
+	}
+	if complexity <= 1 {
+		// The first call is covered by the request itself
+		return nil
+	}
+	_, err := m.limiter.checkLimit(m.client.id, m.client.reqLimit, complexity-1)
+	return err
+}
+
+// @Tags GraphQL
+// @Id GraphQL
+// @Summary GraphQL queries over epochs, blocks, identities, addresses, flips, transactions, contracts, pools and tokens
+// @Param query query string false "GraphQL query, may be passed in the JSON body of POST request instead"
+// @Param operationName query string false "operation to execute"
+// @Param variables query string false "JSON encoded variables"
+// @Success 200 {object} object
+// @Failure 429 "Request number limit exceeded"
+// @Failure 503 "Service unavailable"
+// @Router /GraphQL [get]
+// @Router /GraphQL [post]
+func (s *httpServer) graphql(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("graphql", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	request, err := readGraphQLRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	reqClient, err := s.resolveClient(r)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	meter := &graphqlCostMeter{
+		limiter: s.limiter,
+		client:  reqClient,
+		maxCost: s.graphqlMaxComplexity,
+	}
+	result, err := s.graphqlExecutor.Execute(r.Context(), request, meter)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to write GraphQL response: %v", err))
+	}
+}
+
+func readGraphQLRequest(r *http.Request) (graphql.Request, error) {
+	var request graphql.Request
+	if r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxGraphQLRequestSize)).Decode(&request); err != nil {
+			return request, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "unable to parse GraphQL request"))
+		}
+	} else {
+		request.Query = r.Form.Get("query")
+		request.OperationName = r.Form.Get("operationname")
+		if variables := r.Form.Get("variables"); len(variables) > 0 {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "unable to parse GraphQL variables"))
+			}
+		}
+	}
+	if len(request.Query) == 0 {
+		return request, apierrors.New(apierrors.InvalidArgument, "query required")
+	}
+	return request, nil
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_graphqlCostMeter_maxComplexity(t *testing.T) {
+	meter := &graphqlCostMeter{
+		maxCost: 10,
+	}
+	err := meter.Charge(11)
+	require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err))
+	require.Equal(t, "query complexity 11 exceeds limit 10", err.Error())
+	require.Nil(t, meter.Charge(1))
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/health"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"net/http"
+)
+
+const (
+	healthLivePath  = "/api/health/live"
+	healthReadyPath = "/api/health/ready"
+)
+
+// @Tags Health
+// @Id HealthLive
+// @Success 200 {object} api.Response{result=types.HealthReport}
+// @Router /Health/Live [get]
+func (s *httpServer) healthLive(w http.ResponseWriter, r *http.Request) {
+	if s.healthChecker == nil {
+		s.apiHandler.ServeHTTP(w, r)
+		return
+	}
+	s.writeHealthReport(w, s.healthChecker.Live())
+}
+
+// @Tags Health
+// @Id HealthReady
+// @Success 200 {object} api.Response{result=types.HealthReport}
+// @Failure 503 {object} api.Response{result=types.HealthReport} "Service is not ready"
+// @Router /Health/Ready [get]
+func (s *httpServer) healthReady(w http.ResponseWriter, r *http.Request) {
+	if s.healthChecker == nil {
+		s.apiHandler.ServeHTTP(w, r)
+		return
+	}
+	report := s.healthChecker.Ready()
+	if s.dynamicEndpointLoader != nil {
+		dynamicEndpointsStatus := s.dynamicEndpointsRefresh.Status()
+		report.DynamicEndpoints = &dynamicEndpointsStatus
+	}
+	s.writeHealthReport(w, report)
+}
+
+func (s *httpServer) writeHealthReport(w http.ResponseWriter, report types.HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != health.StatusOk {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(getResponse(report, nil, nil)); err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to write API response: %v", err))
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type cacheClass int
+
+const (
+	// volatileData changes between blocks, e.g. mem pool and online state
+	volatileData cacheClass = iota
+	// perBlockData changes at most once per indexed block
+	perBlockData
+	// immutableData never changes once indexed
+	immutableData
+	// finishedEpochData is immutable once the epoch of the path is earlier than the last epoch
+	finishedEpochData
+	// indexedBlockData is immutable once the block of the path is indexed
+	indexedBlockData
+)
+
+const (
+	perBlockDataMaxAge  = 10
+	immutableDataMaxAge = 365 * 24 * 60 * 60
+)
+
+type cacheRule struct {
+	pattern []string
+	class   cacheClass
+}
+
+// cacheRules are checked in order, the first matching rule wins, unmatched routes are per-block
+var cacheRules = []cacheRule{
+	newCacheRule("/api/MemPool/**", volatileData),
+	newCacheRule("/api/OnlineIdentities/**", volatileData),
+	newCacheRule("/api/OnlineIdentity/*", volatileData),
+	newCacheRule("/api/OnlineMiners/**", volatileData),
+	newCacheRule("/api/OnlineValidators/**", volatileData),
+	newCacheRule("/api/Now", volatileData),
+	newCacheRule("/api/Search", volatileData),
+	newCacheRule("/api/GraphQL", volatileData),
+	newCacheRule("/api/DumpLink", volatileData),
+	newCacheRule("/api/Exports/**", volatileData),
+	newCacheRule("/api/Block/Last", perBlockData),
+	newCacheRule("/api/Block/*/**", indexedBlockData),
+	newCacheRule("/api/Transaction/*", immutableData),
+	newCacheRule("/api/Flip/*/Content", immutableData),
+	newCacheRule("/api/Epoch/*/**", finishedEpochData),
+}
+
+func newCacheRule(path string, class cacheClass) cacheRule {
+	return cacheRule{
+		pattern: splitPath(strings.ToLower(path)),
+		class:   class,
+	}
+}
+
+func getCacheRuleClass(segments []string) cacheClass {
+	for _, rule := range cacheRules {
+		if matchPathPattern(rule.pattern, segments) {
+			return rule.class
+		}
+	}
+	return perBlockData
+}
+
+func (s *httpServer) getCacheClass(ctx context.Context, lowerUrlPath string) cacheClass {
+	segments := splitPath(lowerUrlPath)
+	class := getCacheRuleClass(segments)
+	switch class {
+	case finishedEpochData:
+		// /Epoch/Last is not parsed
+		if epoch, err := strconv.ParseUint(segments[2], 10, 64); err == nil && s.isEpochFinished(ctx, epoch) {
+			return immutableData
+		}
+		return perBlockData
+	case indexedBlockData:
+		if s.isBlockIndexed(ctx, segments[2]) {
+			return immutableData
+		}
+		return perBlockData
+	}
+	return class
+}
+
+func (s *httpServer) isEpochFinished(ctx context.Context, epoch uint64) bool {
+	lastEpoch, err := s.service.LastEpoch(ctx)
+	return err == nil && epoch < lastEpoch.Epoch
+}
+
+func (s *httpServer) isBlockIndexed(ctx context.Context, id string) bool {
+	if height, err := strconv.ParseUint(id, 10, 64); err == nil {
+		lastBlock, err := s.service.LastBlock(ctx)
+		return err == nil && height <= lastBlock.Height
+	}
+	hash, err := validateHash(id)
+	if err != nil {
+		return false
+	}
+	_, err = s.service.BlockByHash(ctx, hash)
+	return err == nil
+}
+
+// apiResponseWriter buffers the response to hash it into the ETag unless the response is streamed
+type apiResponseWriter struct {
+	http.ResponseWriter
+	// ctx is the context of the request whose deadline expiration turns errors into the timeout error
+	ctx context.Context
+	// legacyErrors keeps 200 status of error responses for old clients
+	legacyErrors bool
+	version      apiVersion
+	deprecations *deprecations
+	// route is the path template of the matched route, e.g. "/api/address/{address}/txs"
+	route string
+	// requestUri is the path and the query of the v1 request
+	requestUri  string
+	wroteHeader bool
+	format      responseFormat
+	class       cacheClass
+	stream      bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (w *apiResponseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	if w.stream {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+func (w *apiResponseWriter) Write(b []byte) (int, error) {
+	if w.stream {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *apiResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.stream {
+		flusher.Flush()
+	}
+}
+
+// setCacheClass lets handlers reclassify the response once the data is loaded
+func setCacheClass(w http.ResponseWriter, class cacheClass) {
+	if aw, ok := w.(*apiResponseWriter); ok {
+		aw.class = class
+	}
+}
+
+// limitCacheClass prevents caching of responses, e.g. errors, longer than the class allows
+func limitCacheClass(w http.ResponseWriter, class cacheClass) {
+	if aw, ok := w.(*apiResponseWriter); ok && aw.class > class {
+		aw.class = class
+	}
+}
+
+func (s *httpServer) serveCacheable(next http.Handler, w http.ResponseWriter, r *http.Request, format responseFormat) {
+	// The format may be negotiated by the Accept header, shared caches must not mix up bodies of different formats
+	w.Header().Add("Vary", "Accept")
+	aw := &apiResponseWriter{
+		ResponseWriter: w,
+		ctx:            r.Context(),
+		legacyErrors:   s.legacyErrors,
+		version:        getApiVersion(r.Context()),
+		deprecations:   s.deprecations,
+		requestUri:     r.URL.RequestURI(),
+		format:         format,
+		status:         http.StatusOK,
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		aw.class = s.getCacheClass(r.Context(), r.URL.Path)
+	}
+	if aw.class == volatileData || format == ndjsonFormat {
+		// Responses without ETag, e.g. NDJSON rows written as soon as they are read or export files, are not buffered
+		aw.stream = true
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.Header().Set("Cache-Control", cacheControl(volatileData))
+		}
+		next.ServeHTTP(aw, r)
+		return
+	}
+	next.ServeHTTP(aw, r)
+
+	if aw.status != http.StatusOK {
+		w.Header().Set("Cache-Control", cacheControl(volatileData))
+		w.WriteHeader(aw.status)
+		w.Write(aw.body.Bytes())
+		return
+	}
+	w.Header().Set("Cache-Control", cacheControl(aw.class))
+	if aw.class == volatileData {
+		w.Write(aw.body.Bytes())
+		return
+	}
+	hash := sha256.Sum256(aw.body.Bytes())
+	etag := strconv.Quote(hex.EncodeToString(hash[:16]))
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(aw.body.Bytes())
+}
+
+func cacheControl(class cacheClass) string {
+	switch class {
+	case immutableData:
+		return "public, max-age=" + strconv.Itoa(immutableDataMaxAge) + ", immutable"
+	case perBlockData:
+		return "public, max-age=" + strconv.Itoa(perBlockDataMaxAge)
+	default:
+		return "no-cache"
+	}
+}
+
+// etagMatches applies the weak comparison required for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	if len(ifNoneMatch) == 0 {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
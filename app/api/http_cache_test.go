@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_getCacheRuleClass(t *testing.T) {
+	require.Equal(t, volatileData, getCacheRuleClass(splitPath("/api/mempool/txs")))
+	require.Equal(t, perBlockData, getCacheRuleClass(splitPath("/api/block/last")))
+	require.Equal(t, indexedBlockData, getCacheRuleClass(splitPath("/api/block/0x01")))
+	require.Equal(t, indexedBlockData, getCacheRuleClass(splitPath("/api/block/10/txs/count")))
+	require.Equal(t, immutableData, getCacheRuleClass(splitPath("/api/transaction/0x01")))
+	require.Equal(t, perBlockData, getCacheRuleClass(splitPath("/api/transaction/0x01/raw")))
+	require.Equal(t, finishedEpochData, getCacheRuleClass(splitPath("/api/epoch/10/identity/0x01/rewards")))
+	require.Equal(t, perBlockData, getCacheRuleClass(splitPath("/api/epochs")))
+	require.Equal(t, perBlockData, getCacheRuleClass(splitPath("/api/address/0x01")))
+}
+
+func Test_serveCacheable(t *testing.T) {
+	s := &httpServer{logger: log.New()}
+	serve := func(path, ifNoneMatch string, result interface{}, err error) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if len(ifNoneMatch) > 0 {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		s.serveCacheable(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteResponse(w, result, err, s.logger)
+		}), w, r, jsonFormat)
+		return w
+	}
+
+	w := serve("/api/transaction/0x01", "", "tx", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	require.Equal(t, "Accept", w.Header().Get("Vary"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.JSONEq(t, `{"result":"tx"}`, w.Body.String())
+
+	w = serve("/api/transaction/0x01", `"other", W/`+etag, "tx", nil)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Equal(t, etag, w.Header().Get("ETag"))
+	require.Equal(t, "Accept", w.Header().Get("Vary"))
+	require.Empty(t, w.Body.String())
+
+	w = serve("/api/transaction/0x01", etag, "tx2", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	w = serve("/api/address/0x01", "", "address", nil)
+	require.Equal(t, "public, max-age=10", w.Header().Get("Cache-Control"))
+	require.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = serve("/api/transaction/0x02", "", nil, errors.New("no data found"))
+	require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	require.Empty(t, w.Header().Get("ETag"))
+}
+
+func Test_serveCacheableTimeout(t *testing.T) {
+	s := &httpServer{logger: log.New()}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	r := httptest.NewRequest(http.MethodGet, "/api/transaction/0x01", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	s.serveCacheable(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteResponse(w, nil, errors.New("pq: canceling statement due to user request"), s.logger)
+	}), w, r, jsonFormat)
+	require.Equal(t, http.StatusGatewayTimeout, w.Code)
+	require.Contains(t, w.Body.String(), errRequestTimeout.Error())
+}
+
+func Test_serveCacheableErrorStatus(t *testing.T) {
+	serve := func(legacyErrors bool, err error) *httptest.ResponseRecorder {
+		s := &httpServer{logger: log.New(), legacyErrors: legacyErrors}
+		r := httptest.NewRequest(http.MethodGet, "/api/transaction/0x01", nil)
+		w := httptest.NewRecorder()
+		s.serveCacheable(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteResponse(w, nil, err, s.logger)
+		}), w, r, jsonFormat)
+		return w
+	}
+
+	w := serve(false, apierrors.New(apierrors.NotFound, "no data found"))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	require.Equal(t, `{"error":{"message":"no data found","code":"not_found"}}`+"\n", w.Body.String())
+
+	_, _, err := ReadPaginatorParams(url.Values{"limit": []string{"101"}})
+	w = serve(false, err)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":{"message":"too big value limit=101","code":"invalid_argument","details":{"maxLimit":100}}}`+"\n", w.Body.String())
+
+	w = serve(false, errors.New("unknown"))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = serve(true, apierrors.New(apierrors.NotFound, "no data found"))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"not_found"`)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"net/http"
+)
+
+const metricsPath = "/metrics"
+
+func (s *httpServer) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	s.metrics.Handler().ServeHTTP(w, r)
+}
+
+func (limiter *reqLimiter) registerMetrics(metrics *monitoring.Registry) {
+	if metrics == nil {
+		return
+	}
+	inFlight := metrics.NewGauge("idena_api_limiter_in_flight_requests",
+		"Number of API requests holding a slot of the request limiter queue.", "queue")
+	capacity := metrics.NewGauge("idena_api_limiter_queue_capacity",
+		"Number of slots of the request limiter queue.", "queue")
+	metrics.AddCollector(func() {
+		inFlight.Set(float64(len(limiter.queue)), "default")
+		inFlight.Set(float64(len(limiter.adjacentDataQueue)), "adjacent")
+		capacity.Set(float64(cap(limiter.queue)), "default")
+		capacity.Set(float64(cap(limiter.adjacentDataQueue)), "adjacent")
+		for name, queue := range limiter.tierQueues {
+			inFlight.Set(float64(len(queue)), "tier:"+name)
+			capacity.Set(float64(cap(queue)), "tier:"+name)
+		}
+		for _, class := range limiter.routeClasses {
+			if class.queue == nil {
+				continue
+			}
+			inFlight.Set(float64(len(class.queue)), class.name)
+			capacity.Set(float64(cap(class.queue)), class.name)
+		}
+	})
+}
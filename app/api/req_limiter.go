@@ -1,76 +1,223 @@
 package api
 
 import (
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/apikeys"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
 type reqLimiter struct {
-	queue               chan struct{}
-	adjacentDataQueue   chan struct{}
-	timeout             time.Duration
+	queue             chan struct{}
+	adjacentDataQueue chan struct{}
+	timeout           time.Duration
+	// handlerTimeout is the default deadline of handling a request after it left the queue
+	handlerTimeout      time.Duration
 	mutex               sync.Mutex
 	reqCountsByClientId *cache.Cache
 	reqLimit            int
+
+	connCountsByClientId map[string]int
+	connLimit            int
+
+	tierQueues map[string]chan struct{}
+
+	routeClasses []*routeClass
 }
 
+// client describes limits applied to the request sender
+type client struct {
+	id       string
+	ip       string
+	reqLimit int
+	// queue is reserved for the tier of the client, nil means the common queue
+	queue    chan struct{}
+	maxLimit uint64
+	apiKey   *apikeys.Client
+}
+
+// reqLimitWindow is the period the client budget is counted for
+const reqLimitWindow = time.Second * 30
+
 var (
-	errTimeout        = errors.New("timeout while waiting for resource")
-	errReqLimitExceed = errors.New("request limit exceeded")
+	errTimeout         = apierrors.New(apierrors.Timeout, "timeout while waiting for resource")
+	errReqLimitExceed  = apierrors.New(apierrors.RateLimited, "request limit exceeded")
+	errConnLimitExceed = apierrors.New(apierrors.RateLimited, "connection limit exceeded")
 )
 
-func (limiter *reqLimiter) takeResource(clientId string, lowerUrlPath string) error {
-	if err := limiter.checkReqLimit(clientId); err != nil {
-		return err
+func (limiter *reqLimiter) newClient(ip, ipKey string, apiKey *apikeys.Client) *client {
+	if apiKey == nil {
+		return &client{
+			id:       ipKey,
+			ip:       ip,
+			reqLimit: limiter.reqLimit,
+		}
+	}
+	return &client{
+		id:       "key:" + apiKey.Name,
+		ip:       ip,
+		reqLimit: reqLimitPerWindow(apiKey.Tier.ReqsPerMinute),
+		queue:    limiter.getTierQueue(apiKey),
+		maxLimit: apiKey.Tier.MaxLimit,
+		apiKey:   apiKey,
+	}
+}
+
+// reqLimitPerWindow converts the per minute quota to the budget of reqLimitWindow, 0 means unlimited
+func reqLimitPerWindow(reqsPerMinute int) int {
+	if reqsPerMinute <= 0 {
+		return 0
+	}
+	res := reqsPerMinute / 2
+	if res < 1 {
+		res = 1
+	}
+	return res
+}
+
+// newTierQueues carves queues of tiers out of maxReqCount, the rest of slots is left to the common queue
+func newTierQueues(tiers map[string]apikeys.Tier, maxReqCount int) (map[string]chan struct{}, int, error) {
+	names := make([]string, 0, len(tiers))
+	for name := range tiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make(map[string]chan struct{})
+	commonSize := maxReqCount
+	for _, name := range names {
+		share := tiers[name].QueueShare
+		if share <= 0 {
+			continue
+		}
+		size := int(share * float64(maxReqCount))
+		if size < 1 {
+			size = 1
+		}
+		res[name] = make(chan struct{}, size)
+		commonSize -= size
+	}
+	if commonSize < 1 {
+		return nil, 0, errors.Errorf("request queue size %v is too small for queue shares of api key tiers", maxReqCount)
+	}
+	return res, commonSize, nil
+}
+
+// getTierQueue returns nil for tiers without a queue share and for tiers added after startup
+func (limiter *reqLimiter) getTierQueue(apiKey *apikeys.Client) chan struct{} {
+	return limiter.tierQueues[apiKey.TierName]
+}
+
+func (limiter *reqLimiter) takeResource(c *client, lowerUrlPath string) (*rateLimitStatus, error) {
+	status, err := limiter.checkLimit(c.id, c.reqLimit, limiter.getCost(lowerUrlPath))
+	if err != nil {
+		return status, err
 	}
 	var ok bool
-	queue := limiter.getQueueByUrlPath(lowerUrlPath)
+	queue := limiter.getQueue(c, lowerUrlPath)
 	select {
 	case queue <- struct{}{}:
 		ok = true
 	case <-time.After(limiter.timeout):
 	}
 	if !ok {
-		return errTimeout
+		return status, errTimeout
 	}
-	return nil
+	return status, nil
 }
 
 func (limiter *reqLimiter) checkReqLimit(clientId string) error {
-	if limiter.reqLimit <= 0 {
-		return nil
+	_, err := limiter.checkLimit(clientId, limiter.reqLimit, 1)
+	return err
+}
+
+func (limiter *reqLimiter) checkClientReqLimit(c *client) (*rateLimitStatus, error) {
+	return limiter.checkLimit(c.id, c.reqLimit, 1)
+}
+
+// checkLimit charges the cost to the client budget, the returned status is nil if the client is not limited
+func (limiter *reqLimiter) checkLimit(clientId string, reqLimit int, cost int) (*rateLimitStatus, error) {
+	if reqLimit <= 0 {
+		return nil, nil
 	}
 	getReqCount := func() int {
-		if count, err := limiter.reqCountsByClientId.IncrementInt(clientId, 1); err == nil {
+		if count, err := limiter.reqCountsByClientId.IncrementInt(clientId, cost); err == nil {
 			return count
 		}
 		limiter.mutex.Lock()
 		defer limiter.mutex.Unlock()
-		if err := limiter.reqCountsByClientId.Add(clientId, 1, cache.DefaultExpiration); err == nil {
-			return 1
+		if err := limiter.reqCountsByClientId.Add(clientId, cost, cache.DefaultExpiration); err == nil {
+			return cost
 		}
-		if count, err := limiter.reqCountsByClientId.IncrementInt(clientId, 1); err == nil {
+		if count, err := limiter.reqCountsByClientId.IncrementInt(clientId, cost); err == nil {
 			return count
 		}
-		return 1
+		return cost
 	}
-	if getReqCount() > limiter.reqLimit {
-		return errReqLimitExceed
+	count := getReqCount()
+	status := &rateLimitStatus{
+		limit:     reqLimit,
+		remaining: reqLimit - count,
+		reset:     time.Now().Add(reqLimitWindow),
 	}
-	return nil
+	if status.remaining < 0 {
+		status.remaining = 0
+	}
+	if _, expiration, ok := limiter.reqCountsByClientId.GetWithExpiration(clientId); ok && !expiration.IsZero() {
+		status.reset = expiration
+	}
+	if count > reqLimit {
+		return status, errReqLimitExceed
+	}
+	return status, nil
 }
 
-func (limiter *reqLimiter) releaseResource(lowerUrlPath string) {
-	queue := limiter.getQueueByUrlPath(lowerUrlPath)
+func (limiter *reqLimiter) releaseResource(c *client, lowerUrlPath string) {
+	queue := limiter.getQueue(c, lowerUrlPath)
 	<-queue
 }
 
-func (limiter *reqLimiter) getQueueByUrlPath(lowerUrlPath string) chan struct{} {
+func (limiter *reqLimiter) getQueue(c *client, lowerUrlPath string) chan struct{} {
 	if strings.Contains(lowerUrlPath, "/adjacent") {
 		return limiter.adjacentDataQueue
 	}
+	if class := limiter.getRouteClass(lowerUrlPath); class != nil && class.queue != nil {
+		return class.queue
+	}
+	if c.queue != nil {
+		return c.queue
+	}
 	return limiter.queue
 }
+
+func (limiter *reqLimiter) takeConnection(clientId string) error {
+	if limiter.connLimit <= 0 {
+		return nil
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if limiter.connCountsByClientId == nil {
+		limiter.connCountsByClientId = make(map[string]int)
+	}
+	if limiter.connCountsByClientId[clientId] >= limiter.connLimit {
+		return errConnLimitExceed
+	}
+	limiter.connCountsByClientId[clientId]++
+	return nil
+}
+
+func (limiter *reqLimiter) releaseConnection(clientId string) {
+	if limiter.connLimit <= 0 {
+		return
+	}
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if limiter.connCountsByClientId[clientId] <= 1 {
+		delete(limiter.connCountsByClientId, clientId)
+		return
+	}
+	limiter.connCountsByClientId[clientId]--
+}
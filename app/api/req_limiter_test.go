@@ -1,6 +1,8 @@
 package api
 
 import (
+	"github.com/idena-network/idena-indexer-api/app/apikeys"
+	"github.com/idena-network/idena-indexer-api/config"
 	"github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -34,3 +36,85 @@ func Test_checkReqLimit(t *testing.T) {
 		require.Nil(t, limiter.checkReqLimit("client1"))
 	}
 }
+
+func Test_routeClasses(t *testing.T) {
+	routeClasses, err := newRouteClasses([]config.RouteClassConfig{
+		{Name: "heavy", Paths: []string{"/api/Epoch/*/Identities", "/api/Balances"}, MaxConcurrency: 2, Cost: 5, TimeoutSec: 120},
+		{Name: "contracts", Paths: []string{"/api/contract/**"}},
+	})
+	require.Nil(t, err)
+	limiter := &reqLimiter{
+		queue:               make(chan struct{}, 10),
+		adjacentDataQueue:   make(chan struct{}, 1),
+		timeout:             time.Millisecond * 10,
+		handlerTimeout:      time.Second * 30,
+		reqCountsByClientId: cache.New(reqLimitWindow, time.Minute),
+		routeClasses:        routeClasses,
+	}
+	require.Equal(t, "heavy", limiter.getRouteClass("/api/epoch/100/identities").name)
+	require.Equal(t, "heavy", limiter.getRouteClass("/api/balances").name)
+	require.Nil(t, limiter.getRouteClass("/api/epoch/100/identities/count"))
+	require.Nil(t, limiter.getRouteClass("/api/block/last"))
+	require.Equal(t, "contracts", limiter.getRouteClass("/api/contract/0x1/balanceupdates").name)
+	require.Equal(t, 5, limiter.getCost("/api/balances"))
+	require.Equal(t, 1, limiter.getCost("/api/contract/0x1"))
+	require.Equal(t, time.Minute*2, limiter.getHandlerTimeout("/api/balances"))
+	require.Equal(t, time.Second*30, limiter.getHandlerTimeout("/api/contract/0x1"))
+	require.Equal(t, 2, limiter.getCost("/api/address/0x1/balance/at"))
+	require.Equal(t, 20, limiter.getCost("/api/balances/at"))
+
+	c := &client{id: "client1", reqLimit: 12}
+	status, err := limiter.takeResource(c, "/api/balances")
+	require.Nil(t, err)
+	require.Equal(t, 12, status.limit)
+	require.Equal(t, 7, status.remaining)
+	require.True(t, status.resetSeconds() <= 30)
+	status, err = limiter.takeResource(c, "/api/balances")
+	require.Nil(t, err)
+	require.Equal(t, 2, status.remaining)
+
+	// The class pool is exhausted while cheap routes still use the common queue
+	status, err = limiter.takeResource(c, "/api/balances")
+	require.Equal(t, errReqLimitExceed, err)
+	require.Equal(t, 0, status.remaining)
+	require.Len(t, routeClasses[0].queue, 2)
+	require.Len(t, limiter.queue, 0)
+
+	c2 := &client{id: "client2"}
+	_, err = limiter.takeResource(c2, "/api/balances")
+	require.Equal(t, errTimeout, err)
+	_, err = limiter.takeResource(c2, "/api/block/last")
+	require.Nil(t, err)
+	require.Len(t, limiter.queue, 1)
+}
+
+func Test_tierQueues(t *testing.T) {
+	tierQueues, commonQueueSize, err := newTierQueues(map[string]apikeys.Tier{
+		"free":    {ReqsPerMinute: 1},
+		"pro":     {ReqsPerMinute: 600, QueueShare: 0.5},
+		"partner": {QueueShare: 0.01},
+	}, 10)
+	require.Nil(t, err)
+	require.Len(t, tierQueues, 2)
+	require.Equal(t, 5, cap(tierQueues["pro"]))
+	require.Equal(t, 1, cap(tierQueues["partner"]))
+	// Tier queues are carved out of the request queue so the total limit holds
+	require.Equal(t, 4, commonQueueSize)
+
+	_, _, err = newTierQueues(map[string]apikeys.Tier{"pro": {QueueShare: 0.5}}, 1)
+	require.NotNil(t, err)
+
+	limiter := &reqLimiter{
+		queue:      make(chan struct{}, commonQueueSize),
+		tierQueues: tierQueues,
+	}
+	c := limiter.newClient("1.1.1.1", "", &apikeys.Client{Name: "alice", TierName: "free", Tier: apikeys.Tier{ReqsPerMinute: 1}})
+	require.Equal(t, 1, c.reqLimit)
+	require.Nil(t, c.queue)
+	c = limiter.newClient("1.1.1.1", "", &apikeys.Client{Name: "bob", TierName: "pro", Tier: apikeys.Tier{ReqsPerMinute: 600, QueueShare: 0.5}})
+	require.Equal(t, 300, c.reqLimit)
+	require.Equal(t, tierQueues["pro"], c.queue)
+	c = limiter.newClient("1.1.1.1", "", &apikeys.Client{Name: "carol", TierName: "new", Tier: apikeys.Tier{QueueShare: 0.1}})
+	require.Equal(t, 0, c.reqLimit)
+	require.Nil(t, c.queue)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/pkg/errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// routeClass groups routes sharing a concurrency pool and a cost charged to the client budget per request
+type routeClass struct {
+	name     string
+	patterns [][]string
+	// queue is nil if requests of the class share the common queue
+	queue chan struct{}
+	cost  int
+	// timeout is 0 if requests of the class have the default handler timeout
+	timeout time.Duration
+}
+
+type rateLimitStatus struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// builtinRouteClasses charge routes whose requests are heavier than the default cost, they are matched after configured
+// classes so that the config may override them
+var builtinRouteClasses = []config.RouteClassConfig{
+	{Name: "balanceAt", Paths: []string{"/api/Address/*/Balance/At"}, Cost: 2},
+	{Name: "balancesAt", Paths: []string{"/api/Balances/At"}, Cost: 20},
+}
+
+// newRouteClasses compiles path patterns where '*' matches a single path segment and trailing '**' matches the rest of the path
+func newRouteClasses(confs []config.RouteClassConfig) ([]*routeClass, error) {
+	confs = append(append([]config.RouteClassConfig(nil), confs...), builtinRouteClasses...)
+	res := make([]*routeClass, 0, len(confs))
+	for _, conf := range confs {
+		if len(conf.Name) == 0 || len(conf.Paths) == 0 {
+			return nil, errors.New("route class name and paths must not be empty")
+		}
+		if conf.Cost < 0 || conf.MaxConcurrency < 0 || conf.TimeoutSec < 0 {
+			return nil, errors.Errorf("invalid limits of route class %v", conf.Name)
+		}
+		class := &routeClass{
+			name:    conf.Name,
+			cost:    conf.Cost,
+			timeout: time.Second * time.Duration(conf.TimeoutSec),
+		}
+		if class.cost == 0 {
+			class.cost = 1
+		}
+		if conf.MaxConcurrency > 0 {
+			class.queue = make(chan struct{}, conf.MaxConcurrency)
+		}
+		for _, path := range conf.Paths {
+			class.patterns = append(class.patterns, splitPath(strings.ToLower(path)))
+		}
+		res = append(res, class)
+	}
+	return res, nil
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func (class *routeClass) matches(segments []string) bool {
+	for _, pattern := range class.patterns {
+		if matchPathPattern(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPathPattern(pattern, segments []string) bool {
+	for i, patternSegment := range pattern {
+		if patternSegment == "**" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if patternSegment != "*" && patternSegment != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+func (limiter *reqLimiter) getRouteClass(lowerUrlPath string) *routeClass {
+	if len(limiter.routeClasses) == 0 {
+		return nil
+	}
+	segments := splitPath(lowerUrlPath)
+	for _, class := range limiter.routeClasses {
+		if class.matches(segments) {
+			return class
+		}
+	}
+	return nil
+}
+
+func (limiter *reqLimiter) getCost(lowerUrlPath string) int {
+	if class := limiter.getRouteClass(lowerUrlPath); class != nil {
+		return class.cost
+	}
+	return 1
+}
+
+// getHandlerTimeout returns the deadline of handling the request after it left the queue, 0 means no deadline
+func (limiter *reqLimiter) getHandlerTimeout(lowerUrlPath string) time.Duration {
+	if class := limiter.getRouteClass(lowerUrlPath); class != nil && class.timeout > 0 {
+		return class.timeout
+	}
+	return limiter.handlerTimeout
+}
+
+func writeRateLimitHeaders(w http.ResponseWriter, status *rateLimitStatus) {
+	if status == nil {
+		return
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.remaining))
+	w.Header().Set("X-RateLimit-Reset", fmt.Sprint(status.resetSeconds()))
+}
+
+func writeRetryAfterHeader(w http.ResponseWriter, status *rateLimitStatus) {
+	if status == nil {
+		return
+	}
+	w.Header().Set("Retry-After", fmt.Sprint(status.resetSeconds()))
+}
+
+// resetSeconds returns the number of seconds until the client budget is restored
+func (status *rateLimitStatus) resetSeconds() int64 {
+	seconds := int64(math.Ceil(time.Until(status.reset).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
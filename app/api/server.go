@@ -1,12 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/apikeys"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/exports"
+	"github.com/idena-network/idena-indexer-api/app/graphql"
+	"github.com/idena-network/idena-indexer-api/app/health"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
 	"github.com/idena-network/idena-indexer-api/app/monitoring"
 	service2 "github.com/idena-network/idena-indexer-api/app/service"
 	"github.com/idena-network/idena-indexer-api/app/types"
@@ -27,10 +37,13 @@ import (
 
 type Server interface {
 	Start(swaggerConfig config.SwaggerConfig)
+	// Shutdown stops accepting requests and waits for in-flight ones until ctx is done, event streams are closed
+	Shutdown(ctx context.Context) error
 }
 
 func NewServer(
 	port int,
+	serverConfig config.ServerConfig,
 	latestHours int,
 	activeAddrHours int,
 	contractSizeLimit int,
@@ -42,15 +55,65 @@ func NewServer(
 	pm monitoring.PerformanceMonitor,
 	maxReqCount int,
 	timeout time.Duration,
+	handlerTimeout time.Duration,
 	reqsPerMinuteLimit int,
 	dynamicEndpointLoader service2.DynamicEndpointLoader,
 	cors bool,
+	legacyErrors bool,
+	deprecationSunset string,
+	eventBus events.Bus,
+	wsConfig config.WebSocketConfig,
+	epochEventHistory *events.History,
+	graphqlExecutor graphql.Executor,
+	graphqlConfig config.GraphQLConfig,
+	routeClasses []config.RouteClassConfig,
+	clientIpConfig config.ClientIpConfig,
+	metrics *monitoring.Registry,
+	healthChecker health.Checker,
+	apiKeys apikeys.Holder,
+	adminToken string,
+	exportManager exports.Manager,
+	lc *lifecycle.Manager,
 ) Server {
 	var lowerFrozenBalanceAddrs []string
 	for _, frozenBalanceAddr := range frozenBalanceAddrs {
 		lowerFrozenBalanceAddrs = append(lowerFrozenBalanceAddrs, strings.ToLower(frozenBalanceAddr))
 	}
-	return &httpServer{
+	limiterRouteClasses, err := newRouteClasses(routeClasses)
+	if err != nil {
+		panic(err)
+	}
+	ipResolver, err := newIpResolver(clientIpConfig)
+	if err != nil {
+		panic(err)
+	}
+	if len(clientIpConfig.TrustedProxies) == 0 {
+		logger.Warn("No trusted proxies configured, forwarding headers are ignored and clients are limited by the peer address; set ClientIp.TrustedProxies if the api runs behind a proxy")
+	}
+	deprecations, err := newDeprecations(deprecationSunset, metrics)
+	if err != nil {
+		panic(err)
+	}
+	var tiers map[string]apikeys.Tier
+	if apiKeys != nil {
+		tiers = apiKeys.Tiers()
+	}
+	tierQueues, commonQueueSize, err := newTierQueues(tiers, maxReqCount)
+	if err != nil {
+		panic(err)
+	}
+	s := &httpServer{
+		srv: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			ReadHeaderTimeout: time.Second * time.Duration(serverConfig.ReadHeaderTimeoutSec),
+			ReadTimeout:       time.Second * time.Duration(serverConfig.ReadTimeoutSec),
+			WriteTimeout:      time.Second * time.Duration(serverConfig.WriteTimeoutSec),
+			IdleTimeout:       time.Second * time.Duration(serverConfig.IdleTimeoutSec),
+			MaxHeaderBytes:    serverConfig.MaxHeaderBytes,
+		},
+		maxBodyBytes:       serverConfig.MaxBodyBytes,
+		closing:            make(chan struct{}),
+		lc:                 lc,
 		port:               port,
 		service:            service,
 		contractsService:   contractsService,
@@ -62,18 +125,52 @@ func NewServer(
 		getDumpLink:        getDumpLink,
 		pm:                 pm,
 		cors:               cors,
+		legacyErrors:       legacyErrors,
+		deprecations:       deprecations,
 		limiter: &reqLimiter{
-			queue:               make(chan struct{}, maxReqCount),
+			queue:               make(chan struct{}, commonQueueSize),
 			adjacentDataQueue:   make(chan struct{}, 1),
 			timeout:             timeout,
-			reqCountsByClientId: cache.New(time.Second*30, time.Minute*5),
-			reqLimit:            reqsPerMinuteLimit / 2,
+			handlerTimeout:      handlerTimeout,
+			reqCountsByClientId: cache.New(reqLimitWindow, time.Minute*5),
+			reqLimit:            reqLimitPerWindow(reqsPerMinuteLimit),
+			connLimit:           wsConfig.MaxConnectionsPerClient,
+			tierQueues:          tierQueues,
+			routeClasses:        limiterRouteClasses,
 		},
-		dynamicEndpointLoader: dynamicEndpointLoader,
-	}
+		dynamicEndpointLoader:   dynamicEndpointLoader,
+		dynamicEndpointsRefresh: health.NewRefreshTracker(),
+		eventBus:                eventBus,
+		wsEnabled:               wsConfig.Enabled,
+		wsMaxSubscriptions:      wsConfig.MaxSubscriptionsPerConnection,
+		wsOriginChecker:         newWsOriginChecker(wsConfig.AllowedOrigins),
+		epochEventHistory:       epochEventHistory,
+		graphqlExecutor:         graphqlExecutor,
+		graphqlMaxComplexity:    graphqlConfig.MaxComplexity,
+		metrics:                 metrics,
+		healthChecker:           healthChecker,
+		ipResolver:              ipResolver,
+		apiKeys:                 apiKeys,
+		adminToken:              adminToken,
+		exports:                 exportManager,
+		rejectedRequests: metrics.NewCounter("idena_api_rejected_requests_total",
+			"Total number of API requests rejected by the request limiter by response code.", "code"),
+	}
+	s.limiter.registerMetrics(metrics)
+	// Hijacked ws connections and event streams are not tracked by Shutdown
+	s.srv.RegisterOnShutdown(func() {
+		close(s.closing)
+	})
+	return s
 }
 
 type httpServer struct {
+	srv          *http.Server
+	maxBodyBytes int64
+	// closing is closed once the shutdown starts to close ws connections and event streams
+	closing chan struct{}
+	lc      *lifecycle.Manager
+
 	port               int
 	latestHours        int
 	activeAddrHours    int
@@ -88,10 +185,31 @@ type httpServer struct {
 	mutex              sync.Mutex
 	getDumpLink        func() string
 	cors               bool
+	legacyErrors       bool
+	deprecations       *deprecations
+	eventBus           events.Bus
+	wsEnabled          bool
+	wsMaxSubscriptions int
+	wsOriginChecker    *wsOriginChecker
+	epochEventHistory  *events.History
+	apiHandler         http.Handler
+
+	graphqlExecutor      graphql.Executor
+	graphqlMaxComplexity int
+
+	metrics          *monitoring.Registry
+	rejectedRequests *monitoring.Counter
+	healthChecker    health.Checker
+
+	ipResolver *ipResolver
+	apiKeys    apikeys.Holder
+	adminToken string
+	exports    exports.Manager
 
 	dynamicEndpointLoader    service2.DynamicEndpointLoader
 	dynamicEndpointsHash     string
 	dynamicEndpointsByMethod map[string]types.DynamicEndpoint
+	dynamicEndpointsRefresh  *health.RefreshTracker
 }
 
 func (s *httpServer) generateReqId() int {
@@ -115,21 +233,39 @@ func (s *httpServer) Start(swaggerConfig config.SwaggerConfig) {
 			httpSwagger.URL("/api/swagger/doc.json"),
 		))
 	}
-	handler := s.requestFilter(apiRouter)
+	s.apiHandler = s.requestFilter(apiRouter)
+	handler := s.versionFilter(s.streamFilter(s.apiHandler))
 	if s.cors {
 		headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type"})
 		originsOk := handlers.AllowedOrigins([]string{"*"})
 		methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-		handler = handlers.CORS(originsOk, headersOk, methodsOk)(handler)
+		exposedHeadersOk := handlers.ExposedHeaders([]string{continuationTokenHeader})
+		handler = handlers.CORS(originsOk, headersOk, methodsOk, exposedHeadersOk)(handler)
 	}
-	err := http.ListenAndServe(fmt.Sprintf(":%d", s.port), handler)
-	if err != nil {
+	if s.maxBodyBytes > 0 {
+		handler = s.bodyFilter(handler)
+	}
+	s.srv.Handler = handler
+	err := s.srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
 		panic(err)
 	}
 }
 
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *httpServer) bodyFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *httpServer) refreshDynamicEndpoints() {
 	dynamicEndpoints, err := s.dynamicEndpointLoader.Load()
+	s.dynamicEndpointsRefresh.Done(err)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("Unable to load dynamic endpoints: %v", err.Error()))
 		return
@@ -156,23 +292,63 @@ func (s *httpServer) refreshDynamicEndpoints() {
 	return
 }
 
+// streamFilter serves long-lived connections, batches, probes and metrics bypassing the request queue of requestFilter,
+// batch sub-requests take the queue on their own
+func (s *httpServer) streamFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(r.URL.Path) {
+		case wsPath:
+			s.ws(w, r)
+		case epochEventsPath:
+			s.epochEvents(w, r)
+		case batchPath:
+			s.batch(w, r)
+		case healthLivePath:
+			s.healthLive(w, r)
+		case healthReadyPath:
+			s.healthReady(w, r)
+		case apiKeysUsagePath:
+			s.apiKeysUsage(w, r)
+		case metricsPath:
+			if s.metrics == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			s.writeMetrics(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
 func (s *httpServer) requestFilter(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		reqId := s.generateReqId()
 		var urlToLog *url.URL
 		lowerUrlPath := strings.ToLower(r.URL.Path)
-		if !strings.Contains(lowerUrlPath, "/search") {
+		if !strings.Contains(lowerUrlPath, "/search") && len(readApiKeyParam(r)) == 0 {
 			urlToLog = r.URL
 		}
-		ip := GetIP(r)
-		s.logger.Debug("Got api request", "reqId", reqId, "url", urlToLog, "from", ip)
-		if err := s.limiter.takeResource(ip, lowerUrlPath); err != nil {
+		c, err := s.resolveClient(r)
+		if err != nil {
+			s.logger.Debug("Rejected api request", "reqId", reqId, "url", urlToLog, "from", s.ipResolver.resolve(r), "err", err)
+			s.writeRejectedRequest(w, err)
+			return
+		}
+		s.logger.Debug("Got api request", "reqId", reqId, "url", urlToLog, "from", c.ip, "client", c.id)
+		rateLimit, err := s.limiter.takeResource(c, lowerUrlPath)
+		writeRateLimitHeaders(w, rateLimit)
+		if err != nil {
 			s.logger.Error("Unable to handle API request", "reqId", reqId, "err", err)
+			s.recordApiKeyRequest(c, true)
 			switch err {
 			case errTimeout:
+				s.rejectedRequests.Inc(strconv.Itoa(http.StatusServiceUnavailable))
 				w.WriteHeader(http.StatusServiceUnavailable)
 				break
 			case errReqLimitExceed:
+				s.rejectedRequests.Inc(strconv.Itoa(http.StatusTooManyRequests))
+				writeRetryAfterHeader(w, rateLimit)
 				w.WriteHeader(http.StatusTooManyRequests)
 				break
 			default:
@@ -181,9 +357,17 @@ func (s *httpServer) requestFilter(next http.Handler) http.Handler {
 			WriteErrorResponse(w, err, s.logger)
 			return
 		}
-		defer s.limiter.releaseResource(lowerUrlPath)
+		defer s.limiter.releaseResource(c, lowerUrlPath)
+		s.recordApiKeyRequest(c, false)
+
+		if handlerTimeout := s.limiter.getHandlerTimeout(lowerUrlPath); handlerTimeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), handlerTimeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
 
-		err := r.ParseForm()
+		r = withClient(r, c)
+		err = r.ParseForm()
 		if err != nil {
 			s.logger.Error("Unable to parse API request", "reqId", reqId, "err", err)
 			w.WriteHeader(http.StatusBadRequest)
@@ -194,13 +378,24 @@ func (s *httpServer) requestFilter(next http.Handler) http.Handler {
 			r.Form[strings.ToLower(name)] = value
 		}
 		r.URL.Path = strings.ToLower(r.URL.Path)
-		next.ServeHTTP(w, r)
+		format, err := readResponseFormat(r)
+		if err != nil {
+			s.writeRejectedRequest(w, err)
+			return
+		}
+		s.serveCacheable(next, w, r, format)
 	})
 }
 
 func (s *httpServer) initRouter(router *mux.Router) {
+	router.Use(s.validatePathVars, s.deprecationFilter)
+
 	router.Path(strings.ToLower("/DumpLink")).HandlerFunc(s.dumpLink)
 
+	if s.graphqlExecutor != nil {
+		router.Path(strings.ToLower("/GraphQL")).HandlerFunc(s.graphql)
+	}
+
 	router.Path(strings.ToLower("/Search")).
 		Queries("value", "{value}").
 		HandlerFunc(s.search)
@@ -219,6 +414,9 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Txt/CirculatingSupply")).
 		HandlerFunc(s.txtCirculatingSupply)
 
+	router.Path(strings.ToLower("/Supply/History")).HandlerFunc(s.supplyHistory)
+	router.Path(strings.ToLower("/Stats/Series")).HandlerFunc(s.statsSeries)
+
 	router.Path(strings.ToLower("/Upgrades")).
 		HandlerFunc(s.upgrades)
 
@@ -238,9 +436,9 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/FlipStatesSummary")).HandlerFunc(s.epochFlipStatesSummary)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/FlipWrongWordsSummary")).HandlerFunc(s.epochFlipWrongWordsSummary)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Identities/Count")).
-		HandlerFunc(s.epochIdentitiesCount)
+		HandlerFunc(s.withQueryParams(s.epochIdentitiesCount, identityStatesParam, identityPrevStatesParam))
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Identities")).
-		HandlerFunc(s.epochIdentities)
+		HandlerFunc(s.withQueryParams(s.epochIdentities, identityStatesParam, identityPrevStatesParam))
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/IdentityStatesSummary")).HandlerFunc(s.epochIdentityStatesSummary)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/IdentityStatesInterimSummary")).HandlerFunc(s.epochIdentityStatesInterimSummary)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/InvitesSummary")).HandlerFunc(s.epochInvitesSummary)
@@ -255,6 +453,7 @@ func (s *httpServer) initRouter(router *mux.Router) {
 		HandlerFunc(s.epochTxs)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Coins")).
 		HandlerFunc(s.epochCoins)
+	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Distribution")).HandlerFunc(s.epochDistribution)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/RewardsSummary")).HandlerFunc(s.epochRewardsSummary)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Authors/Bad/Count")).HandlerFunc(s.epochBadAuthorsCount)
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Authors/Bad")).
@@ -301,10 +500,10 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Epoch/{epoch:[0-9]+}/Address/{address}/DelegateeTotalRewards")).HandlerFunc(s.epochAddressDelegateeTotalRewards)
 
 	router.Path(strings.ToLower("/Block/Last")).HandlerFunc(s.lastBlock)
-	router.Path(strings.ToLower("/Block/{id}")).HandlerFunc(s.block)
-	router.Path(strings.ToLower("/Block/{id}/Txs/Count")).HandlerFunc(s.blockTxsCount)
-	router.Path(strings.ToLower("/Block/{id}/Txs")).HandlerFunc(s.blockTxs)
-	router.Path(strings.ToLower("/Block/{id}/Coins")).HandlerFunc(s.blockCoins)
+	router.Path(strings.ToLower("/Block/{block}")).HandlerFunc(s.block)
+	router.Path(strings.ToLower("/Block/{block}/Txs/Count")).HandlerFunc(s.blockTxsCount)
+	router.Path(strings.ToLower("/Block/{block}/Txs")).HandlerFunc(s.blockTxs)
+	router.Path(strings.ToLower("/Block/{block}/Coins")).HandlerFunc(s.blockCoins)
 
 	router.Path(strings.ToLower("/Identity/{address}")).HandlerFunc(s.identity)
 	router.Path(strings.ToLower("/Identity/{address}/Age")).HandlerFunc(s.identityAge)
@@ -328,13 +527,13 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Identity/{address}/Authors/Bad/Count")).HandlerFunc(s.addressBadAuthorsCount)
 	router.Path(strings.ToLower("/Identity/{address}/Authors/Bad")).HandlerFunc(s.addressBadAuthors)
 
-	router.Path(strings.ToLower("/Flip/{hash}")).HandlerFunc(s.flip)
-	router.Path(strings.ToLower("/Flip/{hash}/Content")).HandlerFunc(s.flipContent)
-	router.Path(strings.ToLower("/Flip/{hash}/Answers/Short")).
+	router.Path(strings.ToLower("/Flip/{cid}")).HandlerFunc(s.flip)
+	router.Path(strings.ToLower("/Flip/{cid}/Content")).HandlerFunc(s.flipContent)
+	router.Path(strings.ToLower("/Flip/{cid}/Answers/Short")).
 		HandlerFunc(s.flipShortAnswers)
-	router.Path(strings.ToLower("/Flip/{hash}/Answers/Long")).
+	router.Path(strings.ToLower("/Flip/{cid}/Answers/Long")).
 		HandlerFunc(s.flipLongAnswers)
-	router.Path(strings.ToLower("/Flip/{hash}/Epoch/AdjacentFlips")).HandlerFunc(s.flipEpochAdjacentFlips)
+	router.Path(strings.ToLower("/Flip/{cid}/Epoch/AdjacentFlips")).HandlerFunc(s.flipEpochAdjacentFlips)
 
 	router.Path(strings.ToLower("/Transaction/{hash}")).HandlerFunc(s.transaction)
 	router.Path(strings.ToLower("/Transaction/{hash}/Raw")).HandlerFunc(s.transactionRaw)
@@ -347,10 +546,10 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Address/{address}/Penalties/Count")).HandlerFunc(s.addressPenaltiesCount)
 	router.Path(strings.ToLower("/Address/{address}/Penalties")).HandlerFunc(s.addressPenalties)
 
-	// Deprecated path
-	router.Path(strings.ToLower("/Address/{address}/Flips/Count")).HandlerFunc(s.identityFlipsCount)
-	// Deprecated path
-	router.Path(strings.ToLower("/Address/{address}/Flips")).HandlerFunc(s.identityFlips)
+	s.deprecations.deprecateRoute(router.Path(strings.ToLower("/Address/{address}/Flips/Count")).
+		HandlerFunc(s.identityFlipsCount), "/Identity/{address}/Flips/Count")
+	s.deprecations.deprecateRoute(router.Path(strings.ToLower("/Address/{address}/Flips")).
+		HandlerFunc(s.identityFlips), "/Identity/{address}/Flips")
 
 	router.Path(strings.ToLower("/Address/{address}/States/Count")).HandlerFunc(s.addressStatesCount)
 	router.Path(strings.ToLower("/Address/{address}/States")).HandlerFunc(s.addressStates)
@@ -361,6 +560,8 @@ func (s *httpServer) initRouter(router *mux.Router) {
 
 	router.Path(strings.ToLower("/Address/{address}/Balance/Changes")).HandlerFunc(s.addressBalanceUpdates)
 	router.Path(strings.ToLower("/Address/{address}/Balance/Changes/Summary")).HandlerFunc(s.addressBalanceUpdatesSummary)
+	router.Path(strings.ToLower("/Address/{address}/Balance/At")).HandlerFunc(s.addressBalanceAt)
+	router.Path(strings.ToLower("/Balances/At")).Methods(http.MethodPost).HandlerFunc(s.addressesBalanceAt)
 
 	router.Path(strings.ToLower("/Address/{address}/DelegateeTotalRewards")).HandlerFunc(s.addressDelegateeTotalRewards)
 	router.Path(strings.ToLower("/Address/{address}/MiningRewardSummaries")).HandlerFunc(s.addressMiningRewardSummaries)
@@ -368,7 +569,7 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/Address/{address}/Token/{tokenAddress}")).HandlerFunc(s.addressToken)
 	router.Path(strings.ToLower("/Address/{address}/Delegations")).HandlerFunc(s.addressDelegations)
 
-	router.Path(strings.ToLower("/Balances")).HandlerFunc(s.balances)
+	router.Path(strings.ToLower("/Balances")).HandlerFunc(s.withQueryParams(s.balances, balancesSortByParam))
 	router.Path(strings.ToLower("/Staking")).HandlerFunc(s.staking)
 
 	router.Path(strings.ToLower("/Contract/{address}")).HandlerFunc(s.contract)
@@ -381,8 +582,14 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	router.Path(strings.ToLower("/RefundableOracleLockContract/{address}")).HandlerFunc(s.refundableOracleLockContract)
 	router.Path(strings.ToLower("/MultisigContract/{address}")).HandlerFunc(s.multisigContract)
 
-	router.Path(strings.ToLower("/OracleVotingContracts")).HandlerFunc(s.oracleVotingContracts)
-	router.Path(strings.ToLower("/OracleVotingContract/{address}")).HandlerFunc(s.oracleVotingContract)
+	if s.exports != nil {
+		router.Path(strings.ToLower("/Exports")).Methods(http.MethodPost).HandlerFunc(s.createExport)
+		router.Path(strings.ToLower("/Exports/{id}")).HandlerFunc(s.export)
+		router.Path(strings.ToLower("/Exports/{id}/Download")).HandlerFunc(s.downloadExport)
+	}
+
+	router.Path(strings.ToLower("/OracleVotingContracts")).HandlerFunc(s.withQueryParams(s.oracleVotingContracts, oracleVotingStatesParam, oracleVotingSortByParam, authorParam, oracleParam))
+	router.Path(strings.ToLower("/OracleVotingContract/{address}")).HandlerFunc(s.withQueryParams(s.oracleVotingContract, oracleParam))
 	router.Path(strings.ToLower("/Address/{address}/OracleVotingContracts")).HandlerFunc(s.addressOracleVotingContracts)
 	router.Path(strings.ToLower("/Address/{address}/Contract/{contractAddress}/BalanceUpdates")).HandlerFunc(s.addressContractTxBalanceUpdates)
 	router.Path(strings.ToLower("/OracleVotingContracts/EstimatedOracleRewards")).HandlerFunc(s.estimatedOracleRewards)
@@ -431,13 +638,12 @@ func (s *httpServer) initRouter(router *mux.Router) {
 	if s.dynamicEndpointLoader != nil {
 		router.PathPrefix(strings.ToLower("/Data/")).HandlerFunc(s.data)
 		s.refreshDynamicEndpoints()
-		go s.loopDynamicEndpointsRefreshing()
+		s.lc.Go(s.loopDynamicEndpointsRefreshing)
 	}
 }
 
-func (s *httpServer) loopDynamicEndpointsRefreshing() {
-	for {
-		time.Sleep(time.Minute)
+func (s *httpServer) loopDynamicEndpointsRefreshing(ctx context.Context) {
+	for lifecycle.Sleep(ctx, time.Minute) {
 		s.refreshDynamicEndpoints()
 	}
 }
@@ -453,10 +659,10 @@ func (s *httpServer) data(w http.ResponseWriter, r *http.Request) {
 	dynamicEndpoint, ok := s.dynamicEndpointsByMethod[method]
 	if !ok {
 		w.WriteHeader(http.StatusNotFound)
-		WriteErrorResponse(w, errors.New("unknown method"), s.logger)
+		WriteErrorResponse(w, apierrors.New(apierrors.NotFound, "unknown method"), s.logger)
 		return
 	}
-	res, err := s.service.DynamicEndpointData(dynamicEndpoint.DataSource, dynamicEndpoint.Limit)
+	res, err := s.service.DynamicEndpointData(r.Context(), dynamicEndpoint.DataSource, dynamicEndpoint.Limit)
 	WriteResponse(w, res, err, s.logger)
 }
 
@@ -483,7 +689,7 @@ func (s *httpServer) search(w http.ResponseWriter, r *http.Request) {
 	if addr := keyToAddrOrEmpty(value); len(addr) > 0 {
 		value = addr
 	}
-	resp, err := s.service.Search(value)
+	resp, err := s.service.Search(r.Context(), value)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -511,7 +717,7 @@ func (s *httpServer) coins(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("coins", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Coins()
+	resp, err := s.service.Coins(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -519,7 +725,7 @@ func (s *httpServer) txtTotalSupply(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("coins", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	coins, err := s.service.Coins()
+	coins, err := s.service.Coins(r.Context())
 	var resp string
 	if err == nil {
 		resp = coins.TotalBalance.Add(coins.TotalStake).String()
@@ -543,13 +749,13 @@ func (s *httpServer) circulatingSupply(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	format := strings.ToLower(vars["format"])
 	if len(format) > 0 && format != "short" && format != "full" {
-		WriteErrorResponse(w, errors.Errorf("Unknown value format=%s", format), s.logger)
+		WriteErrorResponse(w, apierrors.Errorf(apierrors.InvalidArgument, "Unknown value format=%s", format), s.logger)
 		return
 	}
 
 	full := "full" == strings.ToLower(vars["format"])
 
-	resp, err := s.service.CirculatingSupply(s.frozenBalanceAddrs)
+	resp, err := s.service.CirculatingSupply(r.Context(), s.frozenBalanceAddrs)
 	if err == nil && full {
 		WriteResponse(w, blockchain.ConvertToInt(resp).String(), err, s.logger)
 		return
@@ -561,7 +767,7 @@ func (s *httpServer) txtCirculatingSupply(w http.ResponseWriter, r *http.Request
 	id := s.pm.Start("circulatingSupply", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	amount, err := s.service.CirculatingSupply(s.frozenBalanceAddrs)
+	amount, err := s.service.CirculatingSupply(r.Context(), s.frozenBalanceAddrs)
 	var resp string
 	if err == nil {
 		resp = amount.String()
@@ -569,6 +775,74 @@ func (s *httpServer) txtCirculatingSupply(w http.ResponseWriter, r *http.Request
 	WriteTextPlainResponse(w, resp, err, s.logger)
 }
 
+// @Tags Coins
+// @Id SupplyHistory
+// @Summary Returns total, circulating (excluding frozen balance addresses) and staked supply at the end of every interval with coins minted and burnt during it
+// @Param interval query string false "interval length" ENUMS(epoch,day)
+// @Param limit query integer true "items to take"
+// @Param continuationToken query string false "continuation token to get next page items"
+// @Success 200 {object} api.ResponsePage{result=[]types.SupplyHistoryItem}
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Failure 503 "Service unavailable"
+// @Router /Supply/History [get]
+func (s *httpServer) supplyHistory(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("supplyHistory", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	interval := db.EpochSupplyInterval
+	if v := strings.ToLower(r.Form.Get("interval")); len(v) > 0 {
+		interval = db.SupplyInterval(v)
+	}
+	if interval != db.EpochSupplyInterval && interval != db.DaySupplyInterval {
+		WriteErrorResponse(w, apierrors.Errorf(apierrors.InvalidArgument, "Unknown value interval=%s", interval), s.logger)
+		return
+	}
+	count, continuationToken, err := s.readPaginatorParams(r)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	resp, nextContinuationToken, err := s.service.SupplyHistory(r.Context(), interval, s.frozenBalanceAddrs, count, continuationToken)
+	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+}
+
+// @Tags Stats
+// @Id StatsSeries
+// @Summary Returns network activity by intervals: tx counts by type, fees, active and new addresses, contract deploys and calls, average block fee rate
+// @Param interval query string false "interval length, weeks start on Monday" ENUMS(hour,day,week)
+// @Param limit query integer true "items to take"
+// @Param continuationToken query string false "continuation token to get next page items"
+// @Success 200 {object} api.ResponsePage{result=[]types.StatsSeriesItem}
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Failure 503 "Service unavailable"
+// @Router /Stats/Series [get]
+func (s *httpServer) statsSeries(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("statsSeries", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	interval := db.DayStatsInterval
+	if v := strings.ToLower(r.Form.Get("interval")); len(v) > 0 {
+		interval = db.StatsInterval(v)
+	}
+	switch interval {
+	case db.HourStatsInterval, db.DayStatsInterval, db.WeekStatsInterval:
+	default:
+		WriteErrorResponse(w, apierrors.Errorf(apierrors.InvalidArgument, "Unknown value interval=%s", interval), s.logger)
+		return
+	}
+	count, continuationToken, err := s.readPaginatorParams(r)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	resp, nextContinuationToken, err := s.service.StatsSeries(r.Context(), interval, count, continuationToken)
+	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+}
+
 // @Tags Upgrades
 // @Id Upgrades
 // @Param limit query integer true "items to take"
@@ -583,12 +857,12 @@ func (s *httpServer) upgrades(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("upgrades", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.Upgrades(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.Upgrades(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -604,7 +878,7 @@ func (s *httpServer) epochsCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("epochsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.EpochsCount()
+	resp, err := s.service.EpochsCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -622,12 +896,12 @@ func (s *httpServer) epochs(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("epochs", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.Epochs(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.Epochs(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -643,7 +917,7 @@ func (s *httpServer) lastEpoch(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("lastEpoch", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.LastEpoch()
+	resp, err := s.service.LastEpoch(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -665,7 +939,7 @@ func (s *httpServer) epoch(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.Epoch(epoch)
+	resp, err := s.service.Epoch(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -687,7 +961,7 @@ func (s *httpServer) epochBlocksCount(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochBlocksCount(epoch)
+	resp, err := s.service.EpochBlocksCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -712,12 +986,12 @@ func (s *httpServer) epochBlocks(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochBlocks(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochBlocks(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -739,7 +1013,7 @@ func (s *httpServer) epochFlipsCount(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochFlipsCount(epoch)
+	resp, err := s.service.EpochFlipsCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -764,12 +1038,12 @@ func (s *httpServer) epochFlips(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochFlips(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochFlips(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -792,7 +1066,7 @@ func (s *httpServer) epochFlipStatesSummary(w http.ResponseWriter, r *http.Reque
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochFlipStatesSummary(epoch)
+	resp, err := s.service.EpochFlipStatesSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -815,7 +1089,7 @@ func (s *httpServer) epochFlipWrongWordsSummary(w http.ResponseWriter, r *http.R
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochFlipWrongWordsSummary(epoch)
+	resp, err := s.service.EpochFlipWrongWordsSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -839,7 +1113,7 @@ func (s *httpServer) epochIdentitiesCount(w http.ResponseWriter, r *http.Request
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentitiesCount(epoch, convertStates(r.Form["prevstates[]"]),
+	resp, err := s.service.EpochIdentitiesCount(r.Context(), epoch, convertStates(r.Form["prevstates[]"]),
 		convertStates(r.Form["states[]"]))
 	WriteResponse(w, resp, err, s.logger)
 }
@@ -867,12 +1141,12 @@ func (s *httpServer) epochIdentities(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochIdentities(epoch, convertStates(r.Form["prevstates[]"]),
+	resp, nextContinuationToken, err := s.service.EpochIdentities(r.Context(), epoch, convertStates(r.Form["prevstates[]"]),
 		convertStates(r.Form["states[]"]), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
@@ -920,7 +1194,7 @@ func (s *httpServer) epochIdentityStatesSummary(w http.ResponseWriter, r *http.R
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityStatesSummary(epoch)
+	resp, err := s.service.EpochIdentityStatesSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -943,7 +1217,7 @@ func (s *httpServer) epochIdentityStatesInterimSummary(w http.ResponseWriter, r
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityStatesInterimSummary(epoch)
+	resp, err := s.service.EpochIdentityStatesInterimSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -966,7 +1240,7 @@ func (s *httpServer) epochInvitesSummary(w http.ResponseWriter, r *http.Request)
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochInvitesSummary(epoch)
+	resp, err := s.service.EpochInvitesSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -989,7 +1263,7 @@ func (s *httpServer) epochInviteStatesSummary(w http.ResponseWriter, r *http.Req
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochInviteStatesSummary(epoch)
+	resp, err := s.service.EpochInviteStatesSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1011,7 +1285,7 @@ func (s *httpServer) epochInvitesCount(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochInvitesCount(epoch)
+	resp, err := s.service.EpochInvitesCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1036,12 +1310,12 @@ func (s *httpServer) epochInvites(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochInvites(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochInvites(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1063,7 +1337,7 @@ func (s *httpServer) epochTxsCount(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochTxsCount(epoch)
+	resp, err := s.service.EpochTxsCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1088,13 +1362,16 @@ func (s *httpServer) epochTxs(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochTxs(epoch, count, continuationToken)
-	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+	s.streamListResponse(w, func(rw db.RowWriter) (*string, error) {
+		return s.service.StreamEpochTxs(r.Context(), epoch, count, continuationToken, rw)
+	}, func() (interface{}, *string, error) {
+		return s.service.EpochTxs(r.Context(), epoch, count, continuationToken)
+	})
 }
 
 // @Tags Epochs
@@ -1116,7 +1393,31 @@ func (s *httpServer) epochCoins(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochCoins(epoch)
+	resp, err := s.service.EpochCoins(r.Context(), epoch)
+	WriteResponse(w, resp, err, s.logger)
+}
+
+// @Tags Epochs
+// @Id EpochDistribution
+// @Summary Returns the distribution of balances and stakes at the end of the epoch excluding frozen balance addresses, the distribution of the current epoch is provisional
+// @Param epoch path integer true "epoch"
+// @Success 200 {object} api.Response{result=types.EpochDistribution}
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Failure 503 "Service unavailable"
+// @Router /Epoch/{epoch}/Distribution [get]
+func (s *httpServer) epochDistribution(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("epochDistribution", r.RequestURI)
+	defer s.pm.Complete(id)
+
+	vars := mux.Vars(r)
+	epoch, err := ReadUint(vars, "epoch")
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	resp, err := s.service.EpochDistribution(r.Context(), epoch, s.frozenBalanceAddrs)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1139,7 +1440,7 @@ func (s *httpServer) epochRewardsSummary(w http.ResponseWriter, r *http.Request)
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochRewardsSummary(epoch)
+	resp, err := s.service.EpochRewardsSummary(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1158,7 +1459,7 @@ func (s *httpServer) epochBadAuthorsCount(w http.ResponseWriter, r *http.Request
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochBadAuthorsCount(epoch)
+	resp, err := s.service.EpochBadAuthorsCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1183,12 +1484,12 @@ func (s *httpServer) epochBadAuthors(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochBadAuthors(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochBadAuthors(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1210,7 +1511,7 @@ func (s *httpServer) epochIdentitiesRewardsCount(w http.ResponseWriter, r *http.
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentitiesRewardsCount(epoch)
+	resp, err := s.service.EpochIdentitiesRewardsCount(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1235,12 +1536,12 @@ func (s *httpServer) epochIdentitiesRewards(w http.ResponseWriter, r *http.Reque
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochIdentitiesRewards(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochIdentitiesRewards(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1263,7 +1564,7 @@ func (s *httpServer) epochFundPayments(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochFundPayments(epoch)
+	resp, err := s.service.EpochFundPayments(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1286,7 +1587,7 @@ func (s *httpServer) epochRewardBounds(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochRewardBounds(epoch)
+	resp, err := s.service.EpochRewardBounds(r.Context(), epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1311,12 +1612,12 @@ func (s *httpServer) epochDelegateeTotalRewards(w http.ResponseWriter, r *http.R
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochDelegateeTotalRewards(epoch, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochDelegateeTotalRewards(r.Context(), epoch, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1340,7 +1641,7 @@ func (s *httpServer) epochIdentity(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentity(epoch, vars["address"])
+	resp, err := s.service.EpochIdentity(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1364,7 +1665,7 @@ func (s *httpServer) epochIdentityShortFlipsToSolve(w http.ResponseWriter, r *ht
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityShortFlipsToSolve(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityShortFlipsToSolve(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1388,7 +1689,7 @@ func (s *httpServer) epochIdentityLongFlipsToSolve(w http.ResponseWriter, r *htt
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityLongFlipsToSolve(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityLongFlipsToSolve(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1412,7 +1713,7 @@ func (s *httpServer) epochIdentityShortAnswers(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityShortAnswers(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityShortAnswers(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1436,7 +1737,7 @@ func (s *httpServer) epochIdentityLongAnswers(w http.ResponseWriter, r *http.Req
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityLongAnswers(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityLongAnswers(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1460,7 +1761,7 @@ func (s *httpServer) epochIdentityFlips(w http.ResponseWriter, r *http.Request)
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityFlips(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityFlips(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1484,7 +1785,7 @@ func (s *httpServer) epochIdentityFlipsWithRewardFlag(w http.ResponseWriter, r *
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityFlipsWithRewardFlag(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityFlipsWithRewardFlag(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1508,7 +1809,7 @@ func (s *httpServer) epochIdentityReportedFlipRewards(w http.ResponseWriter, r *
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityReportedFlipRewards(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityReportedFlipRewards(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1532,7 +1833,7 @@ func (s *httpServer) epochIdentityBadAuthor(w http.ResponseWriter, r *http.Reque
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityBadAuthor(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityBadAuthor(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1556,7 +1857,7 @@ func (s *httpServer) epochIdentityRewards(w http.ResponseWriter, r *http.Request
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityRewards(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityRewards(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1580,7 +1881,7 @@ func (s *httpServer) epochIdentityInvitesWithRewardFlag(w http.ResponseWriter, r
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityInvitesWithRewardFlag(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityInvitesWithRewardFlag(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1604,7 +1905,7 @@ func (s *httpServer) epochIdentitySavedInviteRewards(w http.ResponseWriter, r *h
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentitySavedInviteRewards(epoch, vars["address"])
+	resp, err := s.service.EpochIdentitySavedInviteRewards(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1628,7 +1929,7 @@ func (s *httpServer) epochIdentityAvailableInvites(w http.ResponseWriter, r *htt
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityAvailableInvites(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityAvailableInvites(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1642,7 +1943,7 @@ func (s *httpServer) epochIdentityInviteeWithRewardFlag(w http.ResponseWriter, r
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityInviteeWithRewardFlag(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityInviteeWithRewardFlag(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1666,7 +1967,7 @@ func (s *httpServer) epochIdentityValidationSummary(w http.ResponseWriter, r *ht
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochIdentityValidationSummary(epoch, vars["address"])
+	resp, err := s.service.EpochIdentityValidationSummary(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1689,7 +1990,7 @@ func (s *httpServer) epochIdentityDataWithProof(w http.ResponseWriter, r *http.R
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.IdentityWithProof(vars["address"], epoch)
+	resp, err := s.service.IdentityWithProof(r.Context(), vars["address"], epoch)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1715,12 +2016,12 @@ func (s *httpServer) epochDelegateeRewards(w http.ResponseWriter, r *http.Reques
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.EpochDelegateeRewards(epoch, vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.EpochDelegateeRewards(r.Context(), epoch, vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1744,61 +2045,61 @@ func (s *httpServer) epochAddressDelegateeTotalRewards(w http.ResponseWriter, r
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.EpochAddressDelegateeTotalRewards(epoch, vars["address"])
+	resp, err := s.service.EpochAddressDelegateeTotalRewards(r.Context(), epoch, vars["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 // @Tags Block
 // @Id Block
-// @Param id path string true "block hash or height"
+// @Param block path string true "block hash or height"
 // @Success 200 {object} api.Response{result=types.BlockDetail}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Block/{id} [get]
+// @Router /Block/{block} [get]
 func (s *httpServer) block(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("block", r.RequestURI)
 	defer s.pm.Complete(id)
 
 	var resp interface{}
 	vars := mux.Vars(r)
-	height, err := ReadUint(vars, "id")
+	height, err := ReadUint(vars, "block")
 	if err != nil {
-		resp, err = s.service.BlockByHash(vars["id"])
+		resp, err = s.service.BlockByHash(r.Context(), vars["block"])
 	} else {
-		resp, err = s.service.BlockByHeight(height)
+		resp, err = s.service.BlockByHeight(r.Context(), height)
 	}
 	WriteResponse(w, resp, err, s.logger)
 }
 
 // @Tags Block
 // @Id BlockTxsCount
-// @Param id path string true "block hash or height"
+// @Param block path string true "block hash or height"
 // @Success 200 {object} api.Response{result=integer}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Block/{id}/Txs/Count [get]
+// @Router /Block/{block}/Txs/Count [get]
 func (s *httpServer) blockTxsCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("blockTxsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
 	var resp interface{}
 	vars := mux.Vars(r)
-	height, err := ReadUint(vars, "id")
+	height, err := ReadUint(vars, "block")
 	if err != nil {
-		resp, err = s.service.BlockTxsCountByHash(vars["id"])
+		resp, err = s.service.BlockTxsCountByHash(r.Context(), vars["block"])
 	} else {
-		resp, err = s.service.BlockTxsCountByHeight(height)
+		resp, err = s.service.BlockTxsCountByHeight(r.Context(), height)
 	}
 	WriteResponse(w, resp, err, s.logger)
 }
 
 // @Tags Block
 // @Id BlockTxs
-// @Param id path string true "block hash or height"
+// @Param block path string true "block hash or height"
 // @Param limit query integer true "items to take"
 // @Param continuationToken query string false "continuation token to get next page items"
 // @Success 200 {object} api.ResponsePage{result=[]types.TransactionSummary{data=types.TransactionSpecificData}}
@@ -1806,48 +2107,54 @@ func (s *httpServer) blockTxsCount(w http.ResponseWriter, r *http.Request) {
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Block/{id}/Txs [get]
+// @Router /Block/{block}/Txs [get]
 func (s *httpServer) blockTxs(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("blockTxs", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	height, err := ReadUint(vars, "id")
-	var resp interface{}
-	var nextContinuationToken *string
+	height, err := ReadUint(vars, "block")
 	if err != nil {
-		resp, nextContinuationToken, err = s.service.BlockTxsByHash(vars["id"], count, continuationToken)
-	} else {
-		resp, nextContinuationToken, err = s.service.BlockTxsByHeight(height, count, continuationToken)
+		hash := vars["block"]
+		s.streamListResponse(w, func(rw db.RowWriter) (*string, error) {
+			return s.service.StreamBlockTxsByHash(r.Context(), hash, count, continuationToken, rw)
+		}, func() (interface{}, *string, error) {
+			return s.service.BlockTxsByHash(r.Context(), hash, count, continuationToken)
+		})
+		return
 	}
-	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+	s.streamListResponse(w, func(rw db.RowWriter) (*string, error) {
+		return s.service.StreamBlockTxsByHeight(r.Context(), height, count, continuationToken, rw)
+	}, func() (interface{}, *string, error) {
+		return s.service.BlockTxsByHeight(r.Context(), height, count, continuationToken)
+	})
 }
 
 // @Tags Block
 // @Id BlockCoins
-// @Param id path string true "block hash or height"
+// @Param block path string true "block hash or height"
 // @Success 200 {object} api.Response{result=types.AllCoins}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Block/{id}/Coins [get]
+// @Router /Block/{block}/Coins [get]
 func (s *httpServer) blockCoins(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("blockCoins", r.RequestURI)
 	defer s.pm.Complete(id)
 
 	var resp interface{}
 	vars := mux.Vars(r)
-	height, err := ReadUint(vars, "id")
+	height, err := ReadUint(vars, "block")
 	if err != nil {
-		resp, err = s.service.BlockCoinsByHash(vars["id"])
+		resp, err = s.service.BlockCoinsByHash(r.Context(), vars["block"])
 	} else {
-		resp, err = s.service.BlockCoinsByHeight(height)
+		resp, err = s.service.BlockCoinsByHeight(r.Context(), height)
 	}
 	WriteResponse(w, resp, err, s.logger)
 }
@@ -1864,7 +2171,7 @@ func (s *httpServer) lastBlock(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("lastBlock", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.LastBlock()
+	resp, err := s.service.LastBlock(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1881,7 +2188,7 @@ func (s *httpServer) identity(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identity", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Identity(mux.Vars(r)["address"])
+	resp, err := s.service.Identity(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1898,7 +2205,7 @@ func (s *httpServer) identityAge(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityAge", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityAge(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityAge(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1915,7 +2222,7 @@ func (s *httpServer) identityCurrentFlipCids(w http.ResponseWriter, r *http.Requ
 	id := s.pm.Start("identityCurrentFlipCids", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityCurrentFlipCids(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityCurrentFlipCids(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1932,7 +2239,7 @@ func (s *httpServer) identityEpochsCount(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("identityEpochsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityEpochsCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityEpochsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1950,13 +2257,13 @@ func (s *httpServer) identityEpochsCount(w http.ResponseWriter, r *http.Request)
 func (s *httpServer) identityEpochs(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityEpochs", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityEpochs(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.IdentityEpochs(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -1973,7 +2280,7 @@ func (s *httpServer) identityFlipsCount(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("identityFlipsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityFlipsCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityFlipsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -1991,13 +2298,13 @@ func (s *httpServer) identityFlipsCount(w http.ResponseWriter, r *http.Request)
 func (s *httpServer) identityFlips(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityFlips", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityFlips(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.IdentityFlips(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2014,7 +2321,7 @@ func (s *httpServer) identityFlipStates(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("identityFlipStates", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityFlipStates(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityFlipStates(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2031,7 +2338,7 @@ func (s *httpServer) identityFlipRightAnswers(w http.ResponseWriter, r *http.Req
 	id := s.pm.Start("identityFlipRightAnswers", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityFlipQualifiedAnswers(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityFlipQualifiedAnswers(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2048,7 +2355,7 @@ func (s *httpServer) identityInvitesCount(w http.ResponseWriter, r *http.Request
 	id := s.pm.Start("identityInvitesCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityInvitesCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityInvitesCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2066,13 +2373,13 @@ func (s *httpServer) identityInvitesCount(w http.ResponseWriter, r *http.Request
 func (s *httpServer) identityInvites(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityInvites", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityInvites(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.IdentityInvites(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2089,7 +2396,7 @@ func (s *httpServer) identityTxsCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityTxsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityTxsCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityTxsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2107,14 +2414,17 @@ func (s *httpServer) identityTxsCount(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) identityTxs(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityTxs", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityTxs(vars["address"], count, continuationToken)
-	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
+	address := mux.Vars(r)["address"]
+	s.streamListResponse(w, func(rw db.RowWriter) (*string, error) {
+		return s.service.StreamIdentityTxs(r.Context(), address, count, continuationToken, rw)
+	}, func() (interface{}, *string, error) {
+		return s.service.IdentityTxs(r.Context(), address, count, continuationToken)
+	})
 }
 
 // @Tags Identity
@@ -2130,7 +2440,7 @@ func (s *httpServer) identityRewardsCount(w http.ResponseWriter, r *http.Request
 	id := s.pm.Start("identityRewardsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityRewardsCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityRewardsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2148,13 +2458,13 @@ func (s *httpServer) identityRewardsCount(w http.ResponseWriter, r *http.Request
 func (s *httpServer) identityRewards(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityRewards", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityRewards(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.IdentityRewards(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2171,7 +2481,7 @@ func (s *httpServer) identityEpochRewardsCount(w http.ResponseWriter, r *http.Re
 	id := s.pm.Start("identityEpochRewardsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.IdentityEpochRewardsCount(mux.Vars(r)["address"])
+	resp, err := s.service.IdentityEpochRewardsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2189,59 +2499,62 @@ func (s *httpServer) identityEpochRewardsCount(w http.ResponseWriter, r *http.Re
 func (s *httpServer) identityEpochRewards(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("identityEpochRewards", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.IdentityEpochRewards(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.IdentityEpochRewards(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 // @Tags Flip
 // @Id Flip
-// @Param hash path string true "flip hash"
+// @Param cid path string true "flip cid"
 // @Success 200 {object} api.Response{result=types.Flip}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Flip/{hash} [get]
+// @Router /Flip/{cid} [get]
 func (s *httpServer) flip(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("flip", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Flip(mux.Vars(r)["hash"])
+	resp, err := s.service.Flip(r.Context(), mux.Vars(r)["cid"])
+	if err == nil && s.isEpochFinished(r.Context(), resp.Epoch) {
+		setCacheClass(w, immutableData)
+	}
 	WriteResponse(w, resp, err, s.logger)
 }
 
 // @Tags Flip
 // @Id FlipContent
-// @Param hash path string true "flip hash"
+// @Param cid path string true "flip cid"
 // @Success 200 {object} api.Response{result=types.FlipContent}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Flip/{hash}/Content [get]
+// @Router /Flip/{cid}/Content [get]
 func (s *httpServer) flipContent(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("flipContent", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.FlipContent(mux.Vars(r)["hash"])
+	resp, err := s.service.FlipContent(r.Context(), mux.Vars(r)["cid"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 // @Tags Flip
 // @Id FlipShortAnswers
-// @Param hash path string true "flip hash"
+// @Param cid path string true "flip cid"
 // @Success 200 {object} api.Response{result=[]types.Answer}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Flip/{hash}/Answers/Short [get]
+// @Router /Flip/{cid}/Answers/Short [get]
 func (s *httpServer) flipShortAnswers(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("flipShortAnswers", r.RequestURI)
 	defer s.pm.Complete(id)
@@ -2251,13 +2564,13 @@ func (s *httpServer) flipShortAnswers(w http.ResponseWriter, r *http.Request) {
 
 // @Tags Flip
 // @Id FlipLongAnswers
-// @Param hash path string true "flip hash"
+// @Param cid path string true "flip cid"
 // @Success 200 {object} api.Response{result=[]types.Answer}
 // @Failure 400 "Bad request"
 // @Failure 429 "Request number limit exceeded"
 // @Failure 500 "Internal server error"
 // @Failure 503 "Service unavailable"
-// @Router /Flip/{hash}/Answers/Long [get]
+// @Router /Flip/{cid}/Answers/Long [get]
 func (s *httpServer) flipLongAnswers(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("flipLongAnswers", r.RequestURI)
 	defer s.pm.Complete(id)
@@ -2267,7 +2580,7 @@ func (s *httpServer) flipLongAnswers(w http.ResponseWriter, r *http.Request) {
 
 func (s *httpServer) flipAnswers(w http.ResponseWriter, r *http.Request, isShort bool) {
 	vars := mux.Vars(r)
-	resp, err := s.service.FlipAnswers(vars["hash"], isShort)
+	resp, err := s.service.FlipAnswers(r.Context(), vars["cid"], isShort)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2275,7 +2588,7 @@ func (s *httpServer) flipEpochAdjacentFlips(w http.ResponseWriter, r *http.Reque
 	id := s.pm.Start("flipEpochAdjacentFlips", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.FlipEpochAdjacentFlips(mux.Vars(r)["hash"])
+	resp, err := s.service.FlipEpochAdjacentFlips(r.Context(), mux.Vars(r)["cid"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2292,7 +2605,7 @@ func (s *httpServer) address(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("address", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Address(mux.Vars(r)["address"])
+	resp, err := s.service.Address(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2309,7 +2622,7 @@ func (s *httpServer) addressPenaltiesCount(w http.ResponseWriter, r *http.Reques
 	id := s.pm.Start("addressPenaltiesCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressPenaltiesCount(mux.Vars(r)["address"])
+	resp, err := s.service.AddressPenaltiesCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2327,13 +2640,13 @@ func (s *httpServer) addressPenaltiesCount(w http.ResponseWriter, r *http.Reques
 func (s *httpServer) addressPenalties(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressPenalties", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressPenalties(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressPenalties(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2341,20 +2654,20 @@ func (s *httpServer) addressStatesCount(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("addressStatesCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressStatesCount(mux.Vars(r)["address"])
+	resp, err := s.service.AddressStatesCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) addressStates(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressStates", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressStates(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressStates(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2362,7 +2675,7 @@ func (s *httpServer) addressTotalLatestMiningReward(w http.ResponseWriter, r *ht
 	id := s.pm.Start("addressTotalLatestMiningReward", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressTotalLatestMiningReward(s.getOffsetUTC(), mux.Vars(r)["address"])
+	resp, err := s.service.AddressTotalLatestMiningReward(r.Context(), s.getOffsetUTC(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2370,7 +2683,7 @@ func (s *httpServer) addressTotalLatestBurntCoins(w http.ResponseWriter, r *http
 	id := s.pm.Start("addressTotalLatestBurntCoins", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressTotalLatestBurntCoins(s.getOffsetUTC(), mux.Vars(r)["address"])
+	resp, err := s.service.AddressTotalLatestBurntCoins(r.Context(), s.getOffsetUTC(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2378,20 +2691,20 @@ func (s *httpServer) addressBadAuthorsCount(w http.ResponseWriter, r *http.Reque
 	id := s.pm.Start("addressBadAuthorsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressBadAuthorsCount(mux.Vars(r)["address"])
+	resp, err := s.service.AddressBadAuthorsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) addressBadAuthors(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressBadAuthors", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressBadAuthors(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressBadAuthors(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2399,20 +2712,20 @@ func (s *httpServer) addressBalanceUpdatesCount(w http.ResponseWriter, r *http.R
 	id := s.pm.Start("addressBalanceUpdatesCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.AddressBalanceUpdatesCount(mux.Vars(r)["address"])
+	resp, err := s.service.AddressBalanceUpdatesCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) addressBalanceUpdates(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressBalanceUpdates", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressBalanceUpdates(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressBalanceUpdates(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2429,7 +2742,87 @@ func (s *httpServer) addressBalanceUpdatesSummary(w http.ResponseWriter, r *http
 	id := s.pm.Start("addressBalanceUpdatesSummary", r.RequestURI)
 	defer s.pm.Complete(id)
 	vars := mux.Vars(r)
-	resp, err := s.service.AddressBalanceUpdatesSummary(vars["address"])
+	resp, err := s.service.AddressBalanceUpdatesSummary(r.Context(), vars["address"])
+	WriteResponse(w, resp, err, s.logger)
+}
+
+const maxBalancesAtAddresses = 100
+
+// readBalancePoint reads either the block height or the timestamp given as unix seconds or RFC3339
+func readBalancePoint(params url.Values) (uint64, *time.Time, error) {
+	if v := params.Get("timestamp"); len(v) > 0 {
+		if len(params.Get("height")) > 0 {
+			return 0, nil, apierrors.New(apierrors.InvalidArgument, "height and timestamp cannot be used together")
+		}
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			timestamp := time.Unix(seconds, 0).UTC()
+			return 0, &timestamp, nil
+		}
+		timestamp, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return 0, nil, apierrors.Errorf(apierrors.InvalidArgument, "wrong value timestamp=%v", v)
+		}
+		timestamp = timestamp.UTC()
+		return 0, &timestamp, nil
+	}
+	height, err := ReadUintUrlValue(params, "height")
+	return height, nil, err
+}
+
+// @Tags Address
+// @Id AddressBalanceAt
+// @Summary Returns the balance and stake after the block with the height or at the timestamp
+// @Param address path string true "address"
+// @Param height query integer false "block height"
+// @Param timestamp query string false "unix seconds or RFC3339 time, used instead of height"
+// @Success 200 {object} api.Response{result=types.BalanceAt}
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Failure 503 "Service unavailable"
+// @Router /Address/{address}/Balance/At [get]
+func (s *httpServer) addressBalanceAt(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("addressBalanceAt", r.RequestURI)
+	defer s.pm.Complete(id)
+	height, timestamp, err := readBalancePoint(r.Form)
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	resp, err := s.service.AddressBalanceAt(r.Context(), mux.Vars(r)["address"], height, timestamp)
+	WriteResponse(w, resp, err, s.logger)
+}
+
+// @Tags Address
+// @Id AddressesBalanceAt
+// @Summary Returns balances and stakes of up to 100 addresses after the block with the height
+// @Accept json
+// @Param height query integer true "block height"
+// @Param addresses body []string true "addresses"
+// @Success 200 {object} api.Response{result=[]types.BalanceAt}
+// @Failure 400 "Bad request"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Failure 503 "Service unavailable"
+// @Router /Balances/At [post]
+func (s *httpServer) addressesBalanceAt(w http.ResponseWriter, r *http.Request) {
+	id := s.pm.Start("addressesBalanceAt", r.RequestURI)
+	defer s.pm.Complete(id)
+	height, err := ReadUintUrlValue(r.Form, "height")
+	if err != nil {
+		WriteErrorResponse(w, err, s.logger)
+		return
+	}
+	var addresses []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchRequestSize)).Decode(&addresses); err != nil {
+		WriteErrorResponse(w, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "unable to parse addresses")), s.logger)
+		return
+	}
+	if len(addresses) == 0 || len(addresses) > maxBalancesAtAddresses {
+		WriteErrorResponse(w, apierrors.Errorf(apierrors.InvalidArgument, "addresses count must be from 1 to %d", maxBalancesAtAddresses), s.logger)
+		return
+	}
+	resp, err := s.service.AddressesBalanceAt(r.Context(), addresses, height)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2448,13 +2841,13 @@ func (s *httpServer) addressBalanceUpdatesSummary(w http.ResponseWriter, r *http
 func (s *httpServer) addressDelegateeTotalRewards(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressDelegateeTotalRewards", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressDelegateeTotalRewards(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressDelegateeTotalRewards(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2472,13 +2865,13 @@ func (s *httpServer) addressDelegateeTotalRewards(w http.ResponseWriter, r *http
 func (s *httpServer) addressMiningRewardSummaries(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressMiningRewardSummaries", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressMiningRewardSummaries(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressMiningRewardSummaries(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2497,13 +2890,13 @@ func (s *httpServer) addressMiningRewardSummaries(w http.ResponseWriter, r *http
 func (s *httpServer) addressTokens(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressTokens", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressTokens(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressTokens(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2523,7 +2916,7 @@ func (s *httpServer) addressToken(w http.ResponseWriter, r *http.Request) {
 	defer s.pm.Complete(id)
 
 	vars := mux.Vars(r)
-	resp, err := s.service.AddressToken(vars["address"], vars["tokenaddress"])
+	resp, err := s.service.AddressToken(r.Context(), vars["address"], vars["tokenaddress"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2541,13 +2934,13 @@ func (s *httpServer) addressToken(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) addressDelegations(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressDelegations", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.AddressDelegations(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressDelegations(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2564,7 +2957,11 @@ func (s *httpServer) transaction(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("transaction", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Transaction(mux.Vars(r)["hash"])
+	resp, err := s.service.Transaction(r.Context(), mux.Vars(r)["hash"])
+	if err == nil && (resp == nil || resp.BlockHeight == 0) {
+		// Mem pool transaction
+		setCacheClass(w, volatileData)
+	}
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2581,7 +2978,7 @@ func (s *httpServer) transactionRaw(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("transactionRaw", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.TransactionRaw(mux.Vars(r)["hash"])
+	resp, err := s.service.TransactionRaw(r.Context(), mux.Vars(r)["hash"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2599,12 +2996,12 @@ func (s *httpServer) transactionRaw(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) transactionEvents(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("transactionEvents", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.TransactionEvents(mux.Vars(r)["hash"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.TransactionEvents(r.Context(), mux.Vars(r)["hash"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2612,7 +3009,7 @@ func (s *httpServer) balancesCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("balancesCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.BalancesCount()
+	resp, err := s.service.BalancesCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2630,7 +3027,7 @@ func (s *httpServer) balancesCount(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) balances(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("balances", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
@@ -2639,7 +3036,7 @@ func (s *httpServer) balances(w http.ResponseWriter, r *http.Request) {
 	if v := r.Form.Get("sortby"); len(v) > 0 {
 		sortBy = &v
 	}
-	resp, nextContinuationToken, err := s.service.Balances(sortBy, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.Balances(r.Context(), sortBy, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2654,7 +3051,7 @@ func (s *httpServer) balances(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) staking(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("staking", r.RequestURI)
 	defer s.pm.Complete(id)
-	resp, err := s.service.Staking()
+	resp, err := s.service.Staking(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2671,12 +3068,12 @@ func (s *httpServer) memPoolTxs(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("memPoolTxs", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, _, err := ReadPaginatorParams(r.Form)
+	count, _, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.MemPoolTxs(count)
+	resp, err := s.service.MemPoolTxs(r.Context(), count)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2691,7 +3088,7 @@ func (s *httpServer) memPoolTxs(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) memPoolTxsCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("memPoolTxsCount", r.RequestURI)
 	defer s.pm.Complete(id)
-	resp, err := s.service.MemPoolTxsCount()
+	resp, err := s.service.MemPoolTxsCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2721,7 +3118,7 @@ func getOffsetUTC(hours int) time.Time {
 func (s *httpServer) oracleVotingContracts(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("oracleVotingContracts", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
@@ -2743,7 +3140,7 @@ func (s *httpServer) oracleVotingContracts(w http.ResponseWriter, r *http.Reques
 	if v := r.Form.Get("sortby"); len(v) > 0 {
 		sortBy = &v
 	}
-	resp, nextContinuationToken, err := s.contractsService.OracleVotingContracts(getFormValue(r.Form, "author"),
+	resp, nextContinuationToken, err := s.contractsService.OracleVotingContracts(r.Context(), getFormValue(r.Form, "author"),
 		getFormValue(r.Form, "oracle"), states, all, sortBy, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
@@ -2763,13 +3160,13 @@ func (s *httpServer) oracleVotingContracts(w http.ResponseWriter, r *http.Reques
 func (s *httpServer) addressOracleVotingContracts(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressOracleVotingContracts", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	address := mux.Vars(r)["address"]
-	resp, nextContinuationToken, err := s.service.AddressOracleVotingContracts(address, count, continuationToken)
+	resp, nextContinuationToken, err := s.service.AddressOracleVotingContracts(r.Context(), address, count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2786,7 +3183,7 @@ func (s *httpServer) timeLockContract(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("timeLockContract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.TimeLockContract(mux.Vars(r)["address"])
+	resp, err := s.service.TimeLockContract(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2803,7 +3200,7 @@ func (s *httpServer) oracleLockContract(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("oracleLockContract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.OracleLockContract(mux.Vars(r)["address"])
+	resp, err := s.service.OracleLockContract(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2820,7 +3217,7 @@ func (s *httpServer) refundableOracleLockContract(w http.ResponseWriter, r *http
 	id := s.pm.Start("refundableOracleLockContract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.RefundableOracleLockContract(mux.Vars(r)["address"])
+	resp, err := s.service.RefundableOracleLockContract(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2838,7 +3235,7 @@ func (s *httpServer) oracleVotingContract(w http.ResponseWriter, r *http.Request
 	id := s.pm.Start("oracleVotingContract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.contractsService.OracleVotingContract(mux.Vars(r)["address"], r.Form.Get("oracle"))
+	resp, err := s.contractsService.OracleVotingContract(r.Context(), mux.Vars(r)["address"], r.Form.Get("oracle"))
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2855,7 +3252,7 @@ func (s *httpServer) multisigContract(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("multisigContract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.MultisigContract(mux.Vars(r)["address"])
+	resp, err := s.service.MultisigContract(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2870,7 +3267,7 @@ func (s *httpServer) multisigContract(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) estimatedOracleRewards(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("estimatedOracleRewards", r.RequestURI)
 	defer s.pm.Complete(id)
-	resp, err := s.service.EstimatedOracleRewards()
+	resp, err := s.service.EstimatedOracleRewards(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2890,13 +3287,13 @@ func (s *httpServer) estimatedOracleRewards(w http.ResponseWriter, r *http.Reque
 func (s *httpServer) addressContractTxBalanceUpdates(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("addressContractTxBalanceUpdates", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.contractsService.AddressContractTxBalanceUpdates(vars["address"], vars["contractaddress"], count, continuationToken)
+	resp, nextContinuationToken, err := s.contractsService.AddressContractTxBalanceUpdates(r.Context(), vars["address"], vars["contractaddress"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -2913,7 +3310,7 @@ func (s *httpServer) contract(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("contract", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Contract(mux.Vars(r)["address"])
+	resp, err := s.service.Contract(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -2923,17 +3320,17 @@ func (s *httpServer) verifyContract(w http.ResponseWriter, r *http.Request) {
 
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		WriteResponse(w, nil, errors.Wrap(err, "failed to read request data"), s.logger)
+		WriteResponse(w, nil, apierrors.Wrap(apierrors.InvalidArgument, errors.Wrap(err, "failed to read request data")), s.logger)
 		return
 	}
 
 	if len(data) >= s.contractSizeLimit {
-		WriteResponse(w, nil, errors.New("too large file"), s.logger)
+		WriteResponse(w, nil, apierrors.New(apierrors.InvalidArgument, "too large file"), s.logger)
 		return
 	}
 
 	address := mux.Vars(r)["address"]
-	err = s.service.VerifyContract(address, data)
+	err = s.service.VerifyContract(r.Context(), address, data)
 	WriteResponse(w, nil, err, s.logger)
 }
 
@@ -2942,7 +3339,7 @@ func (s *httpServer) downloadVerifiedCodeFile(w http.ResponseWriter, r *http.Req
 	defer s.pm.Complete(id)
 
 	address := mux.Vars(r)["address"]
-	fileData, err := s.service.ContractVerifiedCodeFile(address)
+	fileData, err := s.service.ContractVerifiedCodeFile(r.Context(), address)
 	WriteFileResponse(w, types.DefaultContractVerifiedCodeFile(address), fileData, err, s.logger)
 }
 
@@ -2960,38 +3357,38 @@ func (s *httpServer) downloadVerifiedCodeFile(w http.ResponseWriter, r *http.Req
 func (s *httpServer) contractTxBalanceUpdates(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("contractTxBalanceUpdates", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.contractsService.ContractTxBalanceUpdates(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.contractsService.ContractTxBalanceUpdates(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 func (s *httpServer) onlineIdentitiesCount(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.GetOnlineIdentitiesCount()
+	resp, err := s.service.GetOnlineIdentitiesCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) onlineIdentities(w http.ResponseWriter, r *http.Request) {
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.GetOnlineIdentities(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.GetOnlineIdentities(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 func (s *httpServer) onlineIdentity(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.GetOnlineIdentity(mux.Vars(r)["address"])
+	resp, err := s.service.GetOnlineIdentity(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) onlineCount(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.GetOnlineCount()
+	resp, err := s.service.GetOnlineCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3006,7 +3403,7 @@ func (s *httpServer) onlineCount(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) minersHistory(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("minersHistory", r.RequestURI)
 	defer s.pm.Complete(id)
-	resp, err := s.service.MinersHistory()
+	resp, err := s.service.MinersHistory(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3027,7 +3424,7 @@ func (s *httpServer) peersHistory(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.PeersHistory(count)
+	resp, err := s.service.PeersHistory(r.Context(), count)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3047,44 +3444,44 @@ func readPeersHistoryCount(params url.Values) (uint64, error) {
 }
 
 func (s *httpServer) validatorsCount(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.ValidatorsCount()
+	resp, err := s.service.ValidatorsCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) validators(w http.ResponseWriter, r *http.Request) {
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.Validators(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.Validators(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 func (s *httpServer) onlineValidatorsCount(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.OnlineValidatorsCount()
+	resp, err := s.service.OnlineValidatorsCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) onlineValidators(w http.ResponseWriter, r *http.Request) {
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.OnlineValidators(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.OnlineValidators(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 func (s *httpServer) forkCommitteeCount(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.ForkCommitteeCount()
+	resp, err := s.service.ForkCommitteeCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
 func (s *httpServer) signatureAddress(w http.ResponseWriter, r *http.Request) {
 	value := mux.Vars(r)["value"]
 	signature := mux.Vars(r)["signature"]
-	resp, err := s.service.SignatureAddress(value, signature)
+	resp, err := s.service.SignatureAddress(r.Context(), value, signature)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3092,17 +3489,17 @@ func (s *httpServer) upgradeVotings(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("upgradeVotings", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.UpgradeVotings(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.UpgradeVotings(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
 func (s *httpServer) upgradeVoting(w http.ResponseWriter, r *http.Request) {
-	resp, err := s.service.UpgradeVoting()
+	resp, err := s.service.UpgradeVoting(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3115,7 +3512,7 @@ func (s *httpServer) upgradeVotingHistory(w http.ResponseWriter, r *http.Request
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.UpgradeVotingHistory(upgrade)
+	resp, err := s.service.UpgradeVotingHistory(r.Context(), upgrade)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3128,7 +3525,7 @@ func (s *httpServer) upgrade(w http.ResponseWriter, r *http.Request) {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, err := s.service.Upgrade(upgrade)
+	resp, err := s.service.Upgrade(r.Context(), upgrade)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3137,7 +3534,7 @@ func (s *httpServer) forkChangeLog(w http.ResponseWriter, r *http.Request) {
 	defer s.pm.Complete(id)
 
 	version := mux.Vars(r)["version"]
-	resp, err := s.service.ForkChangeLog(version)
+	resp, err := s.service.ForkChangeLog(r.Context(), version)
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3157,7 +3554,7 @@ func (s *httpServer) poolsCount(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("poolsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.PoolsCount()
+	resp, err := s.service.PoolsCount(r.Context())
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3175,12 +3572,12 @@ func (s *httpServer) pools(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("pools", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.Pools(count, continuationToken)
+	resp, nextContinuationToken, err := s.service.Pools(r.Context(), count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -3197,7 +3594,7 @@ func (s *httpServer) pool(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("pool", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Pool(mux.Vars(r)["address"])
+	resp, err := s.service.Pool(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3214,7 +3611,7 @@ func (s *httpServer) poolDelegatorsCount(w http.ResponseWriter, r *http.Request)
 	id := s.pm.Start("poolDelegatorsCount", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.PoolDelegatorsCount(mux.Vars(r)["address"])
+	resp, err := s.service.PoolDelegatorsCount(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3232,13 +3629,13 @@ func (s *httpServer) poolDelegatorsCount(w http.ResponseWriter, r *http.Request)
 func (s *httpServer) poolDelegators(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("poolDelegators", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.PoolDelegators(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.PoolDelegators(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -3256,13 +3653,13 @@ func (s *httpServer) poolDelegators(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) poolSizeHistory(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("poolSizeHistory", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
 	vars := mux.Vars(r)
-	resp, nextContinuationToken, err := s.service.PoolSizeHistory(vars["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.PoolSizeHistory(r.Context(), vars["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
 
@@ -3279,7 +3676,7 @@ func (s *httpServer) token(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("token", r.RequestURI)
 	defer s.pm.Complete(id)
 
-	resp, err := s.service.Token(mux.Vars(r)["address"])
+	resp, err := s.service.Token(r.Context(), mux.Vars(r)["address"])
 	WriteResponse(w, resp, err, s.logger)
 }
 
@@ -3297,11 +3694,11 @@ func (s *httpServer) token(w http.ResponseWriter, r *http.Request) {
 func (s *httpServer) tokenHolders(w http.ResponseWriter, r *http.Request) {
 	id := s.pm.Start("tokenHolders", r.RequestURI)
 	defer s.pm.Complete(id)
-	count, continuationToken, err := ReadPaginatorParams(r.Form)
+	count, continuationToken, err := s.readPaginatorParams(r)
 	if err != nil {
 		WriteErrorResponse(w, err, s.logger)
 		return
 	}
-	resp, nextContinuationToken, err := s.service.TokenHolders(mux.Vars(r)["address"], count, continuationToken)
+	resp, nextContinuationToken, err := s.service.TokenHolders(r.Context(), mux.Vars(r)["address"], count, continuationToken)
 	WriteResponsePage(w, resp, nextContinuationToken, err, s.logger)
 }
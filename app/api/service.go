@@ -1,75 +1,136 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-go/crypto"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
 	service2 "github.com/idena-network/idena-indexer-api/app/service"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/idena-network/idena-indexer-api/indexer"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"time"
 )
 
 type Service interface {
 	db.Accessor
 
-	MemPoolTxs(count uint64) ([]*types.TransactionSummary, error)
-	MemPoolTxsCount() (int, error)
-	GetOnlineIdentitiesCount() (uint64, error)
-	GetOnlineIdentities(count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error)
-	GetOnlineIdentity(address string) (*types.OnlineIdentity, error)
-	GetOnlineCount() (uint64, error)
-	ValidatorsCount() (uint64, error)
-	Validators(count uint64, continuationToken *string) ([]types.Validator, *string, error)
-	OnlineValidatorsCount() (uint64, error)
-	OnlineValidators(count uint64, continuationToken *string) ([]types.Validator, *string, error)
-	ForkCommitteeCount() (uint64, error)
-	SignatureAddress(value, signature string) (string, error)
-	UpgradeVoting() ([]*types.UpgradeVotes, error)
-	Staking() (*types.Staking, error)
-
-	IdentityWithProof(address string, height uint64) (*hexutil.Bytes, error)
-
-	ForkChangeLog(version string) (*service2.ChangeLogData, error)
-
-	Upgrades(count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error)
-	UpgradeVotings(count uint64, continuationToken *string) ([]types.Upgrade, *string, error)
-	Upgrade(upgrade uint64) (*types.Upgrade, error)
-
-	VerifyContract(address string, data []byte) error
+	MemPoolTxs(ctx context.Context, count uint64) ([]*types.TransactionSummary, error)
+	MemPoolTxsCount(ctx context.Context) (int, error)
+	GetOnlineIdentitiesCount(ctx context.Context) (uint64, error)
+	GetOnlineIdentities(ctx context.Context, count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error)
+	GetOnlineIdentity(ctx context.Context, address string) (*types.OnlineIdentity, error)
+	GetOnlineCount(ctx context.Context) (uint64, error)
+	ValidatorsCount(ctx context.Context) (uint64, error)
+	Validators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error)
+	OnlineValidatorsCount(ctx context.Context) (uint64, error)
+	OnlineValidators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error)
+	ForkCommitteeCount(ctx context.Context) (uint64, error)
+	SignatureAddress(ctx context.Context, value, signature string) (string, error)
+	UpgradeVoting(ctx context.Context) ([]*types.UpgradeVotes, error)
+	Staking(ctx context.Context) (*types.Staking, error)
+
+	IdentityWithProof(ctx context.Context, address string, height uint64) (*hexutil.Bytes, error)
+
+	ForkChangeLog(ctx context.Context, version string) (*service2.ChangeLogData, error)
+
+	Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error)
+	UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error)
+	Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error)
+
+	// AddressBalanceAt returns the balance and stake after the block with the height or the last block before the timestamp
+	AddressBalanceAt(ctx context.Context, address string, height uint64, timestamp *time.Time) (*types.BalanceAt, error)
+	AddressesBalanceAt(ctx context.Context, addresses []string, height uint64) ([]*types.BalanceAt, error)
+
+	VerifyContract(ctx context.Context, address string, data []byte) error
 }
 
 type MemPool interface {
-	GetTransaction(hash string) (*types.TransactionDetail, error)
-	GetTransactionRaw(hash string) (*hexutil.Bytes, error)
-	GetAddressTransactions(address string, count int) ([]*types.TransactionSummary, error)
-	GetTransactions(count int) ([]*types.TransactionSummary, error)
-	GetTransactionsCount() (int, error)
+	GetTransaction(ctx context.Context, hash string) (*types.TransactionDetail, error)
+	GetTransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error)
+	GetAddressTransactions(ctx context.Context, address string, count int) ([]*types.TransactionSummary, error)
+	GetTransactions(ctx context.Context, count int) ([]*types.TransactionSummary, error)
+	GetTransactionsCount(ctx context.Context) (int, error)
 }
 
-func NewService(dbAccessor db.Accessor, memPool MemPool, indexerApi indexer.Api, changeLog service2.ChangeLog) Service {
+func NewService(dbAccessor db.Accessor, memPool MemPool, indexerApi indexer.Api, changeLog service2.ChangeLog, metrics *monitoring.Registry) Service {
 	return &service{
 		Accessor:   dbAccessor,
 		memPool:    memPool,
 		indexerApi: indexerApi,
 		changeLog:  changeLog,
+		sharedCalls: metrics.NewCounter("idena_api_indexer_shared_calls_total",
+			"Total number of indexer calls served by a concurrent call with the same arguments by method.", "method"),
 	}
 }
 
 type service struct {
 	db.Accessor
-	memPool    MemPool
-	indexerApi indexer.Api
-	changeLog  service2.ChangeLog
+	memPool     MemPool
+	indexerApi  indexer.Api
+	changeLog   service2.ChangeLog
+	calls       singleflight.Group
+	sharedCalls *monitoring.Counter
+}
+
+// do collapses concurrent calls with the same args into one call, the call canceled by the context of the calling
+// request is repeated through the group by other requests sharing it
+func (s *service) do(ctx context.Context, method string, fn func() (interface{}, error), args ...interface{}) (interface{}, error) {
+	key := method
+	for _, arg := range args {
+		if v, ok := arg.(*string); ok && v != nil {
+			arg = *v
+		}
+		key = fmt.Sprintf("%s-%v", key, arg)
+	}
+	for {
+		v, _, shared := s.calls.Do(key, func() (interface{}, error) {
+			res, err := fn()
+			return &callResult{
+				res:      res,
+				err:      err,
+				canceled: err != nil && ctx.Err() != nil,
+			}, nil
+		})
+		// The panic of the shared call is repeated by singleflight for every caller, so the result is always set
+		res, ok := v.(*callResult)
+		if !ok {
+			return nil, errors.Errorf("unexpected result of shared call %v", method)
+		}
+		if shared {
+			s.sharedCalls.Inc(method)
+		}
+		if res.canceled && ctx.Err() == nil {
+			// The call is canceled by the context of another request sharing it
+			continue
+		}
+		return res.res, res.err
+	}
 }
 
-func (s *service) Search(value string) ([]types.Entity, error) {
-	res, err := s.Accessor.Search(value)
+type callResult struct {
+	res      interface{}
+	err      error
+	canceled bool
+}
+
+type pageResult struct {
+	res               interface{}
+	continuationToken *string
+}
+
+func (s *service) Search(ctx context.Context, value string) ([]types.Entity, error) {
+	res, err := s.Accessor.Search(ctx, value)
 	if err != nil {
 		return nil, err
 	}
-	tx, _ := s.memPool.GetTransaction(value)
+	tx, _ := s.memPool.GetTransaction(ctx, value)
 	if tx != nil {
 		res = append(res, types.Entity{
 			Name:     "Transaction",
@@ -83,21 +144,21 @@ func (s *service) Search(value string) ([]types.Entity, error) {
 	return res, err
 }
 
-func (s *service) Transaction(hash string) (*types.TransactionDetail, error) {
-	return s.Accessor.Transaction(hash)
+func (s *service) Transaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
+	return s.Accessor.Transaction(ctx, hash)
 }
 
-func (s *service) TransactionRaw(hash string) (*hexutil.Bytes, error) {
-	return s.Accessor.TransactionRaw(hash)
+func (s *service) TransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
+	return s.Accessor.TransactionRaw(ctx, hash)
 }
 
-func (s *service) IdentityTxs(address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+func (s *service) IdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
 	var res []types.TransactionSummary
 	var nextContinuationToken *string
 	var err error
 	if continuationToken == nil {
 		// Mem pool txs
-		txs, _ := s.memPool.GetAddressTransactions(address, int(count))
+		txs, _ := s.memPool.GetAddressTransactions(ctx, address, int(count))
 		if len(txs) > 0 {
 			res = make([]types.TransactionSummary, 0, len(txs))
 			for _, tx := range txs {
@@ -113,57 +174,95 @@ func (s *service) IdentityTxs(address string, count uint64, continuationToken *s
 	if count > 0 {
 		// DB txs
 		var txs []types.TransactionSummary
-		txs, nextContinuationToken, err = s.Accessor.IdentityTxs(address, count, continuationToken)
+		txs, nextContinuationToken, err = s.Accessor.IdentityTxs(ctx, address, count, continuationToken)
 		res = append(res, txs...)
 	}
 	return res, nextContinuationToken, err
 }
 
-func (s *service) MemPoolTxs(count uint64) ([]*types.TransactionSummary, error) {
-	return s.memPool.GetTransactions(int(count))
+func (s *service) StreamIdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	if continuationToken == nil {
+		// Mem pool txs
+		txs, _ := s.memPool.GetAddressTransactions(ctx, address, int(count))
+		for _, tx := range txs {
+			if count == 0 {
+				break
+			}
+			if err := w(*tx); err != nil {
+				return nil, err
+			}
+			count--
+		}
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	// DB txs
+	return s.Accessor.StreamIdentityTxs(ctx, address, count, continuationToken, w)
+}
+
+func (s *service) MemPoolTxs(ctx context.Context, count uint64) ([]*types.TransactionSummary, error) {
+	return s.memPool.GetTransactions(ctx, int(count))
 }
 
-func (s *service) MemPoolTxsCount() (int, error) {
-	return s.memPool.GetTransactionsCount()
+func (s *service) MemPoolTxsCount(ctx context.Context) (int, error) {
+	return s.memPool.GetTransactionsCount(ctx)
 }
 
-func (s *service) GetOnlineIdentitiesCount() (uint64, error) {
-	return s.indexerApi.OnlineIdentitiesCount()
+func (s *service) GetOnlineIdentitiesCount(ctx context.Context) (uint64, error) {
+	res, err := s.do(ctx, "OnlineIdentitiesCount", func() (interface{}, error) {
+		return s.indexerApi.OnlineIdentitiesCount(ctx)
+	})
+	count, _ := res.(uint64)
+	return count, err
 }
 
-func (s *service) GetOnlineIdentities(count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error) {
-	return s.indexerApi.OnlineIdentities(count, continuationToken)
+func (s *service) GetOnlineIdentities(ctx context.Context, count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error) {
+	res, err := s.do(ctx, "OnlineIdentities", func() (interface{}, error) {
+		identities, nextContinuationToken, err := s.indexerApi.OnlineIdentities(ctx, count, continuationToken)
+		return &pageResult{identities, nextContinuationToken}, err
+	}, count, continuationToken)
+	page, ok := res.(*pageResult)
+	if !ok {
+		return nil, nil, err
+	}
+	identities, _ := page.res.([]*types.OnlineIdentity)
+	return identities, page.continuationToken, err
 }
 
-func (s *service) GetOnlineIdentity(address string) (*types.OnlineIdentity, error) {
-	return s.indexerApi.OnlineIdentity(address)
+func (s *service) GetOnlineIdentity(ctx context.Context, address string) (*types.OnlineIdentity, error) {
+	res, err := s.do(ctx, "OnlineIdentity", func() (interface{}, error) {
+		return s.indexerApi.OnlineIdentity(ctx, address)
+	}, address)
+	identity, _ := res.(*types.OnlineIdentity)
+	return identity, err
 }
 
-func (s *service) GetOnlineCount() (uint64, error) {
-	return s.indexerApi.OnlineCount()
+func (s *service) GetOnlineCount(ctx context.Context) (uint64, error) {
+	return s.indexerApi.OnlineCount(ctx)
 }
 
-func (s *service) ValidatorsCount() (uint64, error) {
-	return s.indexerApi.ValidatorsCount()
+func (s *service) ValidatorsCount(ctx context.Context) (uint64, error) {
+	return s.indexerApi.ValidatorsCount(ctx)
 }
 
-func (s *service) Validators(count uint64, continuationToken *string) ([]types.Validator, *string, error) {
-	return s.indexerApi.Validators(count, continuationToken)
+func (s *service) Validators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error) {
+	return s.indexerApi.Validators(ctx, count, continuationToken)
 }
 
-func (s *service) OnlineValidatorsCount() (uint64, error) {
-	return s.indexerApi.OnlineValidatorsCount()
+func (s *service) OnlineValidatorsCount(ctx context.Context) (uint64, error) {
+	return s.indexerApi.OnlineValidatorsCount(ctx)
 }
 
-func (s *service) ForkCommitteeCount() (uint64, error) {
-	return s.indexerApi.ForkCommitteeCount()
+func (s *service) ForkCommitteeCount(ctx context.Context) (uint64, error) {
+	return s.indexerApi.ForkCommitteeCount(ctx)
 }
 
-func (s *service) OnlineValidators(count uint64, continuationToken *string) ([]types.Validator, *string, error) {
-	return s.indexerApi.OnlineValidators(count, continuationToken)
+func (s *service) OnlineValidators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error) {
+	return s.indexerApi.OnlineValidators(ctx, count, continuationToken)
 }
 
-func (s *service) SignatureAddress(value, signature string) (string, error) {
+func (s *service) SignatureAddress(ctx context.Context, value, signature string) (string, error) {
 	hash := crypto.Hash([]byte(value))
 	hash = crypto.Hash(hash[:])
 	signatureBytes, err := hexutil.Decode(signature)
@@ -181,16 +280,16 @@ func (s *service) SignatureAddress(value, signature string) (string, error) {
 	return addr.Hex(), nil
 }
 
-func (s *service) UpgradeVoting() ([]*types.UpgradeVotes, error) {
-	return s.indexerApi.UpgradeVoting()
+func (s *service) UpgradeVoting(ctx context.Context) ([]*types.UpgradeVotes, error) {
+	return s.indexerApi.UpgradeVoting(ctx)
 }
 
-func (s *service) ForkChangeLog(version string) (*service2.ChangeLogData, error) {
+func (s *service) ForkChangeLog(ctx context.Context, version string) (*service2.ChangeLogData, error) {
 	return s.changeLog.ForkChangeLog(version)
 }
 
-func (s *service) Upgrades(count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
-	upgrades, nextContinuationToken, err := s.Accessor.Upgrades(count, continuationToken)
+func (s *service) Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
+	upgrades, nextContinuationToken, err := s.Accessor.Upgrades(ctx, count, continuationToken)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -202,8 +301,8 @@ func (s *service) Upgrades(count uint64, continuationToken *string) ([]types.Act
 	return upgrades, nextContinuationToken, nil
 }
 
-func (s *service) UpgradeVotings(count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
-	upgrades, nextContinuationToken, err := s.Accessor.UpgradeVotings(count, continuationToken)
+func (s *service) UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
+	upgrades, nextContinuationToken, err := s.Accessor.UpgradeVotings(ctx, count, continuationToken)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -213,8 +312,8 @@ func (s *service) UpgradeVotings(count uint64, continuationToken *string) ([]typ
 	return upgrades, nextContinuationToken, nil
 }
 
-func (s *service) Upgrade(upgrade uint64) (*types.Upgrade, error) {
-	res, err := s.Accessor.Upgrade(upgrade)
+func (s *service) Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error) {
+	res, err := s.Accessor.Upgrade(ctx, upgrade)
 	if err != nil {
 		return nil, err
 	}
@@ -222,23 +321,32 @@ func (s *service) Upgrade(upgrade uint64) (*types.Upgrade, error) {
 	return res, nil
 }
 
-func (s *service) IdentityWithProof(address string, epoch uint64) (*hexutil.Bytes, error) {
-	return s.indexerApi.IdentityWithProof(epoch, address)
+func (s *service) IdentityWithProof(ctx context.Context, address string, epoch uint64) (*hexutil.Bytes, error) {
+	return s.indexerApi.IdentityWithProof(ctx, epoch, address)
 }
 
-func (s *service) Staking() (*types.Staking, error) {
-	staking, err := s.indexerApi.Staking()
+func (s *service) Staking(ctx context.Context) (*types.Staking, error) {
+	// The whole method is shared as it modifies the indexer response
+	res, err := s.do(ctx, "Staking", func() (interface{}, error) {
+		return s.staking(ctx)
+	})
+	staking, _ := res.(*types.Staking)
+	return staking, err
+}
+
+func (s *service) staking(ctx context.Context) (*types.Staking, error) {
+	staking, err := s.indexerApi.Staking(ctx)
 	if err != nil {
 		return nil, err
 	}
-	lastEpoch, err := s.Accessor.LastEpoch()
+	lastEpoch, err := s.Accessor.LastEpoch(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if lastEpoch.Epoch == 0 {
 		return &types.Staking{}, nil
 	}
-	rewardsSummary, err := s.Accessor.EpochRewardsSummary(lastEpoch.Epoch - 1)
+	rewardsSummary, err := s.Accessor.EpochRewardsSummary(ctx, lastEpoch.Epoch-1)
 	if err != nil {
 		return nil, err
 	}
@@ -257,12 +365,12 @@ func (s *service) Staking() (*types.Staking, error) {
 	return staking, nil
 }
 
-func (s *service) MultisigContract(address string) (types.MultisigContract, error) {
-	res, err := s.Accessor.MultisigContract(address)
+func (s *service) MultisigContract(ctx context.Context, address string) (types.MultisigContract, error) {
+	res, err := s.Accessor.MultisigContract(ctx, address)
 	if err != nil {
 		return types.MultisigContract{}, err
 	}
-	indexerContract, err := s.indexerApi.MultisigContract(address)
+	indexerContract, err := s.indexerApi.MultisigContract(ctx, address)
 	if err != nil {
 		return types.MultisigContract{}, err
 	}
@@ -270,20 +378,66 @@ func (s *service) MultisigContract(address string) (types.MultisigContract, erro
 	return res, nil
 }
 
-func (s *service) Pool(address string) (*types.Pool, error) {
-	res, err := s.Accessor.Pool(address)
+func (s *service) Pool(ctx context.Context, address string) (*types.Pool, error) {
+	res, err := s.Accessor.Pool(ctx, address)
 	if err != nil {
 		return nil, err
 	}
-	indexerPool, err := s.indexerApi.Pool(address)
+	indexerPoolRes, err := s.do(ctx, "Pool", func() (interface{}, error) {
+		return s.indexerApi.Pool(ctx, address)
+	}, address)
 	if err != nil {
 		return nil, err
 	}
-	res.TotalStake = indexerPool.TotalStake
-	res.TotalValidatedStake = indexerPool.TotalValidatedStake
+	if indexerPool, ok := indexerPoolRes.(*types.Pool); ok && indexerPool != nil {
+		res.TotalStake = indexerPool.TotalStake
+		res.TotalValidatedStake = indexerPool.TotalValidatedStake
+	}
+	return res, nil
+}
+
+func (s *service) AddressBalanceAt(ctx context.Context, address string, height uint64, timestamp *time.Time) (*types.BalanceAt, error) {
+	res, err := s.balancesAt(ctx, []string{address}, height, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+func (s *service) AddressesBalanceAt(ctx context.Context, addresses []string, height uint64) ([]*types.BalanceAt, error) {
+	return s.balancesAt(ctx, addresses, height, nil)
+}
+
+// balancesAt reads balances of all addresses by a single query and returns them in the order of addresses
+func (s *service) balancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]*types.BalanceAt, error) {
+	if timestamp == nil {
+		lastBlock, err := s.Accessor.LastBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if height > lastBlock.Height {
+			return nil, apierrors.Errorf(apierrors.InvalidArgument, "height %v is above the last block %v", height, lastBlock.Height)
+		}
+	}
+	balances, err := s.Accessor.BalancesAt(ctx, addresses, height, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	balancesByAddress := make(map[string]*types.BalanceAt, len(balances))
+	for i := range balances {
+		balancesByAddress[strings.ToLower(balances[i].Address)] = &balances[i]
+	}
+	res := make([]*types.BalanceAt, 0, len(addresses))
+	for _, address := range addresses {
+		balance, ok := balancesByAddress[strings.ToLower(address)]
+		if !ok {
+			return nil, apierrors.Errorf(apierrors.NotFound, "address %v not found", address)
+		}
+		res = append(res, balance)
+	}
 	return res, nil
 }
 
-func (s *service) VerifyContract(address string, data []byte) error {
-	return s.indexerApi.VerifyContract(address, data)
+func (s *service) VerifyContract(ctx context.Context, address string, data []byte) error {
+	return s.indexerApi.VerifyContract(ctx, address, data)
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type balancesAtAccessor struct {
+	db.Accessor
+	lastHeight uint64
+	balances   map[string]decimal.Decimal
+	calls      int
+}
+
+func (a *balancesAtAccessor) LastBlock(ctx context.Context) (types.BlockDetail, error) {
+	return types.BlockDetail{Height: a.lastHeight}, nil
+}
+
+func (a *balancesAtAccessor) BalancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]types.BalanceAt, error) {
+	a.calls++
+	var res []types.BalanceAt
+	for address, balance := range a.balances {
+		for _, requested := range addresses {
+			if requested == address {
+				res = append(res, types.BalanceAt{Address: address, Balance: balance, BlockHeight: height, Timestamp: timestamp})
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func Test_service_AddressBalanceAt(t *testing.T) {
+	ctx := context.Background()
+	accessor := &balancesAtAccessor{
+		lastHeight: 100,
+		balances: map[string]decimal.Decimal{
+			"0x01": decimal.New(1, 0),
+			"0x02": decimal.New(2, 0),
+		},
+	}
+	s := &service{Accessor: accessor}
+
+	res, err := s.AddressBalanceAt(ctx, "0x01", 100, nil)
+	require.Nil(t, err)
+	require.Equal(t, "1", res.Balance.String())
+	require.Equal(t, uint64(100), res.BlockHeight)
+
+	_, err = s.AddressBalanceAt(ctx, "0x01", 101, nil)
+	require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err))
+
+	// Timestamps are resolved by the accessor
+	timestamp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	res, err = s.AddressBalanceAt(ctx, "0x02", 0, &timestamp)
+	require.Nil(t, err)
+	require.Equal(t, &timestamp, res.Timestamp)
+
+	_, err = s.AddressBalanceAt(ctx, "0x03", 10, nil)
+	require.Equal(t, apierrors.NotFound, apierrors.CodeOf(err))
+
+	// Balances of all addresses are read at once and returned in the requested order
+	accessor.calls = 0
+	balances, err := s.AddressesBalanceAt(ctx, []string{"0x02", "0x01", "0x02"}, 25)
+	require.Nil(t, err)
+	require.Equal(t, 1, accessor.calls)
+	require.Len(t, balances, 3)
+	require.Equal(t, "2", balances[0].Balance.String())
+	require.Equal(t, "1", balances[1].Balance.String())
+	require.Equal(t, "2", balances[2].Balance.String())
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	epochEventsPath         = "/api/events/epoch"
+	sseEventsBufferSize     = 100
+	sseKeepAliveInterval    = time.Second * 30
+	sseRetryInterval        = time.Second * 5
+	sseLastEventIdHeader    = "Last-Event-ID"
+	sseLastEventIdQueryName = "lastEventId"
+)
+
+var errSseNotSupported = errors.New("streaming is not supported")
+
+// @Tags Events
+// @Id EpochEvents
+// @Summary Server-sent events stream of epoch lifecycle: newEpoch, validationStarted, interimSummaryChanged, rewardsAvailable
+// @Param Last-Event-ID header string false "id of the last received event to resume the stream from"
+// @Produce text/event-stream
+// @Success 200 {string} string "event stream"
+// @Failure 404 "Event stream is not enabled"
+// @Failure 429 "Request number limit exceeded"
+// @Failure 500 "Internal server error"
+// @Router /Events/Epoch [get]
+func (s *httpServer) epochEvents(w http.ResponseWriter, r *http.Request) {
+	if s.epochEventHistory == nil {
+		s.writeRejectedRequest(w, errEventsNotEnabled)
+		return
+	}
+	lastEventId, err := readLastEventId(r)
+	if err != nil {
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeRejectedRequest(w, errSseNotSupported)
+		return
+	}
+	reqClient, err := s.resolveClient(r)
+	if err != nil {
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	rateLimit, err := s.limiter.checkClientReqLimit(reqClient)
+	writeRateLimitHeaders(w, rateLimit)
+	if err != nil {
+		s.recordApiKeyRequest(reqClient, true)
+		writeRetryAfterHeader(w, rateLimit)
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	s.recordApiKeyRequest(reqClient, false)
+	if err := s.limiter.takeConnection(reqClient.id); err != nil {
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	defer s.limiter.releaseConnection(reqClient.id)
+
+	// Subscribe before reading the history to not miss events published in between
+	subscription := s.eventBus.Subscribe(sseEventsBufferSize)
+	defer subscription.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryInterval.Milliseconds()); err != nil {
+		return
+	}
+	s.logger.Debug("Opened sse connection", "from", reqClient.ip, "lastEventId", lastEventId)
+	defer s.logger.Debug("Closed sse connection", "from", reqClient.ip)
+
+	if lastEventId > 0 {
+		for _, event := range s.epochEventHistory.Since(lastEventId) {
+			if err := s.writeSseEvent(w, event); err != nil {
+				return
+			}
+			lastEventId = event.Id
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(sseKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if !s.epochEventHistory.HasTopic(event.Topic) || event.Id <= lastEventId {
+				continue
+			}
+			if err := s.writeSseEvent(w, event); err != nil {
+				return
+			}
+			lastEventId = event.Id
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func readLastEventId(r *http.Request) (uint64, error) {
+	value := r.Header.Get(sseLastEventIdHeader)
+	if len(value) == 0 {
+		value = r.URL.Query().Get(sseLastEventIdQueryName)
+	}
+	if len(value) == 0 {
+		return 0, nil
+	}
+	res, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, apierrors.Errorf(apierrors.InvalidArgument, "invalid last event id: %v", value)
+	}
+	return res, nil
+}
+
+func (s *httpServer) writeSseEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Unable to serialize sse event %v: %v", event.Id, err))
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Topic, data)
+	return err
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSseTestServer(t *testing.T) (*httpServer, string) {
+	ipResolver, err := newIpResolver(config.ClientIpConfig{})
+	require.Nil(t, err)
+	lc := lifecycle.NewManager(log.New())
+	t.Cleanup(func() {
+		lc.Stop(context.Background())
+	})
+	bus := events.NewBus()
+	s := &httpServer{
+		logger:  log.New(),
+		closing: make(chan struct{}),
+		limiter: &reqLimiter{
+			reqCountsByClientId: cache.New(reqLimitWindow, time.Minute),
+		},
+		eventBus:          bus,
+		epochEventHistory: events.NewHistory(bus, []events.Topic{events.NewEpochTopic, events.ValidationStartedTopic}, 2, lc),
+		ipResolver:        ipResolver,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(s.epochEvents))
+	t.Cleanup(srv.Close)
+	return s, srv.URL
+}
+
+type sseTestEvent struct {
+	id    uint64
+	topic string
+	data  string
+}
+
+// readSseEvent skips comments and the retry field
+func readSseEvent(t *testing.T, reader *bufio.Reader) sseTestEvent {
+	var res sseTestEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			if len(res.topic) > 0 {
+				return res
+			}
+			continue
+		}
+		name, value, _ := cutString(line, ": ")
+		switch name {
+		case "id":
+			res.id, err = strconv.ParseUint(value, 10, 64)
+			require.Nil(t, err)
+		case "event":
+			res.topic = value
+		case "data":
+			res.data = value
+		}
+	}
+}
+
+func publishHistoryEvents(t *testing.T, s *httpServer, es ...events.Event) []events.Event {
+	for _, event := range es {
+		s.eventBus.Publish(event)
+	}
+	var res []events.Event
+	require.Eventually(t, func() bool {
+		res = s.epochEventHistory.Since(0)
+		return len(res) == 2 && res[1].Data == es[len(es)-1].Data
+	}, time.Second*5, time.Millisecond*10)
+	return res
+}
+
+func Test_epochEvents_historyReplay(t *testing.T) {
+	s, url := newSseTestServer(t)
+	history := publishHistoryEvents(t, s,
+		events.Event{Topic: events.NewEpochTopic, Data: 1},
+		events.Event{Topic: events.NewBlockTopic, Data: 10},
+		events.Event{Topic: events.ValidationStartedTopic, Data: 2},
+		events.Event{Topic: events.NewEpochTopic, Data: 3},
+	)
+	// The history keeps the last 2 events of its topics
+	require.Equal(t, events.ValidationStartedTopic, history[0].Topic)
+	require.Equal(t, events.NewEpochTopic, history[1].Topic)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.Nil(t, err)
+	req.Header.Set(sseLastEventIdHeader, strconv.FormatUint(history[0].Id-1, 10))
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	require.Equal(t, sseTestEvent{id: history[0].Id, topic: string(events.ValidationStartedTopic), data: "2"}, readSseEvent(t, reader))
+	require.Equal(t, sseTestEvent{id: history[1].Id, topic: string(events.NewEpochTopic), data: "3"}, readSseEvent(t, reader))
+}
+
+func Test_epochEvents_stream(t *testing.T) {
+	s, url := newSseTestServer(t)
+	history := publishHistoryEvents(t, s,
+		events.Event{Topic: events.NewEpochTopic, Data: 1},
+		events.Event{Topic: events.ValidationStartedTopic, Data: 2},
+	)
+
+	// Streams without the last event id start from new events
+	resp, err := http.Get(url + "?" + sseLastEventIdQueryName + "=" + strconv.FormatUint(history[1].Id, 10))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	reader := bufio.NewReader(resp.Body)
+	retry, err := reader.ReadString('\n')
+	require.Nil(t, err)
+	require.Equal(t, "retry: 5000\n", retry)
+
+	s.eventBus.Publish(events.Event{Topic: events.NewBlockTopic, Data: 10})
+	s.eventBus.Publish(events.Event{Topic: events.NewEpochTopic, Data: map[string]int{"epoch": 4}})
+	event := readSseEvent(t, reader)
+	require.Equal(t, string(events.NewEpochTopic), event.topic)
+	require.Equal(t, `{"epoch":4}`, event.data)
+	require.True(t, event.id > history[1].Id)
+
+	close(s.closing)
+	_, err = reader.ReadString('\n')
+	require.NotNil(t, err)
+}
+
+func Test_epochEvents_errors(t *testing.T) {
+	s, url := newSseTestServer(t)
+
+	resp, err := http.Get(url + "?" + sseLastEventIdQueryName + "=abc")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	s.epochEventHistory = nil
+	resp, err = http.Get(url)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
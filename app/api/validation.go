@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/hex"
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	addressLength = 20
+	hashLength    = 32
+	// flipCidLength is the length of base32 encoded CIDv1 with sha2-256 digest flips are stored with
+	flipCidLength = 59
+)
+
+// paramValidator checks the param value and returns its normalized form
+type paramValidator func(value string) (string, error)
+
+// pathVarValidators are applied to path variables by their names, so routes declare validation of path segments
+// by naming variables, e.g. "/Address/{address}/Txs"
+var pathVarValidators = map[string]paramValidator{
+	"address":         validateAddress,
+	"contractaddress": validateAddress,
+	"tokenaddress":    validateAddress,
+	"hash":            validateHash,
+	"block":           validateBlockId,
+	"cid":             validateFlipCid,
+	"epoch":           validateUint,
+	"upgrade":         validateUint,
+}
+
+// queryParam declares validation of the query param of the route, list params like states[] may also
+// have comma separated values
+type queryParam struct {
+	name     string
+	list     bool
+	validate paramValidator
+}
+
+var (
+	identityStatesParam     = queryParam{name: "states[]", list: true, validate: newEnumValidator("Undefined", "Invite", "Candidate", "Verified", "Suspended", "Killed", "Zombie", "Newbie", "Human")}
+	identityPrevStatesParam = queryParam{name: "prevstates[]", list: true, validate: identityStatesParam.validate}
+	balancesSortByParam     = queryParam{name: "sortby", validate: newEnumValidator("balance", "stake")}
+	oracleVotingStatesParam = queryParam{name: "states[]", list: true, validate: newEnumValidator("open", "voted", "counting", "pending", "archive", "terminated", "canbeprolonged")}
+	oracleVotingSortByParam = queryParam{name: "sortby", validate: newEnumValidator("reward", "timestamp")}
+	authorParam             = queryParam{name: "author", validate: validateAddress}
+	oracleParam             = queryParam{name: "oracle", validate: validateAddress}
+)
+
+// validatePathVars rejects requests with malformed path variables before they reach handlers and replaces the variables
+// with their normalized values
+func (s *httpServer) validatePathVars(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		for name, value := range vars {
+			validate, ok := pathVarValidators[name]
+			if !ok {
+				continue
+			}
+			normalized, err := validate(value)
+			if err != nil {
+				WriteErrorResponse(w, newValidationError(name, value, err), s.logger)
+				return
+			}
+			vars[name] = normalized
+		}
+		next.ServeHTTP(w, mux.SetURLVars(r, vars))
+	})
+}
+
+// withQueryParams validates the declared query params of the route and replaces them with their normalized values
+func (s *httpServer) withQueryParams(handler http.HandlerFunc, params ...queryParam) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, param := range params {
+			values := r.Form[param.name]
+			if len(values) == 0 {
+				continue
+			}
+			normalizedValues, err := validateQueryParam(param, values)
+			if err != nil {
+				WriteErrorResponse(w, err, s.logger)
+				return
+			}
+			r.Form[param.name] = normalizedValues
+		}
+		handler(w, r)
+	}
+}
+
+// validateQueryParam returns the normalized values of the param, list values are split by commas
+func validateQueryParam(param queryParam, values []string) ([]string, error) {
+	var res []string
+	for _, value := range values {
+		parts := []string{value}
+		if param.list {
+			parts = strings.Split(value, ",")
+		}
+		for _, part := range parts {
+			if len(part) == 0 {
+				continue
+			}
+			normalized, err := param.validate(part)
+			if err != nil {
+				return nil, newValidationError(param.name, part, err)
+			}
+			res = append(res, normalized)
+		}
+	}
+	return res, nil
+}
+
+func newValidationError(name, value string, err error) error {
+	return apierrors.Errorf(apierrors.InvalidArgument, "wrong value %s=%v: %v", name, value, err).
+		WithDetails(map[string]interface{}{"param": name})
+}
+
+// validateAddress accepts hex addresses in any case with or without 0x prefix and returns them in lower case with the prefix
+func validateAddress(value string) (string, error) {
+	return validateHex(value, addressLength)
+}
+
+func validateHash(value string) (string, error) {
+	return validateHex(value, hashLength)
+}
+
+func validateHex(value string, length int) (string, error) {
+	value = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X"))
+	if len(value) != length*2 {
+		return "", errors.Errorf("%d bytes hex expected", length)
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return "", errors.New("invalid hex")
+	}
+	return "0x" + value, nil
+}
+
+// validateBlockId accepts either the block height or the block hash
+func validateBlockId(value string) (string, error) {
+	if _, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return value, nil
+	}
+	res, err := validateHash(value)
+	if err != nil {
+		return "", errors.New("block height or 32 bytes hex hash expected")
+	}
+	return res, nil
+}
+
+func validateFlipCid(value string) (string, error) {
+	value = strings.ToLower(value)
+	if len(value) != flipCidLength || value[0] != 'b' {
+		return "", errors.New("base32 CIDv1 expected")
+	}
+	for _, c := range value[1:] {
+		if (c < 'a' || c > 'z') && (c < '2' || c > '7') {
+			return "", errors.New("base32 CIDv1 expected")
+		}
+	}
+	return value, nil
+}
+
+func validateUint(value string) (string, error) {
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return "", errors.New("unsigned integer expected")
+	}
+	return value, nil
+}
+
+// newEnumValidator accepts the values in any case and returns them as they are declared
+func newEnumValidator(values ...string) paramValidator {
+	valuesByLowerValue := make(map[string]string, len(values))
+	for _, value := range values {
+		valuesByLowerValue[strings.ToLower(value)] = value
+	}
+	return func(value string) (string, error) {
+		if res, ok := valuesByLowerValue[strings.ToLower(value)]; ok {
+			return res, nil
+		}
+		return "", errors.Errorf("one of %v expected", strings.Join(values, ", "))
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_validators(t *testing.T) {
+	address := "0x" + strings.Repeat("aB", addressLength)
+	res, err := validateAddress(address)
+	require.Nil(t, err)
+	require.Equal(t, strings.ToLower(address), res)
+	res, err = validateAddress(strings.Repeat("ab", addressLength))
+	require.Nil(t, err)
+	require.Equal(t, "0x"+strings.Repeat("ab", addressLength), res)
+	_, err = validateAddress("0x" + strings.Repeat("ab", addressLength-1))
+	require.Error(t, err)
+	_, err = validateAddress("0x" + strings.Repeat("zz", addressLength))
+	require.Error(t, err)
+
+	_, err = validateHash("0x" + strings.Repeat("01", hashLength))
+	require.Nil(t, err)
+	_, err = validateHash(address)
+	require.Error(t, err)
+
+	res, err = validateBlockId("100")
+	require.Nil(t, err)
+	require.Equal(t, "100", res)
+	_, err = validateBlockId("0x" + strings.Repeat("01", hashLength))
+	require.Nil(t, err)
+	_, err = validateBlockId("last")
+	require.Error(t, err)
+
+	_, err = validateFlipCid("bafkreiar6xq6j4ok5pfxaagtec7jwq6fzrdntd7ewj5yazuapwmg3pmuja")
+	require.Nil(t, err)
+	_, err = validateFlipCid("bafkreiar6xq6j4ok5pfxaagtec7jwq6fzrdntd7ewj5yazuapwmg3pmuj0")
+	require.Error(t, err)
+	_, err = validateFlipCid("0x" + strings.Repeat("01", hashLength))
+	require.Error(t, err)
+
+	res, err = identityStatesParam.validate("verified")
+	require.Nil(t, err)
+	require.Equal(t, "Verified", res)
+	_, err = identityStatesParam.validate("unknown")
+	require.Error(t, err)
+}
+
+func Test_validatePathVarsAndQueryParams(t *testing.T) {
+	s := &httpServer{logger: log.New()}
+	router := mux.NewRouter()
+	router.Use(s.validatePathVars)
+	var gotAddress string
+	var gotStates []string
+	router.Path("/address/{address}").HandlerFunc(s.withQueryParams(func(w http.ResponseWriter, r *http.Request) {
+		gotAddress = mux.Vars(r)["address"]
+		gotStates = r.Form["states[]"]
+	}, identityStatesParam))
+	serve := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		require.Nil(t, r.ParseForm())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(&apiResponseWriter{ResponseWriter: w, stream: true}, r)
+		return w
+	}
+
+	address := strings.Repeat("ab", addressLength)
+	w := serve("/address/" + address + "?states[]=verified,human&states[]=newbie")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0x"+address, gotAddress)
+	require.Equal(t, []string{"Verified", "Human", "Newbie"}, gotStates)
+
+	gotAddress = ""
+	w = serve("/address/0x01")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"invalid_argument"`)
+	require.Contains(t, w.Body.String(), `"param":"address"`)
+	require.Empty(t, gotAddress)
+
+	w = serve("/address/" + address + "?states[]=verified,unknown")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "states[]=unknown")
+}
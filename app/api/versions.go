@@ -0,0 +1,572 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"github.com/pkg/errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+type apiVersion int
+
+const (
+	apiV1 apiVersion = iota
+	// apiV2 is served by the same routes as v1 with consistent resource names, without deprecated routes and fields
+	// and with uniform list envelopes
+	apiV2
+)
+
+const (
+	apiV2PathPrefix = "/api/v2"
+	// deprecatedFieldTag marks fields of response types omitted in v2 responses, e.g. `deprecated:"true"`
+	deprecatedFieldTag = "deprecated"
+)
+
+type apiVersionKey struct{}
+
+// v2ResourcePath names the v2 resource served by the v1 path, variables match the values accepted
+// by pathVarValidators and "**" matches the rest of the path
+type v2ResourcePath struct {
+	v2 []string
+	v1 []string
+}
+
+// v2ResourcePaths name v2 resources consistently: collections are plural nouns and their items are addressed below
+// them, e.g. /api/v2/Epochs/100/Identities/0x01 is /api/Epoch/100/Identity/0x01 of v1, paths missing in the list
+// are the same in both versions
+var v2ResourcePaths = []v2ResourcePath{
+	newV2ResourcePath("/Epochs/Last", "/Epoch/Last"),
+	newV2ResourcePath("/Epochs/{epoch}/Identities/{address}/**", "/Epoch/{epoch}/Identity/{address}/**"),
+	newV2ResourcePath("/Epochs/{epoch}/Addresses/{address}/**", "/Epoch/{epoch}/Address/{address}/**"),
+	newV2ResourcePath("/Epochs/{epoch}/**", "/Epoch/{epoch}/**"),
+	newV2ResourcePath("/Blocks/Last", "/Block/Last"),
+	newV2ResourcePath("/Blocks/{block}/**", "/Block/{block}/**"),
+	newV2ResourcePath("/Identities/{address}/**", "/Identity/{address}/**"),
+	newV2ResourcePath("/OnlineIdentities/{address}", "/OnlineIdentity/{address}"),
+	newV2ResourcePath("/Flips/{cid}/**", "/Flip/{cid}/**"),
+	newV2ResourcePath("/Transactions/{hash}/**", "/Transaction/{hash}/**"),
+	newV2ResourcePath("/Addresses/{address}/Tokens/{tokenaddress}", "/Address/{address}/Token/{tokenaddress}"),
+	newV2ResourcePath("/Addresses/{address}/Contracts/{contractaddress}/Balance/Changes", "/Address/{address}/Contract/{contractaddress}/BalanceUpdates"),
+	newV2ResourcePath("/Addresses/{address}/**", "/Address/{address}/**"),
+	newV2ResourcePath("/Contracts/{address}/Balance/Changes", "/Contract/{address}/BalanceUpdates"),
+	newV2ResourcePath("/Contracts/{address}/**", "/Contract/{address}/**"),
+	newV2ResourcePath("/TimeLockContracts/{address}", "/TimeLockContract/{address}"),
+	newV2ResourcePath("/OracleLockContracts/{address}", "/OracleLockContract/{address}"),
+	newV2ResourcePath("/RefundableOracleLockContracts/{address}", "/RefundableOracleLockContract/{address}"),
+	newV2ResourcePath("/MultisigContracts/{address}", "/MultisigContract/{address}"),
+	newV2ResourcePath("/OracleVotingContracts/{address}", "/OracleVotingContract/{address}"),
+	newV2ResourcePath("/Pools/{address}/**", "/Pool/{address}/**"),
+	newV2ResourcePath("/Tokens/{address}/**", "/Token/{address}/**"),
+	newV2ResourcePath("/Upgrades/{upgrade}/**", "/Upgrade/{upgrade}/**"),
+}
+
+func newV2ResourcePath(v2, v1 string) v2ResourcePath {
+	return v2ResourcePath{
+		v2: splitPath(v2),
+		v1: splitPath(v1),
+	}
+}
+
+// translatePath matches the path segments with the pattern and returns the path built by the target pattern
+// with the same variables
+func translatePath(pattern, target, segments []string) (string, bool) {
+	vars := make(map[string]string)
+	var rest []string
+	for i, patternSegment := range pattern {
+		if patternSegment == "**" {
+			rest = segments[i:]
+			break
+		}
+		if i >= len(segments) {
+			return "", false
+		}
+		if strings.HasPrefix(patternSegment, "{") {
+			name := strings.Trim(patternSegment, "{}")
+			if _, err := pathVarValidators[name](segments[i]); err != nil {
+				return "", false
+			}
+			vars[name] = segments[i]
+		} else if !strings.EqualFold(patternSegment, segments[i]) {
+			return "", false
+		}
+		if i == len(pattern)-1 && len(segments) > len(pattern) {
+			return "", false
+		}
+	}
+	res := make([]string, 0, len(target)+len(rest))
+	for _, targetSegment := range target {
+		switch {
+		case targetSegment == "**":
+			res = append(res, rest...)
+		case strings.HasPrefix(targetSegment, "{"):
+			res = append(res, vars[strings.Trim(targetSegment, "{}")])
+		default:
+			res = append(res, targetSegment)
+		}
+	}
+	return "/" + strings.Join(res, "/"), true
+}
+
+// v1Path returns the v1 path of the path relative to /api/v2, the error is returned for v1 names of renamed resources
+func v1Path(path string) (string, error) {
+	segments := splitPath(path)
+	for _, resourcePath := range v2ResourcePaths {
+		if res, ok := translatePath(resourcePath.v2, resourcePath.v1, segments); ok {
+			return res, nil
+		}
+	}
+	if res, renamed := v2Path(path); renamed {
+		return "", apierrors.Errorf(apierrors.NotFound, "path is not available in v2, use %v", apiV2PathPrefix+res)
+	}
+	return path, nil
+}
+
+// v2Path returns the v2 path of the path relative to /api and whether the resource is renamed in v2
+func v2Path(path string) (string, bool) {
+	segments := splitPath(path)
+	for _, resourcePath := range v2ResourcePaths {
+		if res, ok := translatePath(resourcePath.v1, resourcePath.v2, segments); ok {
+			return res, true
+		}
+	}
+	return path, false
+}
+
+// versionFilter serves /api/v2 paths by the v1 handlers keeping the version in the request context
+func (s *httpServer) versionFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path, ok := trimApiV2Prefix(r.URL.Path); ok {
+			var err error
+			if r, err = withApiVersion(r, apiV2, path); err != nil {
+				s.writeRejectedRequest(w, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func trimApiV2Prefix(path string) (string, bool) {
+	lowerPath := strings.ToLower(path)
+	if lowerPath != apiV2PathPrefix && !strings.HasPrefix(lowerPath, apiV2PathPrefix+"/") {
+		return path, false
+	}
+	return "/api" + path[len(apiV2PathPrefix):], true
+}
+
+// withApiVersion returns the request of the v1 path serving the path of the version
+func withApiVersion(r *http.Request, version apiVersion, path string) (*http.Request, error) {
+	if version == apiV2 {
+		resourcePath, err := v1Path(strings.TrimPrefix(path, "/api"))
+		if err != nil {
+			return nil, err
+		}
+		path = "/api" + resourcePath
+	}
+	r = r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version))
+	u := *r.URL
+	u.Path = path
+	u.RawPath = ""
+	r.URL = &u
+	return r, nil
+}
+
+func getApiVersion(ctx context.Context) apiVersion {
+	version, _ := ctx.Value(apiVersionKey{}).(apiVersion)
+	return version
+}
+
+func getResponseVersion(w http.ResponseWriter) apiVersion {
+	if aw, ok := w.(*apiResponseWriter); ok {
+		return aw.version
+	}
+	return apiV1
+}
+
+// deprecations announces deprecated v1 routes and fields with Deprecation, Sunset and Link headers and counts their usage
+// to show when they can be removed
+type deprecations struct {
+	// sunset is the Sunset header value, empty if the removal date is not announced
+	sunset string
+	// successorsByRoute holds successor path templates by deprecated route templates, routes are registered
+	// on the router init only
+	successorsByRoute map[string]string
+	usage             *monitoring.Counter
+}
+
+func newDeprecations(sunset string, metrics *monitoring.Registry) (*deprecations, error) {
+	res := &deprecations{
+		successorsByRoute: make(map[string]string),
+		usage: metrics.NewCounter("idena_api_deprecated_requests_total",
+			"Total number of v1 API requests to deprecated routes or with deprecated fields in the response by route.", "route", "kind"),
+	}
+	if len(sunset) > 0 {
+		sunsetTime, err := time.Parse(time.RFC3339, sunset)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid deprecation sunset")
+		}
+		res.sunset = sunsetTime.UTC().Format(http.TimeFormat)
+	}
+	return res, nil
+}
+
+// deprecateRoute marks the v1 route deprecated in favour of the successor path, e.g. "/Identity/{address}/Flips",
+// the route is not available in v2
+func (d *deprecations) deprecateRoute(route *mux.Route, successor string) {
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		panic(err)
+	}
+	d.successorsByRoute[template] = successor
+}
+
+func (d *deprecations) writeHeaders(w http.ResponseWriter, successorUrl string) {
+	w.Header().Set("Deprecation", "true")
+	if len(d.sunset) > 0 {
+		w.Header().Set("Sunset", d.sunset)
+	}
+	w.Header().Set("Link", "<"+successorUrl+">; rel=\"successor-version\"")
+}
+
+// deprecationFilter rejects v2 requests to deprecated routes and announces the deprecation to v1 clients
+func (s *httpServer) deprecationFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var template string
+		if route := mux.CurrentRoute(r); route != nil {
+			template, _ = route.GetPathTemplate()
+		}
+		if aw, ok := w.(*apiResponseWriter); ok {
+			aw.route = template
+		}
+		if successor, ok := s.deprecations.successorsByRoute[template]; ok {
+			successorPath, _ := v2Path(expandPathTemplate(successor, mux.Vars(r)))
+			if getApiVersion(r.Context()) == apiV2 {
+				WriteErrorResponse(w, apierrors.Errorf(apierrors.NotFound, "path is not available in v2, use %v", apiV2PathPrefix+successorPath), s.logger)
+				return
+			}
+			s.deprecations.writeHeaders(w, apiV2PathPrefix+successorPath)
+			s.deprecations.usage.Inc(template, "route")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func expandPathTemplate(template string, vars map[string]string) string {
+	for name, value := range vars {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+	return template
+}
+
+// markDeprecatedFields announces the deprecation of the fields the v1 result contains, the v2 location of the same path
+// is the successor
+func markDeprecatedFields(w http.ResponseWriter, result interface{}) {
+	aw, ok := w.(*apiResponseWriter)
+	if !ok || aw.version != apiV1 || aw.deprecations == nil || result == nil || !containsDeprecatedFields(reflect.ValueOf(result)) {
+		return
+	}
+	// The successor of the deprecated route is kept
+	if len(w.Header().Get("Deprecation")) == 0 {
+		path, query, hasQuery := cutString(aw.requestUri, "?")
+		path, _ = v2Path(strings.TrimPrefix(path, "/api"))
+		if hasQuery {
+			path += "?" + query
+		}
+		aw.deprecations.writeHeaders(w, apiV2PathPrefix+path)
+	}
+	aw.deprecations.usage.Inc(aw.route, "field")
+}
+
+// ResponseV2 is the envelope of v2 single item responses, errors are returned as in v1
+type ResponseV2 struct {
+	Result interface{} `json:"result"`
+} // @Name ResponseV2
+
+// ResponsePageV2 is the envelope of v2 list responses, the result is always an array and the continuation token is null
+// on the last page
+type ResponsePageV2 struct {
+	Result            interface{} `json:"result"`
+	ContinuationToken *string     `json:"continuationToken"`
+} // @Name ResponsePageV2
+
+// getVersionedResponse returns the JSON envelope of the response API version
+func getVersionedResponse(w http.ResponseWriter, result interface{}, continuationToken *string, err error) interface{} {
+	if err != nil {
+		return getErrorResponse(err)
+	}
+	if getResponseVersion(w) == apiV1 {
+		markDeprecatedFields(w, result)
+		return getResponse(result, continuationToken, nil)
+	}
+	items := reflect.ValueOf(result)
+	if continuationToken == nil && (!items.IsValid() || items.Kind() != reflect.Slice) {
+		return ResponseV2{
+			Result: withoutDeprecatedFields(result),
+		}
+	}
+	res := ResponsePageV2{
+		Result:            withoutDeprecatedFields(result),
+		ContinuationToken: continuationToken,
+	}
+	if !items.IsValid() || items.Kind() == reflect.Slice && items.IsNil() {
+		res.Result = []interface{}{}
+	}
+	return res
+}
+
+type fieldsDeprecation int
+
+const (
+	noDeprecatedFields fieldsDeprecation = iota
+	// dynamicDeprecatedFields means the type has no deprecated fields itself but the values of its interfaces may have
+	dynamicDeprecatedFields
+	staticDeprecatedFields
+)
+
+var fieldsDeprecationByType sync.Map
+
+func getFieldsDeprecation(t reflect.Type) fieldsDeprecation {
+	if res, ok := fieldsDeprecationByType.Load(t); ok {
+		return res.(fieldsDeprecation)
+	}
+	res := typeFieldsDeprecation(t, make(map[reflect.Type]bool))
+	fieldsDeprecationByType.Store(t, res)
+	return res
+}
+
+func typeFieldsDeprecation(t reflect.Type, visited map[reflect.Type]bool) fieldsDeprecation {
+	t = indirectType(t)
+	if visited[t] || isJsonLeaf(t) {
+		return noDeprecatedFields
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return dynamicDeprecatedFields
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeFieldsDeprecation(t.Elem(), visited)
+	case reflect.Struct:
+		res := noDeprecatedFields
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if name, _ := jsonFieldName(field); name == "-" || len(field.PkgPath) > 0 && !field.Anonymous {
+				continue
+			}
+			if isDeprecatedField(field) {
+				return staticDeprecatedFields
+			}
+			fieldRes := typeFieldsDeprecation(field.Type, visited)
+			if fieldRes == staticDeprecatedFields {
+				return staticDeprecatedFields
+			}
+			if fieldRes > res {
+				res = fieldRes
+			}
+		}
+		return res
+	}
+	return noDeprecatedFields
+}
+
+func isDeprecatedField(field reflect.StructField) bool {
+	return field.Tag.Get(deprecatedFieldTag) == "true"
+}
+
+func isJsonLeaf(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+func containsDeprecatedFields(v reflect.Value) bool {
+	v, ok := indirectValue(v)
+	if !ok {
+		return false
+	}
+	switch getFieldsDeprecation(v.Type()) {
+	case noDeprecatedFields:
+		return false
+	case staticDeprecatedFields:
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsDeprecatedFields(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if containsDeprecatedFields(iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for _, i := range getDynamicFields(v.Type()) {
+			if containsDeprecatedFields(v.Field(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dynamicFieldsByType caches the indexes of struct fields whose values may contain deprecated fields, so values
+// are walked through their interfaces only
+var dynamicFieldsByType sync.Map
+
+func getDynamicFields(t reflect.Type) []int {
+	if res, ok := dynamicFieldsByType.Load(t); ok {
+		return res.([]int)
+	}
+	var res []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, _ := jsonFieldName(field); name == "-" || len(field.PkgPath) > 0 && !field.Anonymous {
+			continue
+		}
+		if getFieldsDeprecation(field.Type) != noDeprecatedFields {
+			res = append(res, i)
+		}
+	}
+	dynamicFieldsByType.Store(t, res)
+	return res
+}
+
+// withoutDeprecatedFields returns the value to encode instead of the result in v2 responses, structs with deprecated
+// fields are replaced with objects of the remaining fields in the declaration order
+func withoutDeprecatedFields(result interface{}) interface{} {
+	if result == nil {
+		return nil
+	}
+	return projectValue(reflect.ValueOf(result))
+}
+
+func projectValue(v reflect.Value) interface{} {
+	if getFieldsDeprecation(v.Type()) == noDeprecatedFields {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return projectValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		res := make([]interface{}, v.Len())
+		for i := range res {
+			res[i] = projectValue(v.Index(i))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		res := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), emptyInterfaceType), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.ValueOf(projectValue(iter.Value()))
+			if !value.IsValid() {
+				value = reflect.Zero(emptyInterfaceType)
+			}
+			res.SetMapIndex(iter.Key(), value)
+		}
+		return res.Interface()
+	case reflect.Struct:
+		var res jsonObject
+		res.appendStructFields(v)
+		return res
+	}
+	return v.Interface()
+}
+
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+type jsonField struct {
+	name  string
+	value interface{}
+}
+
+// jsonObject keeps the order of struct fields which maps lose
+type jsonObject []jsonField
+
+func (o *jsonObject) appendStructFields(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty := jsonFieldName(field)
+		if name == "-" || isDeprecatedField(field) {
+			continue
+		}
+		if field.Anonymous && len(name) == 0 && indirectType(field.Type).Kind() == reflect.Struct {
+			if embedded, ok := indirectValue(v.Field(i)); ok {
+				o.appendStructFields(embedded)
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if omitEmpty && isEmptyJsonValue(v.Field(i)) {
+			continue
+		}
+		*o = append(*o, jsonField{name: name, value: projectValue(v.Field(i))})
+	}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isEmptyJsonValue reports whether the omitempty field is omitted by encoding/json
+func isEmptyJsonValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_withoutDeprecatedFields(t *testing.T) {
+	entity := types.Entity{NameOld: "Address", Name: "Address", Value: "0x01"}
+	b, err := json.Marshal(withoutDeprecatedFields(entity))
+	require.Nil(t, err)
+	require.Equal(t, `{"name":"Address","value":"0x01","ref":""}`, string(b))
+
+	tx := types.TransactionSummary{
+		Hash: "0x02",
+		Type: "OnlineStatusTx",
+		Data: &types.OnlineStatusTxSpecificData{BecomeOnlineOld: true, BecomeOnline: true},
+	}
+	b, err = json.Marshal(withoutDeprecatedFields([]types.TransactionSummary{tx}))
+	require.Nil(t, err)
+	require.NotContains(t, string(b), "BecomeOnline\"")
+	require.Contains(t, string(b), `"data":{"becomeOnline":true}`)
+	require.True(t, containsDeprecatedFields(reflect.ValueOf(tx)))
+
+	value := types.EpochSummary{Epoch: 1}
+	require.Equal(t, value, withoutDeprecatedFields(value))
+	require.False(t, containsDeprecatedFields(reflect.ValueOf(value)))
+
+	type dynamicItem struct {
+		Epoch uint64      `json:"epoch"`
+		Data  interface{} `json:"data"`
+	}
+	require.Equal(t, []int{1}, getDynamicFields(reflect.TypeOf(dynamicItem{})))
+	require.False(t, containsDeprecatedFields(reflect.ValueOf(dynamicItem{Data: value})))
+	require.True(t, containsDeprecatedFields(reflect.ValueOf(dynamicItem{Data: tx})))
+}
+
+func Test_versionedRoutes(t *testing.T) {
+	deprecations, err := newDeprecations("2027-01-01T00:00:00Z", nil)
+	require.Nil(t, err)
+	s := &httpServer{logger: log.New(), deprecations: deprecations}
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(s.validatePathVars, s.deprecationFilter)
+	flips := func(w http.ResponseWriter, r *http.Request) {
+		WriteResponsePage(w, []types.FlipSummary{{Cid: "cid", WrongWords: true}}, nil, nil, s.logger)
+	}
+	s.deprecations.deprecateRoute(apiRouter.Path("/address/{address}/flips").HandlerFunc(flips), "/Identity/{address}/Flips")
+	apiRouter.Path("/identity/{address}/flips").HandlerFunc(flips)
+	handler := s.versionFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Paths are lower cased by requestFilter
+		r.URL.Path = strings.ToLower(r.URL.Path)
+		s.serveCacheable(router, w, r, jsonFormat)
+	}))
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+	address := "0x" + strings.Repeat("ab", addressLength)
+
+	w := serve("/api/address/" + address + "/flips")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+	require.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	require.Equal(t, "</api/v2/Identities/"+address+"/Flips>; rel=\"successor-version\"", w.Header().Get("Link"))
+	require.Contains(t, w.Body.String(), `"wrongWords":true`)
+
+	w = serve("/api/v2/addresses/" + address + "/flips")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), `"code":"not_found"`)
+	require.Contains(t, w.Body.String(), "/Identities/"+address+"/Flips")
+
+	w = serve("/api/identity/" + address + "/flips")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+	require.Equal(t, "</api/v2/Identities/"+address+"/flips>; rel=\"successor-version\"", w.Header().Get("Link"))
+
+	w = serve("/api/v2/identity/" + address + "/flips")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), "use /api/v2/Identities/"+address+"/flips")
+
+	w = serve("/api/v2/identities/" + address + "/flips")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Deprecation"))
+	require.NotContains(t, w.Body.String(), `"wrongWords"`)
+	require.Contains(t, w.Body.String(), `"continuationToken":null`)
+}
+
+func Test_v2ResourcePaths(t *testing.T) {
+	address := "0x" + strings.Repeat("ab", addressLength)
+	for v2, v1 := range map[string]string{
+		"/Epochs":       "/Epochs",
+		"/Epochs/Count": "/Epochs/Count",
+		"/Epochs/Last":  "/Epoch/Last",
+		"/Epochs/10":    "/Epoch/10",
+		"/epochs/10/identities/" + address + "/rewards":                        "/Epoch/10/Identity/" + address + "/rewards",
+		"/Epochs/10/Addresses/" + address + "/DelegateeRewards":                "/Epoch/10/Address/" + address + "/DelegateeRewards",
+		"/Addresses/" + address + "/Tokens/" + address:                         "/Address/" + address + "/Token/" + address,
+		"/Addresses/" + address + "/Contracts/" + address + "/Balance/Changes": "/Address/" + address + "/Contract/" + address + "/BalanceUpdates",
+		"/Contracts/" + address + "/Balance/Changes":                           "/Contract/" + address + "/BalanceUpdates",
+		"/OracleVotingContracts/EstimatedOracleRewards":                        "/OracleVotingContracts/EstimatedOracleRewards",
+		"/OracleVotingContracts/" + address:                                    "/OracleVotingContract/" + address,
+		"/Upgrades/3/VotingHistory":                                            "/Upgrade/3/VotingHistory",
+		"/Pools/Count":                                                         "/Pools/Count",
+	} {
+		path, err := v1Path(v2)
+		require.Nil(t, err, v2)
+		require.Equal(t, v1, path, v2)
+	}
+
+	_, err := v1Path("/Epoch/10/Txs")
+	require.Equal(t, apierrors.NotFound, apierrors.CodeOf(err))
+	require.Equal(t, "path is not available in v2, use /api/v2/Epochs/10/Txs", err.Error())
+
+	path, renamed := v2Path("/Contract/" + address + "/BalanceUpdates")
+	require.True(t, renamed)
+	require.Equal(t, "/Contracts/"+address+"/Balance/Changes", path)
+	path, renamed = v2Path("/Balances")
+	require.False(t, renamed)
+	require.Equal(t, "/Balances", path)
+}
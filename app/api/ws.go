@@ -0,0 +1,423 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	wsPath               = "/api/ws"
+	wsWriteTimeout       = time.Second * 10
+	wsPongTimeout        = time.Minute
+	wsPingInterval       = wsPongTimeout * 9 / 10
+	wsMaxMessageSize     = 1024
+	wsEventsBufferSize   = 100
+	wsMessagesBufferSize = 10
+
+	wsSubscribeAction   = "subscribe"
+	wsUnsubscribeAction = "unsubscribe"
+)
+
+type wsRequest struct {
+	Action  string       `json:"action"`
+	Topic   events.Topic `json:"topic"`
+	Address string       `json:"address,omitempty"`
+}
+
+type wsResponse struct {
+	Action  string       `json:"action,omitempty"`
+	Topic   events.Topic `json:"topic,omitempty"`
+	Address string       `json:"address,omitempty"`
+	Result  interface{}  `json:"result,omitempty"`
+	Error   *RespError   `json:"error,omitempty"`
+}
+
+type wsTopicKey struct {
+	topic   events.Topic
+	address string
+}
+
+var (
+	errWsUnknownAction       = apierrors.New(apierrors.InvalidArgument, "unknown action")
+	errWsUnknownTopic        = apierrors.New(apierrors.InvalidArgument, "unknown topic")
+	errWsAddressRequired     = apierrors.New(apierrors.InvalidArgument, "address required")
+	errWsSubscriptionsExceed = apierrors.New(apierrors.RateLimited, "subscription limit exceeded")
+	errWsNotSubscribed       = apierrors.New(apierrors.InvalidArgument, "not subscribed")
+	errEventsNotEnabled      = apierrors.New(apierrors.NotFound, "events are not enabled")
+)
+
+func isAddressTopic(topic events.Topic) (isAddressTopic bool, ok bool) {
+	switch topic {
+	case events.NewBlockTopic, events.NewEpochTopic:
+		return false, true
+	case events.MemPoolTxTopic, events.BalanceChangeTopic:
+		return true, true
+	}
+	return false, false
+}
+
+// wsOriginChecker allows upgrades of non-browser clients, pages of the api host and configured origins
+type wsOriginChecker struct {
+	anyOrigin bool
+	origins   map[string]struct{}
+}
+
+func newWsOriginChecker(allowedOrigins []string) *wsOriginChecker {
+	checker := &wsOriginChecker{
+		origins: make(map[string]struct{}, len(allowedOrigins)),
+	}
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			checker.anyOrigin = true
+			continue
+		}
+		checker.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+	return checker
+}
+
+func (checker *wsOriginChecker) check(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 || checker.anyOrigin {
+		return true
+	}
+	if _, ok := checker.origins[strings.ToLower(origin)]; ok {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func (s *httpServer) ws(w http.ResponseWriter, r *http.Request) {
+	if !s.wsEnabled {
+		s.writeRejectedRequest(w, errEventsNotEnabled)
+		return
+	}
+	reqClient, err := s.resolveClient(r)
+	if err != nil {
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	rateLimit, err := s.limiter.checkClientReqLimit(reqClient)
+	writeRateLimitHeaders(w, rateLimit)
+	if err != nil {
+		s.recordApiKeyRequest(reqClient, true)
+		writeRetryAfterHeader(w, rateLimit)
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	s.recordApiKeyRequest(reqClient, false)
+	if err := s.limiter.takeConnection(reqClient.id); err != nil {
+		s.writeRejectedRequest(w, err)
+		return
+	}
+	defer s.limiter.releaseConnection(reqClient.id)
+	upgrader := websocket.Upgrader{
+		CheckOrigin: s.wsOriginChecker.check,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Debug("Unable to upgrade ws connection", "from", reqClient.ip, "err", err)
+		return
+	}
+	s.logger.Debug("Opened ws connection", "from", reqClient.ip)
+	c := &wsConnection{
+		server:       s,
+		conn:         conn,
+		client:       reqClient,
+		subscription: s.eventBus.Subscribe(wsEventsBufferSize),
+		topics:       make(map[wsTopicKey]struct{}),
+		messages:     make(chan *wsResponse, wsMessagesBufferSize),
+		done:         make(chan struct{}),
+	}
+	c.run()
+	s.logger.Debug("Closed ws connection", "from", reqClient.ip)
+}
+
+type wsConnection struct {
+	server       *httpServer
+	conn         *websocket.Conn
+	client       *client
+	subscription events.Subscription
+	topics       map[wsTopicKey]struct{}
+	topicsMutex  sync.RWMutex
+	messages     chan *wsResponse
+	// done is closed once either of the read and write loops exits to stop the other one
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (c *wsConnection) run() {
+	defer c.subscription.Unsubscribe()
+	writerStopped := make(chan struct{})
+	go func() {
+		defer close(writerStopped)
+		defer c.stop()
+		c.writeLoop()
+	}()
+	eventsStopped := make(chan struct{})
+	go func() {
+		defer close(eventsStopped)
+		defer c.stop()
+		c.eventLoop()
+	}()
+	c.readLoop()
+	c.stop()
+	<-writerStopped
+	<-eventsStopped
+}
+
+func (c *wsConnection) stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *wsConnection) readLoop() {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	for {
+		req := &wsRequest{}
+		if err := c.conn.ReadJSON(req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.server.logger.Debug("Unable to read ws message", "from", c.client.ip, "err", err)
+			}
+			return
+		}
+		resp := c.handleRequest(req)
+		select {
+		case c.messages <- resp:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *wsConnection) handleRequest(req *wsRequest) *wsResponse {
+	resp := &wsResponse{
+		Action:  req.Action,
+		Topic:   req.Topic,
+		Address: req.Address,
+	}
+	var err error
+	if _, err = c.server.limiter.checkClientReqLimit(c.client); err == nil {
+		switch req.Action {
+		case wsSubscribeAction:
+			err = c.subscribe(req.Topic, req.Address)
+		case wsUnsubscribeAction:
+			err = c.unsubscribe(req.Topic, req.Address)
+		default:
+			err = errWsUnknownAction
+		}
+	}
+	if err != nil {
+		resp.Error = &RespError{
+			Message: err.Error(),
+			Code:    apierrors.CodeOf(err),
+		}
+	}
+	return resp
+}
+
+func (c *wsConnection) topicKey(topic events.Topic, address string) (wsTopicKey, error) {
+	isAddress, ok := isAddressTopic(topic)
+	if !ok {
+		return wsTopicKey{}, errWsUnknownTopic
+	}
+	key := wsTopicKey{
+		topic: topic,
+	}
+	if isAddress {
+		if len(address) == 0 {
+			return wsTopicKey{}, errWsAddressRequired
+		}
+		key.address = strings.ToLower(address)
+	}
+	return key, nil
+}
+
+func (c *wsConnection) subscribe(topic events.Topic, address string) error {
+	key, err := c.topicKey(topic, address)
+	if err != nil {
+		return err
+	}
+	c.topicsMutex.Lock()
+	defer c.topicsMutex.Unlock()
+	if _, ok := c.topics[key]; ok {
+		return nil
+	}
+	if c.server.wsMaxSubscriptions > 0 && len(c.topics) >= c.server.wsMaxSubscriptions {
+		return errWsSubscriptionsExceed
+	}
+	c.topics[key] = struct{}{}
+	return nil
+}
+
+func (c *wsConnection) unsubscribe(topic events.Topic, address string) error {
+	key, err := c.topicKey(topic, address)
+	if err != nil {
+		return err
+	}
+	c.topicsMutex.Lock()
+	defer c.topicsMutex.Unlock()
+	if _, ok := c.topics[key]; !ok {
+		return errWsNotSubscribed
+	}
+	delete(c.topics, key)
+	return nil
+}
+
+func (c *wsConnection) subscribedAddresses(event events.Event) []string {
+	c.topicsMutex.RLock()
+	defer c.topicsMutex.RUnlock()
+	var res []string
+	for key := range c.topics {
+		if key.topic == event.Topic && event.HasAddress(key.address) {
+			res = append(res, key.address)
+		}
+	}
+	return res
+}
+
+func (c *wsConnection) isSubscribed(topic events.Topic) bool {
+	c.topicsMutex.RLock()
+	defer c.topicsMutex.RUnlock()
+	_, ok := c.topics[wsTopicKey{topic: topic}]
+	return ok
+}
+
+// writeLoop closes the connection on exit which stops the read loop blocked on reading
+func (c *wsConnection) writeLoop() {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	defer c.conn.Close()
+	for {
+		select {
+		case <-c.done:
+			c.write(websocket.CloseMessage, nil)
+			return
+		case <-c.server.closing:
+			c.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown"))
+			return
+		case resp := <-c.messages:
+			if err := c.writeJSON(resp); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventLoop passes subscribed events to the write loop, it loads event data itself not to delay pings and messages
+func (c *wsConnection) eventLoop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+		case <-c.server.closing:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-c.subscription.Events():
+			if !ok {
+				return
+			}
+			for _, resp := range c.eventResponses(ctx, event) {
+				select {
+				case c.messages <- resp:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *wsConnection) eventResponses(ctx context.Context, event events.Event) []*wsResponse {
+	isAddress, ok := isAddressTopic(event.Topic)
+	if !ok {
+		return nil
+	}
+	if !isAddress {
+		if !c.isSubscribed(event.Topic) {
+			return nil
+		}
+		return []*wsResponse{{
+			Topic:  event.Topic,
+			Result: event.Data,
+		}}
+	}
+	addresses := c.subscribedAddresses(event)
+	if len(addresses) == 0 {
+		return nil
+	}
+	res := make([]*wsResponse, 0, len(addresses))
+	for _, address := range addresses {
+		resp := &wsResponse{
+			Topic:   event.Topic,
+			Address: address,
+			Result:  event.Data,
+		}
+		if event.Topic == events.BalanceChangeTopic {
+			if ctx.Err() != nil {
+				return nil
+			}
+			addressInfo, err := c.loadAddress(ctx, address)
+			if err != nil {
+				c.server.logger.Warn(fmt.Sprintf("Unable to load address %v for ws event: %v", address, err))
+				continue
+			}
+			resp.Result = addressInfo
+		}
+		res = append(res, resp)
+	}
+	return res
+}
+
+// loadAddress is limited by the handler timeout of the ws path
+func (c *wsConnection) loadAddress(ctx context.Context, address string) (types.Address, error) {
+	if timeout := c.server.limiter.getHandlerTimeout(wsPath); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return c.server.service.Address(ctx, address)
+}
+
+func (c *wsConnection) writeJSON(v interface{}) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(v)
+}
+
+func (c *wsConnection) write(messageType int, data []byte) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(messageType, data)
+}
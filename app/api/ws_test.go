@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newWsTestServer(t *testing.T, allowedOrigins []string) (*httpServer, string, <-chan struct{}) {
+	ipResolver, err := newIpResolver(config.ClientIpConfig{})
+	require.Nil(t, err)
+	s := &httpServer{
+		logger:  log.New(),
+		closing: make(chan struct{}),
+		limiter: &reqLimiter{
+			reqCountsByClientId: cache.New(reqLimitWindow, time.Minute),
+			connLimit:           1,
+		},
+		eventBus:           events.NewBus(),
+		wsEnabled:          true,
+		wsMaxSubscriptions: 2,
+		wsOriginChecker:    newWsOriginChecker(allowedOrigins),
+		ipResolver:         ipResolver,
+	}
+	handled := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ws(w, r)
+		handled <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return s, "ws" + strings.TrimPrefix(srv.URL, "http"), handled
+}
+
+func requireHandled(t *testing.T, handled <-chan struct{}) {
+	select {
+	case <-handled:
+	case <-time.After(time.Second * 5):
+		require.Fail(t, "ws handler is not completed")
+	}
+}
+
+func Test_ws_checkOrigin(t *testing.T) {
+	_, wsUrl, _ := newWsTestServer(t, []string{"https://scan.idena.io"})
+
+	header := http.Header{}
+	header.Set("Origin", "https://evil.example")
+	_, resp, err := websocket.DefaultDialer.Dial(wsUrl, header)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Origin", "https://scan.idena.io")
+	conn, _, err := websocket.DefaultDialer.Dial(wsUrl, header)
+	require.Nil(t, err)
+	conn.Close()
+
+	checker := newWsOriginChecker(nil)
+	r := httptest.NewRequest(http.MethodGet, "http://api.idena.io/api/ws", nil)
+	require.True(t, checker.check(r))
+	r.Header.Set("Origin", "http://api.idena.io")
+	require.True(t, checker.check(r))
+	r.Header.Set("Origin", "http://other.idena.io")
+	require.False(t, checker.check(r))
+	require.True(t, newWsOriginChecker([]string{"*"}).check(r))
+}
+
+func Test_ws_subscribe(t *testing.T) {
+	s, wsUrl, handled := newWsTestServer(t, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	resp := &wsResponse{}
+	require.Nil(t, conn.WriteJSON(wsRequest{Action: "unknown", Topic: events.NewBlockTopic}))
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Equal(t, apierrors.InvalidArgument, resp.Error.Code)
+
+	resp = &wsResponse{}
+	require.Nil(t, conn.WriteJSON(wsRequest{Action: wsSubscribeAction, Topic: events.MemPoolTxTopic}))
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Equal(t, errWsAddressRequired.Error(), resp.Error.Message)
+
+	resp = &wsResponse{}
+	require.Nil(t, conn.WriteJSON(wsRequest{Action: wsSubscribeAction, Topic: events.NewBlockTopic}))
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Nil(t, resp.Error)
+
+	resp = &wsResponse{}
+	require.Nil(t, conn.WriteJSON(wsRequest{Action: wsSubscribeAction, Topic: events.MemPoolTxTopic, Address: "0xAA"}))
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Nil(t, resp.Error)
+
+	resp = &wsResponse{}
+	require.Nil(t, conn.WriteJSON(wsRequest{Action: wsSubscribeAction, Topic: events.MemPoolTxTopic, Address: "0xbb"}))
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Equal(t, apierrors.RateLimited, resp.Error.Code)
+
+	s.eventBus.Publish(events.Event{Topic: events.NewEpochTopic, Data: 1})
+	s.eventBus.Publish(events.Event{Topic: events.MemPoolTxTopic, Addresses: []string{"0xbb"}, Data: "0x01"})
+	s.eventBus.Publish(events.Event{Topic: events.NewBlockTopic, Data: 100.0})
+	s.eventBus.Publish(events.Event{Topic: events.MemPoolTxTopic, Addresses: []string{"0xaa"}, Data: "0x02"})
+
+	resp = &wsResponse{}
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Equal(t, events.NewBlockTopic, resp.Topic)
+	require.Equal(t, 100.0, resp.Result)
+	resp = &wsResponse{}
+	require.Nil(t, conn.ReadJSON(resp))
+	require.Equal(t, events.MemPoolTxTopic, resp.Topic)
+	require.Equal(t, "0xaa", resp.Address)
+	require.Equal(t, "0x02", resp.Result)
+
+	// The connection limit of the client is released once the handler is completed
+	_, resp2, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp2.StatusCode)
+
+	require.Nil(t, conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	requireHandled(t, handled)
+	requireHandled(t, handled)
+	conn2, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	require.Nil(t, err)
+	conn2.Close()
+}
+
+func Test_ws_stopLoops(t *testing.T) {
+	s, wsUrl, handled := newWsTestServer(t, nil)
+
+	// The read loop is stopped when the write loop exits on the server shutdown
+	conn, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	require.Nil(t, err)
+	defer conn.Close()
+	close(s.closing)
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	requireHandled(t, handled)
+
+	// The write loop is stopped when the read loop exits on the broken client message
+	s.closing = make(chan struct{})
+	conn, _, err = websocket.DefaultDialer.Dial(wsUrl, nil)
+	require.Nil(t, err)
+	defer conn.Close()
+	require.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+	requireHandled(t, handled)
+}
+
+func Test_ws_eventResponsesClosing(t *testing.T) {
+	// The server has no service, addresses must not be loaded for closing connections
+	c := &wsConnection{
+		server: &httpServer{},
+		topics: map[wsTopicKey]struct{}{{topic: events.BalanceChangeTopic, address: "0xaa"}: {}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Empty(t, c.eventResponses(ctx, events.Event{Topic: events.BalanceChangeTopic, Addresses: []string{"0xaa"}}))
+}
@@ -0,0 +1,115 @@
+package apierrors
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+// Code is the machine-readable kind of error clients can rely on instead of the error message
+type Code string
+
+const (
+	NotFound            Code = "not_found"
+	InvalidArgument     Code = "invalid_argument"
+	Unauthorized        Code = "unauthorized"
+	RateLimited         Code = "rate_limited"
+	UpstreamUnavailable Code = "upstream_unavailable"
+	Timeout             Code = "timeout"
+	Conflict            Code = "conflict"
+	Internal            Code = "internal"
+)
+
+// Error carries the code of the error through the accessor, service and indexer layers up to the response
+type Error struct {
+	code    Code
+	message string
+	details map[string]interface{}
+	cause   error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{
+		code:    code,
+		message: message,
+	}
+}
+
+func Errorf(code Code, format string, args ...interface{}) *Error {
+	return New(code, errors.Errorf(format, args...).Error())
+}
+
+// Wrap assigns the code to the error keeping its message, the error remains available with errors.Is and errors.As
+func Wrap(code Code, err error) *Error {
+	return &Error{
+		code:    code,
+		message: err.Error(),
+		cause:   err,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Code() Code {
+	return e.code
+}
+
+func (e *Error) Details() map[string]interface{} {
+	return e.details
+}
+
+// WithDetails returns the copy of the error with the details added to the response
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	res := *e
+	res.details = details
+	return &res
+}
+
+// CodeOf returns the code of the first coded error in the chain, context deadline errors are timeouts,
+// errors without a code are internal
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+	return Internal
+}
+
+// DetailsOf returns the details of the first coded error in the chain
+func DetailsOf(err error) map[string]interface{} {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.details
+	}
+	return nil
+}
+
+func HttpStatus(code Code) int {
+	switch code {
+	case NotFound:
+		return http.StatusNotFound
+	case InvalidArgument:
+		return http.StatusBadRequest
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case UpstreamUnavailable:
+		return http.StatusBadGateway
+	case Timeout:
+		return http.StatusGatewayTimeout
+	case Conflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
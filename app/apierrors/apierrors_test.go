@@ -0,0 +1,34 @@
+package apierrors
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func Test_CodeOf(t *testing.T) {
+	notFound := New(NotFound, "no data found")
+	require.Equal(t, NotFound, CodeOf(notFound))
+	require.Equal(t, NotFound, CodeOf(errors.Wrap(notFound, "address 0x01")))
+	require.Equal(t, Timeout, CodeOf(errors.Wrap(context.DeadlineExceeded, "unable to send request")))
+	require.Equal(t, Internal, CodeOf(errors.New("unknown")))
+
+	err := Wrap(UpstreamUnavailable, context.Canceled)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, context.Canceled.Error(), err.Error())
+
+	withDetails := Errorf(InvalidArgument, "too big value limit=%d", 200).WithDetails(map[string]interface{}{"maxLimit": 100})
+	require.Equal(t, "too big value limit=200", withDetails.Error())
+	require.Equal(t, 100, DetailsOf(errors.Wrap(withDetails, "wrapped"))["maxLimit"])
+	require.Nil(t, DetailsOf(notFound))
+
+	require.Equal(t, http.StatusNotFound, HttpStatus(NotFound))
+	require.Equal(t, http.StatusBadRequest, HttpStatus(InvalidArgument))
+	require.Equal(t, http.StatusTooManyRequests, HttpStatus(RateLimited))
+	require.Equal(t, http.StatusBadGateway, HttpStatus(UpstreamUnavailable))
+	require.Equal(t, http.StatusGatewayTimeout, HttpStatus(Timeout))
+	require.Equal(t, http.StatusConflict, HttpStatus(Conflict))
+	require.Equal(t, http.StatusInternalServerError, HttpStatus(Internal))
+}
@@ -0,0 +1,228 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+// Tier sets limits shared by all keys assigned to it
+type Tier struct {
+	// ReqsPerMinute is the request quota of every key of the tier, 0 means unlimited
+	ReqsPerMinute int
+	// QueueShare is the part of the request queue reserved for the tier on startup, 0 means the common queue is used
+	QueueShare float64
+	// MaxLimit is the max page size, 0 means the default one
+	MaxLimit uint64
+	// ExportsPerDay is the export quota of every key of the tier, 0 means the default one
+	ExportsPerDay int
+}
+
+type Key struct {
+	Key      string
+	Name     string
+	Tier     string
+	Disabled bool
+}
+
+type keysFile struct {
+	Tiers map[string]Tier
+	Keys  []Key
+}
+
+// Client is the owner of a valid key
+type Client struct {
+	Name     string
+	TierName string
+	Tier     Tier
+}
+
+type Usage struct {
+	Name             string     `json:"name"`
+	Tier             string     `json:"tier"`
+	Requests         uint64     `json:"requests"`
+	RejectedRequests uint64     `json:"rejectedRequests"`
+	LastRequestTime  *time.Time `json:"lastRequestTime,omitempty"`
+}
+
+type Holder interface {
+	Client(key string) (*Client, error)
+	RecordRequest(client *Client, rejected bool)
+	Usage() []Usage
+	Tiers() map[string]Tier
+}
+
+// NewHolder loads keys from the JSON file and reloads them when the file changes
+func NewHolder(filePath string, reloadInterval time.Duration, lc *lifecycle.Manager, logger log.Logger) Holder {
+	holder := &holderImpl{
+		filePath:     filePath,
+		logger:       logger,
+		usagesByName: make(map[string]*Usage),
+		clientsByKey: make(map[string]*Client),
+	}
+	if _, err := holder.updateIfNeeded(); err != nil {
+		panic(err)
+	}
+	lc.Go(func(ctx context.Context) {
+		holder.updateLoop(ctx, reloadInterval)
+	})
+	return holder
+}
+
+type holderImpl struct {
+	filePath     string
+	modTime      *time.Time
+	logger       log.Logger
+	mutex        sync.RWMutex
+	clientsByKey map[string]*Client
+	tiers        map[string]Tier
+	usageMutex   sync.Mutex
+	usagesByName map[string]*Usage
+}
+
+func (holder *holderImpl) Client(key string) (*Client, error) {
+	holder.mutex.RLock()
+	defer holder.mutex.RUnlock()
+	client, ok := holder.clientsByKey[key]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return client, nil
+}
+
+func (holder *holderImpl) RecordRequest(client *Client, rejected bool) {
+	now := time.Now().UTC()
+	holder.usageMutex.Lock()
+	defer holder.usageMutex.Unlock()
+	usage, ok := holder.usagesByName[client.Name]
+	if !ok {
+		usage = &Usage{
+			Name: client.Name,
+		}
+		holder.usagesByName[client.Name] = usage
+	}
+	usage.Tier = client.TierName
+	usage.Requests++
+	if rejected {
+		usage.RejectedRequests++
+	}
+	usage.LastRequestTime = &now
+}
+
+func (holder *holderImpl) Usage() []Usage {
+	holder.usageMutex.Lock()
+	res := make([]Usage, 0, len(holder.usagesByName))
+	for _, usage := range holder.usagesByName {
+		res = append(res, *usage)
+	}
+	holder.usageMutex.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func (holder *holderImpl) Tiers() map[string]Tier {
+	holder.mutex.RLock()
+	defer holder.mutex.RUnlock()
+	return holder.tiers
+}
+
+func (holder *holderImpl) updateLoop(ctx context.Context, interval time.Duration) {
+	for lifecycle.Sleep(ctx, interval) {
+		ok, err := holder.updateIfNeeded()
+		if err != nil {
+			holder.logger.Warn(errors.Wrap(err, "Unable to reload api keys").Error())
+			continue
+		}
+		if ok {
+			holder.logger.Info("Api keys updated")
+		}
+	}
+}
+
+func (holder *holderImpl) updateIfNeeded() (bool, error) {
+	fileInfo, err := os.Stat(holder.filePath)
+	if err != nil {
+		return false, errors.Errorf("unable to find api keys file %v", holder.filePath)
+	}
+	if holder.modTime != nil && !fileInfo.ModTime().After(*holder.modTime) {
+		return false, nil
+	}
+	f, err := readKeysFile(holder.filePath)
+	if err != nil {
+		return false, err
+	}
+	clientsByKey, err := parseKeys(f)
+	if err != nil {
+		return false, err
+	}
+	modTime := fileInfo.ModTime()
+	holder.modTime = &modTime
+	holder.mutex.Lock()
+	holder.clientsByKey = clientsByKey
+	holder.tiers = f.Tiers
+	holder.mutex.Unlock()
+	return true, nil
+}
+
+func readKeysFile(filePath string) (*keysFile, error) {
+	bytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read api keys file %v", filePath)
+	}
+	f := &keysFile{}
+	if err := json.Unmarshal(bytes, f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse api keys file %v", filePath)
+	}
+	return f, nil
+}
+
+func parseKeys(f *keysFile) (map[string]*Client, error) {
+	var queueShare float64
+	for name, tier := range f.Tiers {
+		if tier.ReqsPerMinute < 0 || tier.QueueShare < 0 || tier.QueueShare > 1 {
+			return nil, errors.Errorf("invalid limits of tier %v", name)
+		}
+		queueShare += tier.QueueShare
+	}
+	if queueShare >= 1 {
+		return nil, errors.New("queue shares of tiers must leave a part of the request queue to clients without api keys")
+	}
+	res := make(map[string]*Client, len(f.Keys))
+	names := make(map[string]struct{}, len(f.Keys))
+	for _, key := range f.Keys {
+		if len(key.Key) == 0 || len(key.Name) == 0 {
+			return nil, errors.New("api key and its name must not be empty")
+		}
+		if _, ok := res[key.Key]; ok {
+			return nil, errors.Errorf("duplicate api key of %v", key.Name)
+		}
+		if _, ok := names[key.Name]; ok {
+			return nil, errors.Errorf("duplicate api key name %v", key.Name)
+		}
+		names[key.Name] = struct{}{}
+		tier, ok := f.Tiers[key.Tier]
+		if !ok {
+			return nil, errors.Errorf("unknown tier %v of api key %v", key.Tier, key.Name)
+		}
+		if key.Disabled {
+			continue
+		}
+		res[key.Key] = &Client{
+			Name:     key.Name,
+			TierName: key.Tier,
+			Tier:     tier,
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package apikeys
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_parseKeys(t *testing.T) {
+	f := &keysFile{
+		Tiers: map[string]Tier{
+			"free": {ReqsPerMinute: 60},
+			"pro":  {ReqsPerMinute: 600, QueueShare: 0.5, MaxLimit: 1000},
+		},
+		Keys: []Key{
+			{Key: "k1", Name: "alice", Tier: "free"},
+			{Key: "k2", Name: "bob", Tier: "pro"},
+			{Key: "k3", Name: "carol", Tier: "pro", Disabled: true},
+		},
+	}
+	clientsByKey, err := parseKeys(f)
+	require.Nil(t, err)
+	require.Len(t, clientsByKey, 2)
+	require.Equal(t, "bob", clientsByKey["k2"].Name)
+	require.Equal(t, uint64(1000), clientsByKey["k2"].Tier.MaxLimit)
+	require.Nil(t, clientsByKey["k3"])
+
+	f.Keys = append(f.Keys, Key{Key: "k1", Name: "dave", Tier: "free"})
+	_, err = parseKeys(f)
+	require.NotNil(t, err)
+
+	f.Keys = []Key{{Key: "k1", Name: "alice", Tier: "unknown"}}
+	_, err = parseKeys(f)
+	require.NotNil(t, err)
+
+	f.Keys = nil
+	f.Tiers["wrong"] = Tier{QueueShare: 2}
+	_, err = parseKeys(f)
+	require.NotNil(t, err)
+
+	// The common queue can't be reserved by tiers entirely
+	f.Tiers["wrong"] = Tier{QueueShare: 0.5}
+	_, err = parseKeys(f)
+	require.NotNil(t, err)
+}
+
+func Test_holderImpl_RecordRequest(t *testing.T) {
+	holder := &holderImpl{
+		usagesByName: make(map[string]*Usage),
+	}
+	client := &Client{Name: "alice", TierName: "free"}
+	holder.RecordRequest(client, false)
+	holder.RecordRequest(client, true)
+	holder.RecordRequest(&Client{Name: "bob", TierName: "pro"}, false)
+
+	usage := holder.Usage()
+	require.Len(t, usage, 2)
+	require.Equal(t, "alice", usage[0].Name)
+	require.Equal(t, uint64(2), usage[0].Requests)
+	require.Equal(t, uint64(1), usage[0].RejectedRequests)
+	require.NotNil(t, usage[0].LastRequestTime)
+	require.Equal(t, "bob", usage[1].Name)
+}
@@ -1,23 +1,34 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/idena-network/idena-indexer-api/app/api"
+	"github.com/idena-network/idena-indexer-api/app/apikeys"
 	"github.com/idena-network/idena-indexer-api/app/changelog"
 	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/db/cached"
+	"github.com/idena-network/idena-indexer-api/app/db/memory"
 	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/exports"
+	"github.com/idena-network/idena-indexer-api/app/graphql"
+	"github.com/idena-network/idena-indexer-api/app/health"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
 	logUtil "github.com/idena-network/idena-indexer-api/app/log"
 	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"github.com/idena-network/idena-indexer-api/app/redis"
 	service2 "github.com/idena-network/idena-indexer-api/app/service"
 	"github.com/idena-network/idena-indexer-api/config"
 	"github.com/idena-network/idena-indexer-api/indexer"
 	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
 	"time"
 )
 
 type App interface {
 	Start(swaggerConfig config.SwaggerConfig)
+	// Destroy drains in-flight requests, stops background loops and closes connections
 	Destroy()
 }
 
@@ -31,40 +42,103 @@ func InitializeApp(
 	if err != nil {
 		panic(fmt.Sprintf("unable to initialize indexer logger: %v", err))
 	}
-	indexerClient := indexer.NewClient(conf.Indexer.Url, conf.Indexer.MaxConnections)
+	var metrics *monitoring.Registry
+	if conf.Metrics.Enabled {
+		metrics = monitoring.NewRegistry()
+	}
+	logger, err := logUtil.NewFileLogger("api.log", conf.LogFileSize)
+	if err != nil {
+		panic(err)
+	}
+	lc := lifecycle.NewManager(logger.New("component", "lifecycle"))
+	indexerClient := indexer.NewClient(conf.Indexer.Url, conf.Indexer.MaxConnections, metrics, lc)
 	indexerApi := indexer.NewApi(indexerClient, indexerLogger)
 	cachedNetworkSizeLoader := service2.NewCachedNetworkSizeLoader(indexer.NewNetworkSizeLoader(indexerApi))
 
 	memPool := indexer.NewMemPool(indexerApi)
 	contractsMemPool := indexer.NewContractsMemPool(indexerApi)
 
-	logger, err := logUtil.NewFileLogger("api.log", conf.LogFileSize)
+	pm, err := createPerformanceMonitor(conf.PerformanceMonitor, lc)
 	if err != nil {
 		panic(err)
 	}
-	pm, err := createPerformanceMonitor(conf.PerformanceMonitor)
-	if err != nil {
-		panic(err)
+	if metrics != nil {
+		pm = monitoring.NewCompositePerformanceMonitor(pm, monitoring.NewMetricsPerformanceMonitor(metrics))
+	}
+	dbAccessor := createDbAccessor(conf, cachedNetworkSizeLoader, logger)
+	if dbStatsProvider, ok := dbAccessor.(monitoring.DbStatsProvider); ok {
+		monitoring.RegisterDbStats(metrics, dbStatsProvider)
+	}
+	var eventBus events.Bus
+	if conf.WebSocket.Enabled || conf.EpochEvents.Enabled {
+		eventBus = events.NewBus()
+	}
+	if conf.WebSocket.Enabled {
+		events.NewBlockWatcher(dbAccessor, eventBus, lc, logger.New("component", "blockWatcher"))
+		events.NewMemPoolWatcher(memPool, eventBus, lc, logger.New("component", "memPoolWatcher"))
+	}
+	var epochEventHistory *events.History
+	if conf.EpochEvents.Enabled {
+		epochEventHistory = events.NewHistory(eventBus, []events.Topic{
+			events.NewEpochTopic,
+			events.ValidationStartedTopic,
+			events.InterimSummaryChangedTopic,
+			events.RewardsAvailableTopic,
+		}, conf.EpochEvents.HistorySize, lc)
 	}
 	accessor := cached.NewCachedAccessor(
-		postgres.NewPostgresAccessor(
-			conf.PostgresConnStr,
-			conf.ScriptsDir,
-			conf.DynamicEndpointsTable,
-			conf.DynamicEndpointStatesTable,
-			cachedNetworkSizeLoader,
-			conf.EmbeddedContractForkHeight,
-			logger,
-		),
+		dbAccessor,
 		memPool,
+		eventBus,
+		conf.EpochEvents.Enabled && conf.EpochEvents.PollValidation,
 		conf.DefaultCacheMaxItemCount,
 		time.Second*time.Duration(conf.DefaultCacheItemLifeTimeSec),
+		createCacheBackend(conf.Cache, lc, logger),
+		createChangeListener(conf, lc, logger),
+		metrics,
+		lc,
 		logger.New("component", "cachedDbAccessor"),
 	)
-	changeLog := changelog.NewChangeLog(conf.ChangeLogUrl, logger.New("component", "changeLog"))
-	service := api.NewService(accessor, memPool, indexerApi, changeLog)
+	changeLog := changelog.NewChangeLog(conf.ChangeLogUrl, lc, logger.New("component", "changeLog"))
+	service := api.NewService(accessor, memPool, indexerApi, changeLog, metrics)
 	contractsService := service2.NewContracts(accessor, contractsMemPool)
-	dynamicConfigHolder := config.NewDynamicConfigHolder(conf.DynamicConfigFile, logger.New("component", "dConfHolder"))
+	dynamicConfigHolder := config.NewDynamicConfigHolder(conf.DynamicConfigFile, lc, logger.New("component", "dConfHolder"))
+	var graphqlExecutor graphql.Executor
+	if conf.GraphQL.Enabled {
+		graphqlExecutor, err = graphql.NewExecutor(accessor, conf.GraphQL.MaxDepth)
+		if err != nil {
+			panic(err)
+		}
+	}
+	healthChecker := health.NewChecker(
+		dbAccessor,
+		func(ctx context.Context) error {
+			_, err := indexerApi.OnlineCount(ctx)
+			return err
+		},
+		conf.Health.IndexerRequired,
+		changeLog,
+		time.Second*time.Duration(conf.Health.MaxBlockAgeSec),
+		time.Second*time.Duration(conf.Health.CheckTimeoutSec),
+	)
+	var apiKeys apikeys.Holder
+	if len(conf.ApiKeys.File) > 0 {
+		apiKeys = apikeys.NewHolder(
+			conf.ApiKeys.File,
+			time.Second*time.Duration(conf.ApiKeys.ReloadIntervalSec),
+			lc,
+			logger.New("component", "apiKeys"),
+		)
+	}
+	var exportManager exports.Manager
+	if len(conf.Exports.Dir) > 0 {
+		exportManager = exports.NewManager(
+			conf.Exports,
+			api.NewExportRunner(dbAccessor, conf.Exports.PageSize),
+			lc,
+			logger.New("component", "exports"),
+		)
+	}
 	var dynamicEndpointLoader service2.DynamicEndpointLoader
 	if len(conf.DynamicEndpointsTable) > 0 {
 		dynamicEndpointLoader = service2.NewDynamicEndpointLoader(accessor)
@@ -72,6 +146,7 @@ func InitializeApp(
 	app := &app{
 		server: api.NewServer(
 			conf.Port,
+			conf.Server,
 			conf.LatestHours,
 			conf.ActiveAddressHours,
 			conf.ContractSizeLimit,
@@ -89,17 +164,88 @@ func InitializeApp(
 			pm,
 			maxReqCount,
 			timeout,
+			time.Second*time.Duration(conf.HandlerTimeoutSec),
 			reqsPerMinuteLimit,
 			dynamicEndpointLoader,
 			conf.Cors,
+			conf.LegacyErrors,
+			conf.DeprecationSunset,
+			eventBus,
+			conf.WebSocket,
+			epochEventHistory,
+			graphqlExecutor,
+			conf.GraphQL,
+			conf.RouteClasses,
+			conf.ClientIp,
+			metrics,
+			healthChecker,
+			apiKeys,
+			conf.ApiKeys.AdminToken,
+			exportManager,
+			lc,
 		),
-		db:     accessor,
-		logger: logger,
+		db:              accessor,
+		lc:              lc,
+		shutdownTimeout: time.Second * time.Duration(conf.Server.ShutdownTimeoutSec),
+		logger:          logger,
 	}
 	return app
 }
 
-func createPerformanceMonitor(c config.PerformanceMonitorConfig) (monitoring.PerformanceMonitor, error) {
+func createDbAccessor(conf *config.Config, networkSizeLoader service2.NetworkSizeLoader, logger log.Logger) db.Accessor {
+	switch conf.Storage {
+	case config.StorageMemory:
+		return memory.NewMemoryAccessor(conf.FixturesPath, logger.New("component", "memoryDbAccessor"))
+	case config.StoragePostgres, "":
+		return postgres.NewPostgresAccessor(
+			conf.PostgresConnStr,
+			conf.ScriptsDir,
+			conf.DynamicEndpointsTable,
+			conf.DynamicEndpointStatesTable,
+			networkSizeLoader,
+			conf.EmbeddedContractForkHeight,
+			logger,
+		)
+	default:
+		panic(fmt.Sprintf("unknown storage: %v", conf.Storage))
+	}
+}
+
+func createCacheBackend(c config.CacheConfig, lc *lifecycle.Manager, logger log.Logger) cached.Backend {
+	switch c.Backend {
+	case config.CacheBackendRedis:
+		client := redis.NewClient(redis.Options{
+			Address:        c.Redis.Address,
+			Password:       c.Redis.Password,
+			Db:             c.Redis.Db,
+			MaxConnections: c.Redis.MaxConnections,
+			Timeout:        time.Second * time.Duration(c.Redis.TimeoutSec),
+		})
+		lc.OnStop("redis client", func() error {
+			client.Close()
+			return nil
+		})
+		return cached.NewRedisBackend(client, c.Redis.KeyPrefix, logger.New("component", "redisCache"))
+	case config.CacheBackendLocal, "":
+		return cached.NewLocalBackend()
+	default:
+		panic(fmt.Sprintf("unknown cache backend: %v", c.Backend))
+	}
+}
+
+func createChangeListener(conf *config.Config, lc *lifecycle.Manager, logger log.Logger) db.ChangeListener {
+	if len(conf.Invalidation.Channel) == 0 || conf.Storage == config.StorageMemory {
+		return nil
+	}
+	changeListener, err := postgres.NewChangeListener(conf.PostgresConnStr, conf.Invalidation.Channel, lc, logger.New("component", "changeListener"))
+	if err != nil {
+		logger.Warn(errors.Wrap(err, "Unable to listen to db changes, cache items are kept until expiration").Error())
+		return nil
+	}
+	return changeListener
+}
+
+func createPerformanceMonitor(c config.PerformanceMonitorConfig, lc *lifecycle.Manager) (monitoring.PerformanceMonitor, error) {
 	if !c.Enabled {
 		return monitoring.NewEmptyPerformanceMonitor(), nil
 	}
@@ -111,7 +257,7 @@ func createPerformanceMonitor(c config.PerformanceMonitorConfig) (monitoring.Per
 	if err != nil {
 		return monitoring.NewEmptyPerformanceMonitor(), err
 	}
-	return monitoring.NewPerformanceMonitor(interval, logger), nil
+	return monitoring.NewPerformanceMonitor(interval, lc, logger), nil
 }
 
 func createPerformanceMonitorLogger(logFileSize int) (log.Logger, error) {
@@ -126,9 +272,11 @@ func createPerformanceMonitorLogger(logFileSize int) (log.Logger, error) {
 }
 
 type app struct {
-	server api.Server
-	db     db.Accessor
-	logger log.Logger
+	server          api.Server
+	db              db.Accessor
+	lc              *lifecycle.Manager
+	shutdownTimeout time.Duration
+	logger          log.Logger
 }
 
 func (e *app) Start(swaggerConfig config.SwaggerConfig) {
@@ -136,5 +284,19 @@ func (e *app) Start(swaggerConfig config.SwaggerConfig) {
 }
 
 func (e *app) Destroy() {
+	e.shutdownServer()
+	// Background loops get their own deadline, so slow draining of requests does not leave them using the db
+	// being closed
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout)
+	defer cancel()
+	e.lc.Stop(ctx)
 	e.db.Destroy()
 }
+
+func (e *app) shutdownServer() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout)
+	defer cancel()
+	if err := e.server.Shutdown(ctx); err != nil {
+		e.logger.Warn(fmt.Sprintf("In-flight requests are not completed in time: %v", err))
+	}
+}
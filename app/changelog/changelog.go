@@ -1,9 +1,13 @@
 package changelog
 
 import (
+	"context"
 	"fmt"
 	"github.com/coreos/go-semver/semver"
+	"github.com/idena-network/idena-indexer-api/app/health"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
 	"github.com/idena-network/idena-indexer-api/app/service"
+	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/pkg/errors"
 	"io/ioutil"
@@ -19,17 +23,19 @@ type ChangeLog struct {
 	changeLogsByVersion map[string]*service.ChangeLogData
 	urlsByUpgrade       map[uint32]string
 	prevLen             int
+	refreshTracker      *health.RefreshTracker
 	logger              log.Logger
 }
 
-func NewChangeLog(srcUrl string, logger log.Logger) *ChangeLog {
+func NewChangeLog(srcUrl string, lc *lifecycle.Manager, logger log.Logger) *ChangeLog {
 	res := &ChangeLog{
 		srcUrl:              srcUrl,
 		changeLogsByVersion: make(map[string]*service.ChangeLogData),
 		urlsByUpgrade:       make(map[uint32]string),
+		refreshTracker:      health.NewRefreshTracker(),
 		logger:              logger,
 	}
-	go res.loopRefreshing()
+	lc.Go(res.loopRefreshing)
 	return res
 }
 
@@ -46,10 +52,16 @@ func (changeLog *ChangeLog) Url(upgrade uint32) string {
 	return changeLog.urlsByUpgrade[upgrade]
 }
 
-func (changeLog *ChangeLog) loopRefreshing() {
+func (changeLog *ChangeLog) RefreshStatus() types.RefreshHealth {
+	return changeLog.refreshTracker.Status()
+}
+
+func (changeLog *ChangeLog) loopRefreshing(ctx context.Context) {
 	for {
-		changeLog.refresh()
-		time.Sleep(time.Minute * 5)
+		changeLog.refreshTracker.Done(changeLog.refresh())
+		if !lifecycle.Sleep(ctx, time.Minute*5) {
+			return
+		}
 	}
 }
 
@@ -66,20 +78,20 @@ func getData(url string) ([]byte, error) {
 	return body, nil
 }
 
-func (changeLog *ChangeLog) refresh() {
+func (changeLog *ChangeLog) refresh() error {
 	mainData, err := getData(changeLog.srcUrl)
 	if err != nil {
 		changeLog.logger.Error("Unable to get CHANGELOG main file", "err", err)
-		return
+		return errors.Wrap(err, "unable to get CHANGELOG main file")
 	}
 	if len(mainData) == changeLog.prevLen {
-		return
+		return nil
 	}
 	changeLog.prevLen = len(mainData)
 	links, linksByVersion, err := parseMainChangeLog(mainData)
 	if err != nil {
 		changeLog.logger.Error("Unable to parse CHANGELOG main file", "err", err)
-		return
+		return errors.Wrap(err, "unable to parse CHANGELOG main file")
 	}
 
 	var all []byte
@@ -88,7 +100,7 @@ func (changeLog *ChangeLog) refresh() {
 		data, err := getData(url)
 		if err != nil {
 			changeLog.logger.Error("Unable to get CHANGELOG file", "err", err, "url", url)
-			return
+			return errors.Wrapf(err, "unable to get CHANGELOG file %v", url)
 		}
 		all = append(all, data...)
 	}
@@ -96,11 +108,12 @@ func (changeLog *ChangeLog) refresh() {
 	changeLogsByVersion, urlsByUpgrade, err := parseChangeLog(all, linksByVersion)
 	if err != nil {
 		changeLog.logger.Error("Unable to parse changelogs", "err", err)
-		return
+		return errors.Wrap(err, "unable to parse changelogs")
 	}
 
 	changeLog.changeLogsByVersion = changeLogsByVersion
 	changeLog.urlsByUpgrade = urlsByUpgrade
+	return nil
 }
 
 func parseMainChangeLog(data []byte) ([]string, map[string]string, error) {
@@ -1,17 +1,22 @@
 package cached
 
 import (
+	"context"
 	"fmt"
-	"github.com/idena-network/idena-go/common/eventbus"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-indexer-api/app/api"
 	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -21,6 +26,7 @@ import (
 const (
 	permanentDataLifeTime                   = time.Hour * 2
 	activeAddressesCountMethod              = "ActiveAddressesCount"
+	statsSeriesMethod                       = "StatsSeries"
 	epochFlipAnswersSummaryMethod           = "EpochFlipAnswersSummary"
 	epochFlipStatesSummaryMethod            = "EpochFlipStatesSummary"
 	epochFlipWrongWordsSummaryMethod        = "EpochFlipWrongWordsSummary"
@@ -48,39 +54,67 @@ const (
 type cachedAccessor struct {
 	accessor                 db.Accessor
 	memPool                  api.MemPool
-	eventBus                 eventbus.Bus
+	eventBus                 events.Bus
+	pollValidation           bool
 	maxItemCountsByMethod    map[string]int
 	defaultCacheMaxItemCount int
 	maxItemLifeTimesByMethod map[string]time.Duration
 	defaultCacheItemLifeTime time.Duration
+	backend                  Backend
+	changesEnabled           bool
 	cachesByMethod           map[string]Cache
 	mutex                    sync.Mutex
 	logger                   log.Logger
+	cacheRequests            *monitoring.Counter
+	cacheItems               *monitoring.Gauge
+	loads                    singleflight.Group
+	sharedLoads              *monitoring.Counter
 }
 
 func NewCachedAccessor(
 	db db.Accessor,
 	memPool api.MemPool,
+	eventBus events.Bus,
+	pollValidation bool,
 	defaultCacheMaxItemCount int,
 	defaultCacheItemLifeTime time.Duration,
+	backend Backend,
+	changes db.ChangeListener,
+	metrics *monitoring.Registry,
+	lc *lifecycle.Manager,
 	logger log.Logger,
 ) db.Accessor {
 	a := &cachedAccessor{
 		accessor:                 db,
+		backend:                  backend,
+		changesEnabled:           changes != nil,
 		maxItemCountsByMethod:    createMaxItemCountsByMethod(),
 		defaultCacheMaxItemCount: defaultCacheMaxItemCount,
 		maxItemLifeTimesByMethod: createMaxItemLifeTimesByMethod(),
 		defaultCacheItemLifeTime: defaultCacheItemLifeTime,
 		logger:                   logger,
 		memPool:                  memPool,
+		eventBus:                 eventBus,
+		pollValidation:           eventBus != nil && pollValidation,
+		cacheRequests: metrics.NewCounter("idena_api_cache_requests_total",
+			"Total number of db cache lookups by method and result.", "method", "result"),
+		cacheItems: metrics.NewGauge("idena_api_cache_items",
+			"Number of items in db cache by method.", "method"),
+		sharedLoads: metrics.NewCounter("idena_api_cache_shared_loads_total",
+			"Total number of db cache misses served by a concurrent load of the same key by method.", "method"),
 	}
-	go func() {
-		for {
-			time.Sleep(time.Minute)
+	metrics.AddCollector(a.collectCacheItems)
+	lc.Go(func(ctx context.Context) {
+		for lifecycle.Sleep(ctx, time.Minute) {
 			a.log()
 		}
-	}()
-	go a.monitorEpochChange()
+	})
+	lc.Go(a.monitorEpochChange)
+	if changes != nil {
+		lc.Go(func(ctx context.Context) {
+			a.listenChanges(ctx, changes)
+		})
+	}
 	return a
 }
 
@@ -91,9 +125,10 @@ func createMaxItemCountsByMethod() map[string]int {
 }
 
 func createMaxItemLifeTimesByMethod() map[string]time.Duration {
-	return map[string]time.Duration{
+	res := map[string]time.Duration{
 		lastBlock:                               time.Second * 20,
 		activeAddressesCountMethod:              time.Minute * 5,
+		statsSeriesMethod:                       time.Minute * 5,
 		epochIdentityStatesInterimSummaryMethod: time.Minute * 5,
 		epochInvitesSummaryMethod:               time.Minute * 3,
 		epochInviteStatesSummaryMethod:          time.Minute * 3,
@@ -116,15 +151,19 @@ func createMaxItemLifeTimesByMethod() map[string]time.Duration {
 		upgradeMethod:                           permanentDataLifeTime,
 		epochIdentityMethod:                     permanentDataLifeTime,
 	}
+	for _, method := range addressMethods {
+		res[method] = addressDataLifeTime
+	}
+	return res
 }
 
-func (a *cachedAccessor) monitorEpochChange() {
+func (a *cachedAccessor) monitorEpochChange(ctx context.Context) {
 	isFirst := true
 	epoch := uint64(0)
+	state := &epochLifecycle{}
 	const delay = time.Second * 5
-	for {
-		time.Sleep(delay)
-		lastEpoch, err := a.accessor.LastEpoch()
+	for lifecycle.Sleep(ctx, delay) {
+		lastEpoch, err := a.accessor.LastEpoch(ctx)
 		if err != nil {
 			a.logger.Warn(errors.Wrap(err, "Unable to get last epoch from db to detect new one").Error())
 			continue
@@ -136,24 +175,39 @@ func (a *cachedAccessor) monitorEpochChange() {
 				isFirst = false
 			} else {
 				a.logger.Debug("Detected new epoch")
-				a.clearCache()
+				if !a.changesEnabled {
+					// Polling is a fallback for the cache invalidation by db notifications
+					a.clearCache(epoch)
+				}
+				a.onNewEpoch(state, lastEpoch)
 			}
 		}
+		a.detectEpochLifecycleEvents(ctx, state, lastEpoch)
+		if state.pendingRewardsEpoch != nil {
+			continue
+		}
 		timeToStartMonitoring := lastEpoch.ValidationTime.Add(time.Minute * 25)
+		if a.pollValidation {
+			// Validation lifecycle events are detected from the validation start
+			timeToStartMonitoring = lastEpoch.ValidationTime
+		}
 		now := time.Now()
-		if timeToStartMonitoring.After(now) {
-			<-time.After(timeToStartMonitoring.Sub(now))
+		if timeToStartMonitoring.After(now) && !lifecycle.Sleep(ctx, timeToStartMonitoring.Sub(now)) {
+			return
 		}
 	}
 }
 
-func (a *cachedAccessor) clearCache() {
+func (a *cachedAccessor) clearCache(epoch uint64) {
+	a.backend.Clear(epoch)
+	a.logger.Debug(fmt.Sprintf("Cleared cache for epoch %v", epoch))
+}
+
+func (a *cachedAccessor) collectCacheItems() {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
-
 	for method, dbCache := range a.cachesByMethod {
-		dbCache.Clear()
-		a.logger.Debug(fmt.Sprintf("Cleared %v cache", method))
+		a.cacheItems.Set(float64(dbCache.ItemsCount()), method)
 	}
 }
 
@@ -204,41 +258,88 @@ type cachedValue struct {
 	err               error
 }
 
+// key dereferences pointer args so that keys don't depend on addresses and can be shared between processes
 func key(args ...interface{}) string {
 	res := "key"
 	for _, arg := range args {
+		if v := reflect.ValueOf(arg); v.Kind() == reflect.Ptr && !v.IsNil() {
+			arg = v.Elem().Interface()
+		}
 		res = fmt.Sprintf("%s-%v", res, arg)
 	}
 	return res
 }
 
-func (a *cachedAccessor) getOrLoad(method string, load func() (interface{}, error), args ...interface{}) (interface{}, error) {
+func (a *cachedAccessor) getOrLoad(ctx context.Context, method string, load func() (interface{}, error), args ...interface{}) (interface{}, error) {
+	v := a.getOrLoadValue(ctx, method, func() *cachedValue {
+		res, err := load()
+		return &cachedValue{
+			res: res,
+			err: err,
+		}
+	}, args...)
+	return v.res, v.err
+}
+
+func (a *cachedAccessor) getOrLoadWithConToken(ctx context.Context, method string, load func() (interface{}, *string, error), args ...interface{}) (interface{}, *string, error) {
+	v := a.getOrLoadValue(ctx, method, func() *cachedValue {
+		res, continuationToken, err := load()
+		return &cachedValue{
+			res:               res,
+			continuationToken: continuationToken,
+			err:               err,
+		}
+	}, args...)
+	return v.res, v.continuationToken, v.err
+}
+
+// getOrLoadValue collapses concurrent misses of the same key into one load, values failed because of the canceled
+// context of the loading request are not cached and the remaining requests repeat the load through the group
+func (a *cachedAccessor) getOrLoadValue(ctx context.Context, method string, load func() *cachedValue, args ...interface{}) *cachedValue {
 	dbCache := a.getCache(method)
-	key := key(args)
-	if v, ok := dbCache.Get(key); ok {
-		return v.(*cachedValue).res, v.(*cachedValue).err
+	key := key(args...)
+	if cached := getCachedValue(dbCache, key); cached != nil {
+		a.cacheRequests.Inc(method, "hit")
+		return cached
+	}
+	a.cacheRequests.Inc(method, "miss")
+	for {
+		v, loadCtxErr, shared := a.loads.Do(method+"/"+key, func() (interface{}, error) {
+			// The value may be cached by the load which has just been completed, e.g. the repeated canceled one
+			if cached := getCachedValue(dbCache, key); cached != nil {
+				return cached, nil
+			}
+			v := load()
+			// The driver may report the statement canceled by the context with its own error
+			if v.err != nil && ctx.Err() != nil {
+				return v, ctx.Err()
+			}
+			dbCache.Set(key, v, cache.DefaultExpiration)
+			return v, nil
+		})
+		if shared {
+			a.sharedLoads.Inc(method)
+		}
+		if loadCtxErr != nil && ctx.Err() == nil {
+			// The load is canceled by the context of another request sharing it
+			continue
+		}
+		// The panic of the shared load is repeated by singleflight for every caller, so the value is always set
+		res, ok := v.(*cachedValue)
+		if !ok {
+			return &cachedValue{err: errors.Errorf("unexpected result of shared load %v", method)}
+		}
+		return res
 	}
-	res, err := load()
-	dbCache.Set(key, &cachedValue{
-		res: res,
-		err: err,
-	}, cache.DefaultExpiration)
-	return res, err
 }
 
-func (a *cachedAccessor) getOrLoadWithConToken(method string, load func() (interface{}, *string, error), args ...interface{}) (interface{}, *string, error) {
-	dbCache := a.getCache(method)
-	key := key(args)
+func getCachedValue(dbCache Cache, key string) *cachedValue {
 	if v, ok := dbCache.Get(key); ok {
-		return v.(*cachedValue).res, v.(*cachedValue).continuationToken, v.(*cachedValue).err
+		if cached, ok := v.(*cachedValue); ok {
+			return cached
+		}
 	}
-	res, continuationToken, err := load()
-	dbCache.Set(key, &cachedValue{
-		res:               res,
-		continuationToken: continuationToken,
-		err:               err,
-	}, cache.DefaultExpiration)
-	return res, continuationToken, err
+	return nil
 }
 
 func (a *cachedAccessor) getCache(method string) Cache {
@@ -258,7 +359,8 @@ func (a *cachedAccessor) getCache(method string) Cache {
 		if lifeTime, ok := a.maxItemLifeTimesByMethod[method]; ok {
 			defaultExpiration = lifeTime
 		}
-		dbCache = NewCache(
+		dbCache = a.backend.NewCache(
+			method,
 			maxSize,
 			defaultExpiration,
 			a.logger.New("component", fmt.Sprintf("cache-%s", method)),
@@ -268,944 +370,989 @@ func (a *cachedAccessor) getCache(method string) Cache {
 	return dbCache
 }
 
-func (a *cachedAccessor) Search(value string) ([]types.Entity, error) {
-	res, err := a.getOrLoad("Search", func() (interface{}, error) {
-		return a.accessor.Search(value)
+func (a *cachedAccessor) Search(ctx context.Context, value string) ([]types.Entity, error) {
+	res, err := a.getOrLoad(ctx, "Search", func() (interface{}, error) {
+		return a.accessor.Search(ctx, value)
 	}, value)
 	return res.([]types.Entity), err
 }
 
-func (a *cachedAccessor) Coins() (types.AllCoins, error) {
-	res, err := a.getOrLoad("Coins", func() (interface{}, error) {
-		return a.accessor.Coins()
+func (a *cachedAccessor) Coins(ctx context.Context) (types.AllCoins, error) {
+	res, err := a.getOrLoad(ctx, "Coins", func() (interface{}, error) {
+		return a.accessor.Coins(ctx)
 	})
 	return res.(types.AllCoins), err
 }
 
-func (a *cachedAccessor) CirculatingSupply(addressesToExclude []string) (decimal.Decimal, error) {
-	res, err := a.getOrLoad("CirculatingSupply", func() (interface{}, error) {
-		return a.accessor.CirculatingSupply(addressesToExclude)
+func (a *cachedAccessor) CirculatingSupply(ctx context.Context, addressesToExclude []string) (decimal.Decimal, error) {
+	res, err := a.getOrLoad(ctx, "CirculatingSupply", func() (interface{}, error) {
+		return a.accessor.CirculatingSupply(ctx, addressesToExclude)
 	})
 	return res.(decimal.Decimal), err
 }
 
-func (a *cachedAccessor) ActiveAddressesCount(afterTime time.Time) (uint64, error) {
-	res, err := a.getOrLoad(activeAddressesCountMethod, func() (interface{}, error) {
-		return a.accessor.ActiveAddressesCount(afterTime)
+func (a *cachedAccessor) EpochDistribution(ctx context.Context, epoch uint64, addressesToExclude []string) (*types.EpochDistribution, error) {
+	res, err := a.getOrLoad(ctx, "EpochDistribution", func() (interface{}, error) {
+		return a.accessor.EpochDistribution(ctx, epoch, addressesToExclude)
+	}, epoch)
+	return res.(*types.EpochDistribution), err
+}
+
+func (a *cachedAccessor) SupplyHistory(ctx context.Context, interval db.SupplyInterval, addressesToExclude []string, count uint64, continuationToken *string) ([]types.SupplyHistoryItem, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "SupplyHistory", func() (interface{}, *string, error) {
+		return a.accessor.SupplyHistory(ctx, interval, addressesToExclude, count, continuationToken)
+	}, interval, count, continuationToken)
+	return res.([]types.SupplyHistoryItem), nextContinuationToken, err
+}
+
+func (a *cachedAccessor) StatsSeries(ctx context.Context, interval db.StatsInterval, count uint64, continuationToken *string) ([]types.StatsSeriesItem, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, statsSeriesMethod, func() (interface{}, *string, error) {
+		return a.accessor.StatsSeries(ctx, interval, count, continuationToken)
+	}, interval, count, continuationToken)
+	return res.([]types.StatsSeriesItem), nextContinuationToken, err
+}
+
+func (a *cachedAccessor) ActiveAddressesCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	res, err := a.getOrLoad(ctx, activeAddressesCountMethod, func() (interface{}, error) {
+		return a.accessor.ActiveAddressesCount(ctx, afterTime)
 	})
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochsCount() (uint64, error) {
-	res, err := a.getOrLoad("EpochsCount", func() (interface{}, error) {
-		return a.accessor.EpochsCount()
+func (a *cachedAccessor) EpochsCount(ctx context.Context) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochsCount", func() (interface{}, error) {
+		return a.accessor.EpochsCount(ctx)
 	})
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) Epochs(count uint64, continuationToken *string) ([]types.EpochSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("Epochs", func() (interface{}, *string, error) {
-		return a.accessor.Epochs(count, continuationToken)
+func (a *cachedAccessor) Epochs(ctx context.Context, count uint64, continuationToken *string) ([]types.EpochSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "Epochs", func() (interface{}, *string, error) {
+		return a.accessor.Epochs(ctx, count, continuationToken)
 	}, count, continuationToken)
 	return res.([]types.EpochSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) LastEpoch() (types.EpochDetail, error) {
-	res, err := a.getOrLoad("LastEpoch", func() (interface{}, error) {
-		return a.accessor.LastEpoch()
+func (a *cachedAccessor) LastEpoch(ctx context.Context) (types.EpochDetail, error) {
+	res, err := a.getOrLoad(ctx, "LastEpoch", func() (interface{}, error) {
+		return a.accessor.LastEpoch(ctx)
 	})
 	return res.(types.EpochDetail), err
 }
 
-func (a *cachedAccessor) Epoch(epoch uint64) (types.EpochDetail, error) {
-	res, err := a.getOrLoad("Epoch", func() (interface{}, error) {
-		return a.accessor.Epoch(epoch)
+func (a *cachedAccessor) Epoch(ctx context.Context, epoch uint64) (types.EpochDetail, error) {
+	res, err := a.getOrLoad(ctx, "Epoch", func() (interface{}, error) {
+		return a.accessor.Epoch(ctx, epoch)
 	}, epoch)
 	return res.(types.EpochDetail), err
 }
 
-func (a *cachedAccessor) EpochBlocksCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad("EpochBlocksCount", func() (interface{}, error) {
-		return a.accessor.EpochBlocksCount(epoch)
+func (a *cachedAccessor) EpochBlocksCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochBlocksCount", func() (interface{}, error) {
+		return a.accessor.EpochBlocksCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochBlocks(epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochBlocks", func() (interface{}, *string, error) {
-		return a.accessor.EpochBlocks(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochBlocks(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochBlocks", func() (interface{}, *string, error) {
+		return a.accessor.EpochBlocks(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.BlockSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochFlipsCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad("EpochFlipsCount", func() (interface{}, error) {
-		return a.accessor.EpochFlipsCount(epoch)
+func (a *cachedAccessor) EpochFlipsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochFlipsCount", func() (interface{}, error) {
+		return a.accessor.EpochFlipsCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochFlips(epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochFlips", func() (interface{}, *string, error) {
-		return a.accessor.EpochFlips(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochFlips(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochFlips", func() (interface{}, *string, error) {
+		return a.accessor.EpochFlips(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.FlipSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochFlipAnswersSummary(epoch uint64) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad(epochFlipAnswersSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochFlipAnswersSummary(epoch)
+func (a *cachedAccessor) EpochFlipAnswersSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochFlipAnswersSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochFlipAnswersSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochFlipStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad(epochFlipStatesSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochFlipStatesSummary(epoch)
+func (a *cachedAccessor) EpochFlipStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochFlipStatesSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochFlipStatesSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochFlipWrongWordsSummary(epoch uint64) ([]types.NullableBoolValueCount, error) {
-	res, err := a.getOrLoad(epochFlipWrongWordsSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochFlipWrongWordsSummary(epoch)
+func (a *cachedAccessor) EpochFlipWrongWordsSummary(ctx context.Context, epoch uint64) ([]types.NullableBoolValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochFlipWrongWordsSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochFlipWrongWordsSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.NullableBoolValueCount), err
 }
 
-func (a *cachedAccessor) EpochIdentitiesCount(epoch uint64, prevStates []string, states []string) (uint64, error) {
-	res, err := a.getOrLoad("EpochIdentitiesCount", func() (interface{}, error) {
-		return a.accessor.EpochIdentitiesCount(epoch, prevStates, states)
+func (a *cachedAccessor) EpochIdentitiesCount(ctx context.Context, epoch uint64, prevStates []string, states []string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentitiesCount", func() (interface{}, error) {
+		return a.accessor.EpochIdentitiesCount(ctx, epoch, prevStates, states)
 	}, epoch, prevStates, states)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochIdentities(epoch uint64, prevStates []string, states []string, count uint64,
+func (a *cachedAccessor) EpochIdentities(ctx context.Context, epoch uint64, prevStates []string, states []string, count uint64,
 	continuationToken *string) ([]types.EpochIdentity, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochIdentities", func() (interface{}, *string, error) {
-		return a.accessor.EpochIdentities(epoch, prevStates, states, count, continuationToken)
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochIdentities", func() (interface{}, *string, error) {
+		return a.accessor.EpochIdentities(ctx, epoch, prevStates, states, count, continuationToken)
 	}, epoch, prevStates, states, count, continuationToken)
 	return res.([]types.EpochIdentity), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochIdentityStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad(epochIdentityStatesSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochIdentityStatesSummary(epoch)
+func (a *cachedAccessor) EpochIdentityStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochIdentityStatesSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochIdentityStatesSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochIdentityStatesInterimSummary(epoch uint64) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad(epochIdentityStatesInterimSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochIdentityStatesInterimSummary(epoch)
+func (a *cachedAccessor) EpochIdentityStatesInterimSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochIdentityStatesInterimSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochIdentityStatesInterimSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochInvitesSummary(epoch uint64) (types.InvitesSummary, error) {
-	res, err := a.getOrLoad(epochInvitesSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochInvitesSummary(epoch)
+func (a *cachedAccessor) EpochInvitesSummary(ctx context.Context, epoch uint64) (types.InvitesSummary, error) {
+	res, err := a.getOrLoad(ctx, epochInvitesSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochInvitesSummary(ctx, epoch)
 	}, epoch)
 	return res.(types.InvitesSummary), err
 }
 
-func (a *cachedAccessor) EpochInviteStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad(epochInviteStatesSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochInviteStatesSummary(epoch)
+func (a *cachedAccessor) EpochInviteStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, epochInviteStatesSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochInviteStatesSummary(ctx, epoch)
 	}, epoch)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochInvitesCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad("EpochInvitesCount", func() (interface{}, error) {
-		return a.accessor.EpochInvitesCount(epoch)
+func (a *cachedAccessor) EpochInvitesCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochInvitesCount", func() (interface{}, error) {
+		return a.accessor.EpochInvitesCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochInvites(epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochInvites", func() (interface{}, *string, error) {
-		return a.accessor.EpochInvites(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochInvites(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochInvites", func() (interface{}, *string, error) {
+		return a.accessor.EpochInvites(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.Invite), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochTxsCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad("EpochTxsCount", func() (interface{}, error) {
-		return a.accessor.EpochTxsCount(epoch)
+func (a *cachedAccessor) EpochTxsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "EpochTxsCount", func() (interface{}, error) {
+		return a.accessor.EpochTxsCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochTxs(epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochTxs", func() (interface{}, *string, error) {
-		return a.accessor.EpochTxs(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochTxs", func() (interface{}, *string, error) {
+		return a.accessor.EpochTxs(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.TransactionSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochCoins(epoch uint64) (types.AllCoins, error) {
-	res, err := a.getOrLoad("EpochCoins", func() (interface{}, error) {
-		return a.accessor.EpochCoins(epoch)
+func (a *cachedAccessor) StreamEpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.EpochTxs(ctx, epoch, count, continuationToken))
+}
+
+func (a *cachedAccessor) EpochCoins(ctx context.Context, epoch uint64) (types.AllCoins, error) {
+	res, err := a.getOrLoad(ctx, "EpochCoins", func() (interface{}, error) {
+		return a.accessor.EpochCoins(ctx, epoch)
 	}, epoch)
 	return res.(types.AllCoins), err
 }
 
-func (a *cachedAccessor) EpochRewardsSummary(epoch uint64) (types.RewardsSummary, error) {
-	res, err := a.getOrLoad(epochRewardsSummaryMethod, func() (interface{}, error) {
-		return a.accessor.EpochRewardsSummary(epoch)
+func (a *cachedAccessor) EpochRewardsSummary(ctx context.Context, epoch uint64) (types.RewardsSummary, error) {
+	res, err := a.getOrLoad(ctx, epochRewardsSummaryMethod, func() (interface{}, error) {
+		return a.accessor.EpochRewardsSummary(ctx, epoch)
 	}, epoch)
 	return res.(types.RewardsSummary), err
 }
 
-func (a *cachedAccessor) EpochBadAuthorsCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad(epochBadAuthorsCountMethod, func() (interface{}, error) {
-		return a.accessor.EpochBadAuthorsCount(epoch)
+func (a *cachedAccessor) EpochBadAuthorsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, epochBadAuthorsCountMethod, func() (interface{}, error) {
+		return a.accessor.EpochBadAuthorsCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochBadAuthors(epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken(epochBadAuthorsMethod, func() (interface{}, *string, error) {
-		return a.accessor.EpochBadAuthors(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochBadAuthors(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, epochBadAuthorsMethod, func() (interface{}, *string, error) {
+		return a.accessor.EpochBadAuthors(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.BadAuthor), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochIdentitiesRewardsCount(epoch uint64) (uint64, error) {
-	res, err := a.getOrLoad(epochIdentitiesRewardsCountMethod, func() (interface{}, error) {
-		return a.accessor.EpochIdentitiesRewardsCount(epoch)
+func (a *cachedAccessor) EpochIdentitiesRewardsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, epochIdentitiesRewardsCountMethod, func() (interface{}, error) {
+		return a.accessor.EpochIdentitiesRewardsCount(ctx, epoch)
 	}, epoch)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) EpochIdentitiesRewards(epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken(epochIdentitiesRewardsMethod, func() (interface{}, *string, error) {
-		return a.accessor.EpochIdentitiesRewards(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochIdentitiesRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, epochIdentitiesRewardsMethod, func() (interface{}, *string, error) {
+		return a.accessor.EpochIdentitiesRewards(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.Rewards), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochFundPayments(epoch uint64) ([]types.FundPayment, error) {
-	res, err := a.getOrLoad(epochFundPaymentsMethod, func() (interface{}, error) {
-		return a.accessor.EpochFundPayments(epoch)
+func (a *cachedAccessor) EpochFundPayments(ctx context.Context, epoch uint64) ([]types.FundPayment, error) {
+	res, err := a.getOrLoad(ctx, epochFundPaymentsMethod, func() (interface{}, error) {
+		return a.accessor.EpochFundPayments(ctx, epoch)
 	}, epoch)
 	return res.([]types.FundPayment), err
 }
 
-func (a *cachedAccessor) EpochRewardBounds(epoch uint64) ([]types.RewardBounds, error) {
-	res, err := a.getOrLoad(epochRewardBoundsMethod, func() (interface{}, error) {
-		return a.accessor.EpochRewardBounds(epoch)
+func (a *cachedAccessor) EpochRewardBounds(ctx context.Context, epoch uint64) ([]types.RewardBounds, error) {
+	res, err := a.getOrLoad(ctx, epochRewardBoundsMethod, func() (interface{}, error) {
+		return a.accessor.EpochRewardBounds(ctx, epoch)
 	}, epoch)
 	return res.([]types.RewardBounds), err
 }
 
-func (a *cachedAccessor) EpochDelegateeTotalRewards(epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochDelegateeTotalRewards", func() (interface{}, *string, error) {
-		return a.accessor.EpochDelegateeTotalRewards(epoch, count, continuationToken)
+func (a *cachedAccessor) EpochDelegateeTotalRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochDelegateeTotalRewards", func() (interface{}, *string, error) {
+		return a.accessor.EpochDelegateeTotalRewards(ctx, epoch, count, continuationToken)
 	}, epoch, count, continuationToken)
 	return res.([]types.DelegateeTotalRewards), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) EpochIdentity(epoch uint64, address string) (types.EpochIdentity, error) {
-	res, err := a.getOrLoad(epochIdentityMethod, func() (interface{}, error) {
-		return a.accessor.EpochIdentity(epoch, address)
+func (a *cachedAccessor) EpochIdentity(ctx context.Context, epoch uint64, address string) (types.EpochIdentity, error) {
+	res, err := a.getOrLoad(ctx, epochIdentityMethod, func() (interface{}, error) {
+		return a.accessor.EpochIdentity(ctx, epoch, address)
 	}, epoch, address)
 	return res.(types.EpochIdentity), err
 }
 
-func (a *cachedAccessor) EpochIdentityShortFlipsToSolve(epoch uint64, address string) ([]string, error) {
-	res, err := a.getOrLoad("EpochIdentityShortFlipsToSolve", func() (interface{}, error) {
-		return a.accessor.EpochIdentityShortFlipsToSolve(epoch, address)
+func (a *cachedAccessor) EpochIdentityShortFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityShortFlipsToSolve", func() (interface{}, error) {
+		return a.accessor.EpochIdentityShortFlipsToSolve(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]string), err
 }
 
-func (a *cachedAccessor) EpochIdentityLongFlipsToSolve(epoch uint64, address string) ([]string, error) {
-	res, err := a.getOrLoad("EpochIdentityLongFlipsToSolve", func() (interface{}, error) {
-		return a.accessor.EpochIdentityLongFlipsToSolve(epoch, address)
+func (a *cachedAccessor) EpochIdentityLongFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityLongFlipsToSolve", func() (interface{}, error) {
+		return a.accessor.EpochIdentityLongFlipsToSolve(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]string), err
 }
 
-func (a *cachedAccessor) EpochIdentityShortAnswers(epoch uint64, address string) ([]types.Answer, error) {
-	res, err := a.getOrLoad("EpochIdentityShortAnswers", func() (interface{}, error) {
-		return a.accessor.EpochIdentityShortAnswers(epoch, address)
+func (a *cachedAccessor) EpochIdentityShortAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityShortAnswers", func() (interface{}, error) {
+		return a.accessor.EpochIdentityShortAnswers(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.Answer), err
 }
 
-func (a *cachedAccessor) EpochIdentityLongAnswers(epoch uint64, address string) ([]types.Answer, error) {
-	res, err := a.getOrLoad("EpochIdentityLongAnswers", func() (interface{}, error) {
-		return a.accessor.EpochIdentityLongAnswers(epoch, address)
+func (a *cachedAccessor) EpochIdentityLongAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityLongAnswers", func() (interface{}, error) {
+		return a.accessor.EpochIdentityLongAnswers(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.Answer), err
 }
 
-func (a *cachedAccessor) EpochIdentityFlips(epoch uint64, address string) ([]types.FlipSummary, error) {
-	res, err := a.getOrLoad("EpochIdentityFlips", func() (interface{}, error) {
-		return a.accessor.EpochIdentityFlips(epoch, address)
+func (a *cachedAccessor) EpochIdentityFlips(ctx context.Context, epoch uint64, address string) ([]types.FlipSummary, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityFlips", func() (interface{}, error) {
+		return a.accessor.EpochIdentityFlips(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.FlipSummary), err
 }
 
-func (a *cachedAccessor) EpochIdentityFlipsWithRewardFlag(epoch uint64, address string) ([]types.FlipWithRewardFlag, error) {
-	res, err := a.getOrLoad("EpochIdentityFlipsWithRewardFlag", func() (interface{}, error) {
-		return a.accessor.EpochIdentityFlipsWithRewardFlag(epoch, address)
+func (a *cachedAccessor) EpochIdentityFlipsWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.FlipWithRewardFlag, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityFlipsWithRewardFlag", func() (interface{}, error) {
+		return a.accessor.EpochIdentityFlipsWithRewardFlag(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.FlipWithRewardFlag), err
 }
 
-func (a *cachedAccessor) EpochIdentityReportedFlipRewards(epoch uint64, address string) ([]types.ReportedFlipReward, error) {
-	res, err := a.getOrLoad("EpochIdentityReportedFlipRewards", func() (interface{}, error) {
-		return a.accessor.EpochIdentityReportedFlipRewards(epoch, address)
+func (a *cachedAccessor) EpochIdentityReportedFlipRewards(ctx context.Context, epoch uint64, address string) ([]types.ReportedFlipReward, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityReportedFlipRewards", func() (interface{}, error) {
+		return a.accessor.EpochIdentityReportedFlipRewards(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.ReportedFlipReward), err
 }
 
-func (a *cachedAccessor) EpochIdentityRewards(epoch uint64, address string) ([]types.Reward, error) {
-	res, err := a.getOrLoad("EpochIdentityRewards", func() (interface{}, error) {
-		return a.accessor.EpochIdentityRewards(epoch, address)
+func (a *cachedAccessor) EpochIdentityRewards(ctx context.Context, epoch uint64, address string) ([]types.Reward, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityRewards", func() (interface{}, error) {
+		return a.accessor.EpochIdentityRewards(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.Reward), err
 }
 
-func (a *cachedAccessor) EpochIdentityBadAuthor(epoch uint64, address string) (*types.BadAuthor, error) {
-	res, err := a.getOrLoad("EpochIdentityBadAuthor", func() (interface{}, error) {
-		return a.accessor.EpochIdentityBadAuthor(epoch, address)
+func (a *cachedAccessor) EpochIdentityBadAuthor(ctx context.Context, epoch uint64, address string) (*types.BadAuthor, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityBadAuthor", func() (interface{}, error) {
+		return a.accessor.EpochIdentityBadAuthor(ctx, epoch, address)
 	}, epoch, address)
 	return res.(*types.BadAuthor), err
 }
 
-func (a *cachedAccessor) EpochIdentityInvitesWithRewardFlag(epoch uint64, address string) ([]types.InviteWithRewardFlag, error) {
-	res, err := a.getOrLoad("EpochIdentityInvitesWithRewardFlag", func() (interface{}, error) {
-		return a.accessor.EpochIdentityInvitesWithRewardFlag(epoch, address)
+func (a *cachedAccessor) EpochIdentityInvitesWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.InviteWithRewardFlag, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityInvitesWithRewardFlag", func() (interface{}, error) {
+		return a.accessor.EpochIdentityInvitesWithRewardFlag(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.InviteWithRewardFlag), err
 }
 
-func (a *cachedAccessor) EpochIdentitySavedInviteRewards(epoch uint64, address string) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad("EpochIdentitySavedInviteRewards", func() (interface{}, error) {
-		return a.accessor.EpochIdentitySavedInviteRewards(epoch, address)
+func (a *cachedAccessor) EpochIdentitySavedInviteRewards(ctx context.Context, epoch uint64, address string) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentitySavedInviteRewards", func() (interface{}, error) {
+		return a.accessor.EpochIdentitySavedInviteRewards(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) EpochIdentityAvailableInvites(epoch uint64, address string) ([]types.EpochInvites, error) {
-	res, err := a.getOrLoad("EpochIdentityAvailableInvites", func() (interface{}, error) {
-		return a.accessor.EpochIdentityAvailableInvites(epoch, address)
+func (a *cachedAccessor) EpochIdentityAvailableInvites(ctx context.Context, epoch uint64, address string) ([]types.EpochInvites, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityAvailableInvites", func() (interface{}, error) {
+		return a.accessor.EpochIdentityAvailableInvites(ctx, epoch, address)
 	}, epoch, address)
 	return res.([]types.EpochInvites), err
 }
 
-func (a *cachedAccessor) EpochIdentityInviteeWithRewardFlag(epoch uint64, address string) (*types.InviteeWithRewardFlag, error) {
-	res, err := a.getOrLoad("EpochIdentityInviteeWithRewardFlag", func() (interface{}, error) {
-		return a.accessor.EpochIdentityInviteeWithRewardFlag(epoch, address)
+func (a *cachedAccessor) EpochIdentityInviteeWithRewardFlag(ctx context.Context, epoch uint64, address string) (*types.InviteeWithRewardFlag, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityInviteeWithRewardFlag", func() (interface{}, error) {
+		return a.accessor.EpochIdentityInviteeWithRewardFlag(ctx, epoch, address)
 	}, epoch, address)
 	return res.(*types.InviteeWithRewardFlag), err
 }
 
-func (a *cachedAccessor) EpochIdentityValidationSummary(epoch uint64, address string) (types.ValidationSummary, error) {
-	res, err := a.getOrLoad("EpochIdentityValidationSummary", func() (interface{}, error) {
-		return a.accessor.EpochIdentityValidationSummary(epoch, address)
+func (a *cachedAccessor) EpochIdentityValidationSummary(ctx context.Context, epoch uint64, address string) (types.ValidationSummary, error) {
+	res, err := a.getOrLoad(ctx, "EpochIdentityValidationSummary", func() (interface{}, error) {
+		return a.accessor.EpochIdentityValidationSummary(ctx, epoch, address)
 	}, epoch, address)
 	return res.(types.ValidationSummary), err
 }
 
-func (a *cachedAccessor) EpochAddressDelegateeTotalRewards(epoch uint64, address string) (types.DelegateeTotalRewards, error) {
-	res, err := a.getOrLoad("EpochAddressDelegateeTotalRewards", func() (interface{}, error) {
-		return a.accessor.EpochAddressDelegateeTotalRewards(epoch, address)
+func (a *cachedAccessor) EpochAddressDelegateeTotalRewards(ctx context.Context, epoch uint64, address string) (types.DelegateeTotalRewards, error) {
+	res, err := a.getOrLoad(ctx, "EpochAddressDelegateeTotalRewards", func() (interface{}, error) {
+		return a.accessor.EpochAddressDelegateeTotalRewards(ctx, epoch, address)
 	}, epoch, address)
 	return res.(types.DelegateeTotalRewards), err
 }
 
-func (a *cachedAccessor) EpochDelegateeRewards(epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("EpochDelegateeRewards", func() (interface{}, *string, error) {
-		return a.accessor.EpochDelegateeRewards(epoch, address, count, continuationToken)
+func (a *cachedAccessor) EpochDelegateeRewards(ctx context.Context, epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "EpochDelegateeRewards", func() (interface{}, *string, error) {
+		return a.accessor.EpochDelegateeRewards(ctx, epoch, address, count, continuationToken)
 	}, epoch, address, count, continuationToken)
 	return res.([]types.DelegateeReward), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) BlockByHeight(height uint64) (types.BlockDetail, error) {
-	res, err := a.getOrLoad("BlockByHeight", func() (interface{}, error) {
-		return a.accessor.BlockByHeight(height)
+func (a *cachedAccessor) BlockByHeight(ctx context.Context, height uint64) (types.BlockDetail, error) {
+	res, err := a.getOrLoad(ctx, "BlockByHeight", func() (interface{}, error) {
+		return a.accessor.BlockByHeight(ctx, height)
 	}, height)
 	return res.(types.BlockDetail), err
 }
 
-func (a *cachedAccessor) BlockTxsCountByHeight(height uint64) (uint64, error) {
-	res, err := a.getOrLoad("BlockTxsCountByHeight", func() (interface{}, error) {
-		return a.accessor.BlockTxsCountByHeight(height)
+func (a *cachedAccessor) BlockTxsCountByHeight(ctx context.Context, height uint64) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "BlockTxsCountByHeight", func() (interface{}, error) {
+		return a.accessor.BlockTxsCountByHeight(ctx, height)
 	}, height)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) BlockTxsByHeight(height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("BlockTxsByHeight", func() (interface{}, *string, error) {
-		return a.accessor.BlockTxsByHeight(height, count, continuationToken)
+func (a *cachedAccessor) BlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "BlockTxsByHeight", func() (interface{}, *string, error) {
+		return a.accessor.BlockTxsByHeight(ctx, height, count, continuationToken)
 	}, height, count, continuationToken)
 	return res.([]types.TransactionSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) BlockByHash(hash string) (types.BlockDetail, error) {
-	res, err := a.getOrLoad("BlockByHash", func() (interface{}, error) {
-		return a.accessor.BlockByHash(hash)
+func (a *cachedAccessor) StreamBlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.BlockTxsByHeight(ctx, height, count, continuationToken))
+}
+
+func (a *cachedAccessor) BlockByHash(ctx context.Context, hash string) (types.BlockDetail, error) {
+	res, err := a.getOrLoad(ctx, "BlockByHash", func() (interface{}, error) {
+		return a.accessor.BlockByHash(ctx, hash)
 	}, hash)
 	return res.(types.BlockDetail), err
 }
 
-func (a *cachedAccessor) BlockTxsCountByHash(hash string) (uint64, error) {
-	res, err := a.getOrLoad("BlockTxsCountByHash", func() (interface{}, error) {
-		return a.accessor.BlockTxsCountByHash(hash)
+func (a *cachedAccessor) BlockTxsCountByHash(ctx context.Context, hash string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "BlockTxsCountByHash", func() (interface{}, error) {
+		return a.accessor.BlockTxsCountByHash(ctx, hash)
 	}, hash)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) BlockTxsByHash(hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("BlockTxsByHash", func() (interface{}, *string, error) {
-		return a.accessor.BlockTxsByHash(hash, count, continuationToken)
+func (a *cachedAccessor) BlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "BlockTxsByHash", func() (interface{}, *string, error) {
+		return a.accessor.BlockTxsByHash(ctx, hash, count, continuationToken)
 	}, hash, count, continuationToken)
 	return res.([]types.TransactionSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) BlockCoinsByHeight(height uint64) (types.AllCoins, error) {
-	res, err := a.getOrLoad("BlockCoinsByHeight", func() (interface{}, error) {
-		return a.accessor.BlockCoinsByHeight(height)
+func (a *cachedAccessor) StreamBlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.BlockTxsByHash(ctx, hash, count, continuationToken))
+}
+
+func (a *cachedAccessor) BlockCoinsByHeight(ctx context.Context, height uint64) (types.AllCoins, error) {
+	res, err := a.getOrLoad(ctx, "BlockCoinsByHeight", func() (interface{}, error) {
+		return a.accessor.BlockCoinsByHeight(ctx, height)
 	}, height)
 	return res.(types.AllCoins), err
 }
 
-func (a *cachedAccessor) BlockCoinsByHash(hash string) (types.AllCoins, error) {
-	res, err := a.getOrLoad("BlockCoinsByHash", func() (interface{}, error) {
-		return a.accessor.BlockCoinsByHash(hash)
+func (a *cachedAccessor) BlockCoinsByHash(ctx context.Context, hash string) (types.AllCoins, error) {
+	res, err := a.getOrLoad(ctx, "BlockCoinsByHash", func() (interface{}, error) {
+		return a.accessor.BlockCoinsByHash(ctx, hash)
 	}, hash)
 	return res.(types.AllCoins), err
 }
 
-func (a *cachedAccessor) LastBlock() (types.BlockDetail, error) {
-	res, err := a.getOrLoad(lastBlock, func() (interface{}, error) {
-		return a.accessor.LastBlock()
+func (a *cachedAccessor) LastBlock(ctx context.Context) (types.BlockDetail, error) {
+	res, err := a.getOrLoad(ctx, lastBlock, func() (interface{}, error) {
+		return a.accessor.LastBlock(ctx)
 	})
 	return res.(types.BlockDetail), err
 }
 
-func (a *cachedAccessor) Flip(hash string) (types.Flip, error) {
-	res, err := a.getOrLoad("Flip", func() (interface{}, error) {
-		return a.accessor.Flip(hash)
+func (a *cachedAccessor) Flip(ctx context.Context, hash string) (types.Flip, error) {
+	res, err := a.getOrLoad(ctx, "Flip", func() (interface{}, error) {
+		return a.accessor.Flip(ctx, hash)
 	}, hash)
 	return res.(types.Flip), err
 }
 
-func (a *cachedAccessor) FlipContent(hash string) (types.FlipContent, error) {
-	res, err := a.getOrLoad("FlipContent", func() (interface{}, error) {
-		return a.accessor.FlipContent(hash)
+func (a *cachedAccessor) FlipContent(ctx context.Context, hash string) (types.FlipContent, error) {
+	res, err := a.getOrLoad(ctx, "FlipContent", func() (interface{}, error) {
+		return a.accessor.FlipContent(ctx, hash)
 	}, hash)
 	return res.(types.FlipContent), err
 }
 
-func (a *cachedAccessor) FlipAnswers(hash string, isShort bool) ([]types.Answer, error) {
-	res, err := a.getOrLoad("FlipAnswers", func() (interface{}, error) {
-		return a.accessor.FlipAnswers(hash, isShort)
+func (a *cachedAccessor) FlipAnswers(ctx context.Context, hash string, isShort bool) ([]types.Answer, error) {
+	res, err := a.getOrLoad(ctx, "FlipAnswers", func() (interface{}, error) {
+		return a.accessor.FlipAnswers(ctx, hash, isShort)
 	}, hash, isShort)
 	return res.([]types.Answer), err
 }
 
-func (a *cachedAccessor) FlipEpochAdjacentFlips(hash string) (types.AdjacentStrValues, error) {
-	res, err := a.getOrLoad(flipEpochAdjacentFlipsMethod, func() (interface{}, error) {
-		return a.accessor.FlipEpochAdjacentFlips(hash)
+func (a *cachedAccessor) FlipEpochAdjacentFlips(ctx context.Context, hash string) (types.AdjacentStrValues, error) {
+	res, err := a.getOrLoad(ctx, flipEpochAdjacentFlipsMethod, func() (interface{}, error) {
+		return a.accessor.FlipEpochAdjacentFlips(ctx, hash)
 	}, hash)
 	return res.(types.AdjacentStrValues), err
 }
 
-func (a *cachedAccessor) Identity(address string) (types.Identity, error) {
-	res, err := a.getOrLoad("Identity", func() (interface{}, error) {
-		return a.accessor.Identity(address)
+func (a *cachedAccessor) Identity(ctx context.Context, address string) (types.Identity, error) {
+	res, err := a.getOrLoad(ctx, "Identity", func() (interface{}, error) {
+		return a.accessor.Identity(ctx, address)
 	}, address)
 	return res.(types.Identity), err
 }
 
-func (a *cachedAccessor) IdentityAge(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityAge", func() (interface{}, error) {
-		return a.accessor.IdentityAge(address)
+func (a *cachedAccessor) IdentityAge(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityAge", func() (interface{}, error) {
+		return a.accessor.IdentityAge(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityCurrentFlipCids(address string) ([]string, error) {
-	res, err := a.getOrLoad("IdentityCurrentFlipCids", func() (interface{}, error) {
-		return a.accessor.IdentityCurrentFlipCids(address)
+func (a *cachedAccessor) IdentityCurrentFlipCids(ctx context.Context, address string) ([]string, error) {
+	res, err := a.getOrLoad(ctx, "IdentityCurrentFlipCids", func() (interface{}, error) {
+		return a.accessor.IdentityCurrentFlipCids(ctx, address)
 	}, address)
 	return res.([]string), err
 }
 
-func (a *cachedAccessor) IdentityEpochsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityEpochsCount", func() (interface{}, error) {
-		return a.accessor.IdentityEpochsCount(address)
+func (a *cachedAccessor) IdentityEpochsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityEpochsCount", func() (interface{}, error) {
+		return a.accessor.IdentityEpochsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityEpochs(address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityEpochs", func() (interface{}, *string, error) {
-		return a.accessor.IdentityEpochs(address, count, continuationToken)
+func (a *cachedAccessor) IdentityEpochs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityEpochs", func() (interface{}, *string, error) {
+		return a.accessor.IdentityEpochs(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.EpochIdentity), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) IdentityFlipsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityFlipsCount", func() (interface{}, error) {
-		return a.accessor.IdentityFlipsCount(address)
+func (a *cachedAccessor) IdentityFlipsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityFlipsCount", func() (interface{}, error) {
+		return a.accessor.IdentityFlipsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityFlips(address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityFlips", func() (interface{}, *string, error) {
-		return a.accessor.IdentityFlips(address, count, continuationToken)
+func (a *cachedAccessor) IdentityFlips(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityFlips", func() (interface{}, *string, error) {
+		return a.accessor.IdentityFlips(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.FlipSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) IdentityFlipQualifiedAnswers(address string) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad("IdentityFlipQualifiedAnswers", func() (interface{}, error) {
-		return a.accessor.IdentityFlipQualifiedAnswers(address)
+func (a *cachedAccessor) IdentityFlipQualifiedAnswers(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, "IdentityFlipQualifiedAnswers", func() (interface{}, error) {
+		return a.accessor.IdentityFlipQualifiedAnswers(ctx, address)
 	}, address)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) IdentityFlipStates(address string) ([]types.StrValueCount, error) {
-	res, err := a.getOrLoad("IdentityFlipStates", func() (interface{}, error) {
-		return a.accessor.IdentityFlipStates(address)
+func (a *cachedAccessor) IdentityFlipStates(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	res, err := a.getOrLoad(ctx, "IdentityFlipStates", func() (interface{}, error) {
+		return a.accessor.IdentityFlipStates(ctx, address)
 	}, address)
 	return res.([]types.StrValueCount), err
 }
 
-func (a *cachedAccessor) IdentityInvitesCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityInvitesCount", func() (interface{}, error) {
-		return a.accessor.IdentityInvitesCount(address)
+func (a *cachedAccessor) IdentityInvitesCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityInvitesCount", func() (interface{}, error) {
+		return a.accessor.IdentityInvitesCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityInvites(address string, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityInvites", func() (interface{}, *string, error) {
-		return a.accessor.IdentityInvites(address, count, continuationToken)
+func (a *cachedAccessor) IdentityInvites(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityInvites", func() (interface{}, *string, error) {
+		return a.accessor.IdentityInvites(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.Invite), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) IdentityTxsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityTxsCount", func() (interface{}, error) {
-		return a.accessor.IdentityTxsCount(address)
+func (a *cachedAccessor) IdentityTxsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityTxsCount", func() (interface{}, error) {
+		return a.accessor.IdentityTxsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityTxs(address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityTxs", func() (interface{}, *string, error) {
-		return a.accessor.IdentityTxs(address, count, continuationToken)
+func (a *cachedAccessor) IdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityTxs", func() (interface{}, *string, error) {
+		return a.accessor.IdentityTxs(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.TransactionSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) IdentityRewardsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityRewardsCount", func() (interface{}, error) {
-		return a.accessor.IdentityRewardsCount(address)
+func (a *cachedAccessor) StreamIdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.IdentityTxs(ctx, address, count, continuationToken))
+}
+
+func (a *cachedAccessor) IdentityRewardsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityRewardsCount", func() (interface{}, error) {
+		return a.accessor.IdentityRewardsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityRewards(address string, count uint64, continuationToken *string) ([]types.Reward, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityRewards", func() (interface{}, *string, error) {
-		return a.accessor.IdentityRewards(address, count, continuationToken)
+func (a *cachedAccessor) IdentityRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Reward, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityRewards", func() (interface{}, *string, error) {
+		return a.accessor.IdentityRewards(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.Reward), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) IdentityEpochRewardsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("IdentityEpochRewardsCount", func() (interface{}, error) {
-		return a.accessor.IdentityEpochRewardsCount(address)
+func (a *cachedAccessor) IdentityEpochRewardsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "IdentityEpochRewardsCount", func() (interface{}, error) {
+		return a.accessor.IdentityEpochRewardsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) IdentityEpochRewards(address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("IdentityEpochRewards", func() (interface{}, *string, error) {
-		return a.accessor.IdentityEpochRewards(address, count, continuationToken)
+func (a *cachedAccessor) IdentityEpochRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "IdentityEpochRewards", func() (interface{}, *string, error) {
+		return a.accessor.IdentityEpochRewards(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.Rewards), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) Address(address string) (types.Address, error) {
-	res, err := a.getOrLoad("Address", func() (interface{}, error) {
-		return a.accessor.Address(address)
-	}, address)
+func (a *cachedAccessor) Address(ctx context.Context, address string) (types.Address, error) {
+	res, err := a.getOrLoad(ctx, "Address", func() (interface{}, error) {
+		return a.accessor.Address(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.Address), err
 }
 
-func (a *cachedAccessor) AddressPenaltiesCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("AddressPenaltiesCount", func() (interface{}, error) {
-		return a.accessor.AddressPenaltiesCount(address)
+func (a *cachedAccessor) AddressPenaltiesCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "AddressPenaltiesCount", func() (interface{}, error) {
+		return a.accessor.AddressPenaltiesCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) AddressPenalties(address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressPenalties", func() (interface{}, *string, error) {
-		return a.accessor.AddressPenalties(address, count, continuationToken)
+func (a *cachedAccessor) AddressPenalties(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressPenalties", func() (interface{}, *string, error) {
+		return a.accessor.AddressPenalties(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.Penalty), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressStatesCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("AddressStatesCount", func() (interface{}, error) {
-		return a.accessor.AddressStatesCount(address)
+func (a *cachedAccessor) AddressStatesCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "AddressStatesCount", func() (interface{}, error) {
+		return a.accessor.AddressStatesCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) AddressStates(address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressStates", func() (interface{}, *string, error) {
-		return a.accessor.AddressStates(address, count, continuationToken)
+func (a *cachedAccessor) AddressStates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressStates", func() (interface{}, *string, error) {
+		return a.accessor.AddressStates(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.AddressState), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressTotalLatestMiningReward(afterTime time.Time, address string) (types.TotalMiningReward, error) {
-	res, err := a.getOrLoad("AddressTotalLatestMiningReward", func() (interface{}, error) {
-		return a.accessor.AddressTotalLatestMiningReward(afterTime, address)
+func (a *cachedAccessor) AddressTotalLatestMiningReward(ctx context.Context, afterTime time.Time, address string) (types.TotalMiningReward, error) {
+	res, err := a.getOrLoad(ctx, "AddressTotalLatestMiningReward", func() (interface{}, error) {
+		return a.accessor.AddressTotalLatestMiningReward(ctx, afterTime, address)
 	}, afterTime, address)
 	return res.(types.TotalMiningReward), err
 }
 
-func (a *cachedAccessor) AddressTotalLatestBurntCoins(afterTime time.Time, address string) (types.AddressBurntCoins, error) {
-	res, err := a.getOrLoad("AddressTotalLatestBurntCoins", func() (interface{}, error) {
-		return a.accessor.AddressTotalLatestBurntCoins(afterTime, address)
+func (a *cachedAccessor) AddressTotalLatestBurntCoins(ctx context.Context, afterTime time.Time, address string) (types.AddressBurntCoins, error) {
+	res, err := a.getOrLoad(ctx, "AddressTotalLatestBurntCoins", func() (interface{}, error) {
+		return a.accessor.AddressTotalLatestBurntCoins(ctx, afterTime, address)
 	}, afterTime, address)
 	return res.(types.AddressBurntCoins), err
 }
 
-func (a *cachedAccessor) AddressBadAuthorsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("AddressBadAuthorsCount", func() (interface{}, error) {
-		return a.accessor.AddressBadAuthorsCount(address)
+func (a *cachedAccessor) AddressBadAuthorsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "AddressBadAuthorsCount", func() (interface{}, error) {
+		return a.accessor.AddressBadAuthorsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) AddressBadAuthors(address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressBadAuthors", func() (interface{}, *string, error) {
-		return a.accessor.AddressBadAuthors(address, count, continuationToken)
+func (a *cachedAccessor) AddressBadAuthors(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressBadAuthors", func() (interface{}, *string, error) {
+		return a.accessor.AddressBadAuthors(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.BadAuthor), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressBalanceUpdatesCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("AddressBalanceUpdatesCount", func() (interface{}, error) {
-		return a.accessor.AddressBalanceUpdatesCount(address)
+func (a *cachedAccessor) AddressBalanceUpdatesCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "AddressBalanceUpdatesCount", func() (interface{}, error) {
+		return a.accessor.AddressBalanceUpdatesCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) AddressBalanceUpdates(address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressBalanceUpdates", func() (interface{}, *string, error) {
-		return a.accessor.AddressBalanceUpdates(address, count, continuationToken)
+func (a *cachedAccessor) AddressBalanceUpdates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressBalanceUpdates", func() (interface{}, *string, error) {
+		return a.accessor.AddressBalanceUpdates(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.BalanceUpdate), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressBalanceUpdatesSummary(address string) (*types.BalanceUpdatesSummary, error) {
-	res, err := a.getOrLoad("AddressBalanceUpdatesSummary", func() (interface{}, error) {
-		return a.accessor.AddressBalanceUpdatesSummary(address)
+func (a *cachedAccessor) BalancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]types.BalanceAt, error) {
+	res, err := a.getOrLoad(ctx, "BalancesAt", func() (interface{}, error) {
+		return a.accessor.BalancesAt(ctx, addresses, height, timestamp)
+	}, addresses, height, timestamp)
+	balances, _ := res.([]types.BalanceAt)
+	return balances, err
+}
+
+func (a *cachedAccessor) AddressBalanceUpdatesSummary(ctx context.Context, address string) (*types.BalanceUpdatesSummary, error) {
+	res, err := a.getOrLoad(ctx, "AddressBalanceUpdatesSummary", func() (interface{}, error) {
+		return a.accessor.AddressBalanceUpdatesSummary(ctx, address)
 	}, address)
 	return res.(*types.BalanceUpdatesSummary), err
 }
 
-func (a *cachedAccessor) AddressDelegateeTotalRewards(address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressDelegateeTotalRewards", func() (interface{}, *string, error) {
-		return a.accessor.AddressDelegateeTotalRewards(address, count, continuationToken)
+func (a *cachedAccessor) AddressDelegateeTotalRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressDelegateeTotalRewards", func() (interface{}, *string, error) {
+		return a.accessor.AddressDelegateeTotalRewards(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.DelegateeTotalRewards), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressMiningRewardSummaries(address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressMiningRewardSummaries", func() (interface{}, *string, error) {
-		return a.accessor.AddressMiningRewardSummaries(address, count, continuationToken)
+func (a *cachedAccessor) AddressMiningRewardSummaries(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressMiningRewardSummaries", func() (interface{}, *string, error) {
+		return a.accessor.AddressMiningRewardSummaries(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.MiningRewardSummary), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressTokens(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressTokens", func() (interface{}, *string, error) {
-		return a.accessor.AddressTokens(address, count, continuationToken)
+func (a *cachedAccessor) AddressTokens(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressTokens", func() (interface{}, *string, error) {
+		return a.accessor.AddressTokens(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.TokenBalance), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) AddressToken(address, tokenAddress string) (types.TokenBalance, error) {
-	res, err := a.getOrLoad("AddressToken", func() (interface{}, error) {
-		return a.accessor.AddressToken(address, tokenAddress)
+func (a *cachedAccessor) AddressToken(ctx context.Context, address, tokenAddress string) (types.TokenBalance, error) {
+	res, err := a.getOrLoad(ctx, "AddressToken", func() (interface{}, error) {
+		return a.accessor.AddressToken(ctx, address, tokenAddress)
 	}, address, tokenAddress)
 	return res.(types.TokenBalance), err
 }
 
-func (a *cachedAccessor) AddressDelegations(address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressDelegations", func() (interface{}, *string, error) {
-		return a.accessor.AddressDelegations(address, count, continuationToken)
+func (a *cachedAccessor) AddressDelegations(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressDelegations", func() (interface{}, *string, error) {
+		return a.accessor.AddressDelegations(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.Delegation), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) Transaction(hash string) (*types.TransactionDetail, error) {
-	res, err := a.getOrLoad("Transaction", func() (interface{}, error) {
-		tx, err := a.accessor.Transaction(hash)
+func (a *cachedAccessor) Transaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
+	res, err := a.getOrLoad(ctx, "Transaction", func() (interface{}, error) {
+		tx, err := a.accessor.Transaction(ctx, hash)
 		if err == postgres.NoDataFound {
-			tx, err = a.memPool.GetTransaction(hash)
+			tx, err = a.memPool.GetTransaction(ctx, hash)
 		}
 		return tx, err
 	}, hash)
 	return res.(*types.TransactionDetail), err
 }
 
-func (a *cachedAccessor) TransactionRaw(hash string) (*hexutil.Bytes, error) {
-	res, err := a.getOrLoad("TransactionRaw", func() (interface{}, error) {
-		txRaw, err := a.accessor.TransactionRaw(hash)
+func (a *cachedAccessor) TransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
+	res, err := a.getOrLoad(ctx, "TransactionRaw", func() (interface{}, error) {
+		txRaw, err := a.accessor.TransactionRaw(ctx, hash)
 		if err == postgres.NoDataFound {
-			txRaw, err = a.memPool.GetTransactionRaw(hash)
+			txRaw, err = a.memPool.GetTransactionRaw(ctx, hash)
 		}
 		return txRaw, err
 	}, hash)
 	return res.(*hexutil.Bytes), err
 }
 
-func (a *cachedAccessor) TransactionEvents(hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("TransactionEvents", func() (interface{}, *string, error) {
-		return a.accessor.TransactionEvents(hash, count, continuationToken)
+func (a *cachedAccessor) TransactionEvents(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "TransactionEvents", func() (interface{}, *string, error) {
+		return a.accessor.TransactionEvents(ctx, hash, count, continuationToken)
 	}, hash, count, continuationToken)
 	return res.([]types.TxEvent), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) BalancesCount() (uint64, error) {
-	res, err := a.getOrLoad("BalancesCount", func() (interface{}, error) {
-		return a.accessor.BalancesCount()
+func (a *cachedAccessor) BalancesCount(ctx context.Context) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "BalancesCount", func() (interface{}, error) {
+		return a.accessor.BalancesCount(ctx)
 	})
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) Balances(sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("Balances", func() (interface{}, *string, error) {
-		return a.accessor.Balances(sortBy, count, continuationToken)
+func (a *cachedAccessor) Balances(ctx context.Context, sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "Balances", func() (interface{}, *string, error) {
+		return a.accessor.Balances(ctx, sortBy, count, continuationToken)
 	}, sortBy, count, continuationToken)
 	return res.([]types.Balance), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) TotalLatestMiningRewardsCount(afterTime time.Time) (uint64, error) {
-	res, err := a.getOrLoad("TotalLatestMiningRewardsCount", func() (interface{}, error) {
-		return a.accessor.TotalLatestMiningRewardsCount(afterTime)
+func (a *cachedAccessor) TotalLatestMiningRewardsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "TotalLatestMiningRewardsCount", func() (interface{}, error) {
+		return a.accessor.TotalLatestMiningRewardsCount(ctx, afterTime)
 	}, afterTime)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) TotalLatestMiningRewards(afterTime time.Time, startIndex uint64,
+func (a *cachedAccessor) TotalLatestMiningRewards(ctx context.Context, afterTime time.Time, startIndex uint64,
 	count uint64) ([]types.TotalMiningReward, error) {
-	res, err := a.getOrLoad("TotalLatestMiningRewards", func() (interface{}, error) {
-		return a.accessor.TotalLatestMiningRewards(afterTime, startIndex, count)
+	res, err := a.getOrLoad(ctx, "TotalLatestMiningRewards", func() (interface{}, error) {
+		return a.accessor.TotalLatestMiningRewards(ctx, afterTime, startIndex, count)
 	}, afterTime, startIndex, count)
 	return res.([]types.TotalMiningReward), err
 }
 
-func (a *cachedAccessor) TotalLatestBurntCoinsCount(afterTime time.Time) (uint64, error) {
-	res, err := a.getOrLoad("TotalLatestBurntCoinsCount", func() (interface{}, error) {
-		return a.accessor.TotalLatestBurntCoinsCount(afterTime)
+func (a *cachedAccessor) TotalLatestBurntCoinsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "TotalLatestBurntCoinsCount", func() (interface{}, error) {
+		return a.accessor.TotalLatestBurntCoinsCount(ctx, afterTime)
 	}, afterTime)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) TotalLatestBurntCoins(afterTime time.Time, startIndex uint64,
+func (a *cachedAccessor) TotalLatestBurntCoins(ctx context.Context, afterTime time.Time, startIndex uint64,
 	count uint64) ([]types.AddressBurntCoins, error) {
-	res, err := a.getOrLoad("TotalLatestBurntCoins", func() (interface{}, error) {
-		return a.accessor.TotalLatestBurntCoins(afterTime, startIndex, count)
+	res, err := a.getOrLoad(ctx, "TotalLatestBurntCoins", func() (interface{}, error) {
+		return a.accessor.TotalLatestBurntCoins(ctx, afterTime, startIndex, count)
 	}, afterTime, startIndex, count)
 	return res.([]types.AddressBurntCoins), err
 }
 
-func (a *cachedAccessor) Contract(address string) (types.Contract, error) {
-	res, err := a.getOrLoad("Contract", func() (interface{}, error) {
-		return a.accessor.Contract(address)
-	}, address)
+func (a *cachedAccessor) Contract(ctx context.Context, address string) (types.Contract, error) {
+	res, err := a.getOrLoad(ctx, "Contract", func() (interface{}, error) {
+		return a.accessor.Contract(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.Contract), err
 }
 
-func (a *cachedAccessor) ContractTxBalanceUpdates(contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("ContractTxBalanceUpdates", func() (interface{}, *string, error) {
-		return a.accessor.ContractTxBalanceUpdates(contractAddress, count, continuationToken)
-	}, contractAddress, count, continuationToken)
+func (a *cachedAccessor) ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "ContractTxBalanceUpdates", func() (interface{}, *string, error) {
+		return a.accessor.ContractTxBalanceUpdates(ctx, contractAddress, count, continuationToken)
+	}, strings.ToLower(contractAddress), count, continuationToken)
 	return res.([]types.ContractTxBalanceUpdate), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) ContractVerifiedCodeFile(address string) ([]byte, error) {
-	res, err := a.getOrLoad("ContractVerifiedCodeFile", func() (interface{}, error) {
-		return a.accessor.ContractVerifiedCodeFile(address)
-	}, address)
+func (a *cachedAccessor) ContractVerifiedCodeFile(ctx context.Context, address string) ([]byte, error) {
+	res, err := a.getOrLoad(ctx, "ContractVerifiedCodeFile", func() (interface{}, error) {
+		return a.accessor.ContractVerifiedCodeFile(ctx, address)
+	}, strings.ToLower(address))
 	return res.([]byte), err
 }
 
-func (a *cachedAccessor) TimeLockContract(address string) (types.TimeLockContract, error) {
-	res, err := a.getOrLoad("TimeLockContract", func() (interface{}, error) {
-		return a.accessor.TimeLockContract(address)
-	}, address)
+func (a *cachedAccessor) TimeLockContract(ctx context.Context, address string) (types.TimeLockContract, error) {
+	res, err := a.getOrLoad(ctx, "TimeLockContract", func() (interface{}, error) {
+		return a.accessor.TimeLockContract(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.TimeLockContract), err
 }
 
-func (a *cachedAccessor) MultisigContract(address string) (types.MultisigContract, error) {
-	res, err := a.getOrLoad("MultisigContract", func() (interface{}, error) {
-		return a.accessor.MultisigContract(address)
-	}, address)
+func (a *cachedAccessor) MultisigContract(ctx context.Context, address string) (types.MultisigContract, error) {
+	res, err := a.getOrLoad(ctx, "MultisigContract", func() (interface{}, error) {
+		return a.accessor.MultisigContract(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.MultisigContract), err
 }
 
-func (a *cachedAccessor) OracleLockContract(address string) (types.OracleLockContract, error) {
-	res, err := a.getOrLoad("OracleLockContract", func() (interface{}, error) {
-		return a.accessor.OracleLockContract(address)
-	}, address)
+func (a *cachedAccessor) OracleLockContract(ctx context.Context, address string) (types.OracleLockContract, error) {
+	res, err := a.getOrLoad(ctx, "OracleLockContract", func() (interface{}, error) {
+		return a.accessor.OracleLockContract(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.OracleLockContract), err
 }
 
-func (a *cachedAccessor) RefundableOracleLockContract(address string) (types.RefundableOracleLockContract, error) {
-	res, err := a.getOrLoad("RefundableOracleLockContract", func() (interface{}, error) {
-		return a.accessor.RefundableOracleLockContract(address)
-	}, address)
+func (a *cachedAccessor) RefundableOracleLockContract(ctx context.Context, address string) (types.RefundableOracleLockContract, error) {
+	res, err := a.getOrLoad(ctx, "RefundableOracleLockContract", func() (interface{}, error) {
+		return a.accessor.RefundableOracleLockContract(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.RefundableOracleLockContract), err
 }
 
-func (a *cachedAccessor) OracleVotingContracts(authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
-	return a.accessor.OracleVotingContracts(authorAddress, oracleAddress, states, all, sortBy, count, continuationToken)
+func (a *cachedAccessor) OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+	return a.accessor.OracleVotingContracts(ctx, authorAddress, oracleAddress, states, all, sortBy, count, continuationToken)
 }
 
-func (a *cachedAccessor) AddressOracleVotingContracts(address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("AddressOracleVotingContracts", func() (interface{}, *string, error) {
-		return a.accessor.AddressOracleVotingContracts(address, count, continuationToken)
+func (a *cachedAccessor) AddressOracleVotingContracts(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "AddressOracleVotingContracts", func() (interface{}, *string, error) {
+		return a.accessor.AddressOracleVotingContracts(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.OracleVotingContract), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) OracleVotingContract(address, oracle string) (types.OracleVotingContract, error) {
-	return a.accessor.OracleVotingContract(address, oracle)
+func (a *cachedAccessor) OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error) {
+	return a.accessor.OracleVotingContract(ctx, address, oracle)
 }
 
-func (a *cachedAccessor) EstimatedOracleRewards() ([]types.EstimatedOracleReward, error) {
-	return a.accessor.EstimatedOracleRewards()
+func (a *cachedAccessor) EstimatedOracleRewards(ctx context.Context) ([]types.EstimatedOracleReward, error) {
+	return a.accessor.EstimatedOracleRewards(ctx)
 }
 
-func (a *cachedAccessor) AddressContractTxBalanceUpdates(address, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
-	return a.accessor.AddressContractTxBalanceUpdates(address, contractAddress, count, continuationToken)
+func (a *cachedAccessor) AddressContractTxBalanceUpdates(ctx context.Context, address, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	return a.accessor.AddressContractTxBalanceUpdates(ctx, address, contractAddress, count, continuationToken)
 }
 
-func (a *cachedAccessor) Upgrades(count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("Upgrades", func() (interface{}, *string, error) {
-		return a.accessor.Upgrades(count, continuationToken)
+func (a *cachedAccessor) Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "Upgrades", func() (interface{}, *string, error) {
+		return a.accessor.Upgrades(ctx, count, continuationToken)
 	}, count, continuationToken)
 	return res.([]types.ActivatedUpgrade), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) UpgradeVotings(count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("UpgradeVotings", func() (interface{}, *string, error) {
-		return a.accessor.UpgradeVotings(count, continuationToken)
+func (a *cachedAccessor) UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "UpgradeVotings", func() (interface{}, *string, error) {
+		return a.accessor.UpgradeVotings(ctx, count, continuationToken)
 	}, count, continuationToken)
 	return res.([]types.Upgrade), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) UpgradeVotingHistory(upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error) {
-	res, err := a.getOrLoad("UpgradeVotingHistory", func() (interface{}, error) {
-		return a.accessor.UpgradeVotingHistory(upgrade)
+func (a *cachedAccessor) UpgradeVotingHistory(ctx context.Context, upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error) {
+	res, err := a.getOrLoad(ctx, "UpgradeVotingHistory", func() (interface{}, error) {
+		return a.accessor.UpgradeVotingHistory(ctx, upgrade)
 	}, upgrade)
 	return res.([]*types.UpgradeVotingHistoryItem), err
 }
 
-func (a *cachedAccessor) Upgrade(upgrade uint64) (*types.Upgrade, error) {
-	res, err := a.getOrLoad(upgradeMethod, func() (interface{}, error) {
-		return a.accessor.Upgrade(upgrade)
+func (a *cachedAccessor) Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error) {
+	res, err := a.getOrLoad(ctx, upgradeMethod, func() (interface{}, error) {
+		return a.accessor.Upgrade(ctx, upgrade)
 	}, upgrade)
 	return res.(*types.Upgrade), err
 }
 
-func (a *cachedAccessor) PoolsCount() (uint64, error) {
-	res, err := a.getOrLoad("PoolsCount", func() (interface{}, error) {
-		return a.accessor.PoolsCount()
+func (a *cachedAccessor) PoolsCount(ctx context.Context) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "PoolsCount", func() (interface{}, error) {
+		return a.accessor.PoolsCount(ctx)
 	})
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) Pools(count uint64, continuationToken *string) ([]*types.Pool, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("Pools", func() (interface{}, *string, error) {
-		return a.accessor.Pools(count, continuationToken)
+func (a *cachedAccessor) Pools(ctx context.Context, count uint64, continuationToken *string) ([]*types.Pool, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "Pools", func() (interface{}, *string, error) {
+		return a.accessor.Pools(ctx, count, continuationToken)
 	}, count, continuationToken)
 	return res.([]*types.Pool), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) Pool(address string) (*types.Pool, error) {
-	res, err := a.getOrLoad("Pool", func() (interface{}, error) {
-		return a.accessor.Pool(address)
+func (a *cachedAccessor) Pool(ctx context.Context, address string) (*types.Pool, error) {
+	res, err := a.getOrLoad(ctx, "Pool", func() (interface{}, error) {
+		return a.accessor.Pool(ctx, address)
 	}, address)
 	return res.(*types.Pool), err
 }
 
-func (a *cachedAccessor) PoolDelegatorsCount(address string) (uint64, error) {
-	res, err := a.getOrLoad("PoolDelegatorsCount", func() (interface{}, error) {
-		return a.accessor.PoolDelegatorsCount(address)
+func (a *cachedAccessor) PoolDelegatorsCount(ctx context.Context, address string) (uint64, error) {
+	res, err := a.getOrLoad(ctx, "PoolDelegatorsCount", func() (interface{}, error) {
+		return a.accessor.PoolDelegatorsCount(ctx, address)
 	}, address)
 	return res.(uint64), err
 }
 
-func (a *cachedAccessor) PoolDelegators(address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("PoolDelegators", func() (interface{}, *string, error) {
-		return a.accessor.PoolDelegators(address, count, continuationToken)
+func (a *cachedAccessor) PoolDelegators(ctx context.Context, address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "PoolDelegators", func() (interface{}, *string, error) {
+		return a.accessor.PoolDelegators(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]*types.Delegator), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) PoolSizeHistory(address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("PoolSizeHistory", func() (interface{}, *string, error) {
-		return a.accessor.PoolSizeHistory(address, count, continuationToken)
+func (a *cachedAccessor) PoolSizeHistory(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "PoolSizeHistory", func() (interface{}, *string, error) {
+		return a.accessor.PoolSizeHistory(ctx, address, count, continuationToken)
 	}, address, count, continuationToken)
 	return res.([]types.PoolSizeHistoryItem), nextContinuationToken, err
 }
 
-func (a *cachedAccessor) MinersHistory() ([]types.MinersHistoryItem, error) {
-	res, err := a.getOrLoad("MinersHistory", func() (interface{}, error) {
-		return a.accessor.MinersHistory()
+func (a *cachedAccessor) MinersHistory(ctx context.Context) ([]types.MinersHistoryItem, error) {
+	res, err := a.getOrLoad(ctx, "MinersHistory", func() (interface{}, error) {
+		return a.accessor.MinersHistory(ctx)
 	})
 	return res.([]types.MinersHistoryItem), err
 }
 
-func (a *cachedAccessor) PeersHistory(count uint64) ([]types.PeersHistoryItem, error) {
-	res, err := a.getOrLoad("PeersHistory", func() (interface{}, error) {
-		return a.accessor.PeersHistory(count)
+func (a *cachedAccessor) PeersHistory(ctx context.Context, count uint64) ([]types.PeersHistoryItem, error) {
+	res, err := a.getOrLoad(ctx, "PeersHistory", func() (interface{}, error) {
+		return a.accessor.PeersHistory(ctx, count)
 	}, count)
 	return res.([]types.PeersHistoryItem), err
 }
 
-func (a *cachedAccessor) DynamicEndpoints() ([]types.DynamicEndpoint, error) {
-	return a.accessor.DynamicEndpoints()
+func (a *cachedAccessor) DynamicEndpoints(ctx context.Context) ([]types.DynamicEndpoint, error) {
+	return a.accessor.DynamicEndpoints(ctx)
 }
 
-func (a *cachedAccessor) DynamicEndpointData(name string, limit *int) (*types.DynamicEndpointResult, error) {
-	res, err := a.getOrLoad("DynamicEndpointData", func() (interface{}, error) {
-		return a.accessor.DynamicEndpointData(name, limit)
+func (a *cachedAccessor) DynamicEndpointData(ctx context.Context, name string, limit *int) (*types.DynamicEndpointResult, error) {
+	res, err := a.getOrLoad(ctx, "DynamicEndpointData", func() (interface{}, error) {
+		return a.accessor.DynamicEndpointData(ctx, name, limit)
 	}, name, limit)
 	return res.(*types.DynamicEndpointResult), err
 }
 
-func (a *cachedAccessor) Token(address string) (types.Token, error) {
-	res, err := a.getOrLoad("Token", func() (interface{}, error) {
-		return a.accessor.Token(address)
-	}, address)
+func (a *cachedAccessor) Token(ctx context.Context, address string) (types.Token, error) {
+	res, err := a.getOrLoad(ctx, "Token", func() (interface{}, error) {
+		return a.accessor.Token(ctx, address)
+	}, strings.ToLower(address))
 	return res.(types.Token), err
 }
 
-func (a *cachedAccessor) TokenHolders(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
-	res, nextContinuationToken, err := a.getOrLoadWithConToken("TokenHolders", func() (interface{}, *string, error) {
-		return a.accessor.TokenHolders(address, count, continuationToken)
-	}, address, count, continuationToken)
+func (a *cachedAccessor) TokenHolders(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+	res, nextContinuationToken, err := a.getOrLoadWithConToken(ctx, "TokenHolders", func() (interface{}, *string, error) {
+		return a.accessor.TokenHolders(ctx, address, count, continuationToken)
+	}, strings.ToLower(address), count, continuationToken)
 	return res.([]types.TokenBalance), nextContinuationToken, err
 }
 
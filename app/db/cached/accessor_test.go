@@ -0,0 +1,139 @@
+package cached
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_cachedAccessor_getOrLoad(t *testing.T) {
+	a := &cachedAccessor{
+		backend:                  NewLocalBackend(),
+		defaultCacheMaxItemCount: 10,
+		defaultCacheItemLifeTime: time.Minute,
+		logger:                   log.New(),
+	}
+	ctx := context.Background()
+	var loads int32
+	started, release := make(chan struct{}), make(chan struct{})
+	load := func() (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return uint64(7), nil
+	}
+	wg := sync.WaitGroup{}
+	const callers = 5
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			res, err := a.getOrLoad(ctx, "LastBlock", load, "arg")
+			require.Nil(t, err)
+			require.Equal(t, uint64(7), res)
+		}()
+	}
+	// Callers either share the running load or get the value it cached
+	<-started
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), loads)
+
+	res, err := a.getOrLoad(ctx, "LastBlock", load, "arg")
+	require.Nil(t, err)
+	require.Equal(t, uint64(7), res)
+	require.Equal(t, int32(1), loads)
+}
+
+func Test_cachedAccessor_getOrLoadCanceled(t *testing.T) {
+	a := &cachedAccessor{
+		backend:                  NewLocalBackend(),
+		defaultCacheMaxItemCount: 10,
+		defaultCacheItemLifeTime: time.Minute,
+		logger:                   log.New(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := a.getOrLoad(ctx, "LastBlock", func() (interface{}, error) {
+		return nil, errors.New("pq: canceling statement due to user request")
+	}, "arg")
+	require.Error(t, err)
+
+	res, err := a.getOrLoad(context.Background(), "LastBlock", func() (interface{}, error) {
+		return uint64(7), nil
+	}, "arg")
+	require.Nil(t, err)
+	require.Equal(t, uint64(7), res)
+}
+
+func Test_cachedAccessor_getOrLoadLeaderCanceled(t *testing.T) {
+	a := &cachedAccessor{
+		backend:                  NewLocalBackend(),
+		defaultCacheMaxItemCount: 10,
+		defaultCacheItemLifeTime: time.Minute,
+		logger:                   log.New(),
+	}
+	metrics := monitoring.NewRegistry()
+	a.cacheRequests = metrics.NewCounter("cache_requests_total", "", "method", "result")
+	misses := func() float64 {
+		families, err := metrics.Gather()
+		require.Nil(t, err)
+		for _, family := range families {
+			for _, metric := range family.Metric {
+				for _, label := range metric.Label {
+					if label.GetValue() == "miss" {
+						return metric.Counter.GetValue()
+					}
+				}
+			}
+		}
+		return 0
+	}
+	var loads int32
+	started, release := make(chan struct{}), make(chan struct{})
+	load := func() (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(started)
+			<-release
+			return nil, errors.New("pq: canceling statement due to user request")
+		}
+		return uint64(7), nil
+	}
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		_, err := a.getOrLoad(leaderCtx, "LastBlock", load, "arg")
+		require.Error(t, err)
+	}()
+	<-started
+
+	wg := sync.WaitGroup{}
+	const callers = 5
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			res, err := a.getOrLoad(context.Background(), "LastBlock", load, "arg")
+			require.Nil(t, err)
+			require.Equal(t, uint64(7), res)
+		}()
+	}
+	// Callers missed the cache while the load of the leader is running so they share it
+	require.Eventually(t, func() bool {
+		return misses() == callers+1
+	}, time.Second, time.Millisecond)
+	cancel()
+	close(release)
+	wg.Wait()
+	<-leaderDone
+	// Requests sharing the canceled load repeat it once through the group
+	require.Equal(t, int32(2), loads)
+}
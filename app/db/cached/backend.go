@@ -0,0 +1,40 @@
+package cached
+
+import (
+	"github.com/idena-network/idena-indexer-api/log"
+	"sync"
+	"time"
+)
+
+// Backend creates caches of accessor methods and clears all of them at once
+type Backend interface {
+	NewCache(method string, maxSize int, defaultExpiration time.Duration, logger log.Logger) Cache
+	// Clear drops items of all caches on the epoch change, repeated calls for the same epoch may be skipped
+	Clear(epoch uint64)
+}
+
+type localBackend struct {
+	mutex  sync.Mutex
+	caches []Cache
+}
+
+// NewLocalBackend keeps items in the process memory
+func NewLocalBackend() Backend {
+	return &localBackend{}
+}
+
+func (b *localBackend) NewCache(method string, maxSize int, defaultExpiration time.Duration, logger log.Logger) Cache {
+	c := NewCache(maxSize, defaultExpiration, logger)
+	b.mutex.Lock()
+	b.caches = append(b.caches, c)
+	b.mutex.Unlock()
+	return c
+}
+
+func (b *localBackend) Clear(epoch uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, c := range b.caches {
+		c.Clear()
+	}
+}
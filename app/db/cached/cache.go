@@ -3,6 +3,7 @@ package cached
 import (
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/patrickmn/go-cache"
+	"strings"
 	"sync"
 	"time"
 )
@@ -10,6 +11,8 @@ import (
 type Cache interface {
 	Get(key string) (interface{}, bool)
 	Set(key string, value interface{}, lifeTime time.Duration)
+	Delete(key string)
+	DeletePrefix(prefix string)
 	Clear()
 	ItemsCount() int
 }
@@ -47,6 +50,18 @@ func (c *cacheImpl) Set(key string, value interface{}, lifeTime time.Duration) {
 	c.cache.Set(key, value, lifeTime)
 }
 
+func (c *cacheImpl) Delete(key string) {
+	c.cache.Delete(key)
+}
+
+func (c *cacheImpl) DeletePrefix(prefix string) {
+	for key := range c.cache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			c.cache.Delete(key)
+		}
+	}
+}
+
 func (c *cacheImpl) Clear() {
 	c.cache.Flush()
 }
@@ -0,0 +1,101 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
+
+const maxRewardsAvailabilityChecks = 60
+
+type epochLifecycle struct {
+	validationStartedEpoch *uint64
+	interimSummaryEpoch    uint64
+	interimSummary         []types.StrValueCount
+	pendingRewardsEpoch    *uint64
+	rewardsChecks          int
+}
+
+type InterimSummaryEvent struct {
+	Epoch   uint64                `json:"epoch"`
+	Summary []types.StrValueCount `json:"summary"`
+}
+
+func (a *cachedAccessor) onNewEpoch(lifecycle *epochLifecycle, epoch types.EpochDetail) {
+	a.publishNewEpoch(epoch)
+	if !a.pollValidation || epoch.Epoch == 0 {
+		return
+	}
+	prevEpoch := epoch.Epoch - 1
+	lifecycle.pendingRewardsEpoch = &prevEpoch
+	lifecycle.rewardsChecks = 0
+}
+
+func (a *cachedAccessor) publishNewEpoch(epoch types.EpochDetail) {
+	if a.eventBus == nil {
+		return
+	}
+	a.eventBus.Publish(events.Event{
+		Topic: events.NewEpochTopic,
+		Data:  epoch,
+	})
+}
+
+func (a *cachedAccessor) detectEpochLifecycleEvents(ctx context.Context, lifecycle *epochLifecycle, lastEpoch types.EpochDetail) {
+	if !a.pollValidation {
+		return
+	}
+	if lifecycle.pendingRewardsEpoch != nil {
+		rewardsSummary, err := a.accessor.EpochRewardsSummary(ctx, *lifecycle.pendingRewardsEpoch)
+		lifecycle.rewardsChecks++
+		if err != nil {
+			a.logger.Debug(errors.Wrapf(err, "Rewards of epoch %v are not available yet", *lifecycle.pendingRewardsEpoch).Error())
+			if lifecycle.rewardsChecks >= maxRewardsAvailabilityChecks {
+				a.logger.Warn(fmt.Sprintf("Stopped waiting for rewards of epoch %v", *lifecycle.pendingRewardsEpoch))
+				lifecycle.pendingRewardsEpoch = nil
+			}
+		} else {
+			lifecycle.pendingRewardsEpoch = nil
+			a.eventBus.Publish(events.Event{
+				Topic: events.RewardsAvailableTopic,
+				Data:  rewardsSummary,
+			})
+		}
+	}
+	if time.Now().Before(lastEpoch.ValidationTime) {
+		return
+	}
+	if lifecycle.validationStartedEpoch == nil || *lifecycle.validationStartedEpoch != lastEpoch.Epoch {
+		epoch := lastEpoch.Epoch
+		lifecycle.validationStartedEpoch = &epoch
+		a.eventBus.Publish(events.Event{
+			Topic: events.ValidationStartedTopic,
+			Data:  lastEpoch,
+		})
+	}
+	interimSummary, err := a.accessor.EpochIdentityStatesInterimSummary(ctx, lastEpoch.Epoch)
+	if err != nil {
+		a.logger.Warn(errors.Wrap(err, "Unable to get identity states interim summary to detect its changes").Error())
+		return
+	}
+	if lifecycle.interimSummaryEpoch != lastEpoch.Epoch {
+		lifecycle.interimSummaryEpoch = lastEpoch.Epoch
+		lifecycle.interimSummary = interimSummary
+		return
+	}
+	if reflect.DeepEqual(lifecycle.interimSummary, interimSummary) {
+		return
+	}
+	lifecycle.interimSummary = interimSummary
+	a.eventBus.Publish(events.Event{
+		Topic: events.InterimSummaryChangedTopic,
+		Data: InterimSummaryEvent{
+			Epoch:   lastEpoch.Epoch,
+			Summary: interimSummary,
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package cached
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/events"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type validationTestAccessor struct {
+	db.Accessor
+	interimSummary []types.StrValueCount
+}
+
+func (a *validationTestAccessor) EpochRewardsSummary(ctx context.Context, epoch uint64) (types.RewardsSummary, error) {
+	return types.RewardsSummary{Epoch: epoch}, nil
+}
+
+func (a *validationTestAccessor) EpochIdentityStatesInterimSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.interimSummary, nil
+}
+
+func readTopics(subscription events.Subscription) []events.Topic {
+	var res []events.Topic
+	for {
+		select {
+		case event := <-subscription.Events():
+			res = append(res, event.Topic)
+		default:
+			return res
+		}
+	}
+}
+
+func Test_cachedAccessor_epochLifecycleEvents(t *testing.T) {
+	bus := events.NewBus()
+	subscription := bus.Subscribe(10)
+	defer subscription.Unsubscribe()
+	accessor := &validationTestAccessor{}
+	a := &cachedAccessor{
+		accessor: accessor,
+		eventBus: bus,
+		logger:   log.New(),
+	}
+	ctx := context.Background()
+	lastEpoch := types.EpochDetail{Epoch: 5, ValidationTime: time.Now().Add(-time.Minute)}
+
+	// Only new epochs are published unless validation polling is enabled
+	state := &epochLifecycle{}
+	a.onNewEpoch(state, lastEpoch)
+	a.detectEpochLifecycleEvents(ctx, state, lastEpoch)
+	require.Nil(t, state.pendingRewardsEpoch)
+	require.Equal(t, []events.Topic{events.NewEpochTopic}, readTopics(subscription))
+
+	a.pollValidation = true
+	state = &epochLifecycle{}
+	a.onNewEpoch(state, lastEpoch)
+	a.detectEpochLifecycleEvents(ctx, state, lastEpoch)
+	require.Equal(t, []events.Topic{events.NewEpochTopic, events.RewardsAvailableTopic, events.ValidationStartedTopic}, readTopics(subscription))
+
+	a.detectEpochLifecycleEvents(ctx, state, lastEpoch)
+	require.Empty(t, readTopics(subscription))
+	accessor.interimSummary = []types.StrValueCount{{Value: "Verified", Count: 1}}
+	a.detectEpochLifecycleEvents(ctx, state, lastEpoch)
+	require.Equal(t, []events.Topic{events.InterimSummaryChangedTopic}, readTopics(subscription))
+}
@@ -0,0 +1,173 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// addressDataLifeTime limits the staleness of addressMethods since notifications carry no changed addresses and
+// their items are not evicted by changes, so raising the default item life time does not affect them
+const addressDataLifeTime = time.Minute
+
+// addressMethods return data of addresses which may change with any block
+var addressMethods = []string{
+	"Identity",
+	"IdentityAge",
+	"IdentityCurrentFlipCids",
+	"IdentityEpochsCount",
+	"IdentityEpochs",
+	"IdentityFlipsCount",
+	"IdentityFlips",
+	"IdentityFlipQualifiedAnswers",
+	"IdentityFlipStates",
+	"IdentityInvitesCount",
+	"IdentityInvites",
+	"IdentityTxsCount",
+	"IdentityTxs",
+	"IdentityRewardsCount",
+	"IdentityRewards",
+	"IdentityEpochRewardsCount",
+	"IdentityEpochRewards",
+	"Address",
+	"AddressPenaltiesCount",
+	"AddressPenalties",
+	"AddressStatesCount",
+	"AddressStates",
+	"AddressTotalLatestMiningReward",
+	"AddressTotalLatestBurntCoins",
+	"AddressBadAuthorsCount",
+	"AddressBadAuthors",
+	"AddressBalanceUpdatesCount",
+	"AddressBalanceUpdates",
+	"AddressBalanceUpdatesSummary",
+	"AddressDelegateeTotalRewards",
+	"AddressMiningRewardSummaries",
+	"AddressTokens",
+	"AddressToken",
+	"AddressDelegations",
+	"AddressOracleVotingContracts",
+	"AddressContractTxBalanceUpdates",
+	"BalancesAt",
+	"Pool",
+	"PoolDelegatorsCount",
+	"PoolDelegators",
+	"PoolSizeHistory",
+}
+
+// invalidationRule lists caches affected by a change
+type invalidationRule struct {
+	// methods are cleared entirely
+	methods []string
+	// entityMethods are cleared of items whose first arg is the changed epoch or address
+	entityMethods []string
+}
+
+var blockInvalidationRule = invalidationRule{
+	methods: []string{
+		lastBlock,
+		"LastEpoch",
+		"Epochs",
+		"Coins",
+		"CirculatingSupply",
+		"BalancesCount",
+		"Balances",
+		"TotalLatestMiningRewardsCount",
+		"TotalLatestMiningRewards",
+		"TotalLatestBurntCoinsCount",
+		"TotalLatestBurntCoins",
+		"PoolsCount",
+		"Pools",
+	},
+	entityMethods: []string{
+		"Epoch",
+		"EpochBlocksCount",
+		"EpochBlocks",
+		"EpochFlipsCount",
+		"EpochFlips",
+		"EpochTxsCount",
+		"EpochTxs",
+		"EpochCoins",
+		"EpochInvitesCount",
+		"EpochInvites",
+		"EpochIdentitiesCount",
+		"EpochIdentities",
+		epochIdentityStatesInterimSummaryMethod,
+		epochInvitesSummaryMethod,
+		epochInviteStatesSummaryMethod,
+	},
+}
+
+var contractInvalidationRule = invalidationRule{
+	entityMethods: []string{
+		"Address",
+		"Contract",
+		"ContractTxBalanceUpdates",
+		"ContractVerifiedCodeFile",
+		"TimeLockContract",
+		"MultisigContract",
+		"OracleLockContract",
+		"RefundableOracleLockContract",
+		"Token",
+		"TokenHolders",
+	},
+}
+
+// listenChanges evicts items affected by changes of indexed data instead of waiting for their expiration
+func (a *cachedAccessor) listenChanges(ctx context.Context, changes db.ChangeListener) {
+	var epoch uint64
+	if lastEpoch, err := a.accessor.LastEpoch(ctx); err == nil {
+		epoch = lastEpoch.Epoch
+	}
+	for {
+		var change db.Change
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok = <-changes.Changes():
+			if !ok {
+				return
+			}
+		}
+		switch change.Kind {
+		case db.BlockChange:
+			a.invalidate(blockInvalidationRule, change.Epoch)
+		case db.EpochChange:
+			if change.Epoch > epoch {
+				epoch = change.Epoch
+				a.clearCache(epoch)
+			}
+		case db.ContractChange:
+			a.invalidate(contractInvalidationRule, strings.ToLower(change.Address))
+		case db.ResyncChange:
+			a.invalidate(blockInvalidationRule, epoch)
+			lastEpoch, err := a.accessor.LastEpoch(ctx)
+			if err != nil {
+				a.logger.Warn(errors.Wrap(err, "Unable to get last epoch from db to resync cache").Error())
+				continue
+			}
+			if lastEpoch.Epoch > epoch {
+				epoch = lastEpoch.Epoch
+				a.clearCache(epoch)
+			}
+		default:
+			a.logger.Warn(fmt.Sprintf("Unknown change kind %v", change.Kind))
+		}
+	}
+}
+
+func (a *cachedAccessor) invalidate(rule invalidationRule, entity interface{}) {
+	for _, method := range rule.methods {
+		a.getCache(method).Clear()
+	}
+	entityKey := key(entity)
+	for _, method := range rule.entityMethods {
+		dbCache := a.getCache(method)
+		dbCache.Delete(entityKey)
+		dbCache.DeletePrefix(entityKey + "-")
+	}
+}
@@ -0,0 +1,82 @@
+package cached
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/db/memory"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type testChangeListener struct {
+	changes chan db.Change
+}
+
+func (l *testChangeListener) Changes() <-chan db.Change {
+	return l.changes
+}
+
+func Test_cachedAccessor_listenChanges(t *testing.T) {
+	fixtures := &memory.Fixtures{
+		Epochs: []memory.Epoch{{EpochSummary: types.EpochSummary{Epoch: 10}}},
+	}
+	a := &cachedAccessor{
+		accessor:                 memory.NewMemoryAccessorFromFixtures(fixtures, log.New()),
+		backend:                  NewLocalBackend(),
+		defaultCacheMaxItemCount: 100,
+		defaultCacheItemLifeTime: time.Minute,
+		logger:                   log.New(),
+	}
+	set := func(method string, args ...interface{}) {
+		a.getCache(method).Set(key(args...), &cachedValue{res: uint64(1)}, cache.DefaultExpiration)
+	}
+	has := func(method string, args ...interface{}) bool {
+		_, ok := a.getCache(method).Get(key(args...))
+		return ok
+	}
+	token := "5"
+	set(lastBlock)
+	set("EpochBlocksCount", uint64(10))
+	set("EpochBlocksCount", uint64(9))
+	set("EpochBlocks", uint64(10), uint64(20), &token)
+	set("EpochBlocks", uint64(100), uint64(20), &token)
+	set("Contract", "0xabc")
+	set("ContractTxBalanceUpdates", "0xabc", uint64(20), (*string)(nil))
+	set("Contract", "0xdef")
+	set(epochRewardsSummaryMethod, uint64(9))
+
+	listener := &testChangeListener{changes: make(chan db.Change)}
+	go a.listenChanges(context.Background(), listener)
+
+	listener.changes <- db.Change{Kind: db.BlockChange, Height: 1000, Epoch: 10}
+	listener.changes <- db.Change{Kind: db.ContractChange, Address: "0xABC"}
+	// Wait for the previous change to be handled
+	listener.changes <- db.Change{Kind: db.EpochChange, Epoch: 10}
+
+	require.False(t, has(lastBlock))
+	require.False(t, has("EpochBlocksCount", uint64(10)))
+	require.True(t, has("EpochBlocksCount", uint64(9)))
+	require.False(t, has("EpochBlocks", uint64(10), uint64(20), &token))
+	require.True(t, has("EpochBlocks", uint64(100), uint64(20), &token))
+	require.False(t, has("Contract", "0xabc"))
+	require.False(t, has("ContractTxBalanceUpdates", "0xabc", uint64(20), (*string)(nil)))
+	require.True(t, has("Contract", "0xdef"))
+	require.True(t, has(epochRewardsSummaryMethod, uint64(9)))
+
+	listener.changes <- db.Change{Kind: db.EpochChange, Epoch: 11}
+	listener.changes <- db.Change{Kind: db.BlockChange, Epoch: 11}
+	require.False(t, has("Contract", "0xdef"))
+	require.False(t, has(epochRewardsSummaryMethod, uint64(9)))
+	close(listener.changes)
+}
+
+func Test_createMaxItemLifeTimesByMethod(t *testing.T) {
+	lifeTimes := createMaxItemLifeTimesByMethod()
+	require.Equal(t, addressDataLifeTime, lifeTimes["AddressBalanceUpdates"])
+	require.Equal(t, addressDataLifeTime, lifeTimes["BalancesAt"])
+	require.Equal(t, permanentDataLifeTime, lifeTimes[epochIdentityMethod])
+}
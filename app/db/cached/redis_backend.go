@@ -0,0 +1,328 @@
+package cached
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/redis"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const flushMarkerLifeTime = time.Hour * 24
+
+var (
+	errUnknownValueType = errors.New("unknown cached value type")
+	globReplacer        = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+)
+
+// valueTypes keeps types of values loaded by the process, a value cached by another replica
+// is decoded once its type is known, until then it is treated as missing
+var valueTypes sync.Map
+
+type redisBackend struct {
+	client     *redis.Client
+	keyPrefix  string
+	generation uint64
+	logger     log.Logger
+}
+
+// NewRedisBackend shares cached items between replicas. Keys contain a generation which is incremented
+// by the first replica detecting an epoch change and announced to the others so that all of them switch at once.
+func NewRedisBackend(client *redis.Client, keyPrefix string, logger log.Logger) Backend {
+	b := &redisBackend{
+		client:    client,
+		keyPrefix: keyPrefix,
+		logger:    logger,
+	}
+	b.refreshGeneration()
+	client.Subscribe(b.flushChannel(), b.refreshGeneration, b.onFlush, func(err error) {
+		b.logger.Warn(errors.Wrap(err, "Lost subscription to cache flushes").Error())
+	})
+	return b
+}
+
+func (b *redisBackend) NewCache(method string, maxSize int, defaultExpiration time.Duration, logger log.Logger) Cache {
+	return &redisCache{
+		backend:           b,
+		method:            method,
+		defaultExpiration: defaultExpiration,
+		logger:            logger,
+	}
+}
+
+func (b *redisBackend) Clear(epoch uint64) {
+	_, err := b.client.Do("SET", b.flushedEpochKey(epoch), "1", "NX", "PX", strconv.FormatInt(flushMarkerLifeTime.Milliseconds(), 10))
+	if err == redis.ErrNil {
+		b.logger.Debug(fmt.Sprintf("Cache is already cleared for epoch %v", epoch))
+		b.refreshGeneration()
+		return
+	}
+	if err != nil {
+		b.logger.Warn(errors.Wrap(err, "Unable to mark cache flush").Error())
+		return
+	}
+	generation, err := redis.Int64(b.client.Do("INCR", b.generationKey()))
+	if err != nil {
+		b.logger.Warn(errors.Wrap(err, "Unable to increment cache generation").Error())
+		return
+	}
+	b.setGeneration(uint64(generation))
+	if _, err := b.client.Do("PUBLISH", b.flushChannel(), strconv.FormatInt(generation, 10)); err != nil {
+		b.logger.Warn(errors.Wrap(err, "Unable to publish cache flush").Error())
+	}
+	b.logger.Debug(fmt.Sprintf("Cleared cache for epoch %v, generation %v", epoch, generation))
+}
+
+func (b *redisBackend) refreshGeneration() {
+	generation, err := redis.Int64(b.client.Do("GET", b.generationKey()))
+	if err == redis.ErrNil {
+		return
+	}
+	if err != nil {
+		b.logger.Warn(errors.Wrap(err, "Unable to get cache generation").Error())
+		return
+	}
+	b.setGeneration(uint64(generation))
+}
+
+func (b *redisBackend) onFlush(payload string) {
+	generation, err := strconv.ParseUint(payload, 10, 64)
+	if err != nil {
+		b.logger.Warn(fmt.Sprintf("Wrong cache generation %q", payload))
+		return
+	}
+	b.setGeneration(generation)
+}
+
+func (b *redisBackend) setGeneration(generation uint64) {
+	for {
+		current := atomic.LoadUint64(&b.generation)
+		if generation <= current || atomic.CompareAndSwapUint64(&b.generation, current, generation) {
+			return
+		}
+	}
+}
+
+func (b *redisBackend) getGeneration() uint64 {
+	return atomic.LoadUint64(&b.generation)
+}
+
+func (b *redisBackend) generationKey() string {
+	return b.keyPrefix + ":generation"
+}
+
+func (b *redisBackend) flushChannel() string {
+	return b.keyPrefix + ":flush"
+}
+
+func (b *redisBackend) flushedEpochKey(epoch uint64) string {
+	return fmt.Sprintf("%s:flushed:%d", b.keyPrefix, epoch)
+}
+
+type redisCache struct {
+	backend           *redisBackend
+	method            string
+	defaultExpiration time.Duration
+	logger            log.Logger
+	mutex             sync.Mutex
+	itemsGeneration   uint64
+	itemsCount        int
+}
+
+func (c *redisCache) itemKey(generation uint64, key string) string {
+	return fmt.Sprintf("%s:%d:%s:%s", c.backend.keyPrefix, generation, c.method, key)
+}
+
+func (c *redisCache) Get(key string) (interface{}, bool) {
+	data, err := redis.Bytes(c.backend.client.Do("GET", c.itemKey(c.backend.getGeneration(), key)))
+	if err != nil {
+		if err != redis.ErrNil {
+			c.logger.Warn(errors.Wrap(err, "Unable to get cached value").Error())
+		}
+		return nil, false
+	}
+	value, err := decodeCachedValue(data)
+	if err != nil {
+		if err != errUnknownValueType {
+			c.logger.Warn(errors.Wrap(err, "Unable to decode cached value").Error())
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+// Set skips errors except for NoDataFound as they may be caused by temporary problems of a single replica
+func (c *redisCache) Set(key string, value interface{}, lifeTime time.Duration) {
+	v, ok := value.(*cachedValue)
+	if !ok || v.err != nil && v.err != postgres.NoDataFound {
+		return
+	}
+	data, err := encodeCachedValue(v)
+	if err != nil {
+		c.logger.Warn(errors.Wrap(err, "Unable to encode cached value").Error())
+		return
+	}
+	if lifeTime == cache.DefaultExpiration {
+		lifeTime = c.defaultExpiration
+	}
+	generation := c.backend.getGeneration()
+	args := []string{"SET", c.itemKey(generation, key), string(data)}
+	// Items without a positive life time do not expire, redis rejects such expiration times
+	if lifeTime > 0 {
+		args = append(args, "PX", strconv.FormatInt(lifeTime.Milliseconds(), 10))
+	}
+	if _, err := c.backend.client.Do(args...); err != nil {
+		c.logger.Warn(errors.Wrap(err, "Unable to set cached value").Error())
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.itemsGeneration != generation {
+		c.itemsGeneration = generation
+		c.itemsCount = 0
+	}
+	c.itemsCount++
+}
+
+func (c *redisCache) Delete(key string) {
+	if _, err := c.backend.client.Do("DEL", c.itemKey(c.backend.getGeneration(), key)); err != nil {
+		c.logger.Warn(errors.Wrap(err, "Unable to delete cached value").Error())
+	}
+}
+
+func (c *redisCache) DeletePrefix(prefix string) {
+	pattern := escapeGlob(c.itemKey(c.backend.getGeneration(), prefix)) + "*"
+	cursor := "0"
+	for {
+		reply, err := c.backend.client.Do("SCAN", cursor, "MATCH", pattern, "COUNT", "1000")
+		if err != nil {
+			c.logger.Warn(errors.Wrap(err, "Unable to scan cached values").Error())
+			return
+		}
+		items, ok := reply.([]interface{})
+		if !ok || len(items) != 2 {
+			c.logger.Warn(fmt.Sprintf("Unexpected scan reply %v", reply))
+			return
+		}
+		nextCursor, _ := items[0].([]byte)
+		keys, _ := items[1].([]interface{})
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, key := range keys {
+				keyBytes, _ := key.([]byte)
+				args = append(args, string(keyBytes))
+			}
+			if _, err := c.backend.client.Do(args...); err != nil {
+				c.logger.Warn(errors.Wrap(err, "Unable to delete cached values").Error())
+				return
+			}
+		}
+		cursor = string(nextCursor)
+		if cursor == "0" || len(cursor) == 0 {
+			return
+		}
+	}
+}
+
+// Clear drops items of the method, the backend clears items of all methods by the generation change instead
+func (c *redisCache) Clear() {
+	c.DeletePrefix("")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.itemsCount = 0
+}
+
+func escapeGlob(value string) string {
+	return globReplacer.Replace(value)
+}
+
+// ItemsCount returns the number of items set by the replica since the last clearing
+func (c *redisCache) ItemsCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.itemsGeneration != c.backend.getGeneration() {
+		return 0
+	}
+	return c.itemsCount
+}
+
+type encodedCachedValue struct {
+	Type              string          `json:"type,omitempty"`
+	Res               json.RawMessage `json:"res,omitempty"`
+	ContinuationToken *string         `json:"continuationToken,omitempty"`
+	NoDataFound       bool            `json:"noDataFound,omitempty"`
+}
+
+func encodeCachedValue(v *cachedValue) ([]byte, error) {
+	encoded := encodedCachedValue{
+		ContinuationToken: v.continuationToken,
+		NoDataFound:       v.err == postgres.NoDataFound,
+	}
+	if v.res != nil {
+		t := reflect.TypeOf(v.res)
+		encoded.Type = typeName(t)
+		valueTypes.LoadOrStore(encoded.Type, t)
+		res, err := json.Marshal(v.res)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Res = res
+	}
+	return json.Marshal(encoded)
+}
+
+// typeName qualifies names with package paths as types of different packages may have the same short names
+func typeName(t reflect.Type) string {
+	if len(t.Name()) > 0 {
+		if len(t.PkgPath()) == 0 {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), typeName(t.Elem()))
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	}
+	return t.String()
+}
+
+func decodeCachedValue(data []byte) (*cachedValue, error) {
+	encoded := encodedCachedValue{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+	res := &cachedValue{
+		continuationToken: encoded.ContinuationToken,
+	}
+	if encoded.NoDataFound {
+		res.err = postgres.NoDataFound
+	}
+	if len(encoded.Type) == 0 {
+		return res, nil
+	}
+	t, ok := valueTypes.Load(encoded.Type)
+	if !ok {
+		return nil, errUnknownValueType
+	}
+	ptr := reflect.New(t.(reflect.Type))
+	if err := json.Unmarshal(encoded.Res, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	res.res = ptr.Elem().Interface()
+	return res, nil
+}
@@ -0,0 +1,154 @@
+package cached
+
+import (
+	"encoding/json"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/redis"
+	"github.com/idena-network/idena-indexer-api/app/redis/redistest"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestRedisBackend(t *testing.T, server *redistest.Server) *redisBackend {
+	client := redis.NewClient(redis.Options{Address: server.Addr()})
+	t.Cleanup(client.Close)
+	return NewRedisBackend(client, "test", log.New()).(*redisBackend)
+}
+
+func Test_redisBackend(t *testing.T) {
+	server, err := redistest.NewServer()
+	require.Nil(t, err)
+	defer server.Close()
+
+	replica1, replica2 := newTestRedisBackend(t, server), newTestRedisBackend(t, server)
+	cache1 := replica1.NewCache("Epoch", 10, time.Minute, log.New())
+	cache2 := replica2.NewCache("Epoch", 10, time.Minute, log.New())
+
+	epoch := types.EpochDetail{
+		Epoch:          10,
+		ValidationTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	continuationToken := "5"
+	cache1.Set(key(uint64(10)), &cachedValue{res: epoch, continuationToken: &continuationToken}, cache.DefaultExpiration)
+	require.Equal(t, 1, cache1.ItemsCount())
+
+	v, ok := cache2.Get(key(uint64(10)))
+	require.True(t, ok)
+	require.Equal(t, epoch, v.(*cachedValue).res)
+	require.Equal(t, "5", *v.(*cachedValue).continuationToken)
+	require.Nil(t, v.(*cachedValue).err)
+
+	cache1.Set(key(uint64(11)), &cachedValue{res: types.EpochDetail{}, err: postgres.NoDataFound}, cache.DefaultExpiration)
+	v, ok = cache2.Get(key(uint64(11)))
+	require.True(t, ok)
+	require.Equal(t, postgres.NoDataFound, v.(*cachedValue).err)
+
+	cache1.Set(key(uint64(12)), &cachedValue{res: types.EpochDetail{}, err: errors.New("timeout")}, cache.DefaultExpiration)
+	_, ok = cache2.Get(key(uint64(12)))
+	require.False(t, ok)
+
+	cache1.Set(key("short"), &cachedValue{res: decimal.New(15, -1)}, time.Millisecond*50)
+	v, ok = cache2.Get(key("short"))
+	require.True(t, ok)
+	require.True(t, decimal.New(15, -1).Equal(v.(*cachedValue).res.(decimal.Decimal)))
+	time.Sleep(time.Millisecond * 100)
+	_, ok = cache2.Get(key("short"))
+	require.False(t, ok)
+
+	cache1.Set(key("permanent"), &cachedValue{res: epoch}, cache.NoExpiration)
+	_, ok = cache2.Get(key("permanent"))
+	require.True(t, ok)
+
+	// The first replica detecting the new epoch clears the cache of all replicas
+	replica2.Clear(11)
+	require.Equal(t, uint64(1), replica2.getGeneration())
+	require.Eventually(t, func() bool {
+		return replica1.getGeneration() == 1
+	}, time.Second*5, time.Millisecond*10)
+	_, ok = cache1.Get(key(uint64(10)))
+	require.False(t, ok)
+	require.Equal(t, 0, cache1.ItemsCount())
+
+	replica1.Clear(11)
+	require.Equal(t, uint64(1), replica1.getGeneration())
+
+	replica3 := newTestRedisBackend(t, server)
+	require.Equal(t, uint64(1), replica3.getGeneration())
+
+	cache1.Set(key(uint64(10), uint64(20)), &cachedValue{res: epoch}, cache.DefaultExpiration)
+	cache1.Set(key(uint64(100), uint64(20)), &cachedValue{res: epoch}, cache.DefaultExpiration)
+	cache2.DeletePrefix(key(uint64(10)) + "-")
+	_, ok = cache1.Get(key(uint64(10), uint64(20)))
+	require.False(t, ok)
+	_, ok = cache1.Get(key(uint64(100), uint64(20)))
+	require.True(t, ok)
+	cache2.Delete(key(uint64(100), uint64(20)))
+	_, ok = cache1.Get(key(uint64(100), uint64(20)))
+	require.False(t, ok)
+}
+
+func Test_decodeCachedValue(t *testing.T) {
+	data, err := encodeCachedValue(&cachedValue{res: []types.Entity{{Name: "Address", Value: "0x1"}}})
+	require.Nil(t, err)
+	v, err := decodeCachedValue(data)
+	require.Nil(t, err)
+	require.Equal(t, []types.Entity{{Name: "Address", Value: "0x1"}}, v.res)
+
+	_, err = decodeCachedValue([]byte(`{"type":"unknown.Type","res":{}}`))
+	require.Equal(t, errUnknownValueType, err)
+}
+
+func Test_decodeCachedValue_specificData(t *testing.T) {
+	roundTrip := func(value interface{}) interface{} {
+		data, err := encodeCachedValue(&cachedValue{res: value})
+		require.Nil(t, err)
+		v, err := decodeCachedValue(data)
+		require.Nil(t, err)
+		require.IsType(t, value, v.res)
+		expected, err := json.Marshal(value)
+		require.Nil(t, err)
+		actual, err := json.Marshal(v.res)
+		require.Nil(t, err)
+		require.JSONEq(t, string(expected), string(actual))
+		return v.res
+	}
+
+	// Data fields are decoded into the types read from the db rather than into maps
+	transfer := "1.5"
+	txs := roundTrip([]types.TransactionSummary{
+		{Hash: "0x1", Type: "OnlineStatusTx", Data: &types.OnlineStatusTxSpecificData{BecomeOnlineOld: true, BecomeOnline: true}},
+		{Hash: "0x2", Type: "KillTx", Data: &types.KillTxSpecificData{Transfer: &transfer}},
+		{Hash: "0x3", Type: "SendTx"},
+	}).([]types.TransactionSummary)
+	require.Equal(t, &types.OnlineStatusTxSpecificData{BecomeOnlineOld: true, BecomeOnline: true}, txs[0].Data)
+	require.Equal(t, &types.KillTxSpecificData{Transfer: &transfer}, txs[1].Data)
+	require.Nil(t, txs[2].Data)
+
+	tx := roundTrip(&types.TransactionDetail{Hash: "0x4", Type: "ActivationTx", Data: &types.ActivationTxSpecificData{Transfer: &transfer}}).(*types.TransactionDetail)
+	require.Equal(t, &types.ActivationTxSpecificData{Transfer: &transfer}, tx.Data)
+
+	timestamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	updates := roundTrip([]types.BalanceUpdate{
+		{Reason: "Tx", Data: &types.TransactionBalanceUpdate{TxHash: "0x1"}},
+		{Reason: "CommitteeReward", Data: &types.CommitteeRewardBalanceUpdate{LastBlockHeight: 10, LastBlockTimestamp: timestamp, RewardShare: decimal.New(5, -1)}},
+		{Reason: "EpochReward", Data: &types.EpochRewardBalanceUpdate{Epoch: 5}},
+		{Reason: "Contract", Data: &types.ContractBalanceUpdate{TransactionBalanceUpdate: types.TransactionBalanceUpdate{TxHash: "0x2"}, ContractAddress: "0x3"}},
+	}).([]types.BalanceUpdate)
+	require.Equal(t, &types.TransactionBalanceUpdate{TxHash: "0x1"}, updates[0].Data)
+	require.IsType(t, &types.CommitteeRewardBalanceUpdate{}, updates[1].Data)
+	require.Equal(t, uint64(10), updates[1].Data.(*types.CommitteeRewardBalanceUpdate).LastBlockHeight)
+	require.Equal(t, &types.EpochRewardBalanceUpdate{Epoch: 5}, updates[2].Data)
+	require.Equal(t, &types.ContractBalanceUpdate{TransactionBalanceUpdate: types.TransactionBalanceUpdate{TxHash: "0x2"}, ContractAddress: "0x3"}, updates[3].Data)
+}
+
+func Test_key(t *testing.T) {
+	token1, token2 := "10", "10"
+	require.Equal(t, key(uint64(1), &token1), key(uint64(1), &token2))
+	require.Equal(t, "key-1-<nil>", key(uint64(1), (*string)(nil)))
+}
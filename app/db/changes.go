@@ -0,0 +1,23 @@
+package db
+
+type ChangeKind string
+
+const (
+	BlockChange    ChangeKind = "block"
+	EpochChange    ChangeKind = "epoch"
+	ContractChange ChangeKind = "contract"
+	// ResyncChange is sent after reconnection to the db as notifications may have been missed
+	ResyncChange ChangeKind = "resync"
+)
+
+// Change is a notification about new indexed data
+type Change struct {
+	Kind    ChangeKind `json:"kind"`
+	Height  uint64     `json:"height,omitempty"`
+	Epoch   uint64     `json:"epoch,omitempty"`
+	Address string     `json:"address,omitempty"`
+}
+
+type ChangeListener interface {
+	Changes() <-chan Change
+}
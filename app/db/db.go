@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/shopspring/decimal"
@@ -8,161 +9,185 @@ import (
 )
 
 type Accessor interface {
-	Search(value string) ([]types.Entity, error)
-
-	Coins() (types.AllCoins, error)
-	CirculatingSupply(addressesToExclude []string) (decimal.Decimal, error)
-	ActiveAddressesCount(afterTime time.Time) (uint64, error)
-
-	Upgrades(count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error)
-	UpgradeVotings(count uint64, continuationToken *string) ([]types.Upgrade, *string, error)
-	UpgradeVotingHistory(upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error)
-	Upgrade(upgrade uint64) (*types.Upgrade, error)
-
-	EpochsCount() (uint64, error)
-	Epochs(count uint64, continuationToken *string) ([]types.EpochSummary, *string, error)
-
-	LastEpoch() (types.EpochDetail, error)
-	Epoch(epoch uint64) (types.EpochDetail, error)
-	EpochBlocksCount(epoch uint64) (uint64, error)
-	EpochBlocks(epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error)
-	EpochFlipsCount(epoch uint64) (uint64, error)
-	EpochFlips(epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error)
-	EpochFlipAnswersSummary(epoch uint64) ([]types.StrValueCount, error)
-	EpochFlipStatesSummary(epoch uint64) ([]types.StrValueCount, error)
-	EpochFlipWrongWordsSummary(epoch uint64) ([]types.NullableBoolValueCount, error)
-	EpochIdentitiesCount(epoch uint64, prevStates []string, states []string) (uint64, error)
-	EpochIdentities(epoch uint64, prevStates []string, states []string, count uint64,
+	Streamer
+
+	Search(ctx context.Context, value string) ([]types.Entity, error)
+
+	Coins(ctx context.Context) (types.AllCoins, error)
+	CirculatingSupply(ctx context.Context, addressesToExclude []string) (decimal.Decimal, error)
+	EpochDistribution(ctx context.Context, epoch uint64, addressesToExclude []string) (*types.EpochDistribution, error)
+	SupplyHistory(ctx context.Context, interval SupplyInterval, addressesToExclude []string, count uint64, continuationToken *string) ([]types.SupplyHistoryItem, *string, error)
+	StatsSeries(ctx context.Context, interval StatsInterval, count uint64, continuationToken *string) ([]types.StatsSeriesItem, *string, error)
+	ActiveAddressesCount(ctx context.Context, afterTime time.Time) (uint64, error)
+
+	Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error)
+	UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error)
+	UpgradeVotingHistory(ctx context.Context, upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error)
+	Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error)
+
+	EpochsCount(ctx context.Context) (uint64, error)
+	Epochs(ctx context.Context, count uint64, continuationToken *string) ([]types.EpochSummary, *string, error)
+
+	LastEpoch(ctx context.Context) (types.EpochDetail, error)
+	Epoch(ctx context.Context, epoch uint64) (types.EpochDetail, error)
+	EpochBlocksCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochBlocks(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error)
+	EpochFlipsCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochFlips(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error)
+	EpochFlipAnswersSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error)
+	EpochFlipStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error)
+	EpochFlipWrongWordsSummary(ctx context.Context, epoch uint64) ([]types.NullableBoolValueCount, error)
+	EpochIdentitiesCount(ctx context.Context, epoch uint64, prevStates []string, states []string) (uint64, error)
+	EpochIdentities(ctx context.Context, epoch uint64, prevStates []string, states []string, count uint64,
 		continuationToken *string) ([]types.EpochIdentity, *string, error)
-	EpochIdentityStatesSummary(epoch uint64) ([]types.StrValueCount, error)
-	EpochIdentityStatesInterimSummary(epoch uint64) ([]types.StrValueCount, error)
-	EpochInvitesSummary(epoch uint64) (types.InvitesSummary, error)
-	EpochInviteStatesSummary(epoch uint64) ([]types.StrValueCount, error)
-	EpochInvitesCount(epoch uint64) (uint64, error)
-	EpochInvites(epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error)
-	EpochTxsCount(epoch uint64) (uint64, error)
-	EpochTxs(epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
-	EpochCoins(epoch uint64) (types.AllCoins, error)
-	EpochRewardsSummary(epoch uint64) (types.RewardsSummary, error)
-	EpochBadAuthorsCount(epoch uint64) (uint64, error)
-	EpochBadAuthors(epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error)
-	EpochIdentitiesRewardsCount(epoch uint64) (uint64, error)
-	EpochIdentitiesRewards(epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error)
-	EpochFundPayments(epoch uint64) ([]types.FundPayment, error)
-	EpochRewardBounds(epoch uint64) ([]types.RewardBounds, error)
-	EpochDelegateeTotalRewards(epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error)
-
-	EpochIdentity(epoch uint64, address string) (types.EpochIdentity, error)
-	EpochIdentityShortFlipsToSolve(epoch uint64, address string) ([]string, error)
-	EpochIdentityLongFlipsToSolve(epoch uint64, address string) ([]string, error)
-	EpochIdentityShortAnswers(epoch uint64, address string) ([]types.Answer, error)
-	EpochIdentityLongAnswers(epoch uint64, address string) ([]types.Answer, error)
-	EpochIdentityFlips(epoch uint64, address string) ([]types.FlipSummary, error)
-	EpochIdentityFlipsWithRewardFlag(epoch uint64, address string) ([]types.FlipWithRewardFlag, error)
-	EpochIdentityReportedFlipRewards(epoch uint64, address string) ([]types.ReportedFlipReward, error)
-	EpochIdentityRewards(epoch uint64, address string) ([]types.Reward, error)
-	EpochIdentityBadAuthor(epoch uint64, address string) (*types.BadAuthor, error)
-	EpochIdentityInvitesWithRewardFlag(epoch uint64, address string) ([]types.InviteWithRewardFlag, error)
-	EpochIdentitySavedInviteRewards(epoch uint64, address string) ([]types.StrValueCount, error)
-	EpochIdentityAvailableInvites(epoch uint64, address string) ([]types.EpochInvites, error)
-	EpochDelegateeRewards(epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error)
-	EpochIdentityValidationSummary(epoch uint64, address string) (types.ValidationSummary, error)
-	EpochAddressDelegateeTotalRewards(epoch uint64, address string) (types.DelegateeTotalRewards, error)
-	EpochIdentityInviteeWithRewardFlag(epoch uint64, address string) (*types.InviteeWithRewardFlag, error)
-
-	BlockByHeight(height uint64) (types.BlockDetail, error)
-	BlockTxsCountByHeight(height uint64) (uint64, error)
-	BlockTxsByHeight(height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
-	BlockByHash(hash string) (types.BlockDetail, error)
-	BlockTxsCountByHash(hash string) (uint64, error)
-	BlockTxsByHash(hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
-	BlockCoinsByHeight(height uint64) (types.AllCoins, error)
-	BlockCoinsByHash(hash string) (types.AllCoins, error)
-	LastBlock() (types.BlockDetail, error)
-
-	Flip(hash string) (types.Flip, error)
-	FlipContent(hash string) (types.FlipContent, error)
-	FlipAnswers(hash string, isShort bool) ([]types.Answer, error)
-	FlipEpochAdjacentFlips(hash string) (types.AdjacentStrValues, error)
-
-	Identity(address string) (types.Identity, error)
-	IdentityAge(address string) (uint64, error)
-	IdentityCurrentFlipCids(address string) ([]string, error)
-	IdentityEpochsCount(address string) (uint64, error)
-	IdentityEpochs(address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error)
-	IdentityFlipsCount(address string) (uint64, error)
-	IdentityFlips(address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error)
-	IdentityFlipQualifiedAnswers(address string) ([]types.StrValueCount, error)
-	IdentityFlipStates(address string) ([]types.StrValueCount, error)
-	IdentityInvitesCount(address string) (uint64, error)
-	IdentityInvites(address string, count uint64, continuationToken *string) ([]types.Invite, *string, error)
-	IdentityTxsCount(address string) (uint64, error)
-	IdentityTxs(address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
-	IdentityRewardsCount(address string) (uint64, error)
-	IdentityRewards(address string, count uint64, continuationToken *string) ([]types.Reward, *string, error)
-	IdentityEpochRewardsCount(address string) (uint64, error)
-	IdentityEpochRewards(address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error)
-
-	Address(address string) (types.Address, error)
-	AddressPenaltiesCount(address string) (uint64, error)
-	AddressPenalties(address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error)
-	AddressStatesCount(address string) (uint64, error)
-	AddressStates(address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error)
-	AddressTotalLatestMiningReward(afterTime time.Time, address string) (types.TotalMiningReward, error)
-	AddressTotalLatestBurntCoins(afterTime time.Time, address string) (types.AddressBurntCoins, error)
-	AddressBadAuthorsCount(address string) (uint64, error)
-	AddressBadAuthors(address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error)
-	AddressBalanceUpdatesCount(address string) (uint64, error)
-	AddressBalanceUpdates(address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error)
-	AddressBalanceUpdatesSummary(address string) (*types.BalanceUpdatesSummary, error)
-	AddressContractTxBalanceUpdates(address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
-	AddressDelegateeTotalRewards(address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error)
-	AddressMiningRewardSummaries(address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error)
-	AddressTokens(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error)
-	AddressToken(address, tokenAddress string) (types.TokenBalance, error)
-	AddressDelegations(address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error)
-
-	Transaction(hash string) (*types.TransactionDetail, error)
-	TransactionRaw(hash string) (*hexutil.Bytes, error)
-	TransactionEvents(hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error)
-
-	BalancesCount() (uint64, error)
-	Balances(sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error)
-
-	TotalLatestMiningRewardsCount(afterTime time.Time) (uint64, error)
-	TotalLatestMiningRewards(afterTime time.Time, startIndex uint64, count uint64) ([]types.TotalMiningReward, error)
-	TotalLatestBurntCoinsCount(afterTime time.Time) (uint64, error)
-	TotalLatestBurntCoins(afterTime time.Time, startIndex uint64, count uint64) ([]types.AddressBurntCoins, error)
-
-	Contract(address string) (types.Contract, error)
-	ContractTxBalanceUpdates(contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
-	ContractVerifiedCodeFile(address string) ([]byte, error)
-
-	TimeLockContract(address string) (types.TimeLockContract, error)
-	OracleLockContract(address string) (types.OracleLockContract, error)
-	RefundableOracleLockContract(address string) (types.RefundableOracleLockContract, error)
-	MultisigContract(address string) (types.MultisigContract, error)
-
-	OracleVotingContracts(authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
-	AddressOracleVotingContracts(address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
-	OracleVotingContract(address, oracle string) (types.OracleVotingContract, error)
-	EstimatedOracleRewards() ([]types.EstimatedOracleReward, error)
-
-	PoolsCount() (uint64, error)
-	Pools(count uint64, continuationToken *string) ([]*types.Pool, *string, error)
-	Pool(address string) (*types.Pool, error)
-	PoolDelegatorsCount(address string) (uint64, error)
-	PoolDelegators(address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error)
-	PoolSizeHistory(address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error)
-
-	MinersHistory() ([]types.MinersHistoryItem, error)
-	PeersHistory(count uint64) ([]types.PeersHistoryItem, error)
-
-	DynamicEndpoints() ([]types.DynamicEndpoint, error)
-	DynamicEndpointData(dataSource string, limit *int) (*types.DynamicEndpointResult, error)
-
-	Token(address string) (types.Token, error)
-	TokenHolders(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error)
+	EpochIdentityStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error)
+	EpochIdentityStatesInterimSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error)
+	EpochInvitesSummary(ctx context.Context, epoch uint64) (types.InvitesSummary, error)
+	EpochInviteStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error)
+	EpochInvitesCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochInvites(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error)
+	EpochTxsCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
+	EpochCoins(ctx context.Context, epoch uint64) (types.AllCoins, error)
+	EpochRewardsSummary(ctx context.Context, epoch uint64) (types.RewardsSummary, error)
+	EpochBadAuthorsCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochBadAuthors(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error)
+	EpochIdentitiesRewardsCount(ctx context.Context, epoch uint64) (uint64, error)
+	EpochIdentitiesRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error)
+	EpochFundPayments(ctx context.Context, epoch uint64) ([]types.FundPayment, error)
+	EpochRewardBounds(ctx context.Context, epoch uint64) ([]types.RewardBounds, error)
+	EpochDelegateeTotalRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error)
+
+	EpochIdentity(ctx context.Context, epoch uint64, address string) (types.EpochIdentity, error)
+	EpochIdentityShortFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error)
+	EpochIdentityLongFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error)
+	EpochIdentityShortAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error)
+	EpochIdentityLongAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error)
+	EpochIdentityFlips(ctx context.Context, epoch uint64, address string) ([]types.FlipSummary, error)
+	EpochIdentityFlipsWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.FlipWithRewardFlag, error)
+	EpochIdentityReportedFlipRewards(ctx context.Context, epoch uint64, address string) ([]types.ReportedFlipReward, error)
+	EpochIdentityRewards(ctx context.Context, epoch uint64, address string) ([]types.Reward, error)
+	EpochIdentityBadAuthor(ctx context.Context, epoch uint64, address string) (*types.BadAuthor, error)
+	EpochIdentityInvitesWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.InviteWithRewardFlag, error)
+	EpochIdentitySavedInviteRewards(ctx context.Context, epoch uint64, address string) ([]types.StrValueCount, error)
+	EpochIdentityAvailableInvites(ctx context.Context, epoch uint64, address string) ([]types.EpochInvites, error)
+	EpochDelegateeRewards(ctx context.Context, epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error)
+	EpochIdentityValidationSummary(ctx context.Context, epoch uint64, address string) (types.ValidationSummary, error)
+	EpochAddressDelegateeTotalRewards(ctx context.Context, epoch uint64, address string) (types.DelegateeTotalRewards, error)
+	EpochIdentityInviteeWithRewardFlag(ctx context.Context, epoch uint64, address string) (*types.InviteeWithRewardFlag, error)
+
+	BlockByHeight(ctx context.Context, height uint64) (types.BlockDetail, error)
+	BlockTxsCountByHeight(ctx context.Context, height uint64) (uint64, error)
+	BlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
+	BlockByHash(ctx context.Context, hash string) (types.BlockDetail, error)
+	BlockTxsCountByHash(ctx context.Context, hash string) (uint64, error)
+	BlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
+	BlockCoinsByHeight(ctx context.Context, height uint64) (types.AllCoins, error)
+	BlockCoinsByHash(ctx context.Context, hash string) (types.AllCoins, error)
+	LastBlock(ctx context.Context) (types.BlockDetail, error)
+
+	Flip(ctx context.Context, hash string) (types.Flip, error)
+	FlipContent(ctx context.Context, hash string) (types.FlipContent, error)
+	FlipAnswers(ctx context.Context, hash string, isShort bool) ([]types.Answer, error)
+	FlipEpochAdjacentFlips(ctx context.Context, hash string) (types.AdjacentStrValues, error)
+
+	Identity(ctx context.Context, address string) (types.Identity, error)
+	IdentityAge(ctx context.Context, address string) (uint64, error)
+	IdentityCurrentFlipCids(ctx context.Context, address string) ([]string, error)
+	IdentityEpochsCount(ctx context.Context, address string) (uint64, error)
+	IdentityEpochs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error)
+	IdentityFlipsCount(ctx context.Context, address string) (uint64, error)
+	IdentityFlips(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error)
+	IdentityFlipQualifiedAnswers(ctx context.Context, address string) ([]types.StrValueCount, error)
+	IdentityFlipStates(ctx context.Context, address string) ([]types.StrValueCount, error)
+	IdentityInvitesCount(ctx context.Context, address string) (uint64, error)
+	IdentityInvites(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Invite, *string, error)
+	IdentityTxsCount(ctx context.Context, address string) (uint64, error)
+	IdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error)
+	IdentityRewardsCount(ctx context.Context, address string) (uint64, error)
+	IdentityRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Reward, *string, error)
+	IdentityEpochRewardsCount(ctx context.Context, address string) (uint64, error)
+	IdentityEpochRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error)
+
+	Address(ctx context.Context, address string) (types.Address, error)
+	AddressPenaltiesCount(ctx context.Context, address string) (uint64, error)
+	AddressPenalties(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error)
+	AddressStatesCount(ctx context.Context, address string) (uint64, error)
+	AddressStates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error)
+	AddressTotalLatestMiningReward(ctx context.Context, afterTime time.Time, address string) (types.TotalMiningReward, error)
+	AddressTotalLatestBurntCoins(ctx context.Context, afterTime time.Time, address string) (types.AddressBurntCoins, error)
+	AddressBadAuthorsCount(ctx context.Context, address string) (uint64, error)
+	AddressBadAuthors(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error)
+	AddressBalanceUpdatesCount(ctx context.Context, address string) (uint64, error)
+	AddressBalanceUpdates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error)
+	AddressBalanceUpdatesSummary(ctx context.Context, address string) (*types.BalanceUpdatesSummary, error)
+	AddressContractTxBalanceUpdates(ctx context.Context, address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
+	AddressDelegateeTotalRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error)
+	AddressMiningRewardSummaries(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error)
+	AddressTokens(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error)
+	AddressToken(ctx context.Context, address, tokenAddress string) (types.TokenBalance, error)
+	AddressDelegations(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error)
+	// BalancesAt returns balances and stakes of found addresses after the block with the height or the last block before the timestamp
+	BalancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]types.BalanceAt, error)
+
+	Transaction(ctx context.Context, hash string) (*types.TransactionDetail, error)
+	TransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error)
+	TransactionEvents(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error)
+
+	BalancesCount(ctx context.Context) (uint64, error)
+	Balances(ctx context.Context, sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error)
+
+	TotalLatestMiningRewardsCount(ctx context.Context, afterTime time.Time) (uint64, error)
+	TotalLatestMiningRewards(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.TotalMiningReward, error)
+	TotalLatestBurntCoinsCount(ctx context.Context, afterTime time.Time) (uint64, error)
+	TotalLatestBurntCoins(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.AddressBurntCoins, error)
+
+	Contract(ctx context.Context, address string) (types.Contract, error)
+	ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
+	ContractVerifiedCodeFile(ctx context.Context, address string) ([]byte, error)
+
+	TimeLockContract(ctx context.Context, address string) (types.TimeLockContract, error)
+	OracleLockContract(ctx context.Context, address string) (types.OracleLockContract, error)
+	RefundableOracleLockContract(ctx context.Context, address string) (types.RefundableOracleLockContract, error)
+	MultisigContract(ctx context.Context, address string) (types.MultisigContract, error)
+
+	OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
+	AddressOracleVotingContracts(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
+	OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error)
+	EstimatedOracleRewards(ctx context.Context) ([]types.EstimatedOracleReward, error)
+
+	PoolsCount(ctx context.Context) (uint64, error)
+	Pools(ctx context.Context, count uint64, continuationToken *string) ([]*types.Pool, *string, error)
+	Pool(ctx context.Context, address string) (*types.Pool, error)
+	PoolDelegatorsCount(ctx context.Context, address string) (uint64, error)
+	PoolDelegators(ctx context.Context, address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error)
+	PoolSizeHistory(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error)
+
+	MinersHistory(ctx context.Context) ([]types.MinersHistoryItem, error)
+	PeersHistory(ctx context.Context, count uint64) ([]types.PeersHistoryItem, error)
+
+	DynamicEndpoints(ctx context.Context) ([]types.DynamicEndpoint, error)
+	DynamicEndpointData(ctx context.Context, dataSource string, limit *int) (*types.DynamicEndpointResult, error)
+
+	Token(ctx context.Context, address string) (types.Token, error)
+	TokenHolders(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error)
 
 	Destroy()
 }
+
+// SupplyInterval is the length of supply history intervals
+type SupplyInterval string
+
+const (
+	EpochSupplyInterval SupplyInterval = "epoch"
+	DaySupplyInterval   SupplyInterval = "day"
+)
+
+// StatsInterval is the length of network activity series intervals, weeks start on Monday
+type StatsInterval string
+
+const (
+	HourStatsInterval StatsInterval = "hour"
+	DayStatsInterval  StatsInterval = "day"
+	WeekStatsInterval StatsInterval = "week"
+)
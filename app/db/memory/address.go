@@ -0,0 +1,458 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (a *memoryAccessor) Address(ctx context.Context, address string) (types.Address, error) {
+	for _, item := range a.fixtures.Addresses {
+		if equalAddresses(item.Address, address) {
+			return item, nil
+		}
+	}
+	return types.Address{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) addressPenalties(address string) []types.Penalty {
+	var res []types.Penalty
+	for _, penalty := range a.fixtures.Penalties {
+		if equalAddresses(penalty.Address, address) {
+			res = append(res, penalty)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) AddressPenaltiesCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.addressPenalties(address))), nil
+}
+
+func (a *memoryAccessor) AddressPenalties(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error) {
+	penalties := a.addressPenalties(address)
+	from, to, nextContinuationToken, err := pageBounds(len(penalties), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return penalties[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) addressStates(address string) []types.AddressState {
+	var res []types.AddressState
+	for _, state := range a.fixtures.AddressStates {
+		if equalAddresses(state.Address, address) {
+			res = append(res, state.AddressState)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) AddressStatesCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.addressStates(address))), nil
+}
+
+func (a *memoryAccessor) AddressStates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error) {
+	states := a.addressStates(address)
+	from, to, nextContinuationToken, err := pageBounds(len(states), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return states[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) AddressTotalLatestMiningReward(ctx context.Context, afterTime time.Time, address string) (types.TotalMiningReward, error) {
+	res := types.TotalMiningReward{
+		Address: address,
+	}
+	for _, reward := range a.identityMiningRewards(address) {
+		block, err := a.blockByHeight(reward.BlockHeight)
+		if err != nil || block.Timestamp.Before(afterTime) {
+			continue
+		}
+		res.Balance = res.Balance.Add(reward.Balance)
+		res.Stake = res.Stake.Add(reward.Stake)
+		if reward.Type == "Proposer" {
+			res.Proposer++
+		} else if reward.Type == "FinalCommittee" {
+			res.FinalCommittee++
+		}
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) AddressTotalLatestBurntCoins(ctx context.Context, afterTime time.Time, address string) (types.AddressBurntCoins, error) {
+	res := types.AddressBurntCoins{
+		Address: address,
+	}
+	for _, item := range a.latestBurntCoins(afterTime) {
+		if equalAddresses(item.Address, address) {
+			res.Amount = res.Amount.Add(item.Amount)
+		}
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) addressBadAuthors(address string) []types.BadAuthor {
+	var res []types.BadAuthor
+	for _, badAuthor := range a.fixtures.BadAuthors {
+		if equalAddresses(badAuthor.Address, address) {
+			res = append(res, badAuthor)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) AddressBadAuthorsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.addressBadAuthors(address))), nil
+}
+
+func (a *memoryAccessor) AddressBadAuthors(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	badAuthors := a.addressBadAuthors(address)
+	from, to, nextContinuationToken, err := pageBounds(len(badAuthors), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return badAuthors[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) addressBalanceUpdates(address string) []types.BalanceUpdate {
+	var res []types.BalanceUpdate
+	for _, update := range a.fixtures.BalanceUpdates {
+		if equalAddresses(update.Address, address) {
+			res = append(res, update.BalanceUpdate)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) AddressBalanceUpdatesCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.addressBalanceUpdates(address))), nil
+}
+
+func (a *memoryAccessor) AddressBalanceUpdates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error) {
+	updates := a.addressBalanceUpdates(address)
+	from, to, nextContinuationToken, err := pageBounds(len(updates), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updates[from:to], nextContinuationToken, nil
+}
+
+// balanceAt returns the balance and the stake of the address after the block with the height, they are the old values
+// of the first balance update after the block or the current values if there are no updates after it
+func (a *memoryAccessor) balanceAt(address types.Address, height uint64) (decimal.Decimal, decimal.Decimal) {
+	balance, stake := address.Balance, address.Stake
+	for _, update := range a.fixtures.BalanceUpdates {
+		if update.BlockHeight <= height {
+			break
+		}
+		if equalAddresses(update.Address, address.Address) {
+			balance, stake = update.BalanceOld, update.StakeOld
+		}
+	}
+	return balance, stake
+}
+
+func (a *memoryAccessor) BalancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]types.BalanceAt, error) {
+	if timestamp != nil {
+		height = 0
+		for _, block := range a.fixtures.Blocks {
+			if !block.Timestamp.After(*timestamp) {
+				height = block.Height
+				break
+			}
+		}
+	}
+	var res []types.BalanceAt
+	for _, item := range a.fixtures.Addresses {
+		for _, address := range addresses {
+			if !equalAddresses(item.Address, address) {
+				continue
+			}
+			balance, stake := a.balanceAt(item, height)
+			res = append(res, types.BalanceAt{
+				Address:     item.Address,
+				Balance:     balance,
+				Stake:       stake,
+				BlockHeight: height,
+				Timestamp:   timestamp,
+			})
+			break
+		}
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) AddressBalanceUpdatesSummary(ctx context.Context, address string) (*types.BalanceUpdatesSummary, error) {
+	updates := a.addressBalanceUpdates(address)
+	if len(updates) == 0 {
+		return nil, postgres.NoDataFound
+	}
+	res := &types.BalanceUpdatesSummary{}
+	addChange := func(in, out *decimal.Decimal, oldValue, newValue decimal.Decimal) {
+		if change := newValue.Sub(oldValue); change.IsPositive() {
+			*in = in.Add(change)
+		} else {
+			*out = out.Sub(change)
+		}
+	}
+	for _, update := range updates {
+		addChange(&res.BalanceIn, &res.BalanceOut, update.BalanceOld, update.BalanceNew)
+		addChange(&res.StakeIn, &res.StakeOut, update.StakeOld, update.StakeNew)
+		addChange(&res.PenaltyIn, &res.PenaltyOut, update.PenaltyOld, update.PenaltyNew)
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) AddressContractTxBalanceUpdates(ctx context.Context, address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	var updates []types.ContractTxBalanceUpdate
+	for _, update := range a.fixtures.ContractTxBalanceUpdates {
+		if equalAddresses(update.Address, address) && equalAddresses(update.ContractAddress, contractAddress) {
+			updates = append(updates, update)
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(updates), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updates[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) AddressDelegateeTotalRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+	rewards := a.delegateeTotalRewards(func(reward DelegateeReward) bool {
+		return equalAddresses(reward.Delegatee, address)
+	})
+	sort.SliceStable(rewards, func(i, j int) bool {
+		return rewards[i].Epoch > rewards[j].Epoch
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := rewards[from:to]
+	for i := range res {
+		res[i].Address = ""
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) AddressMiningRewardSummaries(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error) {
+	var summaries []types.MiningRewardSummary
+	indexes := make(map[uint64]int)
+	summary := func(epoch uint64) *types.MiningRewardSummary {
+		i, ok := indexes[epoch]
+		if !ok {
+			i = len(summaries)
+			indexes[epoch] = i
+			summaries = append(summaries, types.MiningRewardSummary{Epoch: epoch})
+		}
+		return &summaries[i]
+	}
+	for _, reward := range a.identityMiningRewards(address) {
+		item := summary(reward.Epoch)
+		item.Amount = item.Amount.Add(reward.Balance).Add(reward.Stake)
+	}
+	for _, penalty := range a.addressPenalties(address) {
+		item := summary(penalty.Epoch)
+		item.Penalty = item.Penalty.Add(penalty.Penalty)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Epoch > summaries[j].Epoch
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(summaries), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return summaries[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) addressTokens(address string) []types.TokenBalance {
+	var res []types.TokenBalance
+	for _, balance := range a.fixtures.TokenBalances {
+		if equalAddresses(balance.Address, address) {
+			res = append(res, balance)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) AddressTokens(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+	balances := a.addressTokens(address)
+	from, to, nextContinuationToken, err := pageBounds(len(balances), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return balances[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) AddressToken(ctx context.Context, address, tokenAddress string) (types.TokenBalance, error) {
+	for _, balance := range a.addressTokens(address) {
+		if equalAddresses(balance.Token.ContractAddress, tokenAddress) {
+			return balance, nil
+		}
+	}
+	return types.TokenBalance{}, postgres.NoDataFound
+}
+
+// AddressDelegations matches delegations with the following undelegations by transactions of the address
+func (a *memoryAccessor) AddressDelegations(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error) {
+	var delegations []types.Delegation
+	var undelegation *Transaction
+	var undelegationReason string
+	// Transactions are ordered by height descending so the undelegation is found before its delegation
+	for _, tx := range a.fixtures.Transactions {
+		switch {
+		case tx.Type == "UndelegateTx" && equalAddresses(tx.From, address):
+			item := tx
+			undelegation, undelegationReason = &item, "Undelegation"
+		case tx.Type == "KillDelegatorTx" && equalAddresses(tx.To, address):
+			item := tx
+			undelegation, undelegationReason = &item, "Termination"
+		case tx.Type == "DelegateTx" && equalAddresses(tx.From, address):
+			delegation := types.Delegation{
+				DelegateeAddress: tx.To,
+				DelegationTx:     tx.summary(),
+				DelegationBlock:  a.blockSummary(tx.BlockHeight),
+			}
+			if undelegation != nil {
+				undelegationTx := undelegation.summary()
+				delegation.UndelegationTx = &undelegationTx
+				delegation.UndelegationBlock = a.blockSummary(undelegation.BlockHeight)
+				delegation.UndelegationReason = undelegationReason
+				undelegation = nil
+			}
+			delegations = append(delegations, delegation)
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(delegations), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delegations[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) BalancesCount(ctx context.Context) (uint64, error) {
+	return uint64(len(a.fixtures.Addresses)), nil
+}
+
+func (a *memoryAccessor) Balances(ctx context.Context, sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error) {
+	balances := make([]types.Balance, 0, len(a.fixtures.Addresses))
+	for _, item := range a.fixtures.Addresses {
+		balances = append(balances, types.Balance{
+			Address: item.Address,
+			Balance: item.Balance,
+			Stake:   item.Stake,
+		})
+	}
+	byStake := sortBy != nil && *sortBy == "stake"
+	sort.SliceStable(balances, func(i, j int) bool {
+		if byStake {
+			return balances[i].Stake.GreaterThan(balances[j].Stake)
+		}
+		return balances[i].Balance.GreaterThan(balances[j].Balance)
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(balances), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return balances[from:to], nextContinuationToken, nil
+}
+
+// totalLatestMiningRewards returns latest mining rewards of all rewarded addresses and identities which can mine
+// ordered by the total reward descending
+func (a *memoryAccessor) totalLatestMiningRewards(afterTime time.Time) []types.TotalMiningReward {
+	var res []types.TotalMiningReward
+	addresses := make(map[string]bool)
+	for _, reward := range a.fixtures.Rewards {
+		if _, ok := addresses[strings.ToLower(reward.Address)]; ok || reward.BlockHeight == 0 {
+			continue
+		}
+		total, _ := a.AddressTotalLatestMiningReward(context.Background(), afterTime, reward.Address)
+		rewarded := total.Proposer+total.FinalCommittee > 0
+		addresses[strings.ToLower(reward.Address)] = rewarded
+		if rewarded {
+			res = append(res, total)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Balance.Add(res[i].Stake).GreaterThan(res[j].Balance.Add(res[j].Stake))
+	})
+	for _, identity := range a.fixtures.Identities {
+		if identity.State != "Verified" && identity.State != "Newbie" && identity.State != "Human" {
+			continue
+		}
+		if addresses[strings.ToLower(identity.Address)] {
+			continue
+		}
+		res = append(res, types.TotalMiningReward{
+			Address: identity.Address,
+		})
+	}
+	return res
+}
+
+// latestBurntCoins returns coins burnt in blocks after the time
+func (a *memoryAccessor) latestBurntCoins(afterTime time.Time) []BurntCoins {
+	var res []BurntCoins
+	for _, item := range a.fixtures.BurntCoins {
+		if block, err := a.blockByHeight(item.BlockHeight); err == nil && block.Timestamp.After(afterTime) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) totalLatestBurntCoins(afterTime time.Time) []types.AddressBurntCoins {
+	var res []types.AddressBurntCoins
+	indexes := make(map[string]int)
+	for _, item := range a.latestBurntCoins(afterTime) {
+		i, ok := indexes[strings.ToLower(item.Address)]
+		if !ok {
+			i = len(res)
+			indexes[strings.ToLower(item.Address)] = i
+			res = append(res, types.AddressBurntCoins{Address: item.Address})
+		}
+		res[i].Amount = res[i].Amount.Add(item.Amount)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Amount.GreaterThan(res[j].Amount)
+	})
+	return res
+}
+
+// offsetBounds returns bounds of the page starting from the index
+func offsetBounds(total int, startIndex, count uint64) (from, to int) {
+	from, to = total, total
+	if startIndex < uint64(total) {
+		from = int(startIndex)
+	}
+	if count < uint64(total-from) {
+		to = from + int(count)
+	}
+	return from, to
+}
+
+func (a *memoryAccessor) TotalLatestMiningRewardsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	return uint64(len(a.totalLatestMiningRewards(afterTime))), nil
+}
+
+func (a *memoryAccessor) TotalLatestMiningRewards(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.TotalMiningReward, error) {
+	rewards := a.totalLatestMiningRewards(afterTime)
+	from, to := offsetBounds(len(rewards), startIndex, count)
+	return rewards[from:to], nil
+}
+
+func (a *memoryAccessor) TotalLatestBurntCoinsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	return uint64(len(a.totalLatestBurntCoins(afterTime))), nil
+}
+
+func (a *memoryAccessor) TotalLatestBurntCoins(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.AddressBurntCoins, error) {
+	burntCoins := a.totalLatestBurntCoins(afterTime)
+	from, to := offsetBounds(len(burntCoins), startIndex, count)
+	return burntCoins[from:to], nil
+}
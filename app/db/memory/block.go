@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+func (b Block) summary() types.BlockSummary {
+	return types.BlockSummary{
+		Height:               b.Height,
+		Hash:                 b.Hash,
+		Timestamp:            b.Timestamp,
+		TxCount:              b.TxCount,
+		IsEmpty:              b.IsEmpty,
+		Coins:                b.Coins,
+		BodySize:             b.BodySize,
+		FullSize:             b.FullSize,
+		VrfProposerThreshold: b.VrfProposerThreshold,
+		Proposer:             b.Proposer,
+		ProposerVrfScore:     b.ProposerVrfScore,
+		FeeRate:              b.FeeRate,
+		FeeRatePerByte:       b.FeeRatePerByte,
+		Flags:                b.Flags,
+		Upgrade:              b.Upgrade,
+		OfflineAddress:       b.OfflineAddress,
+		Epoch:                b.Epoch,
+	}
+}
+
+func (a *memoryAccessor) findBlock(match func(block Block) bool) (Block, error) {
+	for _, block := range a.fixtures.Blocks {
+		if match(block) {
+			return block, nil
+		}
+	}
+	return Block{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) blockByHeight(height uint64) (Block, error) {
+	return a.findBlock(func(block Block) bool {
+		return block.Height == height
+	})
+}
+
+func (a *memoryAccessor) blockSummary(height uint64) *types.BlockSummary {
+	block, err := a.blockByHeight(height)
+	if err != nil {
+		return nil
+	}
+	res := block.summary()
+	return &res
+}
+
+func (a *memoryAccessor) blockByHash(hash string) (Block, error) {
+	return a.findBlock(func(block Block) bool {
+		return equalAddresses(block.Hash, hash)
+	})
+}
+
+func (a *memoryAccessor) blockTxs(height uint64) []types.TransactionSummary {
+	var res []types.TransactionSummary
+	for _, tx := range a.fixtures.Transactions {
+		if tx.BlockHeight == height {
+			res = append(res, tx.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) blockTxsPage(height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	txs := a.blockTxs(height)
+	from, to, nextContinuationToken, err := pageBounds(len(txs), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) BlockByHeight(ctx context.Context, height uint64) (types.BlockDetail, error) {
+	block, err := a.blockByHeight(height)
+	return block.BlockDetail, err
+}
+
+func (a *memoryAccessor) BlockTxsCountByHeight(ctx context.Context, height uint64) (uint64, error) {
+	return uint64(len(a.blockTxs(height))), nil
+}
+
+func (a *memoryAccessor) BlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	return a.blockTxsPage(height, count, continuationToken)
+}
+
+func (a *memoryAccessor) BlockByHash(ctx context.Context, hash string) (types.BlockDetail, error) {
+	block, err := a.blockByHash(hash)
+	return block.BlockDetail, err
+}
+
+func (a *memoryAccessor) BlockTxsCountByHash(ctx context.Context, hash string) (uint64, error) {
+	block, err := a.blockByHash(hash)
+	if err != nil {
+		return 0, nil
+	}
+	return uint64(len(a.blockTxs(block.Height))), nil
+}
+
+func (a *memoryAccessor) BlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	block, err := a.blockByHash(hash)
+	if err != nil {
+		return nil, nil, nil
+	}
+	return a.blockTxsPage(block.Height, count, continuationToken)
+}
+
+func (a *memoryAccessor) BlockCoinsByHeight(ctx context.Context, height uint64) (types.AllCoins, error) {
+	block, err := a.blockByHeight(height)
+	return block.Coins, err
+}
+
+func (a *memoryAccessor) BlockCoinsByHash(ctx context.Context, hash string) (types.AllCoins, error) {
+	block, err := a.blockByHash(hash)
+	return block.Coins, err
+}
+
+func (a *memoryAccessor) LastBlock(ctx context.Context) (types.BlockDetail, error) {
+	if len(a.fixtures.Blocks) == 0 {
+		return types.BlockDetail{}, postgres.NoDataFound
+	}
+	return a.fixtures.Blocks[0].BlockDetail, nil
+}
+
+func (tx Transaction) summary() types.TransactionSummary {
+	amount, tips, maxFee, fee := tx.Amount, tx.Tips, tx.MaxFee, tx.Fee
+	return types.TransactionSummary{
+		Hash:      tx.Hash,
+		Type:      tx.Type,
+		Timestamp: tx.Timestamp,
+		From:      tx.From,
+		To:        tx.To,
+		Amount:    &amount,
+		Tips:      &tips,
+		MaxFee:    &maxFee,
+		Fee:       &fee,
+		Size:      tx.Size,
+		Nonce:     tx.Nonce,
+		Transfer:  tx.Transfer,
+		Data:      tx.Data,
+		TxReceipt: tx.TxReceipt,
+	}
+}
+
+func (a *memoryAccessor) findTransaction(hash string) (Transaction, error) {
+	for _, tx := range a.fixtures.Transactions {
+		if equalAddresses(tx.Hash, hash) {
+			return tx, nil
+		}
+	}
+	return Transaction{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) Transaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
+	tx, err := a.findTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &tx.TransactionDetail, nil
+}
+
+func (a *memoryAccessor) TransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
+	tx, err := a.findTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Raw) == 0 {
+		return nil, postgres.NoDataFound
+	}
+	return &tx.Raw, nil
+}
+
+func (a *memoryAccessor) TransactionEvents(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error) {
+	// Unknown transactions have no events the same as transactions without them
+	tx, _ := a.findTransaction(hash)
+	from, to, nextContinuationToken, err := pageBounds(len(tx.Events), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx.Events[from:to], nextContinuationToken, nil
+}
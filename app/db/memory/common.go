@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+const minersHistoryLimit = 2000
+
+func (a *memoryAccessor) Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
+	var upgrades []types.ActivatedUpgrade
+	for _, block := range a.fixtures.Blocks {
+		if block.Upgrade != nil && *block.Upgrade > 0 {
+			upgrades = append(upgrades, types.ActivatedUpgrade{
+				BlockSummary: block.summary(),
+			})
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(upgrades), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return upgrades[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
+	from, to, nextContinuationToken, err := pageBounds(len(a.fixtures.Upgrades), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	var res []types.Upgrade
+	for _, item := range a.fixtures.Upgrades[from:to] {
+		res = append(res, item.Upgrade)
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) findUpgrade(upgrade uint64) (Upgrade, error) {
+	for _, item := range a.fixtures.Upgrades {
+		if uint64(item.Upgrade.Upgrade) == upgrade {
+			return item, nil
+		}
+	}
+	return Upgrade{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) UpgradeVotingHistory(ctx context.Context, upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error) {
+	item, _ := a.findUpgrade(upgrade)
+	var res []*types.UpgradeVotingHistoryItem
+	for i := range item.VotingHistory {
+		res = append(res, &item.VotingHistory[i])
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error) {
+	item, err := a.findUpgrade(upgrade)
+	if err != nil {
+		return nil, err
+	}
+	return &item.Upgrade, nil
+}
+
+func (a *memoryAccessor) MinersHistory(ctx context.Context) ([]types.MinersHistoryItem, error) {
+	res := a.fixtures.MinersHistory
+	if len(res) > minersHistoryLimit {
+		res = res[:minersHistoryLimit]
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) PeersHistory(ctx context.Context, count uint64) ([]types.PeersHistoryItem, error) {
+	res := a.fixtures.PeersHistory
+	if uint64(len(res)) > count {
+		res = res[:count]
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) DynamicEndpoints(ctx context.Context) ([]types.DynamicEndpoint, error) {
+	var res []types.DynamicEndpoint
+	for _, endpoint := range a.fixtures.DynamicEndpoints {
+		item := types.DynamicEndpoint{
+			Method:     endpoint.Method,
+			DataSource: endpoint.DataSource,
+			Limit:      endpoint.Limit,
+		}
+		if len(item.Method) == 0 {
+			item.Method = item.DataSource
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) DynamicEndpointData(ctx context.Context, dataSource string, limit *int) (*types.DynamicEndpointResult, error) {
+	for _, endpoint := range a.fixtures.DynamicEndpoints {
+		if endpoint.DataSource != dataSource {
+			continue
+		}
+		res := endpoint.DynamicEndpointResult
+		if limit != nil && *limit >= 0 && *limit < len(res.Data) {
+			res.Data = res.Data[:*limit]
+		}
+		return &res, nil
+	}
+	return nil, postgres.NoDataFound
+}
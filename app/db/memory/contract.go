@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+// Oracle voting states in lower case accepted by the contracts filter
+var oracleVotingStates = map[string]bool{
+	"pending":        true,
+	"open":           true,
+	"voted":          true,
+	"counting":       true,
+	"archive":        true,
+	"terminated":     true,
+	"canbeprolonged": true,
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, item := range addresses {
+		if equalAddresses(item, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// forOracle returns the contract as it is seen by the oracle, open votings are marked as voted after the oracle votes
+func (c OracleVotingContract) forOracle(oracle string) types.OracleVotingContract {
+	res := c.OracleVotingContract
+	res.IsOracle = containsAddress(c.Committee, oracle)
+	if res.State == "Open" && containsAddress(c.Voters, oracle) {
+		res.State = "Voted"
+	}
+	return res
+}
+
+func (a *memoryAccessor) findContract(address string) (Contract, error) {
+	for _, contract := range a.fixtures.Contracts {
+		if equalAddresses(contract.Address, address) {
+			return contract, nil
+		}
+	}
+	return Contract{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) Contract(ctx context.Context, address string) (types.Contract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.Contract{}, err
+	}
+	return contract.Contract, nil
+}
+
+func (a *memoryAccessor) ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	var updates []types.ContractTxBalanceUpdate
+	for _, update := range a.fixtures.ContractTxBalanceUpdates {
+		if equalAddresses(update.ContractAddress, contractAddress) {
+			updates = append(updates, update)
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(updates), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updates[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) ContractVerifiedCodeFile(ctx context.Context, address string) ([]byte, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(contract.VerifiedCodeFile) == 0 {
+		return nil, postgres.NoDataFound
+	}
+	return contract.VerifiedCodeFile, nil
+}
+
+func (a *memoryAccessor) TimeLockContract(ctx context.Context, address string) (types.TimeLockContract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.TimeLockContract{}, err
+	}
+	if contract.TimeLock == nil {
+		return types.TimeLockContract{}, postgres.NoDataFound
+	}
+	return *contract.TimeLock, nil
+}
+
+func (a *memoryAccessor) OracleLockContract(ctx context.Context, address string) (types.OracleLockContract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.OracleLockContract{}, err
+	}
+	if contract.OracleLock == nil {
+		return types.OracleLockContract{}, postgres.NoDataFound
+	}
+	return *contract.OracleLock, nil
+}
+
+func (a *memoryAccessor) RefundableOracleLockContract(ctx context.Context, address string) (types.RefundableOracleLockContract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.RefundableOracleLockContract{}, err
+	}
+	if contract.RefundableOracleLock == nil {
+		return types.RefundableOracleLockContract{}, postgres.NoDataFound
+	}
+	return *contract.RefundableOracleLock, nil
+}
+
+func (a *memoryAccessor) MultisigContract(ctx context.Context, address string) (types.MultisigContract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.MultisigContract{}, err
+	}
+	if contract.Multisig == nil {
+		return types.MultisigContract{}, postgres.NoDataFound
+	}
+	return *contract.Multisig, nil
+}
+
+// OracleVotingContracts filters contracts the same way postgres does, contracts sorted by reward are ordered by
+// the estimated oracle reward and other ones by the creation time
+func (a *memoryAccessor) OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+	requestedStates := make(map[string]bool)
+	for _, state := range states {
+		if !oracleVotingStates[strings.ToLower(state)] {
+			return nil, nil, apierrors.Errorf(apierrors.InvalidArgument, "unknown state %v", state)
+		}
+		requestedStates[strings.ToLower(state)] = true
+	}
+	sortByReward := requestedStates["open"] || requestedStates["pending"]
+	for _, state := range []string{"voted", "counting", "canbeprolonged", "archive", "terminated"} {
+		sortByReward = sortByReward && !requestedStates[state]
+	}
+	if sortBy != nil {
+		if !sortByReward && *sortBy == "reward" {
+			return nil, nil, apierrors.New(apierrors.InvalidArgument, "invalid combination of values 'states[]' and 'sortBy'")
+		}
+		if *sortBy == "timestamp" {
+			sortByReward = false
+		}
+	}
+	var contracts []types.OracleVotingContract
+	for _, contract := range a.fixtures.Contracts {
+		if contract.OracleVoting == nil {
+			continue
+		}
+		item := contract.OracleVoting.forOracle(oracleAddress)
+		if len(authorAddress) > 0 && !equalAddresses(item.Author, authorAddress) || !all && !item.IsOracle ||
+			!requestedStates[strings.ToLower(item.State)] {
+			continue
+		}
+		contracts = append(contracts, item)
+	}
+	estimatedOracleReward := func(contract types.OracleVotingContract) decimal.Decimal {
+		if contract.EstimatedOracleReward == nil {
+			return decimal.Zero
+		}
+		return *contract.EstimatedOracleReward
+	}
+	sort.SliceStable(contracts, func(i, j int) bool {
+		if sortByReward {
+			return estimatedOracleReward(contracts[i]).GreaterThan(estimatedOracleReward(contracts[j]))
+		}
+		return contracts[i].CreateTime.After(contracts[j].CreateTime)
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(contracts), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contracts[from:to], nextContinuationToken, nil
+}
+
+// AddressOracleVotingContracts returns contracts deployed or voted by the address, the latest created contracts first
+func (a *memoryAccessor) AddressOracleVotingContracts(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+	var contracts []types.OracleVotingContract
+	for _, contract := range a.fixtures.Contracts {
+		if contract.OracleVoting == nil {
+			continue
+		}
+		if equalAddresses(contract.OracleVoting.Author, address) || containsAddress(contract.OracleVoting.Voters, address) {
+			contracts = append(contracts, contract.OracleVoting.forOracle(address))
+		}
+	}
+	sort.SliceStable(contracts, func(i, j int) bool {
+		return contracts[i].CreateTime.After(contracts[j].CreateTime)
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(contracts), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contracts[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error) {
+	contract, err := a.findContract(address)
+	if err != nil {
+		return types.OracleVotingContract{}, err
+	}
+	if contract.OracleVoting == nil {
+		return types.OracleVotingContract{}, postgres.NoDataFound
+	}
+	return contract.OracleVoting.forOracle(oracle), nil
+}
+
+func (a *memoryAccessor) EstimatedOracleRewards(ctx context.Context) ([]types.EstimatedOracleReward, error) {
+	return a.fixtures.EstimatedOracleRewards, nil
+}
+
+func (a *memoryAccessor) Token(ctx context.Context, address string) (types.Token, error) {
+	for _, token := range a.fixtures.Tokens {
+		if equalAddresses(token.ContractAddress, address) {
+			return token, nil
+		}
+	}
+	return types.Token{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) TokenHolders(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+	var holders []types.TokenBalance
+	for _, balance := range a.fixtures.TokenBalances {
+		if equalAddresses(balance.Token.ContractAddress, address) {
+			holders = append(holders, balance)
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(holders), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return holders[from:to], nextContinuationToken, nil
+}
@@ -0,0 +1,479 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+func (e Epoch) detail() types.EpochDetail {
+	return types.EpochDetail{
+		Epoch:                        e.Epoch,
+		ValidationTime:               e.ValidationTime,
+		StateRoot:                    e.StateRoot,
+		ValidationFirstBlockHeight:   e.ValidationFirstBlockHeight,
+		MinScoreForInvite:            e.MinScoreForInvite,
+		CandidateCount:               e.CandidateCount,
+		DiscriminationStakeThreshold: e.DiscriminationStakeThreshold,
+	}
+}
+
+func (a *memoryAccessor) findEpoch(epoch uint64) (Epoch, error) {
+	for _, item := range a.fixtures.Epochs {
+		if item.Epoch == epoch {
+			return item, nil
+		}
+	}
+	return Epoch{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) EpochsCount(ctx context.Context) (uint64, error) {
+	return uint64(len(a.fixtures.Epochs)), nil
+}
+
+func (a *memoryAccessor) Epochs(ctx context.Context, count uint64, continuationToken *string) ([]types.EpochSummary, *string, error) {
+	from, to, nextContinuationToken, err := pageBounds(len(a.fixtures.Epochs), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	var res []types.EpochSummary
+	for _, item := range a.fixtures.Epochs[from:to] {
+		res = append(res, item.EpochSummary)
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) LastEpoch(ctx context.Context) (types.EpochDetail, error) {
+	if len(a.fixtures.Epochs) == 0 {
+		return types.EpochDetail{}, postgres.NoDataFound
+	}
+	return a.fixtures.Epochs[0].detail(), nil
+}
+
+func (a *memoryAccessor) Epoch(ctx context.Context, epoch uint64) (types.EpochDetail, error) {
+	item, err := a.findEpoch(epoch)
+	if err != nil {
+		return types.EpochDetail{}, err
+	}
+	return item.detail(), nil
+}
+
+func (a *memoryAccessor) epochBlocks(epoch uint64) []types.BlockSummary {
+	var res []types.BlockSummary
+	for _, block := range a.fixtures.Blocks {
+		if block.Epoch == epoch {
+			res = append(res, block.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochBlocksCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochBlocks(epoch))), nil
+}
+
+func (a *memoryAccessor) EpochBlocks(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error) {
+	blocks := a.epochBlocks(epoch)
+	from, to, nextContinuationToken, err := pageBounds(len(blocks), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return blocks[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) epochFlips(epoch uint64) []types.FlipSummary {
+	var res []types.FlipSummary
+	for _, flip := range a.fixtures.Flips {
+		if flip.Epoch == epoch {
+			res = append(res, flip.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochFlipsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochFlips(epoch))), nil
+}
+
+func (a *memoryAccessor) EpochFlips(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	flips := a.epochFlips(epoch)
+	from, to, nextContinuationToken, err := pageBounds(len(flips), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flips[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) EpochFlipAnswersSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	var values []string
+	for _, flip := range a.epochFlips(epoch) {
+		values = append(values, flip.Answer)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) EpochFlipStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	var values []string
+	for _, flip := range a.epochFlips(epoch) {
+		values = append(values, flip.Status)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) EpochFlipWrongWordsSummary(ctx context.Context, epoch uint64) ([]types.NullableBoolValueCount, error) {
+	var wrongWords, notWrongWords uint32
+	for _, flip := range a.epochFlips(epoch) {
+		if flip.WrongWords {
+			wrongWords++
+		} else {
+			notWrongWords++
+		}
+	}
+	var res []types.NullableBoolValueCount
+	for _, item := range []struct {
+		value bool
+		count uint32
+	}{{true, wrongWords}, {false, notWrongWords}} {
+		if item.count == 0 {
+			continue
+		}
+		value := item.value
+		res = append(res, types.NullableBoolValueCount{Value: &value, Count: item.count})
+	}
+	return res, nil
+}
+
+func containsState(states []string, state string) bool {
+	if len(states) == 0 {
+		return true
+	}
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *memoryAccessor) epochIdentities(epoch uint64, prevStates []string, states []string) []types.EpochIdentity {
+	var res []types.EpochIdentity
+	for _, identity := range a.fixtures.EpochIdentities {
+		if identity.Epoch == epoch && containsState(prevStates, identity.PrevState) && containsState(states, identity.State) {
+			res = append(res, identity.EpochIdentity)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochIdentitiesCount(ctx context.Context, epoch uint64, prevStates []string, states []string) (uint64, error) {
+	return uint64(len(a.epochIdentities(epoch, prevStates, states))), nil
+}
+
+func (a *memoryAccessor) EpochIdentities(ctx context.Context, epoch uint64, prevStates []string, states []string, count uint64,
+	continuationToken *string) ([]types.EpochIdentity, *string, error) {
+	identities := a.epochIdentities(epoch, prevStates, states)
+	from, to, nextContinuationToken, err := pageBounds(len(identities), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identities[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) EpochIdentityStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	var values []string
+	for _, identity := range a.epochIdentities(epoch, nil, nil) {
+		values = append(values, identity.State)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) EpochIdentityStatesInterimSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	var values []string
+	for _, identity := range a.fixtures.Identities {
+		values = append(values, identity.State)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) epochInvites(epoch uint64) []types.Invite {
+	var res []types.Invite
+	for _, invite := range a.fixtures.Invites {
+		if invite.Epoch == epoch {
+			res = append(res, invite.Invite)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochInvitesSummary(ctx context.Context, epoch uint64) (types.InvitesSummary, error) {
+	res := types.InvitesSummary{}
+	for _, invite := range a.epochInvites(epoch) {
+		res.AllCount++
+		if len(invite.ActivationHash) > 0 {
+			res.UsedCount++
+		}
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) EpochInviteStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	var values []string
+	for _, invite := range a.epochInvites(epoch) {
+		values = append(values, invite.State)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) EpochInvitesCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochInvites(epoch))), nil
+}
+
+func (a *memoryAccessor) EpochInvites(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	invites := a.epochInvites(epoch)
+	from, to, nextContinuationToken, err := pageBounds(len(invites), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invites[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) epochTxs(epoch uint64) []types.TransactionSummary {
+	var res []types.TransactionSummary
+	for _, tx := range a.fixtures.Transactions {
+		if tx.Epoch == epoch {
+			res = append(res, tx.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochTxsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochTxs(epoch))), nil
+}
+
+func (a *memoryAccessor) EpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	txs := a.epochTxs(epoch)
+	from, to, nextContinuationToken, err := pageBounds(len(txs), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) EpochCoins(ctx context.Context, epoch uint64) (types.AllCoins, error) {
+	item, err := a.findEpoch(epoch)
+	if err != nil {
+		return types.AllCoins{}, err
+	}
+	return item.Coins, nil
+}
+
+func (a *memoryAccessor) EpochRewardsSummary(ctx context.Context, epoch uint64) (types.RewardsSummary, error) {
+	item, err := a.findEpoch(epoch)
+	if err != nil {
+		return types.RewardsSummary{}, err
+	}
+	res := item.Rewards
+	res.Epoch = epoch
+	return res, nil
+}
+
+func (a *memoryAccessor) epochBadAuthors(epoch uint64) []types.BadAuthor {
+	var res []types.BadAuthor
+	for _, badAuthor := range a.fixtures.BadAuthors {
+		if badAuthor.Epoch == epoch {
+			res = append(res, badAuthor)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochBadAuthorsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochBadAuthors(epoch))), nil
+}
+
+func (a *memoryAccessor) EpochBadAuthors(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	badAuthors := a.epochBadAuthors(epoch)
+	from, to, nextContinuationToken, err := pageBounds(len(badAuthors), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return badAuthors[from:to], nextContinuationToken, nil
+}
+
+// epochIdentitiesRewards groups validation rewards of the epoch by address keeping the order of the first reward
+func (a *memoryAccessor) epochIdentitiesRewards(epoch uint64, address string) []types.Rewards {
+	var res []types.Rewards
+	indexes := make(map[string]int)
+	for _, reward := range a.fixtures.Rewards {
+		if reward.Epoch != epoch || reward.BlockHeight > 0 || len(address) > 0 && !equalAddresses(reward.Address, address) {
+			continue
+		}
+		i, ok := indexes[reward.Address]
+		if !ok {
+			i = len(res)
+			indexes[reward.Address] = i
+			item := types.Rewards{
+				Address: reward.Address,
+				Epoch:   epoch,
+			}
+			if identity, err := a.EpochIdentity(context.Background(), epoch, reward.Address); err == nil {
+				item.PrevState = identity.PrevState
+				item.State = identity.State
+				item.Age = uint16(epoch - identity.BirthEpoch)
+			}
+			res = append(res, item)
+		}
+		res[i].Rewards = append(res[i].Rewards, reward)
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochIdentitiesRewardsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return uint64(len(a.epochIdentitiesRewards(epoch, ""))), nil
+}
+
+func (a *memoryAccessor) EpochIdentitiesRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+	rewards := a.epochIdentitiesRewards(epoch, "")
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rewards[from:to], nextContinuationToken, nil
+}
+
+// EpochFundPayments returns no payments for unknown epochs like postgres does
+func (a *memoryAccessor) EpochFundPayments(ctx context.Context, epoch uint64) ([]types.FundPayment, error) {
+	item, _ := a.findEpoch(epoch)
+	return item.FundPayments, nil
+}
+
+func (a *memoryAccessor) EpochRewardBounds(ctx context.Context, epoch uint64) ([]types.RewardBounds, error) {
+	item, _ := a.findEpoch(epoch)
+	return item.RewardBounds, nil
+}
+
+func delegationRewardsTotal(rewards []types.DelegationReward) decimal.Decimal {
+	var res decimal.Decimal
+	for _, reward := range rewards {
+		res = res.Add(reward.Balance)
+	}
+	return res
+}
+
+// addDelegationRewards sums up rewards of the same types keeping the order of the first rewards
+func addDelegationRewards(rewards []types.DelegationReward, other []types.DelegationReward) []types.DelegationReward {
+	for _, reward := range other {
+		added := false
+		for i := range rewards {
+			if rewards[i].Type == reward.Type {
+				rewards[i].Balance = rewards[i].Balance.Add(reward.Balance)
+				added = true
+				break
+			}
+		}
+		if !added {
+			rewards = append(rewards, reward)
+		}
+	}
+	return rewards
+}
+
+// delegateeTotalRewards groups matched delegator rewards by epochs and delegatees ordering them by the total reward descending
+func (a *memoryAccessor) delegateeTotalRewards(match func(reward DelegateeReward) bool) []types.DelegateeTotalRewards {
+	var res []types.DelegateeTotalRewards
+	indexes := make(map[string]int)
+	for _, reward := range a.fixtures.DelegateeRewards {
+		if !match(reward) {
+			continue
+		}
+		key := fmt.Sprintf("%d:%s", reward.Epoch, strings.ToLower(reward.Delegatee))
+		i, ok := indexes[key]
+		if !ok {
+			i = len(res)
+			indexes[key] = i
+			res = append(res, types.DelegateeTotalRewards{
+				Address: reward.Delegatee,
+				Epoch:   reward.Epoch,
+			})
+		}
+		res[i].Rewards = addDelegationRewards(res[i].Rewards, reward.Rewards)
+		res[i].Delegators++
+		if reward.Penalized {
+			res[i].PenalizedDelegators++
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return delegationRewardsTotal(res[i].Rewards).GreaterThan(delegationRewardsTotal(res[j].Rewards))
+	})
+	return res
+}
+
+func (a *memoryAccessor) EpochDelegateeTotalRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+	rewards := a.delegateeTotalRewards(func(reward DelegateeReward) bool {
+		return reward.Epoch == epoch
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := rewards[from:to]
+	for i := range res {
+		res[i].Epoch = 0
+	}
+	return res, nextContinuationToken, nil
+}
+
+// EpochDistribution calculates the distribution of closed epochs by balances after their last blocks, the distribution
+// of the last epoch is calculated by current balances and marked as provisional
+func (a *memoryAccessor) EpochDistribution(ctx context.Context, epoch uint64, addressesToExclude []string) (*types.EpochDistribution, error) {
+	if len(a.fixtures.Epochs) == 0 || epoch > a.fixtures.Epochs[0].Epoch {
+		return nil, postgres.NoDataFound
+	}
+	provisional := epoch == a.fixtures.Epochs[0].Epoch
+	var blockHeight uint64
+	for _, block := range a.fixtures.Blocks {
+		if provisional || block.Epoch == epoch {
+			blockHeight = block.Height
+			break
+		}
+	}
+	if !provisional && blockHeight == 0 {
+		return nil, postgres.NoDataFound
+	}
+	var balances []types.Balance
+	for _, address := range a.fixtures.Addresses {
+		excluded := false
+		for _, addressToExclude := range addressesToExclude {
+			if equalAddresses(address.Address, addressToExclude) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+		balance, stake := address.Balance, address.Stake
+		if !provisional {
+			balance, stake = a.balanceAt(address, blockHeight)
+		}
+		if !balance.IsPositive() && !stake.IsPositive() {
+			continue
+		}
+		balances = append(balances, types.Balance{
+			Address: address.Address,
+			Balance: balance,
+			Stake:   stake,
+		})
+	}
+	if len(balances) == 0 {
+		return nil, postgres.NoDataFound
+	}
+	res := postgres.NewEpochDistribution(epoch, blockHeight, balances)
+	res.Provisional = provisional
+	return res, nil
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+func (a *memoryAccessor) findEpochIdentity(epoch uint64, address string) (EpochIdentity, error) {
+	for _, identity := range a.fixtures.EpochIdentities {
+		if identity.Epoch == epoch && equalAddresses(identity.Address, address) {
+			return identity, nil
+		}
+	}
+	return EpochIdentity{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) EpochIdentity(ctx context.Context, epoch uint64, address string) (types.EpochIdentity, error) {
+	identity, err := a.findEpochIdentity(epoch, address)
+	if err != nil {
+		return types.EpochIdentity{}, err
+	}
+	return identity.EpochIdentity, nil
+}
+
+func (a *memoryAccessor) EpochIdentityShortFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	identity, _ := a.findEpochIdentity(epoch, address)
+	return identity.ShortFlipsToSolve, nil
+}
+
+func (a *memoryAccessor) EpochIdentityLongFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	identity, _ := a.findEpochIdentity(epoch, address)
+	return identity.LongFlipsToSolve, nil
+}
+
+// epochIdentityAnswers collects answers of the identity given to flips of the epoch
+func (a *memoryAccessor) epochIdentityAnswers(epoch uint64, address string, isShort bool) []types.Answer {
+	var res []types.Answer
+	for _, flip := range a.fixtures.Flips {
+		if flip.Epoch != epoch {
+			continue
+		}
+		answers := flip.LongAnswers
+		if isShort {
+			answers = flip.ShortAnswers
+		}
+		for _, answer := range answers {
+			if equalAddresses(answer.Address, address) {
+				answer.Cid = flip.Cid
+				answer.Address = ""
+				res = append(res, answer)
+			}
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochIdentityShortAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	return a.epochIdentityAnswers(epoch, address, true), nil
+}
+
+func (a *memoryAccessor) EpochIdentityLongAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	return a.epochIdentityAnswers(epoch, address, false), nil
+}
+
+func (a *memoryAccessor) epochIdentityFlips(epoch uint64, address string) []types.FlipSummary {
+	var res []types.FlipSummary
+	for _, flip := range a.fixtures.Flips {
+		if flip.Epoch == epoch && equalAddresses(flip.Author, address) {
+			res = append(res, flip.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) EpochIdentityFlips(ctx context.Context, epoch uint64, address string) ([]types.FlipSummary, error) {
+	return a.epochIdentityFlips(epoch, address), nil
+}
+
+func (a *memoryAccessor) EpochIdentityFlipsWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.FlipWithRewardFlag, error) {
+	var res []types.FlipWithRewardFlag
+	for _, flip := range a.epochIdentityFlips(epoch, address) {
+		res = append(res, types.FlipWithRewardFlag{
+			FlipSummary: flip,
+		})
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) EpochIdentityReportedFlipRewards(ctx context.Context, epoch uint64, address string) ([]types.ReportedFlipReward, error) {
+	identity, _ := a.findEpochIdentity(epoch, address)
+	return identity.ReportedFlipRewards, nil
+}
+
+func (a *memoryAccessor) EpochIdentityRewards(ctx context.Context, epoch uint64, address string) ([]types.Reward, error) {
+	rewards := a.epochIdentitiesRewards(epoch, address)
+	if len(rewards) == 0 {
+		return nil, nil
+	}
+	return rewards[0].Rewards, nil
+}
+
+func (a *memoryAccessor) EpochIdentityBadAuthor(ctx context.Context, epoch uint64, address string) (*types.BadAuthor, error) {
+	for _, badAuthor := range a.fixtures.BadAuthors {
+		if badAuthor.Epoch == epoch && equalAddresses(badAuthor.Address, address) {
+			res := badAuthor
+			return &res, nil
+		}
+	}
+	return nil, nil
+}
+
+// isRewardedInviteEpoch returns true if the invite author can be rewarded for the invite in the epoch
+func isRewardedInviteEpoch(invite Invite, epoch uint64) bool {
+	return invite.Epoch <= epoch && invite.Epoch+2 >= epoch
+}
+
+func (a *memoryAccessor) EpochIdentityInvitesWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.InviteWithRewardFlag, error) {
+	var res []types.InviteWithRewardFlag
+	for _, invite := range a.fixtures.Invites {
+		if !equalAddresses(invite.Author, address) || !isRewardedInviteEpoch(invite, epoch) {
+			continue
+		}
+		item := types.InviteWithRewardFlag{
+			Invite: invite.Invite,
+		}
+		if invite.RewardEpoch == epoch {
+			item.RewardType = invite.RewardType
+			item.EpochHeight = invite.EpochHeight
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) EpochIdentitySavedInviteRewards(ctx context.Context, epoch uint64, address string) ([]types.StrValueCount, error) {
+	identity, _ := a.findEpochIdentity(epoch, address)
+	return identity.SavedInviteRewards, nil
+}
+
+func (a *memoryAccessor) EpochIdentityAvailableInvites(ctx context.Context, epoch uint64, address string) ([]types.EpochInvites, error) {
+	identity, _ := a.findEpochIdentity(epoch, address)
+	return identity.AvailableInvites, nil
+}
+
+func (a *memoryAccessor) EpochDelegateeRewards(ctx context.Context, epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error) {
+	var rewards []types.DelegateeReward
+	for _, reward := range a.fixtures.DelegateeRewards {
+		if reward.Epoch != epoch || !equalAddresses(reward.Delegatee, address) {
+			continue
+		}
+		item := reward.DelegateeReward
+		if identity, err := a.findEpochIdentity(epoch, item.DelegatorAddress); err == nil && len(item.State) == 0 {
+			item.PrevState = identity.PrevState
+			item.State = identity.State
+		}
+		rewards = append(rewards, item)
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rewards[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) EpochIdentityValidationSummary(ctx context.Context, epoch uint64, address string) (types.ValidationSummary, error) {
+	identity, err := a.EpochIdentity(ctx, epoch, address)
+	if err != nil {
+		return types.ValidationSummary{}, err
+	}
+	return types.ValidationSummary{
+		MadeFlips:         identity.MadeFlips,
+		AvailableFlips:    identity.AvailableFlips,
+		ShortAnswers:      identity.ShortAnswers,
+		TotalShortAnswers: identity.TotalShortAnswers,
+		LongAnswers:       identity.LongAnswers,
+		ShortAnswersCount: identity.ShortAnswersCount,
+		LongAnswersCount:  identity.LongAnswersCount,
+		PrevState:         identity.PrevState,
+		State:             identity.State,
+		Approved:          identity.Approved,
+	}, nil
+}
+
+func (a *memoryAccessor) EpochAddressDelegateeTotalRewards(ctx context.Context, epoch uint64, address string) (types.DelegateeTotalRewards, error) {
+	rewards := a.delegateeTotalRewards(func(reward DelegateeReward) bool {
+		return reward.Epoch == epoch && equalAddresses(reward.Delegatee, address)
+	})
+	if len(rewards) == 0 {
+		return types.DelegateeTotalRewards{}, nil
+	}
+	res := rewards[0]
+	res.Address = ""
+	return res, nil
+}
+
+// EpochIdentityInviteeWithRewardFlag returns the latest invite activated by the identity which its inviter can be rewarded
+// for in the epoch
+func (a *memoryAccessor) EpochIdentityInviteeWithRewardFlag(ctx context.Context, epoch uint64, address string) (*types.InviteeWithRewardFlag, error) {
+	for _, invite := range a.fixtures.Invites {
+		if !equalAddresses(invite.ActivationAuthor, address) || !isRewardedInviteEpoch(invite, epoch) {
+			continue
+		}
+		res := &types.InviteeWithRewardFlag{
+			Epoch:        invite.Epoch,
+			Hash:         invite.Hash,
+			Inviter:      invite.Author,
+			InviterState: "Undefined",
+			State:        "Undefined",
+		}
+		if inviter, err := a.findEpochIdentity(epoch, invite.Author); err == nil {
+			res.InviterStake = inviter.Stake
+			res.InviterState = inviter.State
+		}
+		if invitee, err := a.findEpochIdentity(epoch, address); err == nil {
+			res.State = invitee.State
+		}
+		return res, nil
+	}
+	return nil, nil
+}
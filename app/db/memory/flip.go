@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+func (f Flip) summary() types.FlipSummary {
+	return types.FlipSummary{
+		Cid:             f.Cid,
+		Author:          f.Author,
+		Epoch:           f.Epoch,
+		ShortRespCount:  f.ShortRespCount,
+		LongRespCount:   f.LongRespCount,
+		Status:          f.Status,
+		Answer:          f.Answer,
+		WrongWords:      f.WrongWords,
+		WrongWordsVotes: f.WrongWordsVotes,
+		Timestamp:       f.Timestamp,
+		Size:            f.Size,
+		Words:           f.Words,
+		WithPrivatePart: f.WithPrivatePart,
+		Grade:           f.Grade,
+		GradeScore:      f.GradeScore,
+	}
+}
+
+func (a *memoryAccessor) findFlip(hash string) (int, error) {
+	for i, flip := range a.fixtures.Flips {
+		if flip.Cid == hash {
+			return i, nil
+		}
+	}
+	return 0, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) Flip(ctx context.Context, hash string) (types.Flip, error) {
+	i, err := a.findFlip(hash)
+	if err != nil {
+		return types.Flip{}, err
+	}
+	return a.fixtures.Flips[i].Flip, nil
+}
+
+func (a *memoryAccessor) FlipContent(ctx context.Context, hash string) (types.FlipContent, error) {
+	i, err := a.findFlip(hash)
+	if err != nil {
+		return types.FlipContent{}, err
+	}
+	content := a.fixtures.Flips[i].Content
+	if content == nil {
+		return types.FlipContent{}, postgres.NoDataFound
+	}
+	return *content, nil
+}
+
+func (a *memoryAccessor) FlipAnswers(ctx context.Context, hash string, isShort bool) ([]types.Answer, error) {
+	i, err := a.findFlip(hash)
+	if err != nil {
+		// Unknown flips have no answers
+		return nil, nil
+	}
+	if isShort {
+		return a.fixtures.Flips[i].ShortAnswers, nil
+	}
+	return a.fixtures.Flips[i].LongAnswers, nil
+}
+
+func (a *memoryAccessor) FlipEpochAdjacentFlips(ctx context.Context, hash string) (types.AdjacentStrValues, error) {
+	i, err := a.findFlip(hash)
+	if err != nil {
+		return types.AdjacentStrValues{}, err
+	}
+	epoch := a.fixtures.Flips[i].Epoch
+	var cids []string
+	var index int
+	for _, flip := range a.fixtures.Flips {
+		if flip.Epoch != epoch {
+			continue
+		}
+		if flip.Cid == hash {
+			index = len(cids)
+		}
+		cids = append(cids, flip.Cid)
+	}
+	res := types.AdjacentStrValues{}
+	if index > 0 {
+		res.Prev = types.AdjacentStrValue{Value: cids[index-1]}
+	} else {
+		res.Prev = types.AdjacentStrValue{Value: cids[len(cids)-1], Cycled: true}
+	}
+	if index < len(cids)-1 {
+		res.Next = types.AdjacentStrValue{Value: cids[index+1]}
+	} else {
+		res.Next = types.AdjacentStrValue{Value: cids[0], Cycled: true}
+	}
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+func (a *memoryAccessor) Identity(ctx context.Context, address string) (types.Identity, error) {
+	for _, identity := range a.fixtures.Identities {
+		if equalAddresses(identity.Address, address) {
+			return identity, nil
+		}
+	}
+	return types.Identity{}, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) IdentityAge(ctx context.Context, address string) (uint64, error) {
+	lastEpoch, err := a.LastEpoch(ctx)
+	if err != nil {
+		return 0, nil
+	}
+	for _, identity := range a.identityEpochs(address) {
+		if identity.BirthEpoch > 0 && lastEpoch.Epoch >= identity.BirthEpoch {
+			return lastEpoch.Epoch - identity.BirthEpoch, nil
+		}
+	}
+	return 0, nil
+}
+
+func (a *memoryAccessor) IdentityCurrentFlipCids(ctx context.Context, address string) ([]string, error) {
+	lastEpoch, err := a.LastEpoch(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	var res []string
+	for _, flip := range a.epochIdentityFlips(lastEpoch.Epoch, address) {
+		res = append(res, flip.Cid)
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) identityEpochs(address string) []types.EpochIdentity {
+	var res []types.EpochIdentity
+	for _, identity := range a.fixtures.EpochIdentities {
+		if equalAddresses(identity.Address, address) {
+			res = append(res, identity.EpochIdentity)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityEpochsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityEpochs(address))), nil
+}
+
+func (a *memoryAccessor) IdentityEpochs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error) {
+	identities := a.identityEpochs(address)
+	from, to, nextContinuationToken, err := pageBounds(len(identities), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identities[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) identityFlips(address string) []types.FlipSummary {
+	var res []types.FlipSummary
+	for _, flip := range a.fixtures.Flips {
+		if equalAddresses(flip.Author, address) {
+			res = append(res, flip.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityFlipsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityFlips(address))), nil
+}
+
+func (a *memoryAccessor) IdentityFlips(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	flips := a.identityFlips(address)
+	from, to, nextContinuationToken, err := pageBounds(len(flips), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flips[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) IdentityFlipQualifiedAnswers(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	var values []string
+	for _, flip := range a.identityFlips(address) {
+		values = append(values, flip.Answer)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) IdentityFlipStates(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	var values []string
+	for _, flip := range a.identityFlips(address) {
+		values = append(values, flip.Status)
+	}
+	return strValueCounts(values), nil
+}
+
+func (a *memoryAccessor) identityInvites(address string) []types.Invite {
+	var res []types.Invite
+	for _, invite := range a.fixtures.Invites {
+		if equalAddresses(invite.Author, address) {
+			res = append(res, invite.Invite)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityInvitesCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityInvites(address))), nil
+}
+
+func (a *memoryAccessor) IdentityInvites(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	invites := a.identityInvites(address)
+	from, to, nextContinuationToken, err := pageBounds(len(invites), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invites[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) identityTxs(address string) []types.TransactionSummary {
+	var res []types.TransactionSummary
+	for _, tx := range a.fixtures.Transactions {
+		if equalAddresses(tx.From, address) || equalAddresses(tx.To, address) {
+			res = append(res, tx.summary())
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityTxsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityTxs(address))), nil
+}
+
+func (a *memoryAccessor) IdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	txs := a.identityTxs(address)
+	from, to, nextContinuationToken, err := pageBounds(len(txs), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return txs[from:to], nextContinuationToken, nil
+}
+
+// identityMiningRewards returns rewards of the address bound to blocks
+func (a *memoryAccessor) identityMiningRewards(address string) []types.Reward {
+	var res []types.Reward
+	for _, reward := range a.fixtures.Rewards {
+		if reward.BlockHeight > 0 && equalAddresses(reward.Address, address) {
+			res = append(res, reward)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityRewardsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityMiningRewards(address))), nil
+}
+
+func (a *memoryAccessor) IdentityRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Reward, *string, error) {
+	rewards := a.identityMiningRewards(address)
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rewards[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) identityEpochRewards(address string) []types.Rewards {
+	var res []types.Rewards
+	for _, epoch := range a.fixtures.Epochs {
+		res = append(res, a.epochIdentitiesRewards(epoch.Epoch, address)...)
+	}
+	return res
+}
+
+func (a *memoryAccessor) IdentityEpochRewardsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.identityEpochRewards(address))), nil
+}
+
+func (a *memoryAccessor) IdentityEpochRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+	rewards := a.identityEpochRewards(address)
+	from, to, nextContinuationToken, err := pageBounds(len(rewards), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rewards[from:to], nextContinuationToken, nil
+}
@@ -0,0 +1,465 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fixtures describe the canned dataset served by the memory accessor, items use the same JSON format as API responses
+type Fixtures struct {
+	Coins                    types.AllCoins                  `json:"coins"`
+	Epochs                   []Epoch                         `json:"epochs"`
+	Blocks                   []Block                         `json:"blocks"`
+	Identities               []types.Identity                `json:"identities"`
+	EpochIdentities          []EpochIdentity                 `json:"epochIdentities"`
+	Addresses                []types.Address                 `json:"addresses"`
+	AddressStates            []AddressState                  `json:"addressStates"`
+	BalanceUpdates           []BalanceUpdate                 `json:"balanceUpdates"`
+	Penalties                []types.Penalty                 `json:"penalties"`
+	BurntCoins               []BurntCoins                    `json:"burntCoins"`
+	BadAuthors               []types.BadAuthor               `json:"badAuthors"`
+	Transactions             []Transaction                   `json:"transactions"`
+	Flips                    []Flip                          `json:"flips"`
+	Invites                  []Invite                        `json:"invites"`
+	Rewards                  []types.Reward                  `json:"rewards"`
+	DelegateeRewards         []DelegateeReward               `json:"delegateeRewards"`
+	Contracts                []Contract                      `json:"contracts"`
+	ContractTxBalanceUpdates []types.ContractTxBalanceUpdate `json:"contractTxBalanceUpdates"`
+	EstimatedOracleRewards   []types.EstimatedOracleReward   `json:"estimatedOracleRewards"`
+	Pools                    []types.Pool                    `json:"pools"`
+	PoolSizeHistory          []PoolSizeHistoryItem           `json:"poolSizeHistory"`
+	Delegators               []Delegator                     `json:"delegators"`
+	Tokens                   []types.Token                   `json:"tokens"`
+	TokenBalances            []types.TokenBalance            `json:"tokenBalances"`
+	Upgrades                 []Upgrade                       `json:"upgrades"`
+	MinersHistory            []types.MinersHistoryItem       `json:"minersHistory"`
+	PeersHistory             []types.PeersHistoryItem        `json:"peersHistory"`
+	DynamicEndpoints         []DynamicEndpoint               `json:"dynamicEndpoints"`
+}
+
+type Epoch struct {
+	types.EpochSummary
+	StateRoot                  *string              `json:"stateRoot,omitempty"`
+	ValidationFirstBlockHeight uint64               `json:"validationFirstBlockHeight"`
+	FundPayments               []types.FundPayment  `json:"fundPayments"`
+	RewardBounds               []types.RewardBounds `json:"rewardBounds"`
+}
+
+type Block struct {
+	types.BlockDetail
+	Coins types.AllCoins `json:"coins"`
+}
+
+type EpochIdentity struct {
+	types.EpochIdentity
+	// Stake is the identity stake taken into account for validation rewards
+	Stake               decimal.Decimal            `json:"stake"`
+	ShortFlipsToSolve   []string                   `json:"shortFlipsToSolve"`
+	LongFlipsToSolve    []string                   `json:"longFlipsToSolve"`
+	ReportedFlipRewards []types.ReportedFlipReward `json:"reportedFlipRewards"`
+	SavedInviteRewards  []types.StrValueCount      `json:"savedInviteRewards"`
+	AvailableInvites    []types.EpochInvites       `json:"availableInvites"`
+}
+
+type AddressState struct {
+	types.AddressState
+	Address string `json:"address"`
+}
+
+type BalanceUpdate struct {
+	types.BalanceUpdate
+	Address string `json:"address"`
+}
+
+// UnmarshalJSON decodes the own fields too since the embedded type decoder is promoted and would skip them
+func (u *BalanceUpdate) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &u.BalanceUpdate); err != nil {
+		return err
+	}
+	aux := struct {
+		Address string `json:"address"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	u.Address = aux.Address
+	return nil
+}
+
+type BurntCoins struct {
+	Address     string          `json:"address"`
+	Amount      decimal.Decimal `json:"amount"`
+	BlockHeight uint64          `json:"blockHeight"`
+	// Reason is Penalty, Fee, KilledStake or BurnTx, coins burnt for other reasons are shown as other in the supply history
+	Reason string `json:"reason"`
+}
+
+type Transaction struct {
+	types.TransactionDetail
+	Raw    hexutil.Bytes   `json:"raw,omitempty"`
+	Events []types.TxEvent `json:"events"`
+}
+
+// UnmarshalJSON decodes the own fields too since the embedded type decoder is promoted and would skip them
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.TransactionDetail); err != nil {
+		return err
+	}
+	aux := struct {
+		Raw    hexutil.Bytes   `json:"raw,omitempty"`
+		Events []types.TxEvent `json:"events"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.Raw, t.Events = aux.Raw, aux.Events
+	return nil
+}
+
+type Flip struct {
+	types.Flip
+	Cid            string             `json:"cid"`
+	ShortRespCount uint32             `json:"shortRespCount"`
+	LongRespCount  uint32             `json:"longRespCount"`
+	Content        *types.FlipContent `json:"content"`
+	ShortAnswers   []types.Answer     `json:"shortAnswers"`
+	LongAnswers    []types.Answer     `json:"longAnswers"`
+}
+
+type Invite struct {
+	types.Invite
+	// RewardEpoch is the epoch the invite author was rewarded for the invite in
+	RewardEpoch uint64 `json:"rewardEpoch,omitempty"`
+	RewardType  string `json:"rewardType,omitempty"`
+	EpochHeight uint32 `json:"epochHeight,omitempty"`
+}
+
+type DelegateeReward struct {
+	types.DelegateeReward
+	Epoch     uint64 `json:"epoch"`
+	Delegatee string `json:"delegatee"`
+	Penalized bool   `json:"penalized"`
+}
+
+type Contract struct {
+	types.Contract
+	VerifiedCodeFile     hexutil.Bytes                       `json:"verifiedCodeFile,omitempty"`
+	TimeLock             *types.TimeLockContract             `json:"timeLock"`
+	OracleLock           *types.OracleLockContract           `json:"oracleLock"`
+	RefundableOracleLock *types.RefundableOracleLockContract `json:"refundableOracleLock"`
+	Multisig             *types.MultisigContract             `json:"multisig"`
+	OracleVoting         *OracleVotingContract               `json:"oracleVoting"`
+}
+
+type OracleVotingContract struct {
+	types.OracleVotingContract
+	Committee []string `json:"committee"`
+	Voters    []string `json:"voters"`
+}
+
+type PoolSizeHistoryItem struct {
+	types.PoolSizeHistoryItem
+	Pool string `json:"pool"`
+}
+
+type Delegator struct {
+	types.Delegator
+	Pool string `json:"pool"`
+}
+
+type Upgrade struct {
+	types.Upgrade
+	VotingHistory []types.UpgradeVotingHistoryItem `json:"votingHistory"`
+}
+
+type DynamicEndpoint struct {
+	Method     string `json:"method"`
+	DataSource string `json:"dataSource"`
+	Limit      *int   `json:"limit"`
+	types.DynamicEndpointResult
+}
+
+type memoryAccessor struct {
+	fixtures *Fixtures
+	log      log.Logger
+}
+
+// NewMemoryAccessor loads fixtures from the JSON file or from all JSON files of the directory
+func NewMemoryAccessor(fixturesPath string, logger log.Logger) db.Accessor {
+	fixtures, err := LoadFixtures(fixturesPath)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("Loaded fixtures", "path", fixturesPath, "epochs", len(fixtures.Epochs), "blocks", len(fixtures.Blocks),
+		"identities", len(fixtures.Identities), "txs", len(fixtures.Transactions))
+	return NewMemoryAccessorFromFixtures(fixtures, logger)
+}
+
+func NewMemoryAccessorFromFixtures(fixtures *Fixtures, logger log.Logger) db.Accessor {
+	sortFixtures(fixtures)
+	return &memoryAccessor{
+		fixtures: fixtures,
+		log:      logger,
+	}
+}
+
+func LoadFixtures(path string) (*Fixtures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find fixtures %v", path)
+	}
+	if !info.IsDir() {
+		return readFixturesFile(path)
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read fixtures dir %v", path)
+	}
+	res := &Fixtures{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		fixtures, err := readFixturesFile(filepath.Join(path, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		res.merge(fixtures)
+	}
+	return res, nil
+}
+
+func readFixturesFile(path string) (*Fixtures, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read fixtures file %v", path)
+	}
+	res := &Fixtures{}
+	if err := json.Unmarshal(bytes, res); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse fixtures file %v", path)
+	}
+	return res, nil
+}
+
+func (f *Fixtures) merge(other *Fixtures) {
+	if !other.Coins.TotalBalance.IsZero() || !other.Coins.Minted.IsZero() {
+		f.Coins = other.Coins
+	}
+	f.Epochs = append(f.Epochs, other.Epochs...)
+	f.Blocks = append(f.Blocks, other.Blocks...)
+	f.Identities = append(f.Identities, other.Identities...)
+	f.EpochIdentities = append(f.EpochIdentities, other.EpochIdentities...)
+	f.Addresses = append(f.Addresses, other.Addresses...)
+	f.AddressStates = append(f.AddressStates, other.AddressStates...)
+	f.BalanceUpdates = append(f.BalanceUpdates, other.BalanceUpdates...)
+	f.Penalties = append(f.Penalties, other.Penalties...)
+	f.BurntCoins = append(f.BurntCoins, other.BurntCoins...)
+	f.BadAuthors = append(f.BadAuthors, other.BadAuthors...)
+	f.Transactions = append(f.Transactions, other.Transactions...)
+	f.Flips = append(f.Flips, other.Flips...)
+	f.Invites = append(f.Invites, other.Invites...)
+	f.Rewards = append(f.Rewards, other.Rewards...)
+	f.DelegateeRewards = append(f.DelegateeRewards, other.DelegateeRewards...)
+	f.Contracts = append(f.Contracts, other.Contracts...)
+	f.ContractTxBalanceUpdates = append(f.ContractTxBalanceUpdates, other.ContractTxBalanceUpdates...)
+	f.EstimatedOracleRewards = append(f.EstimatedOracleRewards, other.EstimatedOracleRewards...)
+	f.Pools = append(f.Pools, other.Pools...)
+	f.PoolSizeHistory = append(f.PoolSizeHistory, other.PoolSizeHistory...)
+	f.Delegators = append(f.Delegators, other.Delegators...)
+	f.Tokens = append(f.Tokens, other.Tokens...)
+	f.TokenBalances = append(f.TokenBalances, other.TokenBalances...)
+	f.Upgrades = append(f.Upgrades, other.Upgrades...)
+	f.MinersHistory = append(f.MinersHistory, other.MinersHistory...)
+	f.PeersHistory = append(f.PeersHistory, other.PeersHistory...)
+	f.DynamicEndpoints = append(f.DynamicEndpoints, other.DynamicEndpoints...)
+}
+
+// sortFixtures orders items the same way postgres queries do: the latest items first
+func sortFixtures(f *Fixtures) {
+	sort.SliceStable(f.Epochs, func(i, j int) bool {
+		return f.Epochs[i].Epoch > f.Epochs[j].Epoch
+	})
+	sort.SliceStable(f.Blocks, func(i, j int) bool {
+		return f.Blocks[i].Height > f.Blocks[j].Height
+	})
+	sort.SliceStable(f.EpochIdentities, func(i, j int) bool {
+		return f.EpochIdentities[i].Epoch > f.EpochIdentities[j].Epoch
+	})
+	sort.SliceStable(f.AddressStates, func(i, j int) bool {
+		return f.AddressStates[i].BlockHeight > f.AddressStates[j].BlockHeight
+	})
+	sort.SliceStable(f.BalanceUpdates, func(i, j int) bool {
+		return f.BalanceUpdates[i].BlockHeight > f.BalanceUpdates[j].BlockHeight
+	})
+	sort.SliceStable(f.Penalties, func(i, j int) bool {
+		return f.Penalties[i].BlockHeight > f.Penalties[j].BlockHeight
+	})
+	sort.SliceStable(f.BadAuthors, func(i, j int) bool {
+		return f.BadAuthors[i].Epoch > f.BadAuthors[j].Epoch
+	})
+	sort.SliceStable(f.Transactions, func(i, j int) bool {
+		return f.Transactions[i].BlockHeight > f.Transactions[j].BlockHeight
+	})
+	sort.SliceStable(f.Flips, func(i, j int) bool {
+		return f.Flips[i].Timestamp.After(f.Flips[j].Timestamp)
+	})
+	sort.SliceStable(f.Invites, func(i, j int) bool {
+		return f.Invites[i].Timestamp.After(f.Invites[j].Timestamp)
+	})
+	sort.SliceStable(f.Rewards, func(i, j int) bool {
+		if f.Rewards[i].Epoch != f.Rewards[j].Epoch {
+			return f.Rewards[i].Epoch > f.Rewards[j].Epoch
+		}
+		return f.Rewards[i].BlockHeight > f.Rewards[j].BlockHeight
+	})
+	sort.SliceStable(f.DelegateeRewards, func(i, j int) bool {
+		return delegationRewardsTotal(f.DelegateeRewards[i].Rewards).GreaterThan(delegationRewardsTotal(f.DelegateeRewards[j].Rewards))
+	})
+	sort.SliceStable(f.ContractTxBalanceUpdates, func(i, j int) bool {
+		return f.ContractTxBalanceUpdates[i].Timestamp.After(f.ContractTxBalanceUpdates[j].Timestamp)
+	})
+	sort.SliceStable(f.Pools, func(i, j int) bool {
+		return f.Pools[i].Size > f.Pools[j].Size
+	})
+	sort.SliceStable(f.PoolSizeHistory, func(i, j int) bool {
+		return f.PoolSizeHistory[i].Epoch > f.PoolSizeHistory[j].Epoch
+	})
+	sort.SliceStable(f.Delegators, func(i, j int) bool {
+		return f.Delegators[i].Stake.GreaterThan(f.Delegators[j].Stake)
+	})
+	sort.SliceStable(f.TokenBalances, func(i, j int) bool {
+		return f.TokenBalances[i].Balance.GreaterThan(f.TokenBalances[j].Balance)
+	})
+	sort.SliceStable(f.Upgrades, func(i, j int) bool {
+		return f.Upgrades[i].Upgrade.Upgrade > f.Upgrades[j].Upgrade.Upgrade
+	})
+	sort.SliceStable(f.MinersHistory, func(i, j int) bool {
+		return f.MinersHistory[i].Timestamp.After(f.MinersHistory[j].Timestamp)
+	})
+	sort.SliceStable(f.PeersHistory, func(i, j int) bool {
+		return f.PeersHistory[i].Timestamp.After(f.PeersHistory[j].Timestamp)
+	})
+}
+
+// pageBounds returns bounds of the page of filtered items, continuation token is the offset of the next page
+func pageBounds(total int, count uint64, continuationToken *string) (from, to int, nextContinuationToken *string, err error) {
+	if continuationToken != nil {
+		offset, err := strconv.Atoi(*continuationToken)
+		if err != nil || offset < 0 {
+			return 0, 0, nil, apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
+		}
+		from = offset
+	}
+	if from > total {
+		from = total
+	}
+	to = from + int(count)
+	if to >= total {
+		return from, total, nil, nil
+	}
+	next := strconv.Itoa(to)
+	return from, to, &next, nil
+}
+
+func equalAddresses(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+func strValueCounts(values []string) []types.StrValueCount {
+	var res []types.StrValueCount
+	indexes := make(map[string]int)
+	for _, value := range values {
+		if i, ok := indexes[value]; ok {
+			res[i].Count++
+			continue
+		}
+		indexes[value] = len(res)
+		res = append(res, types.StrValueCount{Value: value, Count: 1})
+	}
+	return res
+}
+
+func newEntity(name, value string) types.Entity {
+	ref := fmt.Sprintf("/api/%s/%s", name, value)
+	return types.Entity{
+		Name:     name,
+		Value:    value,
+		Ref:      ref,
+		NameOld:  name,
+		ValueOld: value,
+		RefOld:   ref,
+	}
+}
+
+func (a *memoryAccessor) Search(ctx context.Context, value string) ([]types.Entity, error) {
+	var res []types.Entity
+	if _, err := a.Identity(ctx, value); err == nil {
+		res = append(res, newEntity("Identity", value))
+	}
+	if _, err := a.Address(ctx, value); err == nil {
+		res = append(res, newEntity("Address", value))
+	}
+	if _, err := a.blockByHash(value); err == nil {
+		res = append(res, newEntity("Block", value))
+	} else if height, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if _, err := a.blockByHeight(height); err == nil {
+			res = append(res, newEntity("Block", value))
+		}
+		if _, err := a.findEpoch(height); err == nil {
+			res = append(res, newEntity("Epoch", value))
+		}
+	}
+	if _, err := a.findFlip(value); err == nil {
+		res = append(res, newEntity("Flip", value))
+	}
+	if _, err := a.Transaction(ctx, value); err == nil {
+		res = append(res, newEntity("Transaction", value))
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) Coins(ctx context.Context) (types.AllCoins, error) {
+	return a.fixtures.Coins, nil
+}
+
+func (a *memoryAccessor) CirculatingSupply(ctx context.Context, addressesToExclude []string) (decimal.Decimal, error) {
+	res := a.fixtures.Coins.TotalBalance
+	for _, address := range addressesToExclude {
+		if item, err := a.Address(ctx, address); err == nil {
+			res = res.Sub(item.Balance)
+		}
+	}
+	return res, nil
+}
+
+func (a *memoryAccessor) ActiveAddressesCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	addresses := make(map[string]struct{})
+	for _, tx := range a.fixtures.Transactions {
+		if tx.Timestamp == nil || tx.Timestamp.Before(afterTime) {
+			continue
+		}
+		addresses[strings.ToLower(tx.From)] = struct{}{}
+		if len(tx.To) > 0 {
+			addresses[strings.ToLower(tx.To)] = struct{}{}
+		}
+	}
+	return uint64(len(addresses)), nil
+}
+
+func (a *memoryAccessor) Destroy() {
+}
@@ -0,0 +1,319 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_memoryAccessor(t *testing.T) {
+	ctx := context.Background()
+	accessor := NewMemoryAccessor(filepath.Join("testdata"), log.New())
+
+	lastEpoch, err := accessor.LastEpoch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), lastEpoch.Epoch)
+
+	lastBlock, err := accessor.LastBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), lastBlock.Height)
+
+	count, err := accessor.EpochTxsCount(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), count)
+
+	txs, continuationToken, err := accessor.EpochTxs(ctx, 1, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.NotNil(t, continuationToken)
+	require.Equal(t, "0x02", txs[0].Hash)
+
+	txs, continuationToken, err = accessor.EpochTxs(ctx, 1, 2, continuationToken)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Nil(t, continuationToken)
+	require.Equal(t, "0x01", txs[0].Hash)
+
+	_, _, err = accessor.EpochTxs(ctx, 1, 2, &[]string{"abc"}[0])
+	require.Error(t, err)
+
+	count, err = accessor.IdentityTxsCount(ctx, "0xaa")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), count)
+
+	_, err = accessor.Identity(ctx, "0xCC")
+	require.Equal(t, postgres.NoDataFound, err)
+
+	entities, err := accessor.Search(ctx, "0xaa")
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+	require.Equal(t, "/api/Identity/0xaa", entities[0].Ref)
+
+	entities, err = accessor.Search(ctx, "10")
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	require.Equal(t, "Block", entities[0].Name)
+
+	sortBy := "stake"
+	balances, _, err := accessor.Balances(ctx, &sortBy, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	require.Equal(t, "0xBB", balances[0].Address)
+
+	supply, err := accessor.CirculatingSupply(ctx, []string{"0xBB"})
+	require.NoError(t, err)
+	require.Equal(t, "950", supply.String())
+
+	activeCount, err := accessor.ActiveAddressesCount(ctx, time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), activeCount)
+}
+
+func Test_memoryAccessor_balances(t *testing.T) {
+	ctx := context.Background()
+	accessor := NewMemoryAccessor(filepath.Join("testdata"), log.New())
+
+	count, err := accessor.AddressBalanceUpdatesCount(ctx, "0xaa")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+
+	updates, _, err := accessor.AddressBalanceUpdates(ctx, "0xaa", 1, nil)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	require.Equal(t, uint64(20), updates[0].BlockHeight)
+
+	summary, err := accessor.AddressBalanceUpdatesSummary(ctx, "0xaa")
+	require.NoError(t, err)
+	require.Equal(t, "3", summary.BalanceIn.String())
+	require.Equal(t, "2", summary.StakeIn.String())
+	require.True(t, summary.BalanceOut.IsZero())
+	_, err = accessor.AddressBalanceUpdatesSummary(ctx, "0xcc")
+	require.Equal(t, postgres.NoDataFound, err)
+
+	// Balances after the block are taken from the first update after it
+	balances, err := accessor.BalancesAt(ctx, []string{"0xaa", "0xbb", "0xcc"}, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	require.Equal(t, "97", balances[0].Balance.String())
+	require.Equal(t, "8", balances[0].Stake.String())
+	require.Equal(t, "52", balances[1].Balance.String())
+	require.Equal(t, uint64(10), balances[0].BlockHeight)
+
+	timestamp := time.Date(2020, 1, 1, 11, 30, 0, 0, time.UTC)
+	balances, err = accessor.BalancesAt(ctx, []string{"0xaa"}, 0, &timestamp)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	require.Equal(t, uint64(11), balances[0].BlockHeight)
+	require.Equal(t, "100", balances[0].Balance.String())
+	require.Equal(t, "8", balances[0].Stake.String())
+
+	distribution, err := accessor.EpochDistribution(ctx, 1, nil)
+	require.NoError(t, err)
+	require.False(t, distribution.Provisional)
+	require.Equal(t, uint64(11), distribution.BlockHeight)
+	require.Equal(t, "150", distribution.TotalBalance.String())
+	require.Equal(t, "28", distribution.TotalStake.String())
+
+	distribution, err = accessor.EpochDistribution(ctx, 2, []string{"0xBB"})
+	require.NoError(t, err)
+	require.True(t, distribution.Provisional)
+	require.Equal(t, uint64(1), distribution.HoldersCount)
+
+	_, err = accessor.EpochDistribution(ctx, 3, nil)
+	require.Equal(t, postgres.NoDataFound, err)
+
+	supply, continuationToken, err := accessor.SupplyHistory(ctx, db.EpochSupplyInterval, []string{"0xBB"}, 1, nil)
+	require.NoError(t, err)
+	require.Len(t, supply, 1)
+	require.Equal(t, uint64(20), supply[0].BlockHeight)
+	require.Equal(t, "1200", supply[0].TotalSupply.String())
+	require.Equal(t, "950", supply[0].CirculatingSupply.String())
+
+	supply, continuationToken, err = accessor.SupplyHistory(ctx, db.EpochSupplyInterval, []string{"0xBB"}, 1, continuationToken)
+	require.NoError(t, err)
+	require.Nil(t, continuationToken)
+	require.Equal(t, uint64(1), *supply[0].Epoch)
+	require.Equal(t, "900", supply[0].CirculatingSupply.String())
+	require.Equal(t, "1", supply[0].BurntByReason.Fees.String())
+
+	_, _, err = accessor.SupplyHistory(ctx, "week", nil, 1, nil)
+	require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err))
+
+	series, _, err := accessor.StatsSeries(ctx, db.DayStatsInterval, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	require.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), series[1].Timestamp)
+	require.Equal(t, uint64(3), series[1].TxCount)
+	require.Equal(t, uint64(3), series[1].NewAddresses)
+	require.Equal(t, "2", series[1].AverageFeeRate.String())
+	require.Equal(t, uint64(1), series[0].NewAddresses)
+
+	miningRewards, err := accessor.TotalLatestMiningRewards(ctx, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, miningRewards, 1)
+	require.Equal(t, uint64(1), miningRewards[0].Proposer)
+
+	burntCoins, err := accessor.TotalLatestBurntCoins(ctx, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, burntCoins, 1)
+	require.Equal(t, "1", burntCoins[0].Amount.String())
+	burntCoins, err = accessor.TotalLatestBurntCoins(ctx, time.Time{}, 1, 10)
+	require.NoError(t, err)
+	require.Empty(t, burntCoins)
+
+	summaries, _, err := accessor.AddressMiningRewardSummaries(ctx, "0xaa", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	require.Equal(t, "3", summaries[0].Amount.String())
+	require.Equal(t, "1", summaries[0].Penalty.String())
+
+	delegations, _, err := accessor.AddressDelegations(ctx, "0xbb", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+	require.Equal(t, "0xDD", delegations[0].DelegateeAddress)
+	require.Equal(t, uint64(20), delegations[0].DelegationBlock.Height)
+	require.Nil(t, delegations[0].UndelegationTx)
+}
+
+func Test_memoryAccessor_epochIdentities(t *testing.T) {
+	ctx := context.Background()
+	accessor := NewMemoryAccessor(filepath.Join("testdata"), log.New())
+
+	answers, err := accessor.EpochIdentityShortAnswers(ctx, 1, "0xaa")
+	require.NoError(t, err)
+	require.Len(t, answers, 1)
+	require.Equal(t, "0xf1", answers[0].Cid)
+	require.Empty(t, answers[0].Address)
+
+	flipsToSolve, err := accessor.EpochIdentityLongFlipsToSolve(ctx, 1, "0xaa")
+	require.NoError(t, err)
+	require.Equal(t, []string{"0xf1"}, flipsToSolve)
+
+	content, err := accessor.FlipContent(ctx, "0xf1")
+	require.NoError(t, err)
+	require.Len(t, content.Pics, 1)
+
+	badAuthor, err := accessor.EpochIdentityBadAuthor(ctx, 1, "0xbb")
+	require.NoError(t, err)
+	require.Equal(t, "WrongWords", badAuthor.Reason)
+	badAuthor, err = accessor.EpochIdentityBadAuthor(ctx, 1, "0xaa")
+	require.NoError(t, err)
+	require.Nil(t, badAuthor)
+
+	invites, err := accessor.EpochIdentityInvitesWithRewardFlag(ctx, 1, "0xaa")
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	require.Equal(t, "Invitations", invites[0].RewardType)
+	invites, err = accessor.EpochIdentityInvitesWithRewardFlag(ctx, 2, "0xaa")
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	require.Empty(t, invites[0].RewardType)
+
+	invitee, err := accessor.EpochIdentityInviteeWithRewardFlag(ctx, 1, "0xbb")
+	require.NoError(t, err)
+	require.Equal(t, "0xAA", invitee.Inviter)
+	require.Equal(t, "Verified", invitee.InviterState)
+	require.Equal(t, "10", invitee.InviterStake.String())
+	require.Equal(t, "Human", invitee.State)
+
+	totalRewards, _, err := accessor.EpochDelegateeTotalRewards(ctx, 1, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, totalRewards, 1)
+	require.Equal(t, "0xAA", totalRewards[0].Address)
+	require.Equal(t, uint32(2), totalRewards[0].Delegators)
+	require.Equal(t, uint32(1), totalRewards[0].PenalizedDelegators)
+	require.Len(t, totalRewards[0].Rewards, 2)
+	require.Equal(t, "4", totalRewards[0].Rewards[0].Balance.String())
+
+	delegateeRewards, continuationToken, err := accessor.EpochDelegateeRewards(ctx, 1, "0xaa", 1, nil)
+	require.NoError(t, err)
+	require.NotNil(t, continuationToken)
+	require.Equal(t, "0xBB", delegateeRewards[0].DelegatorAddress)
+	require.Equal(t, "Human", delegateeRewards[0].State)
+
+	payments, err := accessor.EpochFundPayments(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, payments, 1)
+}
+
+func Test_memoryAccessor_contracts(t *testing.T) {
+	ctx := context.Background()
+	accessor := NewMemoryAccessor(filepath.Join("testdata"), log.New())
+
+	_, err := accessor.TimeLockContract(ctx, "0xc1")
+	require.NoError(t, err)
+	_, err = accessor.TimeLockContract(ctx, "0xc2")
+	require.Equal(t, postgres.NoDataFound, err)
+
+	code, err := accessor.ContractVerifiedCodeFile(ctx, "0xc2")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, code)
+
+	contracts, _, err := accessor.OracleVotingContracts(ctx, "", "0xbb", []string{"Voted"}, false, nil, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	require.True(t, contracts[0].IsOracle)
+	require.Equal(t, "Voted", contracts[0].State)
+
+	contracts, _, err = accessor.OracleVotingContracts(ctx, "", "0xaa", []string{"Open"}, true, nil, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	require.False(t, contracts[0].IsOracle)
+
+	contracts, _, err = accessor.OracleVotingContracts(ctx, "", "0xaa", []string{"Open"}, false, nil, 10, nil)
+	require.NoError(t, err)
+	require.Empty(t, contracts)
+
+	_, _, err = accessor.OracleVotingContracts(ctx, "", "", []string{"Unknown"}, true, nil, 10, nil)
+	require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err))
+	sortBy := "reward"
+	_, _, err = accessor.OracleVotingContracts(ctx, "", "", []string{"Archive"}, true, &sortBy, 10, nil)
+	require.Equal(t, apierrors.InvalidArgument, apierrors.CodeOf(err))
+
+	contracts, _, err = accessor.AddressOracleVotingContracts(ctx, "0xbb", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+
+	balanceUpdates, _, err := accessor.AddressContractTxBalanceUpdates(ctx, "0xbb", "0xc2", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, balanceUpdates, 1)
+
+	raw, err := accessor.TransactionRaw(ctx, "0x01")
+	require.NoError(t, err)
+	require.Equal(t, "0x0a0b", raw.String())
+	_, err = accessor.TransactionRaw(ctx, "0x02")
+	require.Equal(t, postgres.NoDataFound, err)
+
+	events, _, err := accessor.TransactionEvents(ctx, "0x01", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	upgrades, _, err := accessor.Upgrades(ctx, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, upgrades, 1)
+	require.Equal(t, uint64(20), upgrades[0].Height)
+
+	history, err := accessor.UpgradeVotingHistory(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	_, err = accessor.Upgrade(ctx, 8)
+	require.Equal(t, postgres.NoDataFound, err)
+
+	peers, err := accessor.PeersHistory(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	require.Equal(t, uint64(4), peers[0].Count)
+
+	limit := 1
+	data, err := accessor.DynamicEndpointData(ctx, "top_holders", &limit)
+	require.NoError(t, err)
+	require.Len(t, data.Data, 1)
+	_, err = accessor.DynamicEndpointData(ctx, "unknown", nil)
+	require.Equal(t, postgres.NoDataFound, err)
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+func (a *memoryAccessor) PoolsCount(ctx context.Context) (uint64, error) {
+	return uint64(len(a.fixtures.Pools)), nil
+}
+
+func (a *memoryAccessor) Pools(ctx context.Context, count uint64, continuationToken *string) ([]*types.Pool, *string, error) {
+	from, to, nextContinuationToken, err := pageBounds(len(a.fixtures.Pools), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	var res []*types.Pool
+	for i := from; i < to; i++ {
+		pool := a.fixtures.Pools[i]
+		res = append(res, &pool)
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) Pool(ctx context.Context, address string) (*types.Pool, error) {
+	for _, pool := range a.fixtures.Pools {
+		if equalAddresses(pool.Address, address) {
+			res := pool
+			return &res, nil
+		}
+	}
+	return nil, postgres.NoDataFound
+}
+
+func (a *memoryAccessor) poolDelegators(address string) []*types.Delegator {
+	var res []*types.Delegator
+	for _, delegator := range a.fixtures.Delegators {
+		if equalAddresses(delegator.Pool, address) {
+			item := delegator.Delegator
+			res = append(res, &item)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) PoolDelegatorsCount(ctx context.Context, address string) (uint64, error) {
+	return uint64(len(a.poolDelegators(address))), nil
+}
+
+func (a *memoryAccessor) PoolDelegators(ctx context.Context, address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error) {
+	delegators := a.poolDelegators(address)
+	from, to, nextContinuationToken, err := pageBounds(len(delegators), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delegators[from:to], nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) PoolSizeHistory(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error) {
+	var history []types.PoolSizeHistoryItem
+	for _, item := range a.fixtures.PoolSizeHistory {
+		if equalAddresses(item.Pool, address) {
+			history = append(history, item.PoolSizeHistoryItem)
+		}
+	}
+	from, to, nextContinuationToken, err := pageBounds(len(history), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return history[from:to], nextContinuationToken, nil
+}
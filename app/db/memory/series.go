@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+// blockInterval is a group of adjacent blocks with the same interval key, blocks are ordered by height descending
+type blockInterval struct {
+	key    uint64
+	blocks []Block
+}
+
+func (i blockInterval) first() Block {
+	return i.blocks[len(i.blocks)-1]
+}
+
+func (i blockInterval) last() Block {
+	return i.blocks[0]
+}
+
+func (i blockInterval) contains(height uint64) bool {
+	return height >= i.first().Height && height <= i.last().Height
+}
+
+// blockIntervals groups blocks by interval keys, the latest interval goes first
+func (a *memoryAccessor) blockIntervals(key func(block Block) uint64) []blockInterval {
+	var res []blockInterval
+	for _, block := range a.fixtures.Blocks {
+		k := key(block)
+		if len(res) == 0 || res[len(res)-1].key != k {
+			res = append(res, blockInterval{key: k})
+		}
+		res[len(res)-1].blocks = append(res[len(res)-1].blocks, block)
+	}
+	return res
+}
+
+func (a *memoryAccessor) SupplyHistory(ctx context.Context, interval db.SupplyInterval, addressesToExclude []string, count uint64, continuationToken *string) ([]types.SupplyHistoryItem, *string, error) {
+	var key func(block Block) uint64
+	switch interval {
+	case db.EpochSupplyInterval:
+		key = func(block Block) uint64 {
+			return block.Epoch
+		}
+	case db.DaySupplyInterval:
+		key = func(block Block) uint64 {
+			return uint64(block.Timestamp.Unix() / 86400)
+		}
+	default:
+		return nil, nil, apierrors.Errorf(apierrors.InvalidArgument, "unknown interval %v", interval)
+	}
+	intervals := a.blockIntervals(key)
+	from, to, nextContinuationToken, err := pageBounds(len(intervals), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	var res []types.SupplyHistoryItem
+	for _, item := range intervals[from:to] {
+		last := item.last()
+		frozenBalance := decimal.Zero
+		for _, address := range addressesToExclude {
+			if excluded, err := a.Address(ctx, address); err == nil {
+				balance, _ := a.balanceAt(excluded, last.Height)
+				frozenBalance = frozenBalance.Add(balance)
+			}
+		}
+		supply := types.SupplyHistoryItem{
+			BlockHeight:       last.Height,
+			TotalSupply:       last.Coins.TotalBalance.Add(last.Coins.TotalStake),
+			CirculatingSupply: last.Coins.TotalBalance.Sub(frozenBalance),
+			Staked:            last.Coins.TotalStake,
+			Minted:            last.Coins.Minted,
+			Burnt:             last.Coins.Burnt,
+			BurntByReason:     a.burntByReason(item),
+		}
+		if interval == db.EpochSupplyInterval {
+			epoch := item.key
+			supply.Epoch = &epoch
+			supply.Timestamp = item.first().Timestamp.UTC()
+		} else {
+			supply.Timestamp = time.Unix(int64(item.key)*86400, 0).UTC()
+		}
+		res = append(res, supply)
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) burntByReason(interval blockInterval) types.BurntByReason {
+	res := types.BurntByReason{}
+	for _, item := range a.fixtures.BurntCoins {
+		if !interval.contains(item.BlockHeight) {
+			continue
+		}
+		switch item.Reason {
+		case "Fee":
+			res.Fees = res.Fees.Add(item.Amount)
+		case "BurnTx":
+			res.BurnTxs = res.BurnTxs.Add(item.Amount)
+		case "Penalty":
+			res.Penalties = res.Penalties.Add(item.Amount)
+		case "KilledStake":
+			res.KilledStakes = res.KilledStakes.Add(item.Amount)
+		default:
+			res.Other = res.Other.Add(item.Amount)
+		}
+	}
+	return res
+}
+
+func (a *memoryAccessor) StatsSeries(ctx context.Context, interval db.StatsInterval, count uint64, continuationToken *string) ([]types.StatsSeriesItem, *string, error) {
+	// Weeks are shifted by 3 days to start on Monday since the unix epoch started on Thursday
+	var size, offset int64
+	switch interval {
+	case db.HourStatsInterval:
+		size = 3600
+	case db.DayStatsInterval:
+		size = 86400
+	case db.WeekStatsInterval:
+		size, offset = 7*86400, 3*86400
+	default:
+		return nil, nil, apierrors.Errorf(apierrors.InvalidArgument, "unknown interval %v", interval)
+	}
+	intervals := a.blockIntervals(func(block Block) uint64 {
+		timestamp := block.Timestamp.Unix()
+		return uint64(timestamp - (timestamp+offset)%size)
+	})
+	from, to, nextContinuationToken, err := pageBounds(len(intervals), count, continuationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	var res []types.StatsSeriesItem
+	for _, item := range intervals[from:to] {
+		res = append(res, a.statsSeriesItem(item))
+	}
+	return res, nextContinuationToken, nil
+}
+
+func (a *memoryAccessor) statsSeriesItem(interval blockInterval) types.StatsSeriesItem {
+	res := types.StatsSeriesItem{
+		Timestamp:      time.Unix(int64(interval.key), 0).UTC(),
+		TxCountsByType: make(map[string]uint64),
+	}
+	var feeRates decimal.Decimal
+	var feeRatesCount int64
+	for _, block := range interval.blocks {
+		if block.FeeRate != nil {
+			feeRates = feeRates.Add(*block.FeeRate)
+			feeRatesCount++
+		}
+	}
+	if feeRatesCount > 0 {
+		res.AverageFeeRate = feeRates.Div(decimal.NewFromInt(feeRatesCount))
+	}
+	active := make(map[string]struct{})
+	previous := make(map[string]struct{})
+	for _, tx := range a.fixtures.Transactions {
+		if tx.BlockHeight < interval.first().Height {
+			previous[strings.ToLower(tx.From)] = struct{}{}
+			if len(tx.To) > 0 {
+				previous[strings.ToLower(tx.To)] = struct{}{}
+			}
+			continue
+		}
+		if !interval.contains(tx.BlockHeight) {
+			continue
+		}
+		res.TxCount++
+		res.TxCountsByType[tx.Type]++
+		res.Fees = res.Fees.Add(tx.Fee)
+		res.Tips = res.Tips.Add(tx.Tips)
+		if tx.Type != "ActivationTx" {
+			active[strings.ToLower(tx.From)] = struct{}{}
+		}
+		if len(tx.To) > 0 && tx.Type != "InviteTx" {
+			active[strings.ToLower(tx.To)] = struct{}{}
+		}
+	}
+	res.ActiveAddresses = uint64(len(active))
+	for address := range active {
+		if _, ok := previous[address]; !ok {
+			res.NewAddresses++
+		}
+	}
+	res.ContractDeploys = res.TxCountsByType["DeployContract"]
+	res.ContractCalls = res.TxCountsByType["CallContract"]
+	return res
+}
@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db"
+)
+
+func (a *memoryAccessor) StreamEpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.EpochTxs(ctx, epoch, count, continuationToken))
+}
+
+func (a *memoryAccessor) StreamBlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.BlockTxsByHeight(ctx, height, count, continuationToken))
+}
+
+func (a *memoryAccessor) StreamBlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.BlockTxsByHash(ctx, hash, count, continuationToken))
+}
+
+func (a *memoryAccessor) StreamIdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return db.WriteTxs(w)(a.IdentityTxs(ctx, address, count, continuationToken))
+}
@@ -1,9 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
+	"strings"
 	"time"
 )
 
@@ -26,6 +29,7 @@ const (
 	addressTokensQuery                   = "addressTokens.sql"
 	addressTokenQuery                    = "addressToken.sql"
 	addressDelegationsQuery              = "addressDelegations.sql"
+	balancesAtQuery                      = "balancesAt.sql"
 
 	txBalanceUpdateReason              = "Tx"
 	committeeRewardBalanceUpdateReason = "CommitteeReward"
@@ -33,9 +37,9 @@ const (
 	epochRewardBalanceUpdateReason     = "EpochReward"
 )
 
-func (a *postgresAccessor) Address(address string) (types.Address, error) {
+func (a *postgresAccessor) Address(ctx context.Context, address string) (types.Address, error) {
 	res := types.Address{}
-	err := a.db.QueryRow(a.getQuery(addressQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(addressQuery), address).Scan(
 		&res.Address,
 		&res.Balance,
 		&res.Stake,
@@ -53,12 +57,12 @@ func (a *postgresAccessor) Address(address string) (types.Address, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) AddressPenaltiesCount(address string) (uint64, error) {
-	return a.count(addressPenaltiesCountQuery, address)
+func (a *postgresAccessor) AddressPenaltiesCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, addressPenaltiesCountQuery, address)
 }
 
-func (a *postgresAccessor) AddressPenalties(address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error) {
-	res, nextContinuationToken, err := a.page(addressPenaltiesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressPenalties(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Penalty, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressPenaltiesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.Penalty
 		var id uint64
@@ -88,12 +92,12 @@ func (a *postgresAccessor) AddressPenalties(address string, count uint64, contin
 	return res.([]types.Penalty), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressStatesCount(address string) (uint64, error) {
-	return a.count(addressStatesCountQuery, address)
+func (a *postgresAccessor) AddressStatesCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, addressStatesCountQuery, address)
 }
 
-func (a *postgresAccessor) AddressStates(address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error) {
-	res, nextContinuationToken, err := a.page(addressStatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressStates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.AddressState, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressStatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.AddressState
 		var id uint64
@@ -123,9 +127,9 @@ func (a *postgresAccessor) AddressStates(address string, count uint64, continuat
 	return res.([]types.AddressState), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressTotalLatestMiningReward(afterTime time.Time, address string) (types.TotalMiningReward, error) {
+func (a *postgresAccessor) AddressTotalLatestMiningReward(ctx context.Context, afterTime time.Time, address string) (types.TotalMiningReward, error) {
 	res := types.TotalMiningReward{}
-	err := a.db.QueryRow(a.getQuery(addressTotalLatestMiningRewardQuery), afterTime.Unix(), address).
+	err := a.db.QueryRowContext(ctx, a.getQuery(addressTotalLatestMiningRewardQuery), afterTime.Unix(), address).
 		Scan(&res.Balance, &res.Stake, &res.Proposer, &res.FinalCommittee)
 	if err != nil {
 		return types.TotalMiningReward{}, err
@@ -133,9 +137,9 @@ func (a *postgresAccessor) AddressTotalLatestMiningReward(afterTime time.Time, a
 	return res, nil
 }
 
-func (a *postgresAccessor) AddressTotalLatestBurntCoins(afterTime time.Time, address string) (types.AddressBurntCoins, error) {
+func (a *postgresAccessor) AddressTotalLatestBurntCoins(ctx context.Context, afterTime time.Time, address string) (types.AddressBurntCoins, error) {
 	res := types.AddressBurntCoins{}
-	err := a.db.QueryRow(a.getQuery(addressTotalLatestBurntCoinsQuery), afterTime.Unix(), address).
+	err := a.db.QueryRowContext(ctx, a.getQuery(addressTotalLatestBurntCoinsQuery), afterTime.Unix(), address).
 		Scan(&res.Amount)
 	if err != nil {
 		return types.AddressBurntCoins{}, err
@@ -143,12 +147,12 @@ func (a *postgresAccessor) AddressTotalLatestBurntCoins(afterTime time.Time, add
 	return res, nil
 }
 
-func (a *postgresAccessor) AddressBadAuthorsCount(address string) (uint64, error) {
-	return a.count(addressBadAuthorsCountQuery, address)
+func (a *postgresAccessor) AddressBadAuthorsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, addressBadAuthorsCountQuery, address)
 }
 
-func (a *postgresAccessor) AddressBadAuthors(address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
-	res, nextContinuationToken, err := a.page(addressBadAuthorsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressBadAuthors(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressBadAuthorsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readBadAuthors(rows)
 	}, count, continuationToken, address)
 	if err != nil {
@@ -157,8 +161,8 @@ func (a *postgresAccessor) AddressBadAuthors(address string, count uint64, conti
 	return res.([]types.BadAuthor), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressBalanceUpdatesCount(address string) (uint64, error) {
-	return a.count(addressBalanceUpdatesCountQuery, address)
+func (a *postgresAccessor) AddressBalanceUpdatesCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, addressBalanceUpdatesCountQuery, address)
 }
 
 type balanceUpdateOptionalData struct {
@@ -172,8 +176,8 @@ type balanceUpdateOptionalData struct {
 	epoch              uint64
 }
 
-func (a *postgresAccessor) AddressBalanceUpdates(address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error) {
-	res, nextContinuationToken, err := a.page2(addressBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, int64, error) {
+func (a *postgresAccessor) AddressBalanceUpdates(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.BalanceUpdate, *string, error) {
+	res, nextContinuationToken, err := a.page2(ctx, addressBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, int64, error) {
 		defer rows.Close()
 		var res []types.BalanceUpdate
 		var id int64
@@ -249,8 +253,8 @@ func readBalanceUpdateSpecificData(reason string, optionalData *balanceUpdateOpt
 	return res
 }
 
-func (a *postgresAccessor) AddressContractTxBalanceUpdates(address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
-	res, nextContinuationToken, err := a.page(addressContractTxBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressContractTxBalanceUpdates(ctx context.Context, address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressContractTxBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.ContractTxBalanceUpdate
 		var id uint64
@@ -309,9 +313,9 @@ func (a *postgresAccessor) AddressContractTxBalanceUpdates(address string, contr
 	return res.([]types.ContractTxBalanceUpdate), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressBalanceUpdatesSummary(address string) (*types.BalanceUpdatesSummary, error) {
+func (a *postgresAccessor) AddressBalanceUpdatesSummary(ctx context.Context, address string) (*types.BalanceUpdatesSummary, error) {
 	res := &types.BalanceUpdatesSummary{}
-	err := a.db.QueryRow(a.getQuery(addressBalanceUpdatesSummaryQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(addressBalanceUpdatesSummaryQuery), address).Scan(
 		&res.BalanceIn,
 		&res.BalanceOut,
 		&res.StakeIn,
@@ -328,8 +332,36 @@ func (a *postgresAccessor) AddressBalanceUpdatesSummary(address string) (*types.
 	return res, nil
 }
 
-func (a *postgresAccessor) AddressDelegateeTotalRewards(address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
-	res, nextContinuationToken, err := a.page(addressDelegateeTotalRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) BalancesAt(ctx context.Context, addresses []string, height uint64, timestamp *time.Time) ([]types.BalanceAt, error) {
+	lowerAddresses := make([]string, len(addresses))
+	for i, address := range addresses {
+		lowerAddresses[i] = strings.ToLower(address)
+	}
+	var unixTimestamp *int64
+	if timestamp != nil {
+		v := timestamp.Unix()
+		unixTimestamp = &v
+	}
+	rows, err := a.db.QueryContext(ctx, a.getQuery(balancesAtQuery), pq.Array(lowerAddresses), height, unixTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []types.BalanceAt
+	for rows.Next() {
+		item := types.BalanceAt{
+			Timestamp: timestamp,
+		}
+		if err := rows.Scan(&item.Address, &item.Balance, &item.Stake, &item.BlockHeight); err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+func (a *postgresAccessor) AddressDelegateeTotalRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressDelegateeTotalRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.DelegateeTotalRewards
 		var epoch uint64
@@ -370,8 +402,8 @@ func (a *postgresAccessor) AddressDelegateeTotalRewards(address string, count ui
 	return res.([]types.DelegateeTotalRewards), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressMiningRewardSummaries(address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(addressMiningRewardSummariesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressMiningRewardSummaries(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.MiningRewardSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressMiningRewardSummariesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.MiningRewardSummary
 		var epoch uint64
@@ -396,8 +428,8 @@ func (a *postgresAccessor) AddressMiningRewardSummaries(address string, count ui
 	return res.([]types.MiningRewardSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressTokens(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
-	res, nextContinuationToken, err := a.page(addressTokensQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressTokens(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressTokensQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.TokenBalance
 		var id uint64
@@ -426,9 +458,9 @@ func (a *postgresAccessor) AddressTokens(address string, count uint64, continuat
 	return res.([]types.TokenBalance), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressToken(address, tokenAddress string) (types.TokenBalance, error) {
+func (a *postgresAccessor) AddressToken(ctx context.Context, address, tokenAddress string) (types.TokenBalance, error) {
 	res := types.TokenBalance{}
-	err := a.db.QueryRow(a.getQuery(addressTokenQuery), address, tokenAddress).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(addressTokenQuery), address, tokenAddress).Scan(
 		&res.Balance,
 		&res.Token.Name,
 		&res.Token.Symbol,
@@ -446,8 +478,8 @@ func (a *postgresAccessor) AddressToken(address, tokenAddress string) (types.Tok
 	return res, nil
 }
 
-func (a *postgresAccessor) AddressDelegations(address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error) {
-	res, nextContinuationToken, err := a.page(addressDelegationsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) AddressDelegations(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Delegation, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, addressDelegationsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.Delegation
 		var id uint64
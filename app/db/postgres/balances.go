@@ -1,8 +1,9 @@
 package postgres
 
 import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/types"
-	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"strconv"
 	"strings"
@@ -19,18 +20,18 @@ const (
 	totalLatestBurntCoinsQuery         = "totalLatestBurntCoins.sql"
 )
 
-func (a *postgresAccessor) BalancesCount() (uint64, error) {
-	return a.count(balancesCountQuery)
+func (a *postgresAccessor) BalancesCount(ctx context.Context) (uint64, error) {
+	return a.count(ctx, balancesCountQuery)
 }
 
-func (a *postgresAccessor) Balances(sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error) {
+func (a *postgresAccessor) Balances(ctx context.Context, sortBy *string, count uint64, continuationToken *string) ([]types.Balance, *string, error) {
 	parseToken := func(continuationToken *string) (addressId *uint64, balance *decimal.Decimal, err error) {
 		if continuationToken == nil {
 			return
 		}
 		strs := strings.Split(*continuationToken, "-")
 		if len(strs) != 2 {
-			err = errors.New("invalid continuation token")
+			err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 			return
 		}
 		sAddressId := strs[0]
@@ -56,7 +57,7 @@ func (a *postgresAccessor) Balances(sortBy *string, count uint64, continuationTo
 	} else {
 		query = balancesQuery
 	}
-	rows, err := a.db.Query(a.getQuery(query), count+1, addressId, balance)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(query), count+1, addressId, balance)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -89,12 +90,12 @@ func (a *postgresAccessor) Balances(sortBy *string, count uint64, continuationTo
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) TotalLatestMiningRewardsCount(afterTime time.Time) (uint64, error) {
-	return a.count(totalLatestMiningRewardsCountQuery, afterTime.Unix())
+func (a *postgresAccessor) TotalLatestMiningRewardsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	return a.count(ctx, totalLatestMiningRewardsCountQuery, afterTime.Unix())
 }
 
-func (a *postgresAccessor) TotalLatestMiningRewards(afterTime time.Time, startIndex uint64, count uint64) ([]types.TotalMiningReward, error) {
-	rows, err := a.db.Query(a.getQuery(totalLatestMiningRewardsQuery), afterTime.Unix(), startIndex, count)
+func (a *postgresAccessor) TotalLatestMiningRewards(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.TotalMiningReward, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(totalLatestMiningRewardsQuery), afterTime.Unix(), startIndex, count)
 	if err != nil {
 		return nil, err
 	}
@@ -115,12 +116,12 @@ func (a *postgresAccessor) TotalLatestMiningRewards(afterTime time.Time, startIn
 	return res, nil
 }
 
-func (a *postgresAccessor) TotalLatestBurntCoinsCount(afterTime time.Time) (uint64, error) {
-	return a.count(totalLatestBurntCoinsCountQuery, afterTime.Unix())
+func (a *postgresAccessor) TotalLatestBurntCoinsCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	return a.count(ctx, totalLatestBurntCoinsCountQuery, afterTime.Unix())
 }
 
-func (a *postgresAccessor) TotalLatestBurntCoins(afterTime time.Time, startIndex uint64, count uint64) ([]types.AddressBurntCoins, error) {
-	rows, err := a.db.Query(a.getQuery(totalLatestBurntCoinsQuery), afterTime.Unix(), startIndex, count)
+func (a *postgresAccessor) TotalLatestBurntCoins(ctx context.Context, afterTime time.Time, startIndex uint64, count uint64) ([]types.AddressBurntCoins, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(totalLatestBurntCoinsQuery), afterTime.Unix(), startIndex, count)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
@@ -19,25 +21,25 @@ const (
 	lastBlockQuery             = "lastBlock.sql"
 )
 
-func (a *postgresAccessor) BlockByHeight(height uint64) (types.BlockDetail, error) {
-	return a.block(blockQueryByHeight, height)
+func (a *postgresAccessor) BlockByHeight(ctx context.Context, height uint64) (types.BlockDetail, error) {
+	return a.block(ctx, blockQueryByHeight, height)
 }
 
-func (a *postgresAccessor) BlockByHash(hash string) (types.BlockDetail, error) {
-	return a.block(blockQueryByHash, hash)
+func (a *postgresAccessor) BlockByHash(ctx context.Context, hash string) (types.BlockDetail, error) {
+	return a.block(ctx, blockQueryByHash, hash)
 }
 
-func (a *postgresAccessor) LastBlock() (types.BlockDetail, error) {
-	return a.block(lastBlockQuery)
+func (a *postgresAccessor) LastBlock(ctx context.Context) (types.BlockDetail, error) {
+	return a.block(ctx, lastBlockQuery)
 }
 
-func (a *postgresAccessor) block(query string, args ...interface{}) (types.BlockDetail, error) {
+func (a *postgresAccessor) block(ctx context.Context, query string, args ...interface{}) (types.BlockDetail, error) {
 	res := types.BlockDetail{}
 	var timestamp int64
 	var upgrade sql.NullInt64
 	var offlineAddress sql.NullString
 	var blockFeeRate decimal.Decimal
-	err := a.db.QueryRow(a.getQuery(query), args...).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(query), args...).Scan(
 		&res.Epoch,
 		&res.Height,
 		&res.Hash,
@@ -73,16 +75,16 @@ func (a *postgresAccessor) block(query string, args ...interface{}) (types.Block
 	return res, nil
 }
 
-func (a *postgresAccessor) BlockTxsCountByHeight(height uint64) (uint64, error) {
-	return a.count(blockTxsCountByHeightQuery, height)
+func (a *postgresAccessor) BlockTxsCountByHeight(ctx context.Context, height uint64) (uint64, error) {
+	return a.count(ctx, blockTxsCountByHeightQuery, height)
 }
 
-func (a *postgresAccessor) BlockTxsCountByHash(hash string) (uint64, error) {
-	return a.count(blockTxsCountByHashQuery, hash)
+func (a *postgresAccessor) BlockTxsCountByHash(ctx context.Context, hash string) (uint64, error) {
+	return a.count(ctx, blockTxsCountByHashQuery, hash)
 }
 
-func (a *postgresAccessor) BlockTxsByHeight(height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(blockTxsByHeightQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) BlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, blockTxsByHeightQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readTxs(rows)
 	}, count, continuationToken, height)
 	if err != nil {
@@ -91,8 +93,12 @@ func (a *postgresAccessor) BlockTxsByHeight(height uint64, count uint64, continu
 	return res.([]types.TransactionSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) BlockTxsByHash(hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(blockTxsByHashQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) StreamBlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return a.streamTxs(ctx, blockTxsByHeightQuery, w, count, continuationToken, height)
+}
+
+func (a *postgresAccessor) BlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, blockTxsByHashQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readTxs(rows)
 	}, count, continuationToken, hash)
 	if err != nil {
@@ -101,10 +107,14 @@ func (a *postgresAccessor) BlockTxsByHash(hash string, count uint64, continuatio
 	return res.([]types.TransactionSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) BlockCoinsByHeight(height uint64) (types.AllCoins, error) {
-	return a.coins(blockCoinsByHeightQuery, height)
+func (a *postgresAccessor) StreamBlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return a.streamTxs(ctx, blockTxsByHashQuery, w, count, continuationToken, hash)
+}
+
+func (a *postgresAccessor) BlockCoinsByHeight(ctx context.Context, height uint64) (types.AllCoins, error) {
+	return a.coins(ctx, blockCoinsByHeightQuery, height)
 }
 
-func (a *postgresAccessor) BlockCoinsByHash(hash string) (types.AllCoins, error) {
-	return a.coins(blockCoinsByHashQuery, hash)
+func (a *postgresAccessor) BlockCoinsByHash(ctx context.Context, hash string) (types.AllCoins, error) {
+	return a.coins(ctx, blockCoinsByHashQuery, hash)
 }
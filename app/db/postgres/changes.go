@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const changesBufferSize = 1000
+
+type changeListener struct {
+	listener *pq.Listener
+	changes  chan db.Change
+	logger   log.Logger
+}
+
+// NewChangeListener receives changes sent by the indexer db triggers with pg_notify to the channel
+func NewChangeListener(connStr, channel string, lc *lifecycle.Manager, logger log.Logger) (db.ChangeListener, error) {
+	if _, err := pq.NewConnector(connStr); err != nil {
+		return nil, errors.Wrap(err, "invalid db connection string")
+	}
+	l := &changeListener{
+		changes: make(chan db.Change, changesBufferSize),
+		logger:  logger,
+	}
+	l.listener = pq.NewListener(connStr, time.Second*10, time.Minute, l.onEvent)
+	if err := l.listener.Listen(channel); err != nil {
+		l.listener.Close()
+		return nil, errors.Wrapf(err, "unable to listen to channel %v", channel)
+	}
+	lc.Go(l.loop)
+	lc.OnStop("db notifications listener", l.listener.Close)
+	return l, nil
+}
+
+func (l *changeListener) Changes() <-chan db.Change {
+	return l.changes
+}
+
+func (l *changeListener) onEvent(event pq.ListenerEventType, err error) {
+	if err != nil {
+		l.logger.Warn(errors.Wrap(err, "Db notifications listener error").Error())
+	}
+	if event == pq.ListenerEventReconnected {
+		l.logger.Info("Db notifications listener reconnected")
+	}
+}
+
+func (l *changeListener) loop(ctx context.Context) {
+	for {
+		var notification *pq.Notification
+		select {
+		case <-ctx.Done():
+			return
+		case notification = <-l.listener.Notify:
+		}
+		change := db.Change{}
+		if notification == nil {
+			// The connection was re-established
+			change.Kind = db.ResyncChange
+		} else if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil {
+			l.logger.Warn(fmt.Sprintf("Unable to parse db notification %q: %v", notification.Extra, err))
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case l.changes <- change:
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_NewChangeListener_invalidConnStr(t *testing.T) {
+	lc := lifecycle.NewManager(log.New())
+	defer lc.Stop(context.Background())
+	listener, err := NewChangeListener("postgres://%zz", "idena_api_changes", lc, log.New())
+	require.Error(t, err)
+	require.Nil(t, listener)
+}
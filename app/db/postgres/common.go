@@ -1,10 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-go/common"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/types"
-	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"math/big"
 	"reflect"
@@ -46,9 +48,9 @@ func (n *NullDecimal) Scan(value interface{}) error {
 	return nil
 }
 
-func (a *postgresAccessor) count(queryName string, args ...interface{}) (uint64, error) {
+func (a *postgresAccessor) count(ctx context.Context, queryName string, args ...interface{}) (uint64, error) {
 	var res uint64
-	err := a.db.QueryRow(a.getQuery(queryName), args...).Scan(&res)
+	err := a.db.QueryRowContext(ctx, a.getQuery(queryName), args...).Scan(&res)
 	if err != nil {
 		return 0, err
 	}
@@ -97,54 +99,70 @@ func readTxs(rows *sql.Rows) ([]types.TransactionSummary, uint64, error) {
 	var res []types.TransactionSummary
 	var id uint64
 	for rows.Next() {
-		item := types.TransactionSummary{}
-		var timestamp int64
-		var gasCost, transfer NullDecimal
-		var success, becomeOnline sql.NullBool
-		var gasUsed sql.NullInt64
-		var method, errorMsg sql.NullString
-		if err := rows.Scan(
-			&id,
-			&item.Hash,
-			&item.Type,
-			&timestamp,
-			&item.From,
-			&item.To,
-			&item.Amount,
-			&item.Tips,
-			&item.MaxFee,
-			&item.Fee,
-			&item.Size,
-			&item.Nonce,
-			&transfer,
-			&becomeOnline,
-			&success,
-			&gasUsed,
-			&gasCost,
-			&method,
-			&errorMsg,
-		); err != nil {
+		item, itemId, err := scanTx(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		item.Timestamp = timestampToTimeUTCp(timestamp)
-		if transfer.Valid {
-			item.Transfer = &transfer.Decimal
-		}
-		item.Data = readTxSpecificData(item.Type, transfer, becomeOnline)
-		if success.Valid {
-			item.TxReceipt = &types.TxReceipt{
-				Success:  success.Bool,
-				GasUsed:  uint64(gasUsed.Int64),
-				GasCost:  gasCost.Decimal,
-				Method:   method.String,
-				ErrorMsg: errorMsg.String,
-			}
-		}
+		id = itemId
 		res = append(res, item)
 	}
 	return res, id, nil
 }
 
+func scanTx(rows *sql.Rows) (types.TransactionSummary, uint64, error) {
+	item := types.TransactionSummary{}
+	var id uint64
+	var timestamp int64
+	var gasCost, transfer NullDecimal
+	var success, becomeOnline sql.NullBool
+	var gasUsed sql.NullInt64
+	var method, errorMsg sql.NullString
+	if err := rows.Scan(
+		&id,
+		&item.Hash,
+		&item.Type,
+		&timestamp,
+		&item.From,
+		&item.To,
+		&item.Amount,
+		&item.Tips,
+		&item.MaxFee,
+		&item.Fee,
+		&item.Size,
+		&item.Nonce,
+		&transfer,
+		&becomeOnline,
+		&success,
+		&gasUsed,
+		&gasCost,
+		&method,
+		&errorMsg,
+	); err != nil {
+		return types.TransactionSummary{}, 0, err
+	}
+	item.Timestamp = timestampToTimeUTCp(timestamp)
+	if transfer.Valid {
+		item.Transfer = &transfer.Decimal
+	}
+	item.Data = readTxSpecificData(item.Type, transfer, becomeOnline)
+	if success.Valid {
+		item.TxReceipt = &types.TxReceipt{
+			Success:  success.Bool,
+			GasUsed:  uint64(gasUsed.Int64),
+			GasCost:  gasCost.Decimal,
+			Method:   method.String,
+			ErrorMsg: errorMsg.String,
+		}
+	}
+	return item, id, nil
+}
+
+func (a *postgresAccessor) streamTxs(ctx context.Context, queryName string, w db.RowWriter, count uint64, continuationToken *string, args ...interface{}) (*string, error) {
+	return a.streamPage(ctx, queryName, func(rows *sql.Rows) (interface{}, uint64, error) {
+		return scanTx(rows)
+	}, w, count, continuationToken, args...)
+}
+
 func readTxSpecificData(txType string, transfer NullDecimal, becomeOnline sql.NullBool) interface{} {
 	var res interface{}
 	switch txType {
@@ -196,8 +214,8 @@ func readOnlineStatusTxSpecificData(becomeOnline sql.NullBool) *types.OnlineStat
 	}
 }
 
-func (a *postgresAccessor) strValueCounts(queryName string, args ...interface{}) ([]types.StrValueCount, error) {
-	rows, err := a.db.Query(a.getQuery(queryName), args...)
+func (a *postgresAccessor) strValueCounts(ctx context.Context, queryName string, args ...interface{}) ([]types.StrValueCount, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -217,13 +235,13 @@ func readStrValueCounts(rows *sql.Rows) ([]types.StrValueCount, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) flips(
+func (a *postgresAccessor) flips(ctx context.Context,
 	queryName string,
 	count uint64,
 	continuationToken *string,
 	args ...interface{},
 ) ([]types.FlipSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(queryName, func(rows *sql.Rows) (interface{}, uint64, error) {
+	res, nextContinuationToken, err := a.page(ctx, queryName, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readFlips(rows)
 	}, count, continuationToken, args...)
 	if err != nil {
@@ -276,8 +294,8 @@ func readFlips(rows *sql.Rows) ([]types.FlipSummary, uint64, error) {
 	return res, id, nil
 }
 
-func (a *postgresAccessor) flipsWithoutPaging(queryName string, args ...interface{}) ([]types.FlipSummary, error) {
-	rows, err := a.db.Query(a.getQuery(queryName), args...)
+func (a *postgresAccessor) flipsWithoutPaging(ctx context.Context, queryName string, args ...interface{}) ([]types.FlipSummary, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -393,9 +411,9 @@ func readAnswers(rows *sql.Rows) ([]types.Answer, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) coins(queryName string, args ...interface{}) (types.AllCoins, error) {
+func (a *postgresAccessor) coins(ctx context.Context, queryName string, args ...interface{}) (types.AllCoins, error) {
 	res := types.AllCoins{}
-	err := a.db.QueryRow(a.getQuery(queryName), args...).
+	err := a.db.QueryRowContext(ctx, a.getQuery(queryName), args...).
 		Scan(&res.Burnt,
 			&res.Minted,
 			&res.TotalBalance,
@@ -409,8 +427,8 @@ func (a *postgresAccessor) coins(queryName string, args ...interface{}) (types.A
 	return res, nil
 }
 
-func (a *postgresAccessor) rewards(queryName string, args ...interface{}) ([]types.Reward, error) {
-	rows, err := a.db.Query(a.getQuery(queryName), args...)
+func (a *postgresAccessor) rewards(ctx context.Context, queryName string, args ...interface{}) ([]types.Reward, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -452,9 +470,9 @@ func readBadAuthors(rows *sql.Rows) ([]types.BadAuthor, uint64, error) {
 	return res, id, nil
 }
 
-func (a *postgresAccessor) adjacentStrValues(queryName string, value string) (types.AdjacentStrValues, error) {
+func (a *postgresAccessor) adjacentStrValues(ctx context.Context, queryName string, value string) (types.AdjacentStrValues, error) {
 	res := types.AdjacentStrValues{}
-	err := a.db.QueryRow(a.getQuery(queryName), value).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(queryName), value).Scan(
 		&res.Prev.Value,
 		&res.Prev.Cycled,
 		&res.Next.Value,
@@ -476,7 +494,7 @@ func parseUintContinuationToken(continuationToken *string) (*uint64, error) {
 	var result *uint64
 	var err error
 	if num, parsingErr := strconv.ParseUint(*continuationToken, 10, 64); parsingErr != nil {
-		err = errors.New("invalid continuation token")
+		err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 	} else {
 		result = &num
 	}
@@ -494,7 +512,7 @@ func getResWithContinuationToken(lastToken string, count uint64, slice interface
 	return resSlice.Interface(), nextContinuationToken
 }
 
-func (a *postgresAccessor) page(
+func (a *postgresAccessor) page(ctx context.Context,
 	queryName string,
 	readRows func(rows *sql.Rows) (interface{}, uint64, error),
 	count uint64,
@@ -506,7 +524,7 @@ func (a *postgresAccessor) page(
 	if continuationId, err = parseUintContinuationToken(continuationToken); err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(queryName), append(args, count+1, continuationId)...)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), append(args, count+1, continuationId)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -519,7 +537,44 @@ func (a *postgresAccessor) page(
 	return resSlice, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) page2(
+// streamPage writes page items as soon as they are scanned, the extra row requested to detect the next page
+// is not written and its id becomes the continuation token
+func (a *postgresAccessor) streamPage(ctx context.Context,
+	queryName string,
+	scanRow func(rows *sql.Rows) (interface{}, uint64, error),
+	w db.RowWriter,
+	count uint64,
+	continuationToken *string,
+	args ...interface{},
+) (*string, error) {
+	continuationId, err := parseUintContinuationToken(continuationToken)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), append(args, count+1, continuationId)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var written uint64
+	for rows.Next() {
+		item, id, err := scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		if written == count {
+			nextContinuationToken := strconv.FormatUint(id, 10)
+			return &nextContinuationToken, nil
+		}
+		if err := w(item); err != nil {
+			return nil, err
+		}
+		written++
+	}
+	return nil, rows.Err()
+}
+
+func (a *postgresAccessor) page2(ctx context.Context,
 	queryName string,
 	readRows func(rows *sql.Rows) (interface{}, int64, error),
 	count uint64,
@@ -531,7 +586,7 @@ func (a *postgresAccessor) page2(
 	if continuationId, err = parseIntContinuationToken(continuationToken); err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(queryName), append(args, count+1, continuationId)...)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(queryName), append(args, count+1, continuationId)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -551,7 +606,7 @@ func parseIntContinuationToken(continuationToken *string) (*int64, error) {
 	var result *int64
 	var err error
 	if num, parsingErr := strconv.ParseInt(*continuationToken, 10, 64); parsingErr != nil {
-		err = errors.New("invalid continuation token")
+		err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 	} else {
 		result = &num
 	}
@@ -564,7 +619,7 @@ func parseUintAndAmountToken(continuationToken *string) (id *uint64, amount *dec
 	}
 	strs := strings.Split(*continuationToken, "-")
 	if len(strs) != 2 {
-		err = errors.New("invalid continuation token")
+		err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 		return
 	}
 	sId := strs[0]
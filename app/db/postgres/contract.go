@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
@@ -16,7 +17,7 @@ const (
 	contractTxBalanceUpdatesQuery     = "contractTxBalanceUpdates.sql"
 )
 
-func (a *postgresAccessor) Contract(address string) (types.Contract, error) {
+func (a *postgresAccessor) Contract(ctx context.Context, address string) (types.Contract, error) {
 	res := types.Contract{}
 	var terminationTxTime sql.NullInt64
 	var terminationTxHash sql.NullString
@@ -25,7 +26,7 @@ func (a *postgresAccessor) Contract(address string) (types.Contract, error) {
 	var verificationStateTimestamp int64
 	var isToken bool
 	var token types.Token
-	err := a.db.QueryRow(a.getQuery(contractQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(contractQuery), address).Scan(
 		&res.Type,
 		&res.Author,
 		&res.DeployTx.Hash,
@@ -73,8 +74,8 @@ func (a *postgresAccessor) Contract(address string) (types.Contract, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) ContractTxBalanceUpdates(contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
-	res, nextContinuationToken, err := a.page(contractTxBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, contractTxBalanceUpdatesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.ContractTxBalanceUpdate
 		var id uint64
@@ -133,9 +134,9 @@ func (a *postgresAccessor) ContractTxBalanceUpdates(contractAddress string, coun
 	return res.([]types.ContractTxBalanceUpdate), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) ContractVerifiedCodeFile(address string) ([]byte, error) {
+func (a *postgresAccessor) ContractVerifiedCodeFile(ctx context.Context, address string) ([]byte, error) {
 	var res []byte
-	err := a.db.QueryRow(a.getQuery(contractVerifiedCodeFileQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(contractVerifiedCodeFileQuery), address).Scan(
 		&res,
 	)
 	if err == sql.ErrNoRows {
@@ -147,10 +148,10 @@ func (a *postgresAccessor) ContractVerifiedCodeFile(address string) ([]byte, err
 	return res, nil
 }
 
-func (a *postgresAccessor) TimeLockContract(address string) (types.TimeLockContract, error) {
+func (a *postgresAccessor) TimeLockContract(ctx context.Context, address string) (types.TimeLockContract, error) {
 	res := types.TimeLockContract{}
 	var timestamp int64
-	err := a.db.QueryRow(a.getQuery(timeLockContractQuery), address).Scan(&timestamp)
+	err := a.db.QueryRowContext(ctx, a.getQuery(timeLockContractQuery), address).Scan(&timestamp)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -161,9 +162,9 @@ func (a *postgresAccessor) TimeLockContract(address string) (types.TimeLockContr
 	return res, nil
 }
 
-func (a *postgresAccessor) OracleLockContract(address string) (types.OracleLockContract, error) {
+func (a *postgresAccessor) OracleLockContract(ctx context.Context, address string) (types.OracleLockContract, error) {
 	res := types.OracleLockContract{}
-	err := a.db.QueryRow(a.getQuery(oracleLockContractQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(oracleLockContractQuery), address).Scan(
 		&res.OracleVotingAddress,
 		&res.Value,
 		&res.SuccessAddress,
@@ -178,13 +179,13 @@ func (a *postgresAccessor) OracleLockContract(address string) (types.OracleLockC
 	return res, nil
 }
 
-func (a *postgresAccessor) RefundableOracleLockContract(address string) (types.RefundableOracleLockContract, error) {
+func (a *postgresAccessor) RefundableOracleLockContract(ctx context.Context, address string) (types.RefundableOracleLockContract, error) {
 	res := types.RefundableOracleLockContract{}
 	var depositDeadline, headBlockTimestamp int64
 	var headBlockHeight uint64
 	var terminationTime sql.NullInt64
 	var oracleVotingFeeOld, oracleVotingFeeNew sql.NullInt64
-	err := a.db.QueryRow(a.getQuery(refundableOracleLockContractQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(refundableOracleLockContractQuery), address).Scan(
 		&res.OracleVotingAddress,
 		&res.Value,
 		&res.SuccessAddress,
@@ -218,9 +219,9 @@ func (a *postgresAccessor) RefundableOracleLockContract(address string) (types.R
 	return res, nil
 }
 
-func (a *postgresAccessor) MultisigContract(address string) (types.MultisigContract, error) {
+func (a *postgresAccessor) MultisigContract(ctx context.Context, address string) (types.MultisigContract, error) {
 	res := types.MultisigContract{}
-	err := a.db.QueryRow(a.getQuery(multisigContractQuery), address).Scan(&res.MinVotes, &res.MaxVotes)
+	err := a.db.QueryRowContext(ctx, a.getQuery(multisigContractQuery), address).Scan(&res.MinVotes, &res.MaxVotes)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -230,13 +231,13 @@ func (a *postgresAccessor) MultisigContract(address string) (types.MultisigContr
 	return res, nil
 }
 
-func (a *postgresAccessor) OracleVotingContract(address, oracle string) (types.OracleVotingContract, error) {
-	rows, err := a.db.Query(a.getQuery(oracleVotingContractQuery), address, oracle)
+func (a *postgresAccessor) OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(oracleVotingContractQuery), address, oracle)
 	if err != nil {
 		return types.OracleVotingContract{}, err
 	}
 	defer rows.Close()
-	contracts, _, err := a.readOracleVotingContracts(rows)
+	contracts, _, err := a.readOracleVotingContracts(ctx, rows)
 	if err != nil {
 		return types.OracleVotingContract{}, err
 	}
@@ -1,8 +1,10 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	math2 "github.com/idena-network/idena-go/common/math"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
@@ -62,13 +64,13 @@ func createContractsFilter(authorAddress string, states []string, all bool, sort
 		case oracleVotingStateTerminated:
 			res.stateTerminated = true
 		default:
-			return nil, errors.Errorf("unknown state %v", state)
+			return nil, apierrors.Errorf(apierrors.InvalidArgument, "unknown state %v", state)
 		}
 	}
 	res.sortByReward = (res.stateOpen || res.statePending) && !(res.stateVoted || res.stateCounting || res.stateCanBeProlonged || res.stateArchive || res.stateTerminated)
 	if sortBy != nil {
 		if !res.sortByReward && *sortBy == "reward" {
-			return nil, errors.New("invalid combination of values 'states[]' and 'sortBy'")
+			return nil, apierrors.New(apierrors.InvalidArgument, "invalid combination of values 'states[]' and 'sortBy'")
 		}
 		if res.sortByReward && *sortBy == "timestamp" {
 			res.sortByReward = false
@@ -86,7 +88,7 @@ func createContractsFilter(authorAddress string, states []string, all bool, sort
 	return res, nil
 }
 
-func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+func (a *postgresAccessor) OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
 	filter, err := createContractsFilter(authorAddress, states, all, sortBy, continuationToken)
 	if err != nil {
 		return nil, nil, err
@@ -100,7 +102,7 @@ func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress st
 			} else {
 				queryName = ovcAllSortedByDtQuery
 			}
-			rows, err = a.db.Query(a.getQuery(queryName), filter.authorAddress, oracleAddress,
+			rows, err = a.db.QueryContext(ctx, a.getQuery(queryName), filter.authorAddress, oracleAddress,
 				filter.statePending, filter.stateCounting, filter.stateArchive, filter.stateTerminated,
 				filter.stateCanBeProlonged, count+1, continuationToken)
 		} else {
@@ -110,7 +112,7 @@ func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress st
 			} else {
 				queryName = ovcAllOpenSortedByDtQuery
 			}
-			rows, err = a.db.Query(a.getQuery(queryName), filter.authorAddress, oracleAddress,
+			rows, err = a.db.QueryContext(ctx, a.getQuery(queryName), filter.authorAddress, oracleAddress,
 				filter.statePending, filter.stateOpen, filter.stateVoted, filter.stateCounting, filter.stateArchive,
 				filter.stateTerminated, filter.stateCanBeProlonged, count+1, continuationToken)
 		}
@@ -121,7 +123,7 @@ func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress st
 		} else {
 			queryName = ovcByOracleSortedByDtQuery
 		}
-		rows, err = a.db.Query(a.getQuery(queryName), filter.authorAddress, oracleAddress,
+		rows, err = a.db.QueryContext(ctx, a.getQuery(queryName), filter.authorAddress, oracleAddress,
 			filter.statePending, filter.stateOpen, filter.stateVoted, filter.stateCounting, filter.stateArchive,
 			filter.stateTerminated, filter.stateCanBeProlonged, count+1, continuationToken)
 	}
@@ -131,7 +133,7 @@ func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress st
 	}
 	defer rows.Close()
 
-	res, lastContinuationToken, err := a.readOracleVotingContracts(rows)
+	res, lastContinuationToken, err := a.readOracleVotingContracts(ctx, rows)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -144,13 +146,13 @@ func (a *postgresAccessor) OracleVotingContracts(authorAddress, oracleAddress st
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) AddressOracleVotingContracts(address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
-	rows, err := a.db.Query(a.getQuery(addressOracleVotingContractsQuery), address, count+1, continuationToken)
+func (a *postgresAccessor) AddressOracleVotingContracts(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(addressOracleVotingContractsQuery), address, count+1, continuationToken)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer rows.Close()
-	res, lastContinuationToken, err := a.readOracleVotingContracts(rows)
+	res, lastContinuationToken, err := a.readOracleVotingContracts(ctx, rows)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -162,7 +164,7 @@ func (a *postgresAccessor) AddressOracleVotingContracts(address string, count ui
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) readOracleVotingContracts(rows *sql.Rows) ([]types.OracleVotingContract, *string, error) {
+func (a *postgresAccessor) readOracleVotingContracts(ctx context.Context, rows *sql.Rows) ([]types.OracleVotingContract, *string, error) {
 	var res []types.OracleVotingContract
 	var lastContinuationToken string
 	var curItem *types.OracleVotingContract
@@ -446,12 +448,12 @@ func calculateEstimatedTotalReward(
 	return &totalReward
 }
 
-func (a *postgresAccessor) EstimatedOracleRewards() ([]types.EstimatedOracleReward, error) {
+func (a *postgresAccessor) EstimatedOracleRewards(ctx context.Context) ([]types.EstimatedOracleReward, error) {
 	return a.estimatedOracleRewardsCache.get()
 }
 
-func (a *postgresAccessor) lastBlockFeeRate() (decimal.Decimal, error) {
-	rows, err := a.db.Query(a.getQuery(lastBlockFeeRateQuery))
+func (a *postgresAccessor) lastBlockFeeRate(ctx context.Context) (decimal.Decimal, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(lastBlockFeeRateQuery))
 	var res decimal.Decimal
 	if err != nil {
 		return res, err
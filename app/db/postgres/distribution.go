@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+const (
+	epochBalancesQuery   = "epochBalances.sql"
+	currentBalancesQuery = "currentBalances.sql"
+
+	distributionTopHoldersCount = 100
+)
+
+var (
+	distributionTops = []uint64{10, 100, 1000}
+	// distributionBucketBounds are lower bounds of balance buckets, the last bucket has no upper bound
+	distributionBucketBounds = []decimal.Decimal{
+		decimal.Zero,
+		decimal.New(1, 0),
+		decimal.New(1, 1),
+		decimal.New(1, 2),
+		decimal.New(1, 3),
+		decimal.New(1, 4),
+		decimal.New(1, 5),
+		decimal.New(1, 6),
+	}
+)
+
+// EpochDistribution reads balances of closed epochs from snapshots stored by resources/scripts/snapshots/epochBalances.sql,
+// the distribution of the current epoch is calculated by current balances and marked as provisional
+func (a *postgresAccessor) EpochDistribution(ctx context.Context, epoch uint64, addressesToExclude []string) (*types.EpochDistribution, error) {
+	lastEpoch, err := a.LastEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if epoch > lastEpoch.Epoch {
+		return nil, NoDataFound
+	}
+	provisional := epoch == lastEpoch.Epoch
+	var rows *sql.Rows
+	if provisional {
+		rows, err = a.db.QueryContext(ctx, a.getQuery(currentBalancesQuery), pq.Array(addressesToExclude))
+	} else {
+		rows, err = a.db.QueryContext(ctx, a.getQuery(epochBalancesQuery), epoch, pq.Array(addressesToExclude))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var balances []types.Balance
+	var blockHeight uint64
+	for rows.Next() {
+		item := types.Balance{}
+		if err := rows.Scan(&item.Address, &item.Balance, &item.Stake, &blockHeight); err != nil {
+			return nil, err
+		}
+		balances = append(balances, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		return nil, NoDataFound
+	}
+	res := NewEpochDistribution(epoch, blockHeight, balances)
+	res.Provisional = provisional
+	return res, nil
+}
+
+// NewEpochDistribution calculates the distribution of balances and stakes of all holders at the end of the epoch
+func NewEpochDistribution(epoch, blockHeight uint64, balances []types.Balance) *types.EpochDistribution {
+	res := &types.EpochDistribution{
+		Epoch:        epoch,
+		BlockHeight:  blockHeight,
+		HoldersCount: uint64(len(balances)),
+	}
+	for _, bounds := range distributionBucketBounds {
+		res.BalanceBuckets = append(res.BalanceBuckets, types.DistributionBucket{
+			MinBalance: bounds,
+		})
+	}
+	for i := 0; i < len(res.BalanceBuckets)-1; i++ {
+		maxBalance := res.BalanceBuckets[i+1].MinBalance
+		res.BalanceBuckets[i].MaxBalance = &maxBalance
+	}
+
+	sortedBalances := make([]decimal.Decimal, len(balances))
+	sortedStakes := make([]decimal.Decimal, len(balances))
+	for i, item := range balances {
+		res.TotalBalance = res.TotalBalance.Add(item.Balance)
+		res.TotalStake = res.TotalStake.Add(item.Stake)
+		sortedBalances[i] = item.Balance
+		sortedStakes[i] = item.Stake
+		bucket := &res.BalanceBuckets[len(res.BalanceBuckets)-1]
+		for j := range res.BalanceBuckets {
+			if res.BalanceBuckets[j].MaxBalance != nil && item.Balance.LessThan(*res.BalanceBuckets[j].MaxBalance) {
+				bucket = &res.BalanceBuckets[j]
+				break
+			}
+		}
+		bucket.HoldersCount++
+		bucket.TotalBalance = bucket.TotalBalance.Add(item.Balance)
+	}
+	sortDescending(sortedBalances)
+	sortDescending(sortedStakes)
+
+	res.BalanceGini = gini(sortedBalances, res.TotalBalance)
+	res.StakeGini = gini(sortedStakes, res.TotalStake)
+	res.StakeNakamotoCoefficient = nakamotoCoefficient(sortedStakes, res.TotalStake)
+	for _, top := range distributionTops {
+		res.TopConcentrations = append(res.TopConcentrations, types.DistributionConcentration{
+			Top:          top,
+			BalanceShare: topShare(sortedBalances, res.TotalBalance, top),
+			StakeShare:   topShare(sortedStakes, res.TotalStake, top),
+		})
+	}
+
+	topHolders := make([]types.Balance, len(balances))
+	copy(topHolders, balances)
+	sort.SliceStable(topHolders, func(i, j int) bool {
+		if !topHolders[i].Balance.Equal(topHolders[j].Balance) {
+			return topHolders[i].Balance.GreaterThan(topHolders[j].Balance)
+		}
+		return topHolders[i].Address < topHolders[j].Address
+	})
+	if len(topHolders) > distributionTopHoldersCount {
+		topHolders = topHolders[:distributionTopHoldersCount]
+	}
+	res.TopHolders = topHolders
+	return res
+}
+
+func sortDescending(values []decimal.Decimal) {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].GreaterThan(values[j])
+	})
+}
+
+// gini calculates the Gini coefficient of values sorted in descending order
+func gini(values []decimal.Decimal, total decimal.Decimal) float64 {
+	if len(values) == 0 || !total.IsPositive() {
+		return 0
+	}
+	n := len(values)
+	var weightedSum decimal.Decimal
+	for i, value := range values {
+		// Rank in ascending order starting from 1
+		weightedSum = weightedSum.Add(value.Mul(decimal.NewFromInt(int64(n - i))))
+	}
+	res, _ := weightedSum.Mul(decimal.NewFromInt(2)).
+		Div(total.Mul(decimal.NewFromInt(int64(n)))).
+		Sub(decimal.NewFromInt(int64(n + 1)).Div(decimal.NewFromInt(int64(n)))).
+		Float64()
+	return res
+}
+
+// nakamotoCoefficient calculates the number of the largest values sorted in descending order whose sum exceeds a half of the total
+func nakamotoCoefficient(values []decimal.Decimal, total decimal.Decimal) uint64 {
+	if !total.IsPositive() {
+		return 0
+	}
+	half := total.Div(decimal.NewFromInt(2))
+	var sum decimal.Decimal
+	for i, value := range values {
+		sum = sum.Add(value)
+		if sum.GreaterThan(half) {
+			return uint64(i + 1)
+		}
+	}
+	return uint64(len(values))
+}
+
+func topShare(values []decimal.Decimal, total decimal.Decimal, top uint64) float64 {
+	if !total.IsPositive() {
+		return 0
+	}
+	if uint64(len(values)) < top {
+		top = uint64(len(values))
+	}
+	var sum decimal.Decimal
+	for _, value := range values[:top] {
+		sum = sum.Add(value)
+	}
+	res, _ := sum.Div(total).Float64()
+	return res
+}
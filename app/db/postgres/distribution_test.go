@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_NewEpochDistribution(t *testing.T) {
+	balances := []types.Balance{
+		{Address: "0x1", Balance: decimal.New(5, -1), Stake: decimal.New(10, 0)},
+		{Address: "0x2", Balance: decimal.New(50, 0), Stake: decimal.New(10, 0)},
+		{Address: "0x3", Balance: decimal.New(1500, 0), Stake: decimal.New(30, 0)},
+		{Address: "0x4", Balance: decimal.New(2, 6), Stake: decimal.Zero},
+	}
+	res := NewEpochDistribution(5, 1000, balances)
+
+	require.Equal(t, uint64(5), res.Epoch)
+	require.Equal(t, uint64(1000), res.BlockHeight)
+	require.Equal(t, uint64(4), res.HoldersCount)
+	require.Equal(t, "2001550.5", res.TotalBalance.String())
+	require.Equal(t, "50", res.TotalStake.String())
+	require.Equal(t, uint64(1), res.StakeNakamotoCoefficient)
+	require.InDelta(t, 0.45, res.StakeGini, 1e-9)
+	require.InDelta(t, 0.75, res.BalanceGini, 0.01)
+
+	require.Len(t, res.BalanceBuckets, len(distributionBucketBounds))
+	require.Equal(t, uint64(1), res.BalanceBuckets[0].HoldersCount)
+	require.Equal(t, "1", res.BalanceBuckets[0].MaxBalance.String())
+	require.Equal(t, uint64(1), res.BalanceBuckets[2].HoldersCount)
+	require.Equal(t, uint64(1), res.BalanceBuckets[4].HoldersCount)
+	last := res.BalanceBuckets[len(res.BalanceBuckets)-1]
+	require.Nil(t, last.MaxBalance)
+	require.Equal(t, uint64(1), last.HoldersCount)
+
+	require.Equal(t, uint64(10), res.TopConcentrations[0].Top)
+	require.Equal(t, 1.0, res.TopConcentrations[0].BalanceShare)
+	require.Equal(t, 1.0, res.TopConcentrations[0].StakeShare)
+
+	require.Equal(t, "0x4", res.TopHolders[0].Address)
+	require.Equal(t, "0x1", res.TopHolders[3].Address)
+}
@@ -1,13 +1,14 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
 
-func (a *postgresAccessor) DynamicEndpoints() ([]types.DynamicEndpoint, error) {
-	rows, err := a.db.Query(fmt.Sprintf("SELECT name, endpoint_method, \"limit\" FROM %v", a.dynamicEndpointsTable))
+func (a *postgresAccessor) DynamicEndpoints(ctx context.Context) ([]types.DynamicEndpoint, error) {
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf("SELECT name, endpoint_method, \"limit\" FROM %v", a.dynamicEndpointsTable))
 	if err != nil {
 		return nil, err
 	}
@@ -36,13 +37,13 @@ func (a *postgresAccessor) DynamicEndpoints() ([]types.DynamicEndpoint, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) DynamicEndpointData(name string, limit *int) (*types.DynamicEndpointResult, error) {
+func (a *postgresAccessor) DynamicEndpointData(ctx context.Context, name string, limit *int) (*types.DynamicEndpointResult, error) {
 	var rows *sql.Rows
 	var err error
 	if limit != nil {
-		rows, err = a.db.Query(fmt.Sprintf("SELECT t.*, t2.last_refresh_time FROM %v AS t LEFT JOIN (SELECT last_refresh_time FROM %v WHERE name = '%v') t2 ON true LIMIT %d", name, a.dynamicEndpointStatesTable, name, *limit))
+		rows, err = a.db.QueryContext(ctx, fmt.Sprintf("SELECT t.*, t2.last_refresh_time FROM %v AS t LEFT JOIN (SELECT last_refresh_time FROM %v WHERE name = '%v') t2 ON true LIMIT %d", name, a.dynamicEndpointStatesTable, name, *limit))
 	} else {
-		rows, err = a.db.Query(fmt.Sprintf("SELECT t.*, t2.last_refresh_time FROM %v AS t LEFT JOIN (SELECT last_refresh_time FROM %v WHERE name = '%v') t2 ON true", name, a.dynamicEndpointStatesTable, name))
+		rows, err = a.db.QueryContext(ctx, fmt.Sprintf("SELECT t.*, t2.last_refresh_time FROM %v AS t LEFT JOIN (SELECT last_refresh_time FROM %v WHERE name = '%v') t2 ON true", name, a.dynamicEndpointStatesTable, name))
 	}
 	if err != nil {
 		return nil, err
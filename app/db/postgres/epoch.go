@@ -1,10 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/lib/pq"
-	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"strconv"
 )
@@ -62,25 +64,25 @@ func convertIdentityStates(names []string) ([]uint8, error) {
 		if state, ok := identityStatesByName[name]; ok {
 			res = append(res, state)
 		} else {
-			return nil, errors.Errorf("Unknown state %s", name)
+			return nil, apierrors.Errorf(apierrors.InvalidArgument, "Unknown state %s", name)
 		}
 	}
 	return res, nil
 }
 
-func (a *postgresAccessor) LastEpoch() (types.EpochDetail, error) {
-	return a.epoch(lastEpochQuery)
+func (a *postgresAccessor) LastEpoch(ctx context.Context) (types.EpochDetail, error) {
+	return a.epoch(ctx, lastEpochQuery)
 }
 
-func (a *postgresAccessor) Epoch(epoch uint64) (types.EpochDetail, error) {
-	return a.epoch(epochQuery, epoch)
+func (a *postgresAccessor) Epoch(ctx context.Context, epoch uint64) (types.EpochDetail, error) {
+	return a.epoch(ctx, epochQuery, epoch)
 }
 
-func (a *postgresAccessor) epoch(queryName string, args ...interface{}) (types.EpochDetail, error) {
+func (a *postgresAccessor) epoch(ctx context.Context, queryName string, args ...interface{}) (types.EpochDetail, error) {
 	res := types.EpochDetail{}
 	var validationTime int64
 	var discriminationStakeThreshold NullDecimal
-	err := a.db.QueryRow(a.getQuery(queryName), args...).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(queryName), args...).Scan(
 		&res.Epoch,
 		&validationTime,
 		&res.StateRoot,
@@ -102,12 +104,12 @@ func (a *postgresAccessor) epoch(queryName string, args ...interface{}) (types.E
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochBlocksCount(epoch uint64) (uint64, error) {
-	return a.count(epochBlocksCountQuery, epoch)
+func (a *postgresAccessor) EpochBlocksCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochBlocksCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochBlocks(epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(epochBlocksQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) EpochBlocks(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BlockSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochBlocksQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.BlockSummary
 		var height uint64
@@ -160,24 +162,24 @@ func (a *postgresAccessor) EpochBlocks(epoch uint64, count uint64, continuationT
 	return res.([]types.BlockSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochFlipsCount(epoch uint64) (uint64, error) {
-	return a.count(epochFlipsCountQuery, epoch)
+func (a *postgresAccessor) EpochFlipsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochFlipsCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochFlips(epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
-	return a.flips(epochFlipsQuery, count, continuationToken, epoch)
+func (a *postgresAccessor) EpochFlips(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	return a.flips(ctx, epochFlipsQuery, count, continuationToken, epoch)
 }
 
-func (a *postgresAccessor) EpochFlipAnswersSummary(epoch uint64) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochFlipQualifiedAnswersQuery, epoch)
+func (a *postgresAccessor) EpochFlipAnswersSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochFlipQualifiedAnswersQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochFlipStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochFlipStatesQuery, epoch)
+func (a *postgresAccessor) EpochFlipStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochFlipStatesQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochFlipWrongWordsSummary(epoch uint64) ([]types.NullableBoolValueCount, error) {
-	rows, err := a.db.Query(a.getQuery(epochFlipQualifiedWrongWordsQuery), epoch)
+func (a *postgresAccessor) EpochFlipWrongWordsSummary(ctx context.Context, epoch uint64) ([]types.NullableBoolValueCount, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochFlipQualifiedWrongWordsQuery), epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +201,7 @@ func (a *postgresAccessor) EpochFlipWrongWordsSummary(epoch uint64) ([]types.Nul
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentitiesCount(epoch uint64, prevStates []string, states []string) (uint64, error) {
+func (a *postgresAccessor) EpochIdentitiesCount(ctx context.Context, epoch uint64, prevStates []string, states []string) (uint64, error) {
 	prevStateIds, err := convertIdentityStates(prevStates)
 	if err != nil {
 		return 0, err
@@ -208,10 +210,10 @@ func (a *postgresAccessor) EpochIdentitiesCount(epoch uint64, prevStates []strin
 	if err != nil {
 		return 0, err
 	}
-	return a.count(epochIdentitiesQueryCount, epoch, pq.Array(prevStateIds), pq.Array(stateIds))
+	return a.count(ctx, epochIdentitiesQueryCount, epoch, pq.Array(prevStateIds), pq.Array(stateIds))
 }
 
-func (a *postgresAccessor) EpochIdentities(epoch uint64, prevStates []string, states []string, count uint64,
+func (a *postgresAccessor) EpochIdentities(ctx context.Context, epoch uint64, prevStates []string, states []string, count uint64,
 	continuationToken *string) ([]types.EpochIdentity, *string, error) {
 	prevStateIds, err := convertIdentityStates(prevStates)
 	if err != nil {
@@ -221,7 +223,7 @@ func (a *postgresAccessor) EpochIdentities(epoch uint64, prevStates []string, st
 	if err != nil {
 		return nil, nil, err
 	}
-	res, nextContinuationToken, err := a.page(epochIdentitiesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochIdentitiesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readEpochIdentities(rows)
 	}, count, continuationToken, epoch, pq.Array(prevStateIds), pq.Array(stateIds))
 	if err != nil {
@@ -230,17 +232,17 @@ func (a *postgresAccessor) EpochIdentities(epoch uint64, prevStates []string, st
 	return res.([]types.EpochIdentity), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochIdentityStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochIdentityStatesSummaryQuery, epoch)
+func (a *postgresAccessor) EpochIdentityStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochIdentityStatesSummaryQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochIdentityStatesInterimSummary(epoch uint64) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochIdentityStatesInterimSummaryQuery, epoch)
+func (a *postgresAccessor) EpochIdentityStatesInterimSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochIdentityStatesInterimSummaryQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochInvitesSummary(epoch uint64) (types.InvitesSummary, error) {
+func (a *postgresAccessor) EpochInvitesSummary(ctx context.Context, epoch uint64) (types.InvitesSummary, error) {
 	res := types.InvitesSummary{}
-	err := a.db.QueryRow(a.getQuery(epochInvitesSummaryQuery), epoch).Scan(&res.AllCount, &res.UsedCount)
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochInvitesSummaryQuery), epoch).Scan(&res.AllCount, &res.UsedCount)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -250,16 +252,16 @@ func (a *postgresAccessor) EpochInvitesSummary(epoch uint64) (types.InvitesSumma
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochInviteStatesSummary(epoch uint64) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochInviteStatesSummaryQuery, epoch)
+func (a *postgresAccessor) EpochInviteStatesSummary(ctx context.Context, epoch uint64) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochInviteStatesSummaryQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochInvitesCount(epoch uint64) (uint64, error) {
-	return a.count(epochInvitesCountQuery, epoch)
+func (a *postgresAccessor) EpochInvitesCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochInvitesCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochInvites(epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
-	res, nextContinuationToken, err := a.page(epochInvitesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) EpochInvites(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochInvitesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readInvites(rows)
 	}, count, continuationToken, epoch)
 	if err != nil {
@@ -268,12 +270,12 @@ func (a *postgresAccessor) EpochInvites(epoch uint64, count uint64, continuation
 	return res.([]types.Invite), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochTxsCount(epoch uint64) (uint64, error) {
-	return a.count(epochTxsCountQuery, epoch)
+func (a *postgresAccessor) EpochTxsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochTxsCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochTxs(epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(epochTxsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) EpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochTxsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readTxs(rows)
 	}, count, continuationToken, epoch)
 	if err != nil {
@@ -282,14 +284,18 @@ func (a *postgresAccessor) EpochTxs(epoch uint64, count uint64, continuationToke
 	return res.([]types.TransactionSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochCoins(epoch uint64) (types.AllCoins, error) {
-	return a.coins(epochCoinsQuery, epoch)
+func (a *postgresAccessor) StreamEpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return a.streamTxs(ctx, epochTxsQuery, w, count, continuationToken, epoch)
 }
 
-func (a *postgresAccessor) EpochRewardsSummary(epoch uint64) (types.RewardsSummary, error) {
+func (a *postgresAccessor) EpochCoins(ctx context.Context, epoch uint64) (types.AllCoins, error) {
+	return a.coins(ctx, epochCoinsQuery, epoch)
+}
+
+func (a *postgresAccessor) EpochRewardsSummary(ctx context.Context, epoch uint64) (types.RewardsSummary, error) {
 	res := types.RewardsSummary{}
 	var prevEpochDuration, prevPrevEpochDuration uint32
-	err := a.db.QueryRow(a.getQuery(epochRewardsSummaryQuery), epoch).
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochRewardsSummaryQuery), epoch).
 		Scan(
 			&res.Epoch,
 			&res.Total,
@@ -333,12 +339,12 @@ func (a *postgresAccessor) EpochRewardsSummary(epoch uint64) (types.RewardsSumma
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochBadAuthorsCount(epoch uint64) (uint64, error) {
-	return a.count(epochBadAuthorsCountQuery, epoch)
+func (a *postgresAccessor) EpochBadAuthorsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochBadAuthorsCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochBadAuthors(epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
-	res, nextContinuationToken, err := a.page(epochBadAuthorsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) EpochBadAuthors(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.BadAuthor, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochBadAuthorsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readBadAuthors(rows)
 	}, count, continuationToken, epoch)
 	if err != nil {
@@ -347,15 +353,15 @@ func (a *postgresAccessor) EpochBadAuthors(epoch uint64, count uint64, continuat
 	return res.([]types.BadAuthor), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochRewardsCount(epoch uint64) (uint64, error) {
-	return a.count(epochRewardsCountQuery, epoch)
+func (a *postgresAccessor) EpochRewardsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochRewardsCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochIdentitiesRewardsCount(epoch uint64) (uint64, error) {
-	return a.count(epochIdentitiesRewardsCountQuery, epoch)
+func (a *postgresAccessor) EpochIdentitiesRewardsCount(ctx context.Context, epoch uint64) (uint64, error) {
+	return a.count(ctx, epochIdentitiesRewardsCountQuery, epoch)
 }
 
-func (a *postgresAccessor) EpochIdentitiesRewards(epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+func (a *postgresAccessor) EpochIdentitiesRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
 	var continuationId *uint64
 	var err error
 	if continuationId, err = parseUintContinuationToken(continuationToken); err != nil {
@@ -365,7 +371,7 @@ func (a *postgresAccessor) EpochIdentitiesRewards(epoch uint64, count uint64, co
 		v := uint64(0)
 		continuationId = &v
 	}
-	rows, err := a.db.Query(a.getQuery(epochIdentitiesRewardsQuery), epoch, count+1, continuationId)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentitiesRewardsQuery), epoch, count+1, continuationId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -402,8 +408,8 @@ func (a *postgresAccessor) EpochIdentitiesRewards(epoch uint64, count uint64, co
 	return resSlice.([]types.Rewards), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochFundPayments(epoch uint64) ([]types.FundPayment, error) {
-	rows, err := a.db.Query(a.getQuery(epochFundPaymentsQuery), epoch)
+func (a *postgresAccessor) EpochFundPayments(ctx context.Context, epoch uint64) ([]types.FundPayment, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochFundPaymentsQuery), epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -419,8 +425,8 @@ func (a *postgresAccessor) EpochFundPayments(epoch uint64) ([]types.FundPayment,
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochRewardBounds(epoch uint64) ([]types.RewardBounds, error) {
-	rows, err := a.db.Query(a.getQuery(epochRewardBoundsQuery), epoch)
+func (a *postgresAccessor) EpochRewardBounds(ctx context.Context, epoch uint64) ([]types.RewardBounds, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochRewardBoundsQuery), epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -442,12 +448,12 @@ func (a *postgresAccessor) EpochRewardBounds(epoch uint64) ([]types.RewardBounds
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochDelegateeTotalRewards(epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
+func (a *postgresAccessor) EpochDelegateeTotalRewards(ctx context.Context, epoch uint64, count uint64, continuationToken *string) ([]types.DelegateeTotalRewards, *string, error) {
 	addressId, reward, err := parseUintAndAmountToken(continuationToken)
 	if err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(epochDelegateeTotalRewardsQuery), epoch, count+1, reward, addressId)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochDelegateeTotalRewardsQuery), epoch, count+1, reward, addressId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"strconv"
 )
@@ -10,12 +11,12 @@ const (
 	epochAddressDelegateeTotalRewardsQuery = "epochAddressDelegateeTotalRewards.sql"
 )
 
-func (a *postgresAccessor) EpochDelegateeRewards(epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error) {
+func (a *postgresAccessor) EpochDelegateeRewards(ctx context.Context, epoch uint64, address string, count uint64, continuationToken *string) ([]types.DelegateeReward, *string, error) {
 	addressId, reward, err := parseUintAndAmountToken(continuationToken)
 	if err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(epochDelegateeRewardsQuery), epoch, address, count+1, reward, addressId)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochDelegateeRewardsQuery), epoch, address, count+1, reward, addressId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -60,8 +61,8 @@ func (a *postgresAccessor) EpochDelegateeRewards(epoch uint64, address string, c
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) EpochAddressDelegateeTotalRewards(epoch uint64, address string) (types.DelegateeTotalRewards, error) {
-	rows, err := a.db.Query(a.getQuery(epochAddressDelegateeTotalRewardsQuery), epoch, address)
+func (a *postgresAccessor) EpochAddressDelegateeTotalRewards(ctx context.Context, epoch uint64, address string) (types.DelegateeTotalRewards, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochAddressDelegateeTotalRewardsQuery), epoch, address)
 	if err != nil {
 		return types.DelegateeTotalRewards{}, err
 	}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/shopspring/decimal"
@@ -23,9 +24,9 @@ const (
 	epochIdentityRewardedInviteeQuery     = "epochIdentityRewardedInvitee.sql"
 )
 
-func (a *postgresAccessor) EpochIdentity(epoch uint64, address string) (types.EpochIdentity, error) {
+func (a *postgresAccessor) EpochIdentity(ctx context.Context, epoch uint64, address string) (types.EpochIdentity, error) {
 	res := types.EpochIdentity{}
-	err := a.db.QueryRow(a.getQuery(epochIdentityQuery), epoch, address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochIdentityQuery), epoch, address).Scan(
 		&res.State,
 		&res.PrevState,
 		&res.ShortAnswers.Point,
@@ -53,16 +54,16 @@ func (a *postgresAccessor) EpochIdentity(epoch uint64, address string) (types.Ep
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityShortFlipsToSolve(epoch uint64, address string) ([]string, error) {
-	return a.epochIdentityFlipsToSolve(epoch, address, true)
+func (a *postgresAccessor) EpochIdentityShortFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	return a.epochIdentityFlipsToSolve(ctx, epoch, address, true)
 }
 
-func (a *postgresAccessor) EpochIdentityLongFlipsToSolve(epoch uint64, address string) ([]string, error) {
-	return a.epochIdentityFlipsToSolve(epoch, address, false)
+func (a *postgresAccessor) EpochIdentityLongFlipsToSolve(ctx context.Context, epoch uint64, address string) ([]string, error) {
+	return a.epochIdentityFlipsToSolve(ctx, epoch, address, false)
 }
 
-func (a *postgresAccessor) epochIdentityFlipsToSolve(epoch uint64, address string, isShort bool) ([]string, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityFlipsToSolveQuery), epoch, address, isShort)
+func (a *postgresAccessor) epochIdentityFlipsToSolve(ctx context.Context, epoch uint64, address string, isShort bool) ([]string, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityFlipsToSolveQuery), epoch, address, isShort)
 	if err != nil {
 		return nil, err
 	}
@@ -79,16 +80,16 @@ func (a *postgresAccessor) epochIdentityFlipsToSolve(epoch uint64, address strin
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityShortAnswers(epoch uint64, address string) ([]types.Answer, error) {
-	return a.epochIdentityAnswers(epoch, address, true)
+func (a *postgresAccessor) EpochIdentityShortAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	return a.epochIdentityAnswers(ctx, epoch, address, true)
 }
 
-func (a *postgresAccessor) EpochIdentityLongAnswers(epoch uint64, address string) ([]types.Answer, error) {
-	return a.epochIdentityAnswers(epoch, address, false)
+func (a *postgresAccessor) EpochIdentityLongAnswers(ctx context.Context, epoch uint64, address string) ([]types.Answer, error) {
+	return a.epochIdentityAnswers(ctx, epoch, address, false)
 }
 
-func (a *postgresAccessor) epochIdentityAnswers(epoch uint64, address string, isShort bool) ([]types.Answer, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityAnswersQuery), epoch, address, isShort)
+func (a *postgresAccessor) epochIdentityAnswers(ctx context.Context, epoch uint64, address string, isShort bool) ([]types.Answer, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityAnswersQuery), epoch, address, isShort)
 	if err != nil {
 		return nil, err
 	}
@@ -106,12 +107,12 @@ func (a *postgresAccessor) epochIdentityAnswers(epoch uint64, address string, is
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityFlips(epoch uint64, address string) ([]types.FlipSummary, error) {
-	return a.flipsWithoutPaging(epochIdentityFlipsQuery, epoch, address)
+func (a *postgresAccessor) EpochIdentityFlips(ctx context.Context, epoch uint64, address string) ([]types.FlipSummary, error) {
+	return a.flipsWithoutPaging(ctx, epochIdentityFlipsQuery, epoch, address)
 }
 
-func (a *postgresAccessor) EpochIdentityFlipsWithRewardFlag(epoch uint64, address string) ([]types.FlipWithRewardFlag, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityRewardedFlipsQuery), epoch, address)
+func (a *postgresAccessor) EpochIdentityFlipsWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.FlipWithRewardFlag, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityRewardedFlipsQuery), epoch, address)
 	if err != nil {
 		return nil, err
 	}
@@ -157,8 +158,8 @@ func (a *postgresAccessor) EpochIdentityFlipsWithRewardFlag(epoch uint64, addres
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityReportedFlipRewards(epoch uint64, address string) ([]types.ReportedFlipReward, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityReportedFlipRewardsQuery), epoch, address)
+func (a *postgresAccessor) EpochIdentityReportedFlipRewards(ctx context.Context, epoch uint64, address string) ([]types.ReportedFlipReward, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityReportedFlipRewardsQuery), epoch, address)
 	if err != nil {
 		return nil, err
 	}
@@ -192,13 +193,13 @@ func (a *postgresAccessor) EpochIdentityReportedFlipRewards(epoch uint64, addres
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityRewards(epoch uint64, address string) ([]types.Reward, error) {
-	return a.rewards(epochIdentityRewardsQuery, epoch, address)
+func (a *postgresAccessor) EpochIdentityRewards(ctx context.Context, epoch uint64, address string) ([]types.Reward, error) {
+	return a.rewards(ctx, epochIdentityRewardsQuery, epoch, address)
 }
 
-func (a *postgresAccessor) EpochIdentityBadAuthor(epoch uint64, address string) (*types.BadAuthor, error) {
+func (a *postgresAccessor) EpochIdentityBadAuthor(ctx context.Context, epoch uint64, address string) (*types.BadAuthor, error) {
 	res := types.BadAuthor{}
-	err := a.db.QueryRow(a.getQuery(epochIdentityBadAuthorQuery), epoch, address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochIdentityBadAuthorQuery), epoch, address).Scan(
 		&res.Address,
 		&res.Epoch,
 		&res.WrongWords,
@@ -215,8 +216,8 @@ func (a *postgresAccessor) EpochIdentityBadAuthor(epoch uint64, address string)
 	return &res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityInvitesWithRewardFlag(epoch uint64, address string) ([]types.InviteWithRewardFlag, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityRewardedInvitesQuery), epoch, address)
+func (a *postgresAccessor) EpochIdentityInvitesWithRewardFlag(ctx context.Context, epoch uint64, address string) ([]types.InviteWithRewardFlag, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityRewardedInvitesQuery), epoch, address)
 	if err != nil {
 		return nil, err
 	}
@@ -256,12 +257,12 @@ func (a *postgresAccessor) EpochIdentityInvitesWithRewardFlag(epoch uint64, addr
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentitySavedInviteRewards(epoch uint64, address string) ([]types.StrValueCount, error) {
-	return a.strValueCounts(epochIdentitySavedInviteRewardsQuery, epoch, address)
+func (a *postgresAccessor) EpochIdentitySavedInviteRewards(ctx context.Context, epoch uint64, address string) ([]types.StrValueCount, error) {
+	return a.strValueCounts(ctx, epochIdentitySavedInviteRewardsQuery, epoch, address)
 }
 
-func (a *postgresAccessor) EpochIdentityAvailableInvites(epoch uint64, address string) ([]types.EpochInvites, error) {
-	rows, err := a.db.Query(a.getQuery(epochIdentityAvailableInvitesQuery), epoch, address)
+func (a *postgresAccessor) EpochIdentityAvailableInvites(ctx context.Context, epoch uint64, address string) ([]types.EpochInvites, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(epochIdentityAvailableInvitesQuery), epoch, address)
 	if err != nil {
 		return nil, err
 	}
@@ -277,9 +278,9 @@ func (a *postgresAccessor) EpochIdentityAvailableInvites(epoch uint64, address s
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityInviteeWithRewardFlag(epoch uint64, address string) (*types.InviteeWithRewardFlag, error) {
+func (a *postgresAccessor) EpochIdentityInviteeWithRewardFlag(ctx context.Context, epoch uint64, address string) (*types.InviteeWithRewardFlag, error) {
 	res := &types.InviteeWithRewardFlag{}
-	err := a.db.QueryRow(a.getQuery(epochIdentityRewardedInviteeQuery), epoch, address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochIdentityRewardedInviteeQuery), epoch, address).Scan(
 		&res.Epoch,
 		&res.Hash,
 		&res.Inviter,
@@ -296,12 +297,12 @@ func (a *postgresAccessor) EpochIdentityInviteeWithRewardFlag(epoch uint64, addr
 	return res, nil
 }
 
-func (a *postgresAccessor) EpochIdentityValidationSummary(epoch uint64, address string) (types.ValidationSummary, error) {
+func (a *postgresAccessor) EpochIdentityValidationSummary(ctx context.Context, epoch uint64, address string) (types.ValidationSummary, error) {
 	res := types.ValidationSummary{}
 	var validationReason, flipsReason, extraFlipsReason, reportsReason, invitationsReason, inviteeReason, candidateReason, stakingReason byte
 	var delegateeAddress string
 	var delegateeReward decimal.Decimal
-	err := a.db.QueryRow(a.getQuery(epochIdentityValidationSummaryQuery), epoch, address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(epochIdentityValidationSummaryQuery), epoch, address).Scan(
 		&res.ShortAnswers.Point,
 		&res.ShortAnswers.FlipsCount,
 		&res.TotalShortAnswers.Point,
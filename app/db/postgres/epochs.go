@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
@@ -10,12 +11,12 @@ const (
 	epochsQuery      = "epochs.sql"
 )
 
-func (a *postgresAccessor) EpochsCount() (uint64, error) {
-	return a.count(epochsCountQuery)
+func (a *postgresAccessor) EpochsCount(ctx context.Context) (uint64, error) {
+	return a.count(ctx, epochsCountQuery)
 }
 
-func (a *postgresAccessor) Epochs(count uint64, continuationToken *string) ([]types.EpochSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(epochsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) Epochs(ctx context.Context, count uint64, continuationToken *string) ([]types.EpochSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, epochsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.EpochSummary
 		var epoch uint64
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-indexer-api/app/types"
@@ -15,11 +16,11 @@ const (
 	flipEpochAdjacentFlipsQuery = "flipEpochAdjacentFlips.sql"
 )
 
-func (a *postgresAccessor) Flip(hash string) (types.Flip, error) {
+func (a *postgresAccessor) Flip(ctx context.Context, hash string) (types.Flip, error) {
 	flip := types.Flip{}
 	words := types.FlipWords{}
 	var timestamp int64
-	err := a.db.QueryRow(a.getQuery(flipQuery), hash).
+	err := a.db.QueryRowContext(ctx, a.getQuery(flipQuery), hash).
 		Scan(
 			&flip.Author,
 			&flip.Size,
@@ -55,9 +56,9 @@ func (a *postgresAccessor) Flip(hash string) (types.Flip, error) {
 	return flip, nil
 }
 
-func (a *postgresAccessor) FlipContent(hash string) (types.FlipContent, error) {
+func (a *postgresAccessor) FlipContent(ctx context.Context, hash string) (types.FlipContent, error) {
 	res := types.FlipContent{}
-	if pics, err := a.flipPics(hash); err != nil {
+	if pics, err := a.flipPics(ctx, hash); err != nil {
 		return types.FlipContent{}, err
 	} else if len(pics) == 0 {
 		return types.FlipContent{}, NoDataFound
@@ -65,7 +66,7 @@ func (a *postgresAccessor) FlipContent(hash string) (types.FlipContent, error) {
 		res.Pics = pics
 		res.PicsOld = pics
 	}
-	if leftOrder, rightOrder, err := a.flipPicOrders(hash); err != nil {
+	if leftOrder, rightOrder, err := a.flipPicOrders(ctx, hash); err != nil {
 		return types.FlipContent{}, err
 	} else {
 		res.LeftOrder, res.RightOrder = leftOrder, rightOrder
@@ -74,8 +75,8 @@ func (a *postgresAccessor) FlipContent(hash string) (types.FlipContent, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) flipPics(hash string) ([]hexutil.Bytes, error) {
-	rows, err := a.db.Query(a.getQuery(flipPicsQuery), hash)
+func (a *postgresAccessor) flipPics(ctx context.Context, hash string) ([]hexutil.Bytes, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(flipPicsQuery), hash)
 	if err != nil {
 		return nil, err
 	}
@@ -92,8 +93,8 @@ func (a *postgresAccessor) flipPics(hash string) ([]hexutil.Bytes, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) flipPicOrders(hash string) ([]uint16, []uint16, error) {
-	rows, err := a.db.Query(a.getQuery(flipPicOrdersQuery), hash)
+func (a *postgresAccessor) flipPicOrders(ctx context.Context, hash string) ([]uint16, []uint16, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(flipPicOrdersQuery), hash)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -117,14 +118,14 @@ func (a *postgresAccessor) flipPicOrders(hash string) ([]uint16, []uint16, error
 	return leftOrder, rightOrder, nil
 }
 
-func (a *postgresAccessor) FlipAnswers(hash string, isShort bool) ([]types.Answer, error) {
-	rows, err := a.db.Query(a.getQuery(flipAnswersQuery), hash, isShort)
+func (a *postgresAccessor) FlipAnswers(ctx context.Context, hash string, isShort bool) ([]types.Answer, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(flipAnswersQuery), hash, isShort)
 	if err != nil {
 		return nil, err
 	}
 	return readAnswers(rows)
 }
 
-func (a *postgresAccessor) FlipEpochAdjacentFlips(hash string) (types.AdjacentStrValues, error) {
-	return a.adjacentStrValues(flipEpochAdjacentFlipsQuery, hash)
+func (a *postgresAccessor) FlipEpochAdjacentFlips(ctx context.Context, hash string) (types.AdjacentStrValues, error) {
+	return a.adjacentStrValues(ctx, flipEpochAdjacentFlipsQuery, hash)
 }
@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
 
@@ -26,9 +28,9 @@ const (
 	identityFlipsQuery             = "identityFlips.sql"
 )
 
-func (a *postgresAccessor) Identity(address string) (types.Identity, error) {
+func (a *postgresAccessor) Identity(ctx context.Context, address string) (types.Identity, error) {
 	identity := types.Identity{}
-	err := a.db.QueryRow(a.getQuery(identityQuery), address).Scan(&identity.Address, &identity.State)
+	err := a.db.QueryRowContext(ctx, a.getQuery(identityQuery), address).Scan(&identity.Address, &identity.State)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -36,15 +38,15 @@ func (a *postgresAccessor) Identity(address string) (types.Identity, error) {
 		return types.Identity{}, err
 	}
 
-	if identity.TotalShortAnswers, err = a.identityAnswerPoints(address); err != nil {
+	if identity.TotalShortAnswers, err = a.identityAnswerPoints(ctx, address); err != nil {
 		return types.Identity{}, err
 	}
 
 	return identity, nil
 }
 
-func (a *postgresAccessor) identityAnswerPoints(address string) (totalShort types.IdentityAnswersSummary, err error) {
-	rows, err := a.db.Query(a.getQuery(identityAnswerPointsQuery), address)
+func (a *postgresAccessor) identityAnswerPoints(ctx context.Context, address string) (totalShort types.IdentityAnswersSummary, err error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(identityAnswerPointsQuery), address)
 	if err != nil {
 		return
 	}
@@ -56,9 +58,9 @@ func (a *postgresAccessor) identityAnswerPoints(address string) (totalShort type
 	return
 }
 
-func (a *postgresAccessor) IdentityAge(address string) (uint64, error) {
+func (a *postgresAccessor) IdentityAge(ctx context.Context, address string) (uint64, error) {
 	var res uint64
-	err := a.db.QueryRow(a.getQuery(identityAgeQuery), address).Scan(&res)
+	err := a.db.QueryRowContext(ctx, a.getQuery(identityAgeQuery), address).Scan(&res)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -68,8 +70,8 @@ func (a *postgresAccessor) IdentityAge(address string) (uint64, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) IdentityCurrentFlipCids(address string) ([]string, error) {
-	rows, err := a.db.Query(a.getQuery(identityCurrentFlipsQuery), address)
+func (a *postgresAccessor) IdentityCurrentFlipCids(ctx context.Context, address string) ([]string, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(identityCurrentFlipsQuery), address)
 	if err != nil {
 		return nil, err
 	}
@@ -86,12 +88,12 @@ func (a *postgresAccessor) IdentityCurrentFlipCids(address string) ([]string, er
 	return res, nil
 }
 
-func (a *postgresAccessor) IdentityEpochsCount(address string) (uint64, error) {
-	return a.count(identityEpochsCountQuery, address)
+func (a *postgresAccessor) IdentityEpochsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityEpochsCountQuery, address)
 }
 
-func (a *postgresAccessor) IdentityEpochs(address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error) {
-	res, nextContinuationToken, err := a.page(identityEpochsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) IdentityEpochs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.EpochIdentity, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, identityEpochsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readEpochIdentities(rows)
 	}, count, continuationToken, address)
 	if err != nil {
@@ -100,28 +102,28 @@ func (a *postgresAccessor) IdentityEpochs(address string, count uint64, continua
 	return res.([]types.EpochIdentity), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) IdentityFlipStates(address string) ([]types.StrValueCount, error) {
-	rows, err := a.db.Query(a.getQuery(identityFlipStatesQuery), address)
+func (a *postgresAccessor) IdentityFlipStates(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(identityFlipStatesQuery), address)
 	if err != nil {
 		return nil, err
 	}
 	return readStrValueCounts(rows)
 }
 
-func (a *postgresAccessor) IdentityFlipQualifiedAnswers(address string) ([]types.StrValueCount, error) {
-	rows, err := a.db.Query(a.getQuery(identityFlipRightAnswersQuery), address)
+func (a *postgresAccessor) IdentityFlipQualifiedAnswers(ctx context.Context, address string) ([]types.StrValueCount, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(identityFlipRightAnswersQuery), address)
 	if err != nil {
 		return nil, err
 	}
 	return readStrValueCounts(rows)
 }
 
-func (a *postgresAccessor) IdentityInvitesCount(address string) (uint64, error) {
-	return a.count(identityInvitesCountQuery, address)
+func (a *postgresAccessor) IdentityInvitesCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityInvitesCountQuery, address)
 }
 
-func (a *postgresAccessor) IdentityInvites(address string, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
-	res, nextContinuationToken, err := a.page(identityInvitesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) IdentityInvites(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Invite, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, identityInvitesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readInvites(rows)
 	}, count, continuationToken, address)
 	if err != nil {
@@ -130,12 +132,12 @@ func (a *postgresAccessor) IdentityInvites(address string, count uint64, continu
 	return res.([]types.Invite), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) IdentityTxsCount(address string) (uint64, error) {
-	return a.count(identityTxsCountQuery, address)
+func (a *postgresAccessor) IdentityTxsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityTxsCountQuery, address)
 }
 
-func (a *postgresAccessor) IdentityTxs(address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
-	res, nextContinuationToken, err := a.page(identityTxsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) IdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TransactionSummary, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, identityTxsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		return readTxs(rows)
 	}, count, continuationToken, address)
 	if err != nil {
@@ -144,12 +146,16 @@ func (a *postgresAccessor) IdentityTxs(address string, count uint64, continuatio
 	return res.([]types.TransactionSummary), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) IdentityRewardsCount(address string) (uint64, error) {
-	return a.count(identityRewardsCountQuery, address)
+func (a *postgresAccessor) StreamIdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string, w db.RowWriter) (*string, error) {
+	return a.streamTxs(ctx, identityTxsQuery, w, count, continuationToken, address)
 }
 
-func (a *postgresAccessor) IdentityRewards(address string, count uint64, continuationToken *string) ([]types.Reward, *string, error) {
-	res, nextContinuationToken, err := a.page(identityRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) IdentityRewardsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityRewardsCountQuery, address)
+}
+
+func (a *postgresAccessor) IdentityRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Reward, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, identityRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.Reward
 		for rows.Next() {
@@ -175,12 +181,12 @@ func (a *postgresAccessor) IdentityRewards(address string, count uint64, continu
 	return res.([]types.Reward), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) IdentityEpochRewardsCount(address string) (uint64, error) {
-	return a.count(identityEpochRewardsCountQuery, address)
+func (a *postgresAccessor) IdentityEpochRewardsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityEpochRewardsCountQuery, address)
 }
 
-func (a *postgresAccessor) IdentityEpochRewards(address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
-	res, nextContinuationToken, err := a.page(identityEpochRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) IdentityEpochRewards(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.Rewards, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, identityEpochRewardsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.Rewards
 		var item *types.Rewards
@@ -220,10 +226,10 @@ func (a *postgresAccessor) IdentityEpochRewards(address string, count uint64, co
 	return res.([]types.Rewards), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) IdentityFlipsCount(address string) (uint64, error) {
-	return a.count(identityFlipsCountQuery, address)
+func (a *postgresAccessor) IdentityFlipsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, identityFlipsCountQuery, address)
 }
 
-func (a *postgresAccessor) IdentityFlips(address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
-	return a.flips(identityFlipsQuery, count, continuationToken, address)
+func (a *postgresAccessor) IdentityFlips(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.FlipSummary, *string, error) {
+	return a.flips(ctx, identityFlipsQuery, count, continuationToken, address)
 }
@@ -1,11 +1,14 @@
 package postgres
 
-import "github.com/idena-network/idena-indexer-api/app/types"
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
 
 const minersHistoryQuery = "minersHistory.sql"
 
-func (a *postgresAccessor) MinersHistory() ([]types.MinersHistoryItem, error) {
-	rows, err := a.db.Query(a.getQuery(minersHistoryQuery))
+func (a *postgresAccessor) MinersHistory(ctx context.Context) ([]types.MinersHistoryItem, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(minersHistoryQuery))
 	if err != nil {
 		return nil, err
 	}
@@ -1,11 +1,14 @@
 package postgres
 
-import "github.com/idena-network/idena-indexer-api/app/types"
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
 
 const peersHistoryQuery = "peersHistory.sql"
 
-func (a *postgresAccessor) PeersHistory(count uint64) ([]types.PeersHistoryItem, error) {
-	rows, err := a.db.Query(a.getQuery(peersHistoryQuery), count)
+func (a *postgresAccessor) PeersHistory(ctx context.Context, count uint64) ([]types.PeersHistoryItem, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(peersHistoryQuery), count)
 	if err != nil {
 		return nil, err
 	}
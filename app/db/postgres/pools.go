@@ -1,9 +1,10 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/types"
-	"github.com/pkg/errors"
 	"strconv"
 	"strings"
 )
@@ -17,18 +18,18 @@ const (
 	poolSizeHistoryQuery     = "poolSizeHistory.sql"
 )
 
-func (a *postgresAccessor) PoolsCount() (uint64, error) {
-	return a.count(poolsCountQuery)
+func (a *postgresAccessor) PoolsCount(ctx context.Context) (uint64, error) {
+	return a.count(ctx, poolsCountQuery)
 }
 
-func (a *postgresAccessor) Pools(count uint64, continuationToken *string) ([]*types.Pool, *string, error) {
+func (a *postgresAccessor) Pools(ctx context.Context, count uint64, continuationToken *string) ([]*types.Pool, *string, error) {
 	parseToken := func(continuationToken *string) (addressId, size *uint64, err error) {
 		if continuationToken == nil {
 			return
 		}
 		strs := strings.Split(*continuationToken, "-")
 		if len(strs) != 2 {
-			err = errors.New("invalid continuation token")
+			err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 			return
 		}
 		if addressId, err = parseUintContinuationToken(&strs[0]); err != nil {
@@ -43,7 +44,7 @@ func (a *postgresAccessor) Pools(count uint64, continuationToken *string) ([]*ty
 	if err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(poolsQuery), count+1, addressId, size)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(poolsQuery), count+1, addressId, size)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -70,9 +71,9 @@ func (a *postgresAccessor) Pools(count uint64, continuationToken *string) ([]*ty
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) Pool(address string) (*types.Pool, error) {
+func (a *postgresAccessor) Pool(ctx context.Context, address string) (*types.Pool, error) {
 	res := &types.Pool{}
-	err := a.db.QueryRow(a.getQuery(poolQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(poolQuery), address).Scan(
 		&res.Address,
 		&res.Size,
 	)
@@ -85,18 +86,18 @@ func (a *postgresAccessor) Pool(address string) (*types.Pool, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) PoolDelegatorsCount(address string) (uint64, error) {
-	return a.count(poolDelegatorsCountQuery, address)
+func (a *postgresAccessor) PoolDelegatorsCount(ctx context.Context, address string) (uint64, error) {
+	return a.count(ctx, poolDelegatorsCountQuery, address)
 }
 
-func (a *postgresAccessor) PoolDelegators(address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error) {
+func (a *postgresAccessor) PoolDelegators(ctx context.Context, address string, count uint64, continuationToken *string) ([]*types.Delegator, *string, error) {
 	parseToken := func(continuationToken *string) (addressId, birthEpoch *uint64, err error) {
 		if continuationToken == nil {
 			return
 		}
 		strs := strings.Split(*continuationToken, "-")
 		if len(strs) != 2 {
-			err = errors.New("invalid continuation token")
+			err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 			return
 		}
 		if addressId, err = parseUintContinuationToken(&strs[0]); err != nil {
@@ -111,7 +112,7 @@ func (a *postgresAccessor) PoolDelegators(address string, count uint64, continua
 	if err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(poolDelegatorsQuery), count+1, addressId, birthEpoch, address)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(poolDelegatorsQuery), count+1, addressId, birthEpoch, address)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -141,8 +142,8 @@ func (a *postgresAccessor) PoolDelegators(address string, count uint64, continua
 	return res, nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) PoolSizeHistory(address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error) {
-	res, nextContinuationToken, err := a.page(poolSizeHistoryQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) PoolSizeHistory(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.PoolSizeHistoryItem, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, poolSizeHistoryQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.PoolSizeHistoryItem
 		var epoch uint64
@@ -1,11 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/service"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/idena-network/idena-indexer-api/log"
@@ -44,15 +45,15 @@ const (
 	coinsQuery                = "coinsQuery.sql"
 )
 
-var NoDataFound = errors.New("no data found")
+var NoDataFound error = apierrors.New(apierrors.NotFound, "no data found")
 
-func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
+func (a *postgresAccessor) Search(ctx context.Context, value string) ([]types.Entity, error) {
 	var isNum bool
 	if _, err := strconv.ParseUint(value, 10, 64); err == nil {
 		isNum = true
 	}
 	var res []types.Entity
-	if exists, err := a.isEntity(value, isAddressQuery); err != nil {
+	if exists, err := a.isEntity(ctx, value, isAddressQuery); err != nil {
 		return nil, err
 	} else if exists {
 		res = append(res, types.Entity{
@@ -73,7 +74,7 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 		})
 	}
 
-	if exists, err := a.isEntity(value, isBlockHashQuery); err != nil {
+	if exists, err := a.isEntity(ctx, value, isBlockHashQuery); err != nil {
 		return nil, err
 	} else if exists {
 		res = append(res, types.Entity{
@@ -85,7 +86,7 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 			RefOld:   fmt.Sprintf("/api/Block/%s", value),
 		})
 	} else if isNum {
-		if exists, err := a.isEntity(value, isBlockHeightQuery); err != nil {
+		if exists, err := a.isEntity(ctx, value, isBlockHeightQuery); err != nil {
 			return nil, err
 		} else if exists {
 			res = append(res, types.Entity{
@@ -100,7 +101,7 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 	}
 
 	if isNum {
-		if exists, err := a.isEntity(value, isEpochQuery); err != nil {
+		if exists, err := a.isEntity(ctx, value, isEpochQuery); err != nil {
 			return nil, err
 		} else if exists {
 			res = append(res, types.Entity{
@@ -114,7 +115,7 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 		}
 	}
 
-	if exists, err := a.isEntity(value, isFlipQuery); err != nil {
+	if exists, err := a.isEntity(ctx, value, isFlipQuery); err != nil {
 		return nil, err
 	} else if exists {
 		res = append(res, types.Entity{
@@ -127,7 +128,7 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 		})
 	}
 
-	if exists, err := a.isEntity(value, isTxQuery); err != nil {
+	if exists, err := a.isEntity(ctx, value, isTxQuery); err != nil {
 		return nil, err
 	} else if exists {
 		res = append(res, types.Entity{
@@ -143,9 +144,9 @@ func (a *postgresAccessor) Search(value string) ([]types.Entity, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) Coins() (types.AllCoins, error) {
+func (a *postgresAccessor) Coins(ctx context.Context) (types.AllCoins, error) {
 	res := types.AllCoins{}
-	err := a.db.QueryRow(a.getQuery(coinsQuery)).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(coinsQuery)).Scan(
 		&res.TotalBalance,
 		&res.TotalStake,
 		&res.Burnt,
@@ -160,13 +161,13 @@ func (a *postgresAccessor) Coins() (types.AllCoins, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) CirculatingSupply(addressesToExclude []string) (decimal.Decimal, error) {
+func (a *postgresAccessor) CirculatingSupply(ctx context.Context, addressesToExclude []string) (decimal.Decimal, error) {
 	var totalBalance, totalStake decimal.Decimal
 	var err error
 	if len(addressesToExclude) == 0 {
-		err = a.db.QueryRow(a.getQuery(coinsTotalQuery)).Scan(&totalBalance, &totalStake)
+		err = a.db.QueryRowContext(ctx, a.getQuery(coinsTotalQuery)).Scan(&totalBalance, &totalStake)
 	} else {
-		err = a.db.QueryRow(a.getQuery(circulatingSupplyQuery), pq.Array(addressesToExclude)).Scan(&totalBalance)
+		err = a.db.QueryRowContext(ctx, a.getQuery(circulatingSupplyQuery), pq.Array(addressesToExclude)).Scan(&totalBalance)
 	}
 	if err == sql.ErrNoRows {
 		err = NoDataFound
@@ -177,13 +178,13 @@ func (a *postgresAccessor) CirculatingSupply(addressesToExclude []string) (decim
 	return totalBalance, nil
 }
 
-func (a *postgresAccessor) ActiveAddressesCount(afterTime time.Time) (uint64, error) {
-	return a.count(activeAddressesCountQuery, afterTime.Unix())
+func (a *postgresAccessor) ActiveAddressesCount(ctx context.Context, afterTime time.Time) (uint64, error) {
+	return a.count(ctx, activeAddressesCountQuery, afterTime.Unix())
 }
 
-func (a *postgresAccessor) isEntity(value, queryName string) (bool, error) {
+func (a *postgresAccessor) isEntity(ctx context.Context, value, queryName string) (bool, error) {
 	var exists bool
-	err := a.db.QueryRow(a.getQuery(queryName), value).Scan(&exists)
+	err := a.db.QueryRowContext(ctx, a.getQuery(queryName), value).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -197,7 +198,7 @@ func (a *postgresAccessor) getQuery(name string) string {
 	panic(fmt.Sprintf("There is no query '%s'", name))
 }
 
-func (a *postgresAccessor) Transaction(hash string) (*types.TransactionDetail, error) {
+func (a *postgresAccessor) Transaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
 	res := types.TransactionDetail{}
 	var timestamp int64
 	var gasCost, transfer NullDecimal
@@ -205,7 +206,7 @@ func (a *postgresAccessor) Transaction(hash string) (*types.TransactionDetail, e
 	var gasUsed sql.NullInt64
 	var method, errorMsg, contractAddress sql.NullString
 	var actionResult hexutil.Bytes
-	err := a.db.QueryRow(a.getQuery(transactionQuery), hash).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(transactionQuery), hash).Scan(
 		&res.Epoch,
 		&res.BlockHeight,
 		&res.BlockHash,
@@ -289,9 +290,9 @@ func convertActionResult(protoModel *models.ActionResult) *types.ActionResult {
 	return result
 }
 
-func (a *postgresAccessor) TransactionRaw(hash string) (*hexutil.Bytes, error) {
+func (a *postgresAccessor) TransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
 	var res hexutil.Bytes
-	err := a.db.QueryRow(a.getQuery(transactionRawQuery), hash).Scan(&res)
+	err := a.db.QueryRowContext(ctx, a.getQuery(transactionRawQuery), hash).Scan(&res)
 	if err == sql.ErrNoRows {
 		err = NoDataFound
 	}
@@ -301,8 +302,8 @@ func (a *postgresAccessor) TransactionRaw(hash string) (*hexutil.Bytes, error) {
 	return &res, nil
 }
 
-func (a *postgresAccessor) TransactionEvents(hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error) {
-	res, nextContinuationToken, err := a.page(transactionEventsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) TransactionEvents(ctx context.Context, hash string, count uint64, continuationToken *string) ([]types.TxEvent, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, transactionEventsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.TxEvent
 		var index uint64
@@ -334,3 +335,13 @@ func (a *postgresAccessor) Destroy() {
 		a.log.Error("Unable to close db: %v", err)
 	}
 }
+
+// Stats returns connection pool stats of the db
+func (a *postgresAccessor) Stats() sql.DBStats {
+	return a.db.Stats()
+}
+
+// Ping verifies a connection to the db is still alive
+func (a *postgresAccessor) Ping(ctx context.Context) error {
+	return a.db.PingContext(ctx)
+}
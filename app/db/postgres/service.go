@@ -15,35 +15,32 @@ type estimatedOracleRewardsService struct {
 
 type estimatedOracleRewardsServiceCache struct {
 	networkSize uint64
+	expiresAt   time.Time
 }
 
+const estimatedOracleRewardsCacheLifeTime = time.Minute
+
 func newEstimatedOracleRewardsCache(
 	networkSizeFn func() (uint64, error),
 ) *estimatedOracleRewardsService {
-	res := &estimatedOracleRewardsService{
+	return &estimatedOracleRewardsService{
 		networkSizeFn: networkSizeFn,
 	}
-	go func() {
-		for {
-			time.Sleep(time.Minute)
-			res.cache = nil
-		}
-	}()
-	return res
 }
 
 func (c *estimatedOracleRewardsService) get() ([]types.EstimatedOracleReward, error) {
 	data := c.cache
-	if data == nil {
+	if data == nil || time.Now().After(data.expiresAt) {
 		c.mutex.Lock()
 		data = c.cache
-		if data == nil {
+		if data == nil || time.Now().After(data.expiresAt) {
 			var err error
 			data, err = c.loadData()
 			if err != nil {
 				c.mutex.Unlock()
 				return nil, err
 			}
+			c.cache = data
 		}
 		c.mutex.Unlock()
 	}
@@ -57,6 +54,7 @@ func (c *estimatedOracleRewardsService) loadData() (*estimatedOracleRewardsServi
 	}
 	return &estimatedOracleRewardsServiceCache{
 		networkSize: networkSize,
+		expiresAt:   time.Now().Add(estimatedOracleRewardsCacheLifeTime),
 	}, nil
 }
 
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	statsSeriesQuery = "statsSeries.sql"
+
+	deployContractTxType = "DeployContract"
+	callContractTxType   = "CallContract"
+)
+
+// statsIntervalBounds returns the interval length and the offset of interval starts from the unix epoch start in seconds,
+// the unix epoch started on Thursday so weeks are shifted by 3 days to start on Monday
+func statsIntervalBounds(interval db.StatsInterval) (size, offset int64, err error) {
+	switch interval {
+	case db.HourStatsInterval:
+		return 3600, 0, nil
+	case db.DayStatsInterval:
+		return 86400, 0, nil
+	case db.WeekStatsInterval:
+		return 7 * 86400, 3 * 86400, nil
+	}
+	return 0, 0, apierrors.Errorf(apierrors.InvalidArgument, "unknown interval %v", interval)
+}
+
+func (a *postgresAccessor) StatsSeries(ctx context.Context, interval db.StatsInterval, count uint64, continuationToken *string) ([]types.StatsSeriesItem, *string, error) {
+	size, offset, err := statsIntervalBounds(interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, nextContinuationToken, err := a.page(ctx, statsSeriesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+		defer rows.Close()
+		var res []types.StatsSeriesItem
+		var bucket uint64
+		for rows.Next() {
+			item := types.StatsSeriesItem{}
+			var typeNames []string
+			var typeCounts []int64
+			if err := rows.Scan(
+				&bucket,
+				&item.AverageFeeRate,
+				&item.Fees,
+				&item.Tips,
+				pq.Array(&typeNames),
+				pq.Array(&typeCounts),
+				&item.ActiveAddresses,
+				&item.NewAddresses,
+			); err != nil {
+				return nil, 0, err
+			}
+			item.Timestamp = time.Unix(int64(bucket), 0).UTC()
+			item.TxCountsByType = make(map[string]uint64, len(typeNames))
+			for i, name := range typeNames {
+				item.TxCountsByType[name] = uint64(typeCounts[i])
+				item.TxCount += uint64(typeCounts[i])
+			}
+			item.ContractDeploys = item.TxCountsByType[deployContractTxType]
+			item.ContractCalls = item.TxCountsByType[callContractTxType]
+			res = append(res, item)
+		}
+		return res, bucket, nil
+	}, count, continuationToken, size, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.([]types.StatsSeriesItem), nextContinuationToken, nil
+}
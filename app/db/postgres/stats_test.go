@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_statsIntervalBounds(t *testing.T) {
+	// Wednesday
+	timestamp := time.Date(2022, 3, 9, 15, 30, 0, 0, time.UTC).Unix()
+	start := func(interval db.StatsInterval) time.Time {
+		size, offset, err := statsIntervalBounds(interval)
+		require.Nil(t, err)
+		return time.Unix(timestamp-(timestamp+offset)%size, 0).UTC()
+	}
+	require.Equal(t, time.Date(2022, 3, 9, 15, 0, 0, 0, time.UTC), start(db.HourStatsInterval))
+	require.Equal(t, time.Date(2022, 3, 9, 0, 0, 0, 0, time.UTC), start(db.DayStatsInterval))
+	require.Equal(t, time.Date(2022, 3, 7, 0, 0, 0, 0, time.UTC), start(db.WeekStatsInterval))
+
+	_, _, err := statsIntervalBounds("month")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const supplyHistoryQuery = "supplyHistory.sql"
+
+// Reasons of burnt coins stored by the indexer which are shown separately in the supply history, they must match
+// values of the indexer enum, coins burnt for other reasons are summed up as other
+const (
+	penaltyBurntCoinsReason     = 0
+	feeBurntCoinsReason         = 2
+	killedStakeBurntCoinsReason = 3
+	burnTxBurntCoinsReason      = 4
+)
+
+func (a *postgresAccessor) SupplyHistory(ctx context.Context, interval db.SupplyInterval, addressesToExclude []string, count uint64, continuationToken *string) ([]types.SupplyHistoryItem, *string, error) {
+	if interval != db.EpochSupplyInterval && interval != db.DaySupplyInterval {
+		return nil, nil, apierrors.Errorf(apierrors.InvalidArgument, "unknown interval %v", interval)
+	}
+	res, nextContinuationToken, err := a.page(ctx, supplyHistoryQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+		defer rows.Close()
+		var res []types.SupplyHistoryItem
+		var bucket uint64
+		for rows.Next() {
+			item := types.SupplyHistoryItem{}
+			var timestamp int64
+			var totalBalance, frozenBalance decimal.Decimal
+			if err := rows.Scan(
+				&bucket,
+				&timestamp,
+				&item.BlockHeight,
+				&totalBalance,
+				&item.Staked,
+				&item.Minted,
+				&item.Burnt,
+				&item.BurntByReason.Fees,
+				&item.BurntByReason.BurnTxs,
+				&item.BurntByReason.Penalties,
+				&item.BurntByReason.KilledStakes,
+				&item.BurntByReason.Other,
+				&frozenBalance,
+			); err != nil {
+				return nil, 0, err
+			}
+			item.TotalSupply = totalBalance.Add(item.Staked)
+			item.CirculatingSupply = totalBalance.Sub(frozenBalance)
+			if interval == db.EpochSupplyInterval {
+				epoch := bucket
+				item.Epoch = &epoch
+				item.Timestamp = timestampToTimeUTC(timestamp)
+			} else {
+				item.Timestamp = time.Unix(int64(bucket)*86400, 0).UTC()
+			}
+			res = append(res, item)
+		}
+		return res, bucket, nil
+	}, count, continuationToken, string(interval), pq.Array(addressesToExclude),
+		feeBurntCoinsReason, burnTxBurntCoinsReason, penaltyBurntCoinsReason, killedStakeBurntCoinsReason)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.([]types.SupplyHistoryItem), nextContinuationToken, nil
+}
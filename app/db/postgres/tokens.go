@@ -1,10 +1,11 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/types"
-	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"strings"
 )
@@ -14,9 +15,9 @@ const (
 	tokenHoldersQuery = "tokenHolders.sql"
 )
 
-func (a *postgresAccessor) Token(address string) (types.Token, error) {
+func (a *postgresAccessor) Token(ctx context.Context, address string) (types.Token, error) {
 	var res types.Token
-	err := a.db.QueryRow(a.getQuery(tokenQuery), address).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(tokenQuery), address).Scan(
 		&res.ContractAddress,
 		&res.Name,
 		&res.Symbol,
@@ -31,14 +32,14 @@ func (a *postgresAccessor) Token(address string) (types.Token, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) TokenHolders(address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
+func (a *postgresAccessor) TokenHolders(ctx context.Context, address string, count uint64, continuationToken *string) ([]types.TokenBalance, *string, error) {
 	parseToken := func(continuationToken *string) (address, balance *string, err error) {
 		if continuationToken == nil {
 			return
 		}
 		strs := strings.Split(*continuationToken, "-")
 		if len(strs) != 2 {
-			err = errors.New("invalid continuation token")
+			err = apierrors.New(apierrors.InvalidArgument, "invalid continuation token")
 			return
 		}
 		address = &strs[0]
@@ -49,7 +50,7 @@ func (a *postgresAccessor) TokenHolders(address string, count uint64, continuati
 	if err != nil {
 		return nil, nil, err
 	}
-	rows, err := a.db.Query(a.getQuery(tokenHoldersQuery), address, count+1, continuationTokenAddress, continuationTokenBalance)
+	rows, err := a.db.QueryContext(ctx, a.getQuery(tokenHoldersQuery), address, count+1, continuationTokenAddress, continuationTokenBalance)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/lib/pq"
@@ -14,8 +15,8 @@ const (
 	upgradeVotingsQuery       = "upgradeVotings.sql"
 )
 
-func (a *postgresAccessor) Upgrades(count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
-	res, nextContinuationToken, err := a.page(upgradesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) Upgrades(ctx context.Context, count uint64, continuationToken *string) ([]types.ActivatedUpgrade, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, upgradesQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.ActivatedUpgrade
 		var height uint64
@@ -68,8 +69,8 @@ func (a *postgresAccessor) Upgrades(count uint64, continuationToken *string) ([]
 	return res.([]types.ActivatedUpgrade), nextContinuationToken, nil
 }
 
-func (a *postgresAccessor) UpgradeVotingHistory(upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error) {
-	rows, err := a.db.Query(a.getQuery(upgradeVotingHistoryQuery), upgrade)
+func (a *postgresAccessor) UpgradeVotingHistory(ctx context.Context, upgrade uint64) ([]*types.UpgradeVotingHistoryItem, error) {
+	rows, err := a.db.QueryContext(ctx, a.getQuery(upgradeVotingHistoryQuery), upgrade)
 	if err != nil {
 		return nil, err
 	}
@@ -93,10 +94,10 @@ func (a *postgresAccessor) UpgradeVotingHistory(upgrade uint64) ([]*types.Upgrad
 	return res, nil
 }
 
-func (a *postgresAccessor) Upgrade(upgrade uint64) (*types.Upgrade, error) {
+func (a *postgresAccessor) Upgrade(ctx context.Context, upgrade uint64) (*types.Upgrade, error) {
 	res := &types.Upgrade{}
 	var startActivationDate, endActivationDate int64
-	err := a.db.QueryRow(a.getQuery(upgradeQuery), upgrade).Scan(
+	err := a.db.QueryRowContext(ctx, a.getQuery(upgradeQuery), upgrade).Scan(
 		&startActivationDate,
 		&endActivationDate,
 	)
@@ -117,8 +118,8 @@ func (a *postgresAccessor) Upgrade(upgrade uint64) (*types.Upgrade, error) {
 	return res, nil
 }
 
-func (a *postgresAccessor) UpgradeVotings(count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
-	res, nextContinuationToken, err := a.page(upgradeVotingsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
+func (a *postgresAccessor) UpgradeVotings(ctx context.Context, count uint64, continuationToken *string) ([]types.Upgrade, *string, error) {
+	res, nextContinuationToken, err := a.page(ctx, upgradeVotingsQuery, func(rows *sql.Rows) (interface{}, uint64, error) {
 		defer rows.Close()
 		var res []types.Upgrade
 		var upgrade uint64
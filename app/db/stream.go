@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/types"
+)
+
+// RowWriter receives items of a page as soon as they are read
+type RowWriter func(item interface{}) error
+
+// Streamer writes pages of the largest lists row by row instead of collecting them into slices,
+// the cached accessor writes rows of cached pages so that streamed pages share the cache with other formats
+type Streamer interface {
+	StreamEpochTxs(ctx context.Context, epoch uint64, count uint64, continuationToken *string, w RowWriter) (*string, error)
+	StreamBlockTxsByHeight(ctx context.Context, height uint64, count uint64, continuationToken *string, w RowWriter) (*string, error)
+	StreamBlockTxsByHash(ctx context.Context, hash string, count uint64, continuationToken *string, w RowWriter) (*string, error)
+	StreamIdentityTxs(ctx context.Context, address string, count uint64, continuationToken *string, w RowWriter) (*string, error)
+}
+
+// WriteTxs writes an already loaded page
+func WriteTxs(w RowWriter) func([]types.TransactionSummary, *string, error) (*string, error) {
+	return func(txs []types.TransactionSummary, nextContinuationToken *string, err error) (*string, error) {
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range txs {
+			if err := w(tx); err != nil {
+				return nil, err
+			}
+		}
+		return nextContinuationToken, nil
+	}
+}
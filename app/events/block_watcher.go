@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	blockWatcherDelay = time.Second * 2
+	maxBlocksPerCheck = 10
+	blockTxsPageSize  = 100
+)
+
+type BlockWatcher struct {
+	accessor db.Accessor
+	bus      Bus
+	height   uint64
+	logger   log.Logger
+}
+
+// NewBlockWatcher polls the last block and publishes new block and balance change events
+func NewBlockWatcher(accessor db.Accessor, bus Bus, lc *lifecycle.Manager, logger log.Logger) *BlockWatcher {
+	w := &BlockWatcher{
+		accessor: accessor,
+		bus:      bus,
+		logger:   logger,
+	}
+	lc.Go(w.loop)
+	return w
+}
+
+func (w *BlockWatcher) loop(ctx context.Context) {
+	for lifecycle.Sleep(ctx, blockWatcherDelay) {
+		w.check(ctx)
+	}
+}
+
+func (w *BlockWatcher) check(ctx context.Context) {
+	lastBlock, err := w.accessor.LastBlock(ctx)
+	if err != nil {
+		w.logger.Warn(errors.Wrap(err, "Unable to get last block from db to detect new one").Error())
+		return
+	}
+	if w.height == 0 {
+		w.height = lastBlock.Height
+		return
+	}
+	if lastBlock.Height <= w.height {
+		return
+	}
+	from := w.height + 1
+	if lastBlock.Height-w.height > maxBlocksPerCheck {
+		from = lastBlock.Height - maxBlocksPerCheck + 1
+	}
+	for height := from; height <= lastBlock.Height; height++ {
+		if err := w.publishBlock(ctx, height); err != nil {
+			w.logger.Warn(errors.Wrapf(err, "Unable to publish block %v", height).Error())
+			return
+		}
+		w.height = height
+	}
+}
+
+func (w *BlockWatcher) publishBlock(ctx context.Context, height uint64) error {
+	block, err := w.accessor.BlockByHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	coins, err := w.accessor.BlockCoinsByHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	addresses := make(map[string]struct{})
+	addAddress := func(address string) {
+		if len(address) > 0 {
+			addresses[strings.ToLower(address)] = struct{}{}
+		}
+	}
+	addAddress(block.Proposer)
+	var continuationToken *string
+	for {
+		var txs []types.TransactionSummary
+		txs, continuationToken, err = w.accessor.BlockTxsByHeight(ctx, height, blockTxsPageSize, continuationToken)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			addAddress(tx.From)
+			addAddress(tx.To)
+		}
+		if continuationToken == nil {
+			break
+		}
+	}
+	w.bus.Publish(Event{
+		Topic: NewBlockTopic,
+		Data:  toBlockSummary(block, coins),
+	})
+	if len(addresses) > 0 {
+		event := Event{
+			Topic:     BalanceChangeTopic,
+			Addresses: make([]string, 0, len(addresses)),
+			Data:      height,
+		}
+		for address := range addresses {
+			event.Addresses = append(event.Addresses, address)
+		}
+		w.bus.Publish(event)
+	}
+	w.logger.Debug(fmt.Sprintf("Published block %v, addresses: %v", height, len(addresses)))
+	return nil
+}
+
+func toBlockSummary(block types.BlockDetail, coins types.AllCoins) types.BlockSummary {
+	return types.BlockSummary{
+		Height:               block.Height,
+		Hash:                 block.Hash,
+		Timestamp:            block.Timestamp,
+		TxCount:              block.TxCount,
+		IsEmpty:              block.IsEmpty,
+		Coins:                coins,
+		BodySize:             block.BodySize,
+		FullSize:             block.FullSize,
+		VrfProposerThreshold: block.VrfProposerThreshold,
+		Proposer:             block.Proposer,
+		ProposerVrfScore:     block.ProposerVrfScore,
+		FeeRate:              block.FeeRate,
+		FeeRatePerByte:       block.FeeRatePerByte,
+		Flags:                block.Flags,
+		Upgrade:              block.Upgrade,
+		OfflineAddress:       block.OfflineAddress,
+		Epoch:                block.Epoch,
+	}
+}
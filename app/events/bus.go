@@ -0,0 +1,108 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type Topic string
+
+const (
+	NewBlockTopic              Topic = "newBlock"
+	NewEpochTopic              Topic = "newEpoch"
+	MemPoolTxTopic             Topic = "memPoolTx"
+	BalanceChangeTopic         Topic = "balanceChange"
+	ValidationStartedTopic     Topic = "validationStarted"
+	InterimSummaryChangedTopic Topic = "interimSummaryChanged"
+	RewardsAvailableTopic      Topic = "rewardsAvailable"
+)
+
+type Event struct {
+	// Increasing event id assigned by the bus on publishing
+	Id    uint64
+	Topic Topic
+	// Lower-case addresses the event relates to, empty for network-wide events
+	Addresses []string
+	Data      interface{}
+}
+
+func (e Event) HasAddress(address string) bool {
+	for _, eventAddress := range e.Addresses {
+		if eventAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
+type Bus interface {
+	Publish(event Event)
+	Subscribe(bufferSize int) Subscription
+}
+
+type Subscription interface {
+	Events() <-chan Event
+	Unsubscribe()
+}
+
+func NewBus() Bus {
+	return &busImpl{
+		subscriptions: make(map[uint64]*subscriptionImpl),
+		// Event ids start from the current time in milliseconds to stay increasing after restart
+		lastEventId: uint64(time.Now().UnixNano() / int64(time.Millisecond)),
+	}
+}
+
+type busImpl struct {
+	subscriptions map[uint64]*subscriptionImpl
+	counter       uint64
+	lastEventId   uint64
+	mutex         sync.Mutex
+}
+
+type subscriptionImpl struct {
+	id     uint64
+	events chan Event
+	bus    *busImpl
+	once   sync.Once
+}
+
+// Publish delivers the event to every subscription without blocking, slow subscribers miss events
+func (bus *busImpl) Publish(event Event) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	bus.lastEventId++
+	event.Id = bus.lastEventId
+	for _, subscription := range bus.subscriptions {
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+func (bus *busImpl) Subscribe(bufferSize int) Subscription {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	bus.counter++
+	subscription := &subscriptionImpl{
+		id:     bus.counter,
+		events: make(chan Event, bufferSize),
+		bus:    bus,
+	}
+	bus.subscriptions[subscription.id] = subscription
+	return subscription
+}
+
+func (subscription *subscriptionImpl) Events() <-chan Event {
+	return subscription.events
+}
+
+func (subscription *subscriptionImpl) Unsubscribe() {
+	subscription.once.Do(func() {
+		subscription.bus.mutex.Lock()
+		defer subscription.bus.mutex.Unlock()
+		delete(subscription.bus.subscriptions, subscription.id)
+		close(subscription.events)
+	})
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"sync"
+)
+
+// historyEventsBufferSize lets the history keep up with bursts of events of other topics
+const historyEventsBufferSize = 100
+
+type History struct {
+	topics map[Topic]struct{}
+	size   int
+	events []Event
+	mutex  sync.RWMutex
+}
+
+// NewHistory keeps the last published events of the given topics to let clients resume event streams
+func NewHistory(bus Bus, topics []Topic, size int, lc *lifecycle.Manager) *History {
+	h := &History{
+		topics: make(map[Topic]struct{}, len(topics)),
+		size:   size,
+	}
+	for _, topic := range topics {
+		h.topics[topic] = struct{}{}
+	}
+	subscription := bus.Subscribe(historyEventsBufferSize)
+	lc.Go(func(ctx context.Context) {
+		h.loop(ctx, subscription)
+	})
+	return h
+}
+
+func (h *History) loop(ctx context.Context, subscription Subscription) {
+	defer subscription.Unsubscribe()
+	for {
+		var event Event
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok = <-subscription.Events():
+			if !ok {
+				return
+			}
+		}
+		if !h.HasTopic(event.Topic) {
+			continue
+		}
+		h.mutex.Lock()
+		h.events = append(h.events, event)
+		if len(h.events) > h.size {
+			h.events = h.events[len(h.events)-h.size:]
+		}
+		h.mutex.Unlock()
+	}
+}
+
+func (h *History) HasTopic(topic Topic) bool {
+	_, ok := h.topics[topic]
+	return ok
+}
+
+// Since returns kept events with ids greater than the given one
+func (h *History) Since(eventId uint64) []Event {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var res []Event
+	for _, event := range h.events {
+		if event.Id > eventId {
+			res = append(res, event)
+		}
+	}
+	return res
+}
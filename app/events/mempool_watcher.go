@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	memPoolWatcherDelay    = time.Second * 2
+	memPoolWatcherTxsLimit = 1000
+)
+
+type MemPool interface {
+	GetTransactions(ctx context.Context, count int) ([]*types.TransactionSummary, error)
+}
+
+type MemPoolWatcher struct {
+	memPool MemPool
+	bus     Bus
+	hashes  map[string]struct{}
+	logger  log.Logger
+}
+
+// NewMemPoolWatcher polls the indexer mem pool and publishes an event for every new transaction
+func NewMemPoolWatcher(memPool MemPool, bus Bus, lc *lifecycle.Manager, logger log.Logger) *MemPoolWatcher {
+	w := &MemPoolWatcher{
+		memPool: memPool,
+		bus:     bus,
+		logger:  logger,
+	}
+	lc.Go(w.loop)
+	return w
+}
+
+func (w *MemPoolWatcher) loop(ctx context.Context) {
+	for lifecycle.Sleep(ctx, memPoolWatcherDelay) {
+		w.check(ctx)
+	}
+}
+
+func (w *MemPoolWatcher) check(ctx context.Context) {
+	txs, err := w.memPool.GetTransactions(ctx, memPoolWatcherTxsLimit)
+	if err != nil {
+		w.logger.Warn(errors.Wrap(err, "Unable to get mem pool txs").Error())
+		return
+	}
+	isFirst := w.hashes == nil
+	hashes := make(map[string]struct{}, len(txs))
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		hashes[tx.Hash] = struct{}{}
+		if isFirst {
+			continue
+		}
+		if _, ok := w.hashes[tx.Hash]; ok {
+			continue
+		}
+		event := Event{
+			Topic: MemPoolTxTopic,
+			Data:  tx,
+		}
+		if len(tx.From) > 0 {
+			event.Addresses = append(event.Addresses, strings.ToLower(tx.From))
+		}
+		if len(tx.To) > 0 && !strings.EqualFold(tx.From, tx.To) {
+			event.Addresses = append(event.Addresses, strings.ToLower(tx.To))
+		}
+		w.bus.Publish(event)
+	}
+	w.hashes = hashes
+}
@@ -0,0 +1,392 @@
+package exports
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound      = apierrors.New(apierrors.NotFound, "export not found")
+	ErrNotCompleted  = apierrors.New(apierrors.Conflict, "export is not completed")
+	ErrQuotaExceeded = apierrors.New(apierrors.RateLimited, "export quota exceeded")
+	ErrQueueFull     = apierrors.New(apierrors.RateLimited, "too many exports in progress")
+)
+
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Running   Status = "running"
+	Completed Status = "completed"
+	Failed    Status = "failed"
+)
+
+const (
+	// filePrefix marks files of exports so that other files of the dir are never touched
+	filePrefix    = "export-"
+	fileExtension = ".gz"
+	jobExtension  = ".json"
+	tmpExtension  = ".tmp"
+	quotaWindow   = time.Hour * 24
+)
+
+// Spec selects the rows to export, e.g. route /Epoch/10/Identities with filters {"states": "Human,Verified"}
+type Spec struct {
+	Route   string            `json:"route"`
+	Filters map[string]string `json:"filters,omitempty"`
+	Format  string            `json:"format"`
+}
+
+type Job struct {
+	Id     string `json:"id"`
+	Spec   Spec   `json:"spec"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Rows   uint64 `json:"rows"`
+	// Truncated is true if the export was stopped by the row limit
+	Truncated   bool       `json:"truncated,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+}
+
+// storedJob is the job state shared by api instances through the exports dir
+type storedJob struct {
+	Job
+	Owner string `json:"owner"`
+	// Expired jobs are kept while they count to the quota
+	Expired bool `json:"expired,omitempty"`
+}
+
+// Runner writes rows selected by the spec
+type Runner interface {
+	Validate(spec Spec) error
+	Run(ctx context.Context, spec Spec, w io.Writer, maxRows uint64) (rows uint64, truncated bool, err error)
+}
+
+type Manager interface {
+	// Create queues the export of the owner, jobsPerDay overrides the default quota if it is positive
+	Create(owner string, jobsPerDay int, spec Spec) (Job, error)
+	// Job returns the export of the owner, exports of other owners are not found
+	Job(owner, id string) (Job, error)
+	// Open returns the gzip compressed result of the completed export of the owner
+	Open(owner, id string) (*os.File, Job, error)
+}
+
+// NewManager runs exports in background workers and removes them with their files once they expire,
+// jobs are stored in the exports dir so that any api instance sharing the dir serves them
+func NewManager(conf config.ExportsConfig, runner Runner, lc *lifecycle.Manager, logger log.Logger) Manager {
+	if err := os.MkdirAll(conf.Dir, 0755); err != nil {
+		panic(errors.Wrapf(err, "unable to create exports dir %v", conf.Dir))
+	}
+	m := &manager{
+		conf:   conf,
+		runner: runner,
+		logger: logger,
+		queue:  make(chan *storedJob, conf.QueueSize),
+	}
+	for i := 0; i < conf.Workers; i++ {
+		lc.Go(m.work)
+	}
+	lc.Go(m.expireLoop)
+	return m
+}
+
+type manager struct {
+	conf   config.ExportsConfig
+	runner Runner
+	logger log.Logger
+	queue  chan *storedJob
+	// mutex serializes quota checks of the instance
+	mutex sync.Mutex
+}
+
+func (m *manager) Create(owner string, jobsPerDay int, spec Spec) (Job, error) {
+	if err := m.runner.Validate(spec); err != nil {
+		return Job{}, err
+	}
+	if jobsPerDay <= 0 {
+		jobsPerDay = m.conf.JobsPerDay
+	}
+	id, err := newId()
+	if err != nil {
+		return Job{}, err
+	}
+	now := time.Now().UTC()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	jobs, err := m.readJobs()
+	if err != nil {
+		return Job{}, err
+	}
+	var created, active int
+	for _, job := range jobs {
+		if job.Owner != owner {
+			continue
+		}
+		if now.Sub(job.CreatedAt) < quotaWindow {
+			created++
+		}
+		if job.Status == Pending || job.Status == Running {
+			active++
+		}
+	}
+	if created >= jobsPerDay || active >= m.conf.MaxActiveJobsPerClient {
+		return Job{}, ErrQuotaExceeded
+	}
+	if len(m.queue) == cap(m.queue) {
+		return Job{}, ErrQueueFull
+	}
+	job := &storedJob{
+		Job: Job{
+			Id:        id,
+			Spec:      spec,
+			Status:    Pending,
+			CreatedAt: now,
+			ExpiresAt: now.Add(m.expiry()),
+		},
+		Owner: owner,
+	}
+	// The state is written before the job is queued so that the worker updates it
+	if err := m.writeJob(job); err != nil {
+		return Job{}, err
+	}
+	select {
+	case m.queue <- job:
+	default:
+		m.removeFile(m.jobPath(id))
+		return Job{}, ErrQueueFull
+	}
+	return job.Job, nil
+}
+
+func (m *manager) Job(owner, id string) (Job, error) {
+	job, err := m.readJob(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Owner != owner || job.Expired {
+		return Job{}, ErrNotFound
+	}
+	return job.Job, nil
+}
+
+func (m *manager) Open(owner, id string) (*os.File, Job, error) {
+	job, err := m.Job(owner, id)
+	if err != nil {
+		return nil, Job{}, err
+	}
+	if job.Status != Completed {
+		return nil, job, ErrNotCompleted
+	}
+	file, err := os.Open(m.filePath(id))
+	if os.IsNotExist(err) {
+		// Expired between the calls
+		return nil, job, ErrNotFound
+	}
+	return file, job, err
+}
+
+func (m *manager) work(ctx context.Context) {
+	for {
+		var job *storedJob
+		select {
+		case <-ctx.Done():
+			return
+		case job = <-m.queue:
+		}
+		job.Status = Running
+		job.ExpiresAt = time.Now().UTC().Add(m.expiry())
+		if err := m.writeJob(job); err != nil {
+			m.logger.Warn(fmt.Sprintf("Unable to update export %v: %v", job.Id, err))
+		}
+		rows, truncated, err := m.run(ctx, &job.Job)
+		now := time.Now().UTC()
+		job.Rows = rows
+		job.Truncated = truncated
+		job.CompletedAt = &now
+		job.ExpiresAt = now.Add(m.expiry())
+		if err != nil {
+			job.Status = Failed
+			job.Error = err.Error()
+			m.logger.Warn(fmt.Sprintf("Export %v failed: %v", job.Id, err))
+		} else {
+			job.Status = Completed
+		}
+		if err := m.writeJob(job); err != nil {
+			m.logger.Warn(fmt.Sprintf("Unable to update export %v: %v", job.Id, err))
+		}
+	}
+}
+
+func (m *manager) run(ctx context.Context, job *Job) (uint64, bool, error) {
+	tmpPath := m.filePath(job.Id) + tmpExtension
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "unable to create export file")
+	}
+	defer os.Remove(tmpPath)
+	gz := gzip.NewWriter(file)
+	rows, truncated, err := m.runner.Run(ctx, job.Spec, gz, uint64(m.conf.MaxRows))
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return rows, truncated, err
+	}
+	return rows, truncated, os.Rename(tmpPath, m.filePath(job.Id))
+}
+
+func (m *manager) expireLoop(ctx context.Context) {
+	for lifecycle.Sleep(ctx, time.Minute) {
+		m.expire(time.Now())
+	}
+}
+
+// expire removes files of expired jobs, jobs left pending or running by stopped instances fail once they expire
+func (m *manager) expire(now time.Time) {
+	jobs, err := m.readJobs()
+	if err != nil {
+		m.logger.Warn(fmt.Sprintf("Unable to read exports: %v", err))
+		return
+	}
+	now = now.UTC()
+	for _, job := range jobs {
+		if now.Before(job.ExpiresAt) {
+			continue
+		}
+		if job.Status == Pending || job.Status == Running {
+			m.removeFile(m.filePath(job.Id) + tmpExtension)
+			job.Status = Failed
+			job.Error = "export was interrupted"
+			completedAt := now
+			job.CompletedAt = &completedAt
+			job.ExpiresAt = now.Add(m.expiry())
+			if err := m.writeJob(job); err != nil {
+				m.logger.Warn(fmt.Sprintf("Unable to update export %v: %v", job.Id, err))
+			}
+			continue
+		}
+		if now.Sub(job.CreatedAt) >= quotaWindow {
+			m.removeFile(m.filePath(job.Id))
+			m.removeFile(m.jobPath(job.Id))
+			continue
+		}
+		if !job.Expired {
+			m.removeFile(m.filePath(job.Id))
+			job.Expired = true
+			if err := m.writeJob(job); err != nil {
+				m.logger.Warn(fmt.Sprintf("Unable to update export %v: %v", job.Id, err))
+			}
+		}
+	}
+}
+
+func (m *manager) readJobs() ([]*storedJob, error) {
+	entries, err := os.ReadDir(m.conf.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read exports dir")
+	}
+	var res []*storedJob
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, jobExtension) {
+			continue
+		}
+		job, err := m.readJob(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), jobExtension))
+		if err == ErrNotFound {
+			// Removed by another instance
+			continue
+		}
+		if err != nil {
+			m.logger.Warn(err.Error())
+			continue
+		}
+		res = append(res, job)
+	}
+	return res, nil
+}
+
+func (m *manager) readJob(id string) (*storedJob, error) {
+	if !isValidId(id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(m.jobPath(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read export %v", id)
+	}
+	job := &storedJob{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, errors.Wrapf(err, "unable to decode export %v", id)
+	}
+	return job, nil
+}
+
+// writeJob replaces the job state atomically so that other instances never read partially written states
+func (m *manager) writeJob(job *storedJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	tmpPath := m.jobPath(job.Id) + tmpExtension
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return errors.Wrap(err, "unable to write export state")
+	}
+	return errors.Wrap(os.Rename(tmpPath, m.jobPath(job.Id)), "unable to write export state")
+}
+
+func (m *manager) removeFile(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		m.logger.Warn(fmt.Sprintf("Unable to remove export file: %v", err))
+	}
+}
+
+func (m *manager) filePath(id string) string {
+	return filepath.Join(m.conf.Dir, filePrefix+id+fileExtension)
+}
+
+func (m *manager) jobPath(id string) string {
+	return filepath.Join(m.conf.Dir, filePrefix+id+jobExtension)
+}
+
+func (m *manager) expiry() time.Duration {
+	return time.Second * time.Duration(m.conf.ExpirySec)
+}
+
+const idLength = 16
+
+func newId() (string, error) {
+	b := make([]byte, idLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isValidId prevents ids read from requests from addressing files out of the exports dir
+func isValidId(id string) bool {
+	b, err := hex.DecodeString(id)
+	return err == nil && len(b) == idLength
+}
@@ -0,0 +1,141 @@
+package exports
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/idena-network/idena-indexer-api/config"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testRunner struct {
+	release chan struct{}
+}
+
+func (runner *testRunner) Validate(spec Spec) error {
+	if spec.Route != "/epoch/1/txs" {
+		return errors.New("route cannot be exported")
+	}
+	return nil
+}
+
+func (runner *testRunner) Run(ctx context.Context, spec Spec, w io.Writer, maxRows uint64) (uint64, bool, error) {
+	<-runner.release
+	_, err := w.Write([]byte("row\n"))
+	return 1, false, err
+}
+
+func Test_manager(t *testing.T) {
+	runner := &testRunner{release: make(chan struct{})}
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "other.gz"), nil, 0644))
+	conf := config.ExportsConfig{
+		Dir:                    dir,
+		Workers:                1,
+		QueueSize:              10,
+		ExpirySec:              60,
+		MaxActiveJobsPerClient: 1,
+		JobsPerDay:             2,
+	}
+	m := NewManager(conf, runner, nil, log.New()).(*manager)
+	spec := Spec{Route: "/epoch/1/txs", Format: "csv"}
+
+	_, err := m.Create("client1", 0, Spec{Route: "/epoch/1/identities"})
+	require.NotNil(t, err)
+
+	job, err := m.Create("client1", 0, spec)
+	require.Nil(t, err)
+	require.Equal(t, Pending, job.Status)
+
+	_, err = m.Create("client1", 0, spec)
+	require.Equal(t, ErrQuotaExceeded, err)
+
+	_, _, err = m.Open("client1", job.Id)
+	require.Equal(t, ErrNotCompleted, err)
+
+	runner.release <- struct{}{}
+	require.Eventually(t, func() bool {
+		job, _ = m.Job("client1", job.Id)
+		return job.Status == Completed
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, uint64(1), job.Rows)
+
+	// Jobs are bound to their owners
+	_, err = m.Job("client2", job.Id)
+	require.Equal(t, ErrNotFound, err)
+	_, _, err = m.Open("client2", job.Id)
+	require.Equal(t, ErrNotFound, err)
+	_, err = m.Job("client1", "../other")
+	require.Equal(t, ErrNotFound, err)
+
+	// Instances sharing the dir serve jobs of each other
+	conf.Workers = 0
+	m2 := NewManager(conf, runner, nil, log.New())
+	job2, err := m2.Job("client1", job.Id)
+	require.Nil(t, err)
+	require.Equal(t, job, job2)
+
+	file, _, err := m.Open("client1", job.Id)
+	require.Nil(t, err)
+	gz, err := gzip.NewReader(file)
+	require.Nil(t, err)
+	content, err := io.ReadAll(gz)
+	require.Nil(t, err)
+	require.Equal(t, "row\n", string(content))
+	file.Close()
+
+	// The daily quota counts completed jobs too
+	job2, err = m.Create("client1", 0, spec)
+	require.Nil(t, err)
+	runner.release <- struct{}{}
+	_, err = m.Create("client1", 0, spec)
+	require.Equal(t, ErrQuotaExceeded, err)
+	_, err = m.Create("client2", 0, spec)
+	require.Nil(t, err)
+	close(runner.release)
+	require.Eventually(t, func() bool {
+		job2, _ = m.Job("client1", job2.Id)
+		return job2.Status == Completed
+	}, time.Second, time.Millisecond*10)
+
+	m.expire(job.ExpiresAt)
+	_, err = m.Job("client1", job.Id)
+	require.Equal(t, ErrNotFound, err)
+	_, err = os.Stat(m.filePath(job.Id))
+	require.True(t, os.IsNotExist(err))
+	// Files of other services are kept
+	_, err = os.Stat(filepath.Join(dir, "other.gz"))
+	require.Nil(t, err)
+
+	// Expired jobs still count to the quota
+	_, err = m.Create("client1", 0, spec)
+	require.Equal(t, ErrQuotaExceeded, err)
+	m.expire(job.CreatedAt.Add(quotaWindow))
+	_, err = m.Create("client1", 0, spec)
+	require.Nil(t, err)
+}
+
+func Test_manager_interruptedJob(t *testing.T) {
+	m := NewManager(config.ExportsConfig{
+		Dir:                    t.TempDir(),
+		QueueSize:              1,
+		ExpirySec:              60,
+		MaxActiveJobsPerClient: 1,
+		JobsPerDay:             1,
+	}, &testRunner{}, nil, log.New()).(*manager)
+
+	// No workers, the job is left pending as if its instance was stopped
+	job, err := m.Create("client1", 0, Spec{Route: "/epoch/1/txs"})
+	require.Nil(t, err)
+	m.expire(job.ExpiresAt)
+	job, err = m.Job("client1", job.Id)
+	require.Nil(t, err)
+	require.Equal(t, Failed, job.Status)
+	require.Equal(t, "export was interrupted", job.Error)
+}
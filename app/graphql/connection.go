@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+type connection struct {
+	Edges    []edge        `json:"edges"`
+	Nodes    []interface{} `json:"nodes"`
+	PageInfo pageInfo      `json:"pageInfo"`
+}
+
+type edge struct {
+	Node interface{} `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+type pageLoader func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error)
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"endCursor": &graphql.Field{
+			Type:        graphql.String,
+			Description: "continuation token to pass as `after` to get the next page",
+		},
+	},
+})
+
+func (b *schemaBuilder) connectionType(nodeType *graphql.Object) *graphql.Object {
+	if res, ok := b.connectionTypes[nodeType.Name()]; ok {
+		return res
+	}
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: nodeType.Name() + "Edge",
+		Fields: graphql.Fields{
+			"node": &graphql.Field{Type: nodeType},
+		},
+	})
+	res := graphql.NewObject(graphql.ObjectConfig{
+		Name: nodeType.Name() + "Connection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewList(edgeType)},
+			"nodes":    &graphql.Field{Type: graphql.NewList(nodeType)},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+	b.connectionTypes[nodeType.Name()] = res
+	return res
+}
+
+func connectionArgs(extraArgs graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	res := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: defaultPageSize,
+			Description:  "items to take",
+		},
+		"after": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "continuation token to get next page items",
+		},
+	}
+	for name, arg := range extraArgs {
+		res[name] = arg
+	}
+	return res
+}
+
+func validatePageSize(first int) error {
+	if first < 0 {
+		return errors.Errorf("wrong value first=%d", first)
+	}
+	if first > maxPageSize {
+		return errors.Errorf("too big value first=%d", first)
+	}
+	return nil
+}
+
+func readPageArgs(args map[string]interface{}) (uint64, *string, error) {
+	first, _ := args["first"].(int)
+	if err := validatePageSize(first); err != nil {
+		return 0, nil, err
+	}
+	var continuationToken *string
+	if after, ok := args["after"].(string); ok && len(after) > 0 {
+		continuationToken = &after
+	}
+	return uint64(first), continuationToken, nil
+}
+
+func newConnection(items interface{}, continuationToken *string) *connection {
+	res := &connection{
+		PageInfo: pageInfo{
+			HasNextPage: continuationToken != nil,
+			EndCursor:   continuationToken,
+		},
+	}
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		return res
+	}
+	res.Edges = make([]edge, 0, value.Len())
+	res.Nodes = make([]interface{}, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		node := value.Index(i).Interface()
+		res.Edges = append(res.Edges, edge{Node: node})
+		res.Nodes = append(res.Nodes, node)
+	}
+	return res
+}
+
+func (b *schemaBuilder) connectionField(nodeType *graphql.Object, description string, extraArgs graphql.FieldConfigArgument, load pageLoader) *graphql.Field {
+	return &graphql.Field{
+		Type:        b.connectionType(nodeType),
+		Description: description,
+		Args:        connectionArgs(extraArgs),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			count, continuationToken, err := readPageArgs(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			items, nextContinuationToken, err := load(p, count, continuationToken)
+			if err != nil {
+				return nil, err
+			}
+			return newConnection(items, nextContinuationToken), nil
+		},
+	}
+}
@@ -0,0 +1,247 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// listCostFactor is the estimated size of lists which are not paginated, e.g. flips of an epoch identity
+	listCostFactor = defaultPageSize
+	// maxQueryComplexity caps the estimation of deeply nested queries instead of overflowing
+	maxQueryComplexity = math.MaxInt32
+)
+
+// CostMeter is charged with the query complexity before the query is executed
+type CostMeter interface {
+	Charge(complexity int) error
+}
+
+// complexityCalculator estimates the number of db calls of a query, every field having a resolver makes a db call
+// once per item of the lists containing it, connection lists have the requested page size
+type complexityCalculator struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// err is the first wrong page size of the query, the query is rejected before it is charged
+	err error
+}
+
+// queryComplexity returns the error if a connection is requested with the page size readPageArgs rejects, otherwise
+// negative page sizes would decrease the complexity of sibling fields
+func queryComplexity(schema *graphql.Schema, document *ast.Document, operationName string, variables map[string]interface{}) (int, error) {
+	c := &complexityCalculator{
+		fragments: documentFragments(document),
+		variables: variables,
+	}
+	var res int
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if len(operationName) > 0 && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+		if complexity := c.selectionSetComplexity(schema, schema.QueryType(), operation.SelectionSet, listCostFactor, make(map[string]struct{})); complexity > res {
+			res = complexity
+		}
+	}
+	if c.err != nil {
+		return 0, c.err
+	}
+	return res, nil
+}
+
+func (c *complexityCalculator) selectionSetComplexity(
+	schema *graphql.Schema,
+	parentType *graphql.Object,
+	selectionSet *ast.SelectionSet,
+	listSize int,
+	visitedFragments map[string]struct{},
+) int {
+	if parentType == nil || selectionSet == nil {
+		return 0
+	}
+	var res int
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name == nil || strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			fieldDefinition, ok := parentType.Fields()[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			if fieldDefinition.Resolve != nil {
+				res++
+			}
+			childListSize := listCostFactor
+			if hasArgument(fieldDefinition, "first") {
+				childListSize = c.intArgument(selection, "first", defaultPageSize)
+				if err := validatePageSize(childListSize); err != nil {
+					if c.err == nil {
+						c.err = err
+					}
+					childListSize = 0
+				}
+			}
+			childType, _ := graphql.GetNamed(fieldDefinition.Type).(*graphql.Object)
+			complexity := c.selectionSetComplexity(schema, childType, selection.SelectionSet, childListSize, visitedFragments)
+			if isList(fieldDefinition.Type) {
+				complexity = multiplyComplexity(complexity, listSize)
+			}
+			res = addComplexity(res, complexity)
+		case *ast.InlineFragment:
+			fragmentType := parentType
+			if selection.TypeCondition != nil && selection.TypeCondition.Name != nil {
+				fragmentType, _ = schema.Type(selection.TypeCondition.Name.Value).(*graphql.Object)
+			}
+			res = addComplexity(res, c.selectionSetComplexity(schema, fragmentType, selection.SelectionSet, listSize, visitedFragments))
+		case *ast.FragmentSpread:
+			if selection.Name == nil {
+				continue
+			}
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if _, visited := visitedFragments[name]; !ok || visited {
+				continue
+			}
+			fragmentType := parentType
+			if fragment.TypeCondition != nil && fragment.TypeCondition.Name != nil {
+				fragmentType, _ = schema.Type(fragment.TypeCondition.Name.Value).(*graphql.Object)
+			}
+			visitedFragments[name] = struct{}{}
+			res = addComplexity(res, c.selectionSetComplexity(schema, fragmentType, fragment.SelectionSet, listSize, visitedFragments))
+			delete(visitedFragments, name)
+		}
+	}
+	return res
+}
+
+func addComplexity(a, b int) int {
+	if a > maxQueryComplexity-b {
+		return maxQueryComplexity
+	}
+	return a + b
+}
+
+func multiplyComplexity(complexity, listSize int) int {
+	if listSize > 0 && complexity > maxQueryComplexity/listSize {
+		return maxQueryComplexity
+	}
+	return complexity * listSize
+}
+
+// intArgument returns the argument value given either literally or by a variable
+func (c *complexityCalculator) intArgument(field *ast.Field, name string, defaultValue int) int {
+	for _, argument := range field.Arguments {
+		if argument.Name == nil || argument.Name.Value != name {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if res, err := strconv.Atoi(value.Value); err == nil {
+				return res
+			}
+		case *ast.Variable:
+			if value.Name == nil {
+				break
+			}
+			switch variable := c.variables[value.Name.Value].(type) {
+			case int:
+				return variable
+			case float64:
+				return int(variable)
+			}
+		}
+	}
+	return defaultValue
+}
+
+func hasArgument(fieldDefinition *graphql.FieldDefinition, name string) bool {
+	for _, argument := range fieldDefinition.Args {
+		if argument.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+func documentFragments(document *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return fragments
+}
+
+func checkDepth(document *ast.Document, operationName string, maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	fragments := documentFragments(document)
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if len(operationName) > 0 && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+		if depth := selectionSetDepth(operation.SelectionSet, fragments, make(map[string]struct{})); depth > maxDepth {
+			return errors.Errorf("query depth %d exceeds limit %d", depth, maxDepth)
+		}
+	}
+	return nil
+}
+
+func selectionSetDepth(selectionSet *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visitedFragments map[string]struct{}) int {
+	if selectionSet == nil {
+		return 0
+	}
+	var res int
+	for _, selection := range selectionSet.Selections {
+		var depth int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			// Introspection does not touch db
+			if selection.Name != nil && strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			depth = 1 + selectionSetDepth(selection.SelectionSet, fragments, visitedFragments)
+		case *ast.InlineFragment:
+			depth = selectionSetDepth(selection.SelectionSet, fragments, visitedFragments)
+		case *ast.FragmentSpread:
+			if selection.Name == nil {
+				continue
+			}
+			name := selection.Name.Value
+			fragment, ok := fragments[name]
+			if _, visited := visitedFragments[name]; !ok || visited {
+				continue
+			}
+			visitedFragments[name] = struct{}{}
+			depth = selectionSetDepth(fragment.SelectionSet, fragments, visitedFragments)
+			delete(visitedFragments, name)
+		}
+		if depth > res {
+			res = depth
+		}
+	}
+	return res
+}
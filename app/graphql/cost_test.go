@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_queryComplexity(t *testing.T) {
+	schema, err := newSchema(nil)
+	require.Nil(t, err)
+	complexity := func(query, operationName string, variables map[string]interface{}) int {
+		document, err := parser.Parse(parser.ParseParams{Source: query})
+		require.Nil(t, err)
+		res, err := queryComplexity(&schema, document, operationName, variables)
+		require.Nil(t, err)
+		return res
+	}
+
+	require.Equal(t, 1, complexity(`{ lastEpoch { epoch validationTime } }`, "", nil))
+	require.Equal(t, 2, complexity(`{ lastEpoch { epoch } lastBlock { height } __typename }`, "", nil))
+
+	// Nested resolvers are charged once per item of the requested page
+	require.Equal(t, 1+5*(1+3), complexity(`{
+		epochs(first: 5) { nodes { epoch blocks(first: 3) { nodes { height proposerInfo { balance } } } } }
+	}`, "", nil))
+	require.Equal(t, 1+defaultPageSize, complexity(`{ epochs { edges { node { coins { minted } } } } }`, "", nil))
+	require.Equal(t, 1+2*(1+7), complexity(`query Q($n: Int) {
+		epochs(first: 2) { nodes { ...epochBlocks } }
+	}
+	fragment epochBlocks on Epoch { blocks(first: $n) { nodes { ... on Block { epochInfo { epoch } } } } }`,
+		"Q", map[string]interface{}{"n": float64(7)}))
+
+	// Lists which are not paginated are charged by the estimated size
+	require.Equal(t, 1+1+2*(1+listCostFactor), complexity(`{
+		epoch(epoch: 1) { identities(first: 2) { nodes { flips { authorInfo { state } } } } }
+	}`, "", nil))
+
+	// Only the requested operation is charged
+	require.Equal(t, 1, complexity(`query A { lastEpoch { epoch } } query B { lastEpoch { epoch } lastBlock { height } }`, "A", nil))
+
+	// Wrong page sizes are rejected before the query is charged
+	complexityErr := func(query string, variables map[string]interface{}) error {
+		document, err := parser.Parse(parser.ParseParams{Source: query})
+		require.Nil(t, err)
+		_, err = queryComplexity(&schema, document, "", variables)
+		return err
+	}
+	err = complexityErr(`{ epochs(first: 5) { nodes { epoch blocks(first: 3) { nodes { height } } } } lastBlock { height } blocks: epochs(first: -100000) { nodes { epoch } } }`, nil)
+	require.EqualError(t, err, "wrong value first=-100000")
+	err = complexityErr(`query Q($n: Int) { epochs(first: $n) { nodes { epoch } } }`, map[string]interface{}{"n": float64(1 << 40)})
+	require.EqualError(t, err, "too big value first=1099511627776")
+}
+
+func Test_multiplyComplexity(t *testing.T) {
+	require.Equal(t, 300, multiplyComplexity(3, 100))
+	require.Equal(t, maxQueryComplexity, multiplyComplexity(maxQueryComplexity/10, 100))
+	require.Equal(t, maxQueryComplexity, addComplexity(maxQueryComplexity, 1))
+}
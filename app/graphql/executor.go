@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/idena-network/idena-indexer-api/app/db"
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Executor interface {
+	// Execute returns the error if the query is not charged to the meter, query errors are returned within the result
+	Execute(ctx context.Context, request Request, meter CostMeter) (*graphql.Result, error)
+}
+
+type executorImpl struct {
+	schema   graphql.Schema
+	maxDepth int
+}
+
+func NewExecutor(accessor db.Accessor, maxDepth int) (Executor, error) {
+	schema, err := newSchema(accessor)
+	if err != nil {
+		return nil, err
+	}
+	return &executorImpl{
+		schema:   schema,
+		maxDepth: maxDepth,
+	}, nil
+}
+
+// Execute validates the query depth and charges the query complexity to the meter before running the query
+func (e *executorImpl) Execute(ctx context.Context, request Request, meter CostMeter) (*graphql.Result, error) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(request.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+	validationResult := graphql.ValidateDocument(&e.schema, document, nil)
+	if !validationResult.IsValid {
+		return &graphql.Result{Errors: validationResult.Errors}, nil
+	}
+	if err := checkDepth(document, request.OperationName, e.maxDepth); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+	complexity, err := queryComplexity(&e.schema, document, request.OperationName, request.Variables)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, nil
+	}
+	if err := meter.Charge(complexity); err != nil {
+		return nil, err
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	}), nil
+}
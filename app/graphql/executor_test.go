@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/idena-network/idena-indexer-api/app/db/memory"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+type testCostMeter struct {
+	complexity int
+	err        error
+}
+
+func (m *testCostMeter) Charge(complexity int) error {
+	m.complexity = complexity
+	return m.err
+}
+
+func newTestExecutor(t *testing.T) Executor {
+	accessor := memory.NewMemoryAccessor(filepath.Join("..", "db", "memory", "testdata"), log.New())
+	executor, err := NewExecutor(accessor, 6)
+	require.Nil(t, err)
+	return executor
+}
+
+func execute(t *testing.T, executor Executor, query string, variables map[string]interface{}) (string, int) {
+	meter := &testCostMeter{}
+	result, err := executor.Execute(context.Background(), Request{Query: query, Variables: variables}, meter)
+	require.Nil(t, err)
+	data, err := json.Marshal(result)
+	require.Nil(t, err)
+	return string(data), meter.complexity
+}
+
+func Test_executor_resolvers(t *testing.T) {
+	executor := newTestExecutor(t)
+
+	data, complexity := execute(t, executor, `{ lastEpoch { epoch } epoch(epoch: 1) { epoch blocks(first: 1) { nodes { height } pageInfo { hasNextPage } } } }`, nil)
+	require.JSONEq(t, `{"data":{
+		"lastEpoch":{"epoch":2},
+		"epoch":{"epoch":1,"blocks":{"nodes":[{"height":11}],"pageInfo":{"hasNextPage":true}}}
+	}}`, data)
+	require.Equal(t, 3, complexity)
+
+	data, _ = execute(t, executor, `query($hash: String!) { transaction(hash: $hash) { hash amount fromInfo { address balance } toInfo { address } } }`,
+		map[string]interface{}{"hash": "0x01"})
+	require.JSONEq(t, `{"data":{"transaction":{"hash":"0x01","amount":"1","fromInfo":{"address":"0xAA","balance":"100"},"toInfo":{"address":"0xBB"}}}}`, data)
+
+	// Missing data is resolved to null
+	data, _ = execute(t, executor, `{ identity(address: "0xCC") { state } }`, nil)
+	require.JSONEq(t, `{"data":{"identity":null}}`, data)
+
+	data, _ = execute(t, executor, `{ address(address: "0xaa") { transactions(first: 2) { edges { node { hash } } pageInfo { endCursor } } } }`, nil)
+	require.JSONEq(t, `{"data":{"address":{"transactions":{"edges":[{"node":{"hash":"0x02"}},{"node":{"hash":"0x03"}}],"pageInfo":{"endCursor":"2"}}}}}`, data)
+
+	data, _ = execute(t, executor, `{ epochs(first: 101) { nodes { epoch } } }`, nil)
+	require.Contains(t, data, "too big value first=101")
+}
+
+func Test_executor_charge(t *testing.T) {
+	executor := newTestExecutor(t)
+
+	// Queries are not executed if the meter rejects their complexity
+	meter := &testCostMeter{err: errors.New("limit exceeded")}
+	result, err := executor.Execute(context.Background(), Request{Query: `{ epochs(first: 100) { nodes { coins { burnt } } } }`}, meter)
+	require.Nil(t, result)
+	require.Equal(t, meter.err, err)
+	require.Equal(t, 101, meter.complexity)
+
+	// Invalid queries are neither executed nor charged
+	meter = &testCostMeter{}
+	result, err = executor.Execute(context.Background(), Request{Query: `{ unknown }`}, meter)
+	require.Nil(t, err)
+	require.NotEmpty(t, result.Errors)
+	require.Zero(t, meter.complexity)
+
+	result, err = executor.Execute(context.Background(), Request{
+		Query: `{ lastBlock { epochInfo { blocks { nodes { proposerInfo { identityInfo { state } } } } } } }`,
+	}, meter)
+	require.Nil(t, err)
+	require.Contains(t, result.Errors[0].Message, "query depth 7 exceeds limit 6")
+	require.Zero(t, meter.complexity)
+
+	result, err = executor.Execute(context.Background(), Request{
+		Query: `{ lastBlock { height } epochs(first: -100000) { nodes { coins { burnt } } } }`,
+	}, meter)
+	require.Nil(t, err)
+	require.Nil(t, result.Data)
+	require.Contains(t, result.Errors[0].Message, "wrong value first=-100000")
+	require.Zero(t, meter.complexity)
+}
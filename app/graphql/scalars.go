@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/shopspring/decimal"
+)
+
+var decimalType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Decimal",
+	Description: "The `Decimal` scalar type represents an arbitrary precision number serialized as a string",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case decimal.Decimal:
+			return value.String()
+		case *decimal.Decimal:
+			if value == nil {
+				return nil
+			}
+			return value.String()
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			if res, err := decimal.NewFromString(s); err == nil {
+				return res
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if value, ok := valueAST.(*ast.StringValue); ok {
+			if res, err := decimal.NewFromString(value.Value); err == nil {
+				return res
+			}
+		}
+		return nil
+	},
+})
@@ -0,0 +1,797 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/db/postgres"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/pkg/errors"
+)
+
+type schemaBuilder struct {
+	accessor        db.Accessor
+	connectionTypes map[string]*graphql.Object
+
+	coinsType          *graphql.Object
+	answersSummaryType *graphql.Object
+	epochType          *graphql.Object
+	blockType          *graphql.Object
+	identityType       *graphql.Object
+	epochIdentityType  *graphql.Object
+	addressType        *graphql.Object
+	flipType           *graphql.Object
+	transactionType    *graphql.Object
+	rewardType         *graphql.Object
+	epochRewardsType   *graphql.Object
+	contractType       *graphql.Object
+	poolType           *graphql.Object
+	delegatorType      *graphql.Object
+	tokenType          *graphql.Object
+	tokenBalanceType   *graphql.Object
+}
+
+// flipWithCid adds the hash to the flip loaded by hash since types.Flip does not contain it
+type flipWithCid struct {
+	cid  string
+	flip types.Flip
+}
+
+func (f flipWithCid) Resolve(p graphql.ResolveParams) (interface{}, error) {
+	if p.Info.FieldName == "cid" {
+		return f.cid, nil
+	}
+	p.Source = f.flip
+	return graphql.DefaultResolveFn(p)
+}
+
+func newSchema(accessor db.Accessor) (graphql.Schema, error) {
+	b := &schemaBuilder{
+		accessor:        accessor,
+		connectionTypes: make(map[string]*graphql.Object),
+	}
+	b.coinsType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Coins",
+		Fields: graphql.Fields{
+			"minted":       field(decimalType),
+			"burnt":        field(decimalType),
+			"totalBalance": field(decimalType),
+			"totalStake":   field(decimalType),
+		},
+	})
+	b.answersSummaryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "AnswersSummary",
+		Fields: graphql.Fields{
+			"point":      field(graphql.Float),
+			"flipsCount": field(graphql.Int),
+		},
+	})
+	b.epochType = graphql.NewObject(graphql.ObjectConfig{Name: "Epoch", Fields: graphql.FieldsThunk(b.epochFields)})
+	b.blockType = graphql.NewObject(graphql.ObjectConfig{Name: "Block", Fields: graphql.FieldsThunk(b.blockFields)})
+	b.identityType = graphql.NewObject(graphql.ObjectConfig{Name: "Identity", Fields: graphql.FieldsThunk(b.identityFields)})
+	b.epochIdentityType = graphql.NewObject(graphql.ObjectConfig{Name: "EpochIdentity", Fields: graphql.FieldsThunk(b.epochIdentityFields)})
+	b.addressType = graphql.NewObject(graphql.ObjectConfig{Name: "Address", Fields: graphql.FieldsThunk(b.addressFields)})
+	b.flipType = graphql.NewObject(graphql.ObjectConfig{Name: "Flip", Fields: graphql.FieldsThunk(b.flipFields)})
+	b.transactionType = graphql.NewObject(graphql.ObjectConfig{Name: "Transaction", Fields: graphql.FieldsThunk(b.transactionFields)})
+	b.rewardType = graphql.NewObject(graphql.ObjectConfig{Name: "Reward", Fields: graphql.FieldsThunk(b.rewardFields)})
+	b.epochRewardsType = graphql.NewObject(graphql.ObjectConfig{Name: "EpochRewards", Fields: graphql.FieldsThunk(b.epochRewardsFields)})
+	b.contractType = graphql.NewObject(graphql.ObjectConfig{Name: "Contract", Fields: graphql.FieldsThunk(b.contractFields)})
+	b.poolType = graphql.NewObject(graphql.ObjectConfig{Name: "Pool", Fields: graphql.FieldsThunk(b.poolFields)})
+	b.delegatorType = graphql.NewObject(graphql.ObjectConfig{Name: "Delegator", Fields: graphql.FieldsThunk(b.delegatorFields)})
+	b.tokenType = graphql.NewObject(graphql.ObjectConfig{Name: "Token", Fields: graphql.FieldsThunk(b.tokenFields)})
+	b.tokenBalanceType = graphql.NewObject(graphql.ObjectConfig{Name: "TokenBalance", Fields: graphql.FieldsThunk(b.tokenBalanceFields)})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.FieldsThunk(b.queryFields)}),
+	})
+}
+
+func field(t graphql.Output) *graphql.Field {
+	return &graphql.Field{Type: t}
+}
+
+func stringArg() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}
+}
+
+func intArg() *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+}
+
+func readUintArg(args map[string]interface{}, name string) (uint64, error) {
+	value, _ := args[name].(int)
+	if value < 0 {
+		return 0, errors.Errorf("wrong value %s=%d", name, value)
+	}
+	return uint64(value), nil
+}
+
+func readStringsArg(args map[string]interface{}, name string) []string {
+	values, _ := args[name].([]interface{})
+	var res []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// load converts missing data to null
+func load(p graphql.ResolveParams, fn func() (interface{}, error)) (interface{}, error) {
+	res, err := fn()
+	if err == postgres.NoDataFound {
+		return nil, nil
+	}
+	return res, err
+}
+
+func (b *schemaBuilder) queryFields() graphql.Fields {
+	return graphql.Fields{
+		"lastEpoch": &graphql.Field{
+			Type: b.epochType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return load(p, func() (interface{}, error) {
+					return b.accessor.LastEpoch(p.Context)
+				})
+			},
+		},
+		"epoch": &graphql.Field{
+			Type: b.epochType,
+			Args: graphql.FieldConfigArgument{"epoch": intArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				epoch, err := readUintArg(p.Args, "epoch")
+				if err != nil {
+					return nil, err
+				}
+				return b.epoch(p, epoch)
+			},
+		},
+		"epochs": b.connectionField(b.epochType, "epochs in descending order", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.Epochs(p.Context, count, continuationToken)
+			}),
+		"lastBlock": &graphql.Field{
+			Type: b.blockType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return load(p, func() (interface{}, error) {
+					return b.accessor.LastBlock(p.Context)
+				})
+			},
+		},
+		"block": &graphql.Field{
+			Type: b.blockType,
+			Args: graphql.FieldConfigArgument{
+				"height": &graphql.ArgumentConfig{Type: graphql.Int},
+				"hash":   &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if hash, ok := p.Args["hash"].(string); ok {
+					return load(p, func() (interface{}, error) {
+						return b.accessor.BlockByHash(p.Context, hash)
+					})
+				}
+				if _, ok := p.Args["height"]; !ok {
+					return nil, errors.New("height or hash required")
+				}
+				height, err := readUintArg(p.Args, "height")
+				if err != nil {
+					return nil, err
+				}
+				return b.block(p, height)
+			},
+		},
+		"identity": &graphql.Field{
+			Type: b.identityType,
+			Args: graphql.FieldConfigArgument{"address": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.identity(p, p.Args["address"].(string))
+			},
+		},
+		"address": &graphql.Field{
+			Type: b.addressType,
+			Args: graphql.FieldConfigArgument{"address": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.address(p, p.Args["address"].(string))
+			},
+		},
+		"flip": &graphql.Field{
+			Type: b.flipType,
+			Args: graphql.FieldConfigArgument{"hash": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				hash := p.Args["hash"].(string)
+				return load(p, func() (interface{}, error) {
+					flip, err := b.accessor.Flip(p.Context, hash)
+					if err != nil {
+						return nil, err
+					}
+					return flipWithCid{cid: hash, flip: flip}, nil
+				})
+			},
+		},
+		"transaction": &graphql.Field{
+			Type: b.transactionType,
+			Args: graphql.FieldConfigArgument{"hash": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				hash := p.Args["hash"].(string)
+				return load(p, func() (interface{}, error) {
+					return b.accessor.Transaction(p.Context, hash)
+				})
+			},
+		},
+		"contract": &graphql.Field{
+			Type: b.contractType,
+			Args: graphql.FieldConfigArgument{"address": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.contract(p, p.Args["address"].(string))
+			},
+		},
+		"pool": &graphql.Field{
+			Type: b.poolType,
+			Args: graphql.FieldConfigArgument{"address": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.pool(p, p.Args["address"].(string))
+			},
+		},
+		"pools": b.connectionField(b.poolType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.Pools(p.Context, count, continuationToken)
+			}),
+		"token": &graphql.Field{
+			Type: b.tokenType,
+			Args: graphql.FieldConfigArgument{"address": stringArg()},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.token(p, p.Args["address"].(string))
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) epoch(p graphql.ResolveParams, epoch uint64) (interface{}, error) {
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Epoch(p.Context, epoch)
+	})
+}
+
+func (b *schemaBuilder) block(p graphql.ResolveParams, height uint64) (interface{}, error) {
+	return load(p, func() (interface{}, error) {
+		return b.accessor.BlockByHeight(p.Context, height)
+	})
+}
+
+func (b *schemaBuilder) identity(p graphql.ResolveParams, address string) (interface{}, error) {
+	if len(address) == 0 {
+		return nil, nil
+	}
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Identity(p.Context, address)
+	})
+}
+
+func (b *schemaBuilder) address(p graphql.ResolveParams, address string) (interface{}, error) {
+	if len(address) == 0 {
+		return nil, nil
+	}
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Address(p.Context, address)
+	})
+}
+
+func (b *schemaBuilder) contract(p graphql.ResolveParams, address string) (interface{}, error) {
+	if len(address) == 0 {
+		return nil, nil
+	}
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Contract(p.Context, address)
+	})
+}
+
+func (b *schemaBuilder) pool(p graphql.ResolveParams, address string) (interface{}, error) {
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Pool(p.Context, address)
+	})
+}
+
+func (b *schemaBuilder) token(p graphql.ResolveParams, address string) (interface{}, error) {
+	return load(p, func() (interface{}, error) {
+		return b.accessor.Token(p.Context, address)
+	})
+}
+
+func epochNumber(source interface{}) uint64 {
+	switch source := source.(type) {
+	case types.EpochDetail:
+		return source.Epoch
+	case types.EpochSummary:
+		return source.Epoch
+	}
+	return 0
+}
+
+func (b *schemaBuilder) epochFields() graphql.Fields {
+	return graphql.Fields{
+		"epoch":                        field(graphql.NewNonNull(graphql.Int)),
+		"validationTime":               field(graphql.DateTime),
+		"validationFirstBlockHeight":   field(graphql.Int),
+		"minScoreForInvite":            field(graphql.Float),
+		"candidateCount":               field(graphql.Int),
+		"discriminationStakeThreshold": field(decimalType),
+		"validatedCount": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "available in epoch lists only",
+		},
+		"blockCount":      field(graphql.Int),
+		"emptyBlockCount": field(graphql.Int),
+		"txCount":         field(graphql.Int),
+		"inviteCount":     field(graphql.Int),
+		"flipCount":       field(graphql.Int),
+		"coins": &graphql.Field{
+			Type: b.coinsType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if summary, ok := p.Source.(types.EpochSummary); ok {
+					return summary.Coins, nil
+				}
+				return load(p, func() (interface{}, error) {
+					return b.accessor.EpochCoins(p.Context, epochNumber(p.Source))
+				})
+			},
+		},
+		"blocks": b.connectionField(b.blockType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.EpochBlocks(p.Context, epochNumber(p.Source), count, continuationToken)
+			}),
+		"flips": b.connectionField(b.flipType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.EpochFlips(p.Context, epochNumber(p.Source), count, continuationToken)
+			}),
+		"identities": b.connectionField(b.epochIdentityType, "", graphql.FieldConfigArgument{
+			"states":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+			"prevStates": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+		}, func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+			epoch := epochNumber(p.Source)
+			identities, nextContinuationToken, err := b.accessor.EpochIdentities(p.Context, epoch, readStringsArg(p.Args, "prevStates"),
+				readStringsArg(p.Args, "states"), count, continuationToken)
+			for i := range identities {
+				identities[i].Epoch = epoch
+			}
+			return identities, nextContinuationToken, err
+		}),
+		"transactions": b.connectionField(b.transactionType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.EpochTxs(p.Context, epochNumber(p.Source), count, continuationToken)
+			}),
+		"rewards": b.connectionField(b.epochRewardsType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				epoch := epochNumber(p.Source)
+				rewards, nextContinuationToken, err := b.accessor.EpochIdentitiesRewards(p.Context, epoch, count, continuationToken)
+				for i := range rewards {
+					rewards[i].Epoch = epoch
+				}
+				return rewards, nextContinuationToken, err
+			}),
+	}
+}
+
+func blockHeight(source interface{}) uint64 {
+	switch source := source.(type) {
+	case types.BlockDetail:
+		return source.Height
+	case types.BlockSummary:
+		return source.Height
+	}
+	return 0
+}
+
+func (b *schemaBuilder) blockFields() graphql.Fields {
+	return graphql.Fields{
+		"height":               field(graphql.NewNonNull(graphql.Int)),
+		"hash":                 field(graphql.String),
+		"epoch":                field(graphql.Int),
+		"timestamp":            field(graphql.DateTime),
+		"txCount":              field(graphql.Int),
+		"isEmpty":              field(graphql.Boolean),
+		"bodySize":             field(graphql.Int),
+		"fullSize":             field(graphql.Int),
+		"vrfProposerThreshold": field(graphql.Float),
+		"proposer":             field(graphql.String),
+		"proposerVrfScore":     field(graphql.Float),
+		"feeRate":              field(decimalType),
+		"feeRatePerByte":       field(decimalType),
+		"flags":                field(graphql.NewList(graphql.String)),
+		"upgrade":              field(graphql.Int),
+		"offlineAddress":       field(graphql.String),
+		"epochInfo": &graphql.Field{
+			Type: b.epochType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case types.BlockDetail:
+					return b.epoch(p, source.Epoch)
+				case types.BlockSummary:
+					return b.epoch(p, source.Epoch)
+				}
+				return nil, nil
+			},
+		},
+		"proposerInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case types.BlockDetail:
+					return b.address(p, source.Proposer)
+				case types.BlockSummary:
+					return b.address(p, source.Proposer)
+				}
+				return nil, nil
+			},
+		},
+		"coins": &graphql.Field{
+			Type: b.coinsType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if summary, ok := p.Source.(types.BlockSummary); ok {
+					return summary.Coins, nil
+				}
+				return load(p, func() (interface{}, error) {
+					return b.accessor.BlockCoinsByHeight(p.Context, blockHeight(p.Source))
+				})
+			},
+		},
+		"transactions": b.connectionField(b.transactionType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.BlockTxsByHeight(p.Context, blockHeight(p.Source), count, continuationToken)
+			}),
+	}
+}
+
+func (b *schemaBuilder) identityFields() graphql.Fields {
+	identityAddress := func(p graphql.ResolveParams) string {
+		return p.Source.(types.Identity).Address
+	}
+	return graphql.Fields{
+		"address":           field(graphql.NewNonNull(graphql.String)),
+		"state":             field(graphql.String),
+		"totalShortAnswers": field(b.answersSummaryType),
+		"age": &graphql.Field{
+			Type: graphql.Int,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return load(p, func() (interface{}, error) {
+					return b.accessor.IdentityAge(p.Context, identityAddress(p))
+				})
+			},
+		},
+		"addressInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.address(p, identityAddress(p))
+			},
+		},
+		"epochs": b.connectionField(b.epochIdentityType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				address := identityAddress(p)
+				epochs, nextContinuationToken, err := b.accessor.IdentityEpochs(p.Context, address, count, continuationToken)
+				for i := range epochs {
+					epochs[i].Address = address
+				}
+				return epochs, nextContinuationToken, err
+			}),
+		"flips": b.connectionField(b.flipType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.IdentityFlips(p.Context, identityAddress(p), count, continuationToken)
+			}),
+		"rewards": b.connectionField(b.epochRewardsType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				address := identityAddress(p)
+				rewards, nextContinuationToken, err := b.accessor.IdentityEpochRewards(p.Context, address, count, continuationToken)
+				for i := range rewards {
+					rewards[i].Address = address
+				}
+				return rewards, nextContinuationToken, err
+			}),
+	}
+}
+
+func (b *schemaBuilder) epochIdentityFields() graphql.Fields {
+	return graphql.Fields{
+		"address":               field(graphql.String),
+		"epoch":                 field(graphql.Int),
+		"prevState":             field(graphql.String),
+		"state":                 field(graphql.String),
+		"shortAnswers":          field(b.answersSummaryType),
+		"totalShortAnswers":     field(b.answersSummaryType),
+		"longAnswers":           field(b.answersSummaryType),
+		"shortAnswersCount":     field(graphql.Int),
+		"longAnswersCount":      field(graphql.Int),
+		"approved":              field(graphql.Boolean),
+		"missed":                field(graphql.Boolean),
+		"requiredFlips":         field(graphql.Int),
+		"madeFlips":             field(graphql.Int),
+		"availableFlips":        field(graphql.Int),
+		"totalValidationReward": field(decimalType),
+		"birthEpoch":            field(graphql.Int),
+		"identityInfo": &graphql.Field{
+			Type: b.identityType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.identity(p, p.Source.(types.EpochIdentity).Address)
+			},
+		},
+		"epochInfo": &graphql.Field{
+			Type: b.epochType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.epoch(p, p.Source.(types.EpochIdentity).Epoch)
+			},
+		},
+		"rewards": &graphql.Field{
+			Type: graphql.NewList(b.rewardType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				source := p.Source.(types.EpochIdentity)
+				return load(p, func() (interface{}, error) {
+					return b.accessor.EpochIdentityRewards(p.Context, source.Epoch, source.Address)
+				})
+			},
+		},
+		"flips": &graphql.Field{
+			Type: graphql.NewList(b.flipType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				source := p.Source.(types.EpochIdentity)
+				return load(p, func() (interface{}, error) {
+					return b.accessor.EpochIdentityFlips(p.Context, source.Epoch, source.Address)
+				})
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) addressFields() graphql.Fields {
+	addressValue := func(p graphql.ResolveParams) string {
+		return p.Source.(types.Address).Address
+	}
+	return graphql.Fields{
+		"address":            field(graphql.NewNonNull(graphql.String)),
+		"balance":            field(decimalType),
+		"stake":              field(decimalType),
+		"txCount":            field(graphql.Int),
+		"flipsCount":         field(graphql.Int),
+		"reportedFlipsCount": field(graphql.Int),
+		"tokenCount":         field(graphql.Int),
+		"identityInfo": &graphql.Field{
+			Type: b.identityType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.identity(p, addressValue(p))
+			},
+		},
+		"contractInfo": &graphql.Field{
+			Type: b.contractType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.contract(p, addressValue(p))
+			},
+		},
+		"transactions": b.connectionField(b.transactionType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.IdentityTxs(p.Context, addressValue(p), count, continuationToken)
+			}),
+		"tokens": b.connectionField(b.tokenBalanceType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.AddressTokens(p.Context, addressValue(p), count, continuationToken)
+			}),
+	}
+}
+
+func (b *schemaBuilder) flipFields() graphql.Fields {
+	return graphql.Fields{
+		"cid":             field(graphql.String),
+		"author":          field(graphql.String),
+		"epoch":           field(graphql.Int),
+		"timestamp":       field(graphql.DateTime),
+		"size":            field(graphql.Int),
+		"status":          field(graphql.String),
+		"answer":          field(graphql.String),
+		"wrongWordsVotes": field(graphql.Int),
+		"withPrivatePart": field(graphql.Boolean),
+		"grade":           field(graphql.Int),
+		"gradeScore":      field(graphql.Float),
+		"shortRespCount":  field(graphql.Int),
+		"longRespCount":   field(graphql.Int),
+		"txHash":          field(graphql.String),
+		"blockHeight":     field(graphql.Int),
+		"authorInfo": &graphql.Field{
+			Type: b.identityType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case types.FlipSummary:
+					return b.identity(p, source.Author)
+				case flipWithCid:
+					return b.identity(p, source.flip.Author)
+				}
+				return nil, nil
+			},
+		},
+		"epochInfo": &graphql.Field{
+			Type: b.epochType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch source := p.Source.(type) {
+				case types.FlipSummary:
+					return b.epoch(p, source.Epoch)
+				case flipWithCid:
+					return b.epoch(p, source.flip.Epoch)
+				}
+				return nil, nil
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) transactionFields() graphql.Fields {
+	addresses := func(source interface{}) (from, to string) {
+		switch source := source.(type) {
+		case types.TransactionSummary:
+			return source.From, source.To
+		case *types.TransactionSummary:
+			return source.From, source.To
+		case *types.TransactionDetail:
+			return source.From, source.To
+		}
+		return "", ""
+	}
+	return graphql.Fields{
+		"hash":        field(graphql.NewNonNull(graphql.String)),
+		"type":        field(graphql.String),
+		"timestamp":   field(graphql.DateTime),
+		"from":        field(graphql.String),
+		"to":          field(graphql.String),
+		"amount":      field(decimalType),
+		"tips":        field(decimalType),
+		"maxFee":      field(decimalType),
+		"fee":         field(decimalType),
+		"size":        field(graphql.Int),
+		"nonce":       field(graphql.Int),
+		"epoch":       field(graphql.Int),
+		"blockHeight": field(graphql.Int),
+		"blockHash":   field(graphql.String),
+		"fromInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				from, _ := addresses(p.Source)
+				return b.address(p, from)
+			},
+		},
+		"toInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				_, to := addresses(p.Source)
+				return b.address(p, to)
+			},
+		},
+		"blockInfo": &graphql.Field{
+			Type:        b.blockType,
+			Description: "available for transactions loaded by hash only",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if tx, ok := p.Source.(*types.TransactionDetail); ok && tx != nil {
+					return b.block(p, tx.BlockHeight)
+				}
+				return nil, nil
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) rewardFields() graphql.Fields {
+	return graphql.Fields{
+		"address":     field(graphql.String),
+		"epoch":       field(graphql.Int),
+		"blockHeight": field(graphql.Int),
+		"balance":     field(decimalType),
+		"stake":       field(decimalType),
+		"type":        field(graphql.String),
+	}
+}
+
+func (b *schemaBuilder) epochRewardsFields() graphql.Fields {
+	return graphql.Fields{
+		"address":   field(graphql.String),
+		"epoch":     field(graphql.Int),
+		"prevState": field(graphql.String),
+		"state":     field(graphql.String),
+		"age":       field(graphql.Int),
+		"rewards":   field(graphql.NewList(b.rewardType)),
+		"identityInfo": &graphql.Field{
+			Type: b.identityType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.identity(p, p.Source.(types.Rewards).Address)
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) contractFields() graphql.Fields {
+	return graphql.Fields{
+		"address":       field(graphql.NewNonNull(graphql.String)),
+		"type":          field(graphql.String),
+		"author":        field(graphql.String),
+		"deployTx":      field(b.transactionType),
+		"terminationTx": field(b.transactionType),
+		"token":         field(b.tokenType),
+		"authorInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.address(p, p.Source.(types.Contract).Author)
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) poolFields() graphql.Fields {
+	poolAddress := func(p graphql.ResolveParams) string {
+		return p.Source.(*types.Pool).Address
+	}
+	return graphql.Fields{
+		"address":             field(graphql.NewNonNull(graphql.String)),
+		"size":                field(graphql.Int),
+		"totalStake":          field(decimalType),
+		"totalValidatedStake": field(decimalType),
+		"addressInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.address(p, poolAddress(p))
+			},
+		},
+		"delegators": b.connectionField(b.delegatorType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.PoolDelegators(p.Context, poolAddress(p), count, continuationToken)
+			}),
+	}
+}
+
+func (b *schemaBuilder) delegatorFields() graphql.Fields {
+	return graphql.Fields{
+		"address": field(graphql.NewNonNull(graphql.String)),
+		"state":   field(graphql.String),
+		"age":     field(graphql.Int),
+		"stake":   field(decimalType),
+		"identityInfo": &graphql.Field{
+			Type: b.identityType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.identity(p, p.Source.(*types.Delegator).Address)
+			},
+		},
+	}
+}
+
+func (b *schemaBuilder) tokenFields() graphql.Fields {
+	tokenAddress := func(source interface{}) string {
+		switch source := source.(type) {
+		case types.Token:
+			return source.ContractAddress
+		case *types.Token:
+			return source.ContractAddress
+		}
+		return ""
+	}
+	return graphql.Fields{
+		"contractAddress": field(graphql.NewNonNull(graphql.String)),
+		"name":            field(graphql.String),
+		"symbol":          field(graphql.String),
+		"decimals":        field(graphql.Int),
+		"contractInfo": &graphql.Field{
+			Type: b.contractType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.contract(p, tokenAddress(p.Source))
+			},
+		},
+		"holders": b.connectionField(b.tokenBalanceType, "", nil,
+			func(p graphql.ResolveParams, count uint64, continuationToken *string) (interface{}, *string, error) {
+				return b.accessor.TokenHolders(p.Context, tokenAddress(p.Source), count, continuationToken)
+			}),
+	}
+}
+
+func (b *schemaBuilder) tokenBalanceFields() graphql.Fields {
+	return graphql.Fields{
+		"token":   field(b.tokenType),
+		"address": field(graphql.String),
+		"balance": field(decimalType),
+		"addressInfo": &graphql.Field{
+			Type: b.addressType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return b.address(p, p.Source.(types.TokenBalance).Address)
+			},
+		},
+	}
+}
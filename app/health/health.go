@@ -0,0 +1,195 @@
+package health
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// readyReportLifeTime protects dependencies from probes sent by many clients at once
+const readyReportLifeTime = time.Second * 5
+
+var errCheckTimeout = errors.New("check timeout")
+
+type Checker interface {
+	Live() types.HealthReport
+	Ready() types.HealthReport
+}
+
+// Pinger is implemented by db accessors backed by a real database
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// IndexerProbe checks whether the indexer responds
+type IndexerProbe func(ctx context.Context) error
+
+type RefreshStatusProvider interface {
+	RefreshStatus() types.RefreshHealth
+}
+
+func NewChecker(
+	dbAccessor db.Accessor,
+	indexerProbe IndexerProbe,
+	indexerRequired bool,
+	changeLog RefreshStatusProvider,
+	maxBlockAge time.Duration,
+	timeout time.Duration,
+) Checker {
+	return &checkerImpl{
+		dbAccessor:      dbAccessor,
+		indexerProbe:    indexerProbe,
+		indexerRequired: indexerRequired,
+		changeLog:       changeLog,
+		maxBlockAge:     maxBlockAge,
+		timeout:         timeout,
+	}
+}
+
+type checkerImpl struct {
+	dbAccessor      db.Accessor
+	indexerProbe    IndexerProbe
+	indexerRequired bool
+	changeLog       RefreshStatusProvider
+	maxBlockAge     time.Duration
+	timeout         time.Duration
+
+	mutex       sync.Mutex
+	readyReport *types.HealthReport
+}
+
+func (c *checkerImpl) Live() types.HealthReport {
+	return types.HealthReport{
+		Status:    StatusOk,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func (c *checkerImpl) Ready() types.HealthReport {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.readyReport != nil && time.Since(c.readyReport.Timestamp) < readyReportLifeTime {
+		return *c.readyReport
+	}
+	report := c.check()
+	c.readyReport = &report
+	return report
+}
+
+func (c *checkerImpl) check() types.HealthReport {
+	report := types.HealthReport{
+		Status:    StatusOk,
+		Timestamp: time.Now().UTC(),
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		report.Db, report.LastBlock = c.checkDb()
+	}()
+	go func() {
+		defer wg.Done()
+		report.Indexer = c.checkIndexer()
+	}()
+	wg.Wait()
+	if c.changeLog != nil {
+		changeLogStatus := c.changeLog.RefreshStatus()
+		report.ChangeLog = &changeLogStatus
+	}
+	if report.Db.Status != StatusOk || report.LastBlock.Status != StatusOk ||
+		report.Indexer != nil && report.Indexer.Required && report.Indexer.Status != StatusOk {
+		report.Status = StatusFail
+	}
+	return report
+}
+
+func (c *checkerImpl) checkDb() (*types.DbHealth, *types.LastBlockHealth) {
+	dbHealth := &types.DbHealth{
+		Status: StatusOk,
+	}
+	lastBlockHealth := &types.LastBlockHealth{
+		Status:    StatusOk,
+		MaxAgeSec: int64(c.maxBlockAge.Seconds()),
+	}
+	if pinger, ok := c.dbAccessor.(Pinger); ok {
+		latency, err := c.withTimeout(func(ctx context.Context) error {
+			return pinger.Ping(ctx)
+		})
+		dbHealth.PingLatencyMs = milliseconds(latency)
+		if err != nil {
+			dbHealth.Status = StatusFail
+			dbHealth.Error = errors.Wrap(err, "ping failed").Error()
+			lastBlockHealth.Status = StatusFail
+			lastBlockHealth.Error = "db is unavailable"
+			return dbHealth, lastBlockHealth
+		}
+	}
+	var lastBlock types.BlockDetail
+	latency, err := c.withTimeout(func(ctx context.Context) error {
+		var err error
+		lastBlock, err = c.dbAccessor.LastBlock(ctx)
+		return err
+	})
+	dbHealth.QueryLatencyMs = milliseconds(latency)
+	if err != nil {
+		dbHealth.Status = StatusFail
+		dbHealth.Error = errors.Wrap(err, "query failed").Error()
+		lastBlockHealth.Status = StatusFail
+		lastBlockHealth.Error = "unable to get last block"
+		return dbHealth, lastBlockHealth
+	}
+	timestamp := lastBlock.Timestamp.UTC()
+	lastBlockHealth.Height = lastBlock.Height
+	lastBlockHealth.Timestamp = &timestamp
+	age := time.Since(timestamp)
+	lastBlockHealth.AgeSec = int64(age.Seconds())
+	if c.maxBlockAge > 0 && age > c.maxBlockAge {
+		lastBlockHealth.Status = StatusFail
+		lastBlockHealth.Error = "last block is too old"
+	}
+	return dbHealth, lastBlockHealth
+}
+
+func (c *checkerImpl) checkIndexer() *types.ServiceHealth {
+	if c.indexerProbe == nil {
+		return nil
+	}
+	res := &types.ServiceHealth{
+		Status:   StatusOk,
+		Required: c.indexerRequired,
+	}
+	latency, err := c.withTimeout(func(ctx context.Context) error {
+		return c.indexerProbe(ctx)
+	})
+	res.LatencyMs = milliseconds(latency)
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// withTimeout stops waiting for the check after the timeout, the check itself keeps running in the background
+// unless it respects the context
+func (c *checkerImpl) withTimeout(check func(ctx context.Context) error) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-ctx.Done():
+		return time.Since(start), errCheckTimeout
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
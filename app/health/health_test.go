@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/app/db/memory"
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_checkerImpl_Ready(t *testing.T) {
+	fixtures := &memory.Fixtures{
+		Blocks: []memory.Block{
+			{BlockDetail: types.BlockDetail{Height: 10, Timestamp: time.Now().Add(-time.Minute)}},
+		},
+	}
+	dbAccessor := memory.NewMemoryAccessorFromFixtures(fixtures, log.New())
+	var indexerErr error
+	checker := NewChecker(dbAccessor, func(ctx context.Context) error {
+		return indexerErr
+	}, false, nil, time.Minute*5, time.Second).(*checkerImpl)
+
+	report := checker.Ready()
+	require.Equal(t, StatusOk, report.Status)
+	require.Equal(t, uint64(10), report.LastBlock.Height)
+	require.Equal(t, StatusOk, report.Indexer.Status)
+
+	indexerErr = errors.New("unavailable")
+	report = checker.check()
+	require.Equal(t, StatusOk, report.Status)
+	require.Equal(t, StatusFail, report.Indexer.Status)
+
+	checker.indexerRequired = true
+	report = checker.check()
+	require.Equal(t, StatusFail, report.Status)
+
+	checker.indexerRequired = false
+	checker.maxBlockAge = time.Second
+	report = checker.check()
+	require.Equal(t, StatusFail, report.Status)
+	require.Equal(t, StatusFail, report.LastBlock.Status)
+	require.Equal(t, StatusOk, report.Db.Status)
+}
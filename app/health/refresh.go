@@ -0,0 +1,48 @@
+package health
+
+import (
+	"github.com/idena-network/idena-indexer-api/app/types"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOk      = "Ok"
+	StatusFail    = "Fail"
+	StatusPending = "Pending"
+)
+
+// RefreshTracker keeps the outcome of the latest refresh of periodically reloaded data
+type RefreshTracker struct {
+	mutex  sync.Mutex
+	status types.RefreshHealth
+}
+
+func NewRefreshTracker() *RefreshTracker {
+	return &RefreshTracker{
+		status: types.RefreshHealth{
+			Status: StatusPending,
+		},
+	}
+}
+
+func (t *RefreshTracker) Done(err error) {
+	now := time.Now().UTC()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.status.LastRefreshTime = &now
+	if err != nil {
+		t.status.Status = StatusFail
+		t.status.Error = err.Error()
+		return
+	}
+	t.status.Status = StatusOk
+	t.status.Error = ""
+	t.status.LastSuccessTime = &now
+}
+
+func (t *RefreshTracker) Status() types.RefreshHealth {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.status
+}
@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/log"
+	"sync"
+	"time"
+)
+
+// Manager runs background loops with the shared context canceled on shutdown and releases resources once the loops
+// are stopped, the nil manager runs loops until the process exits
+type Manager struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	closers []closer
+	logger  log.Logger
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+func NewManager(logger log.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger,
+	}
+}
+
+// Context is done once the shutdown starts, background loops use it for their requests instead of context.Background()
+func (m *Manager) Context() context.Context {
+	if m == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// Go runs the loop which is to return once the context is done
+func (m *Manager) Go(loop func(ctx context.Context)) {
+	if m == nil {
+		go loop(context.Background())
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		loop(m.ctx)
+	}()
+}
+
+// OnStop registers the release of the resource, resources are released in the reverse order of the registration
+func (m *Manager) OnStop(name string, close func() error) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Stop cancels the context, waits for the loops until ctx is done and releases resources
+func (m *Manager) Stop(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		m.logger.Warn("Background loops are not stopped in time")
+	}
+	m.mutex.Lock()
+	closers := m.closers
+	m.closers = nil
+	m.mutex.Unlock()
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].close(); err != nil {
+			m.logger.Error(fmt.Sprintf("Unable to close %v: %v", closers[i].name, err))
+		}
+	}
+}
+
+// Sleep pauses the loop for the duration, it returns false if the context is done earlier
+func Sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"context"
+	"github.com/idena-network/idena-indexer-api/log"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestManager_Stop(t *testing.T) {
+	m := NewManager(log.New())
+	var iterations int
+	loopStopped := false
+	m.Go(func(ctx context.Context) {
+		for Sleep(ctx, time.Millisecond) {
+			iterations++
+		}
+		loopStopped = true
+	})
+	var closed []string
+	m.OnStop("db", func() error {
+		require.True(t, loopStopped)
+		closed = append(closed, "db")
+		return nil
+	})
+	m.OnStop("client", func() error {
+		closed = append(closed, "client")
+		return errors.New("already closed")
+	})
+	time.Sleep(time.Millisecond * 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m.Stop(ctx)
+	require.True(t, loopStopped)
+	require.Positive(t, iterations)
+	require.Equal(t, []string{"client", "db"}, closed)
+	require.Error(t, m.Context().Err())
+
+	// Nil manager runs loops without stopping them
+	var nilManager *Manager
+	done := make(chan struct{})
+	nilManager.Go(func(ctx context.Context) {
+		require.Nil(t, ctx.Err())
+		close(done)
+	})
+	<-done
+	nilManager.OnStop("db", func() error {
+		return nil
+	})
+	nilManager.Stop(ctx)
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	m := NewManager(log.New())
+	release := make(chan struct{})
+	defer close(release)
+	m.Go(func(ctx context.Context) {
+		<-release
+	})
+	var closed bool
+	m.OnStop("db", func() error {
+		closed = true
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	m.Stop(ctx)
+	require.True(t, closed)
+}
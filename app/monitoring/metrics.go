@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultBuckets are upper bounds in seconds of request duration histograms
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Collector refreshes values which are cheaper to read on scrape than to track on every change
+type Collector func()
+
+// Registry keeps metrics in the Prometheus registry and serves them in the Prometheus exposition format.
+// All metric methods are no-op for metrics created by a nil registry so that callers don't need to check whether metrics are enabled.
+type Registry struct {
+	registry   *prometheus.Registry
+	mutex      sync.Mutex
+	collectors []Collector
+}
+
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+type Gauge struct {
+	vec *prometheus.GaugeVec
+}
+
+type Histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		registry: prometheus.NewRegistry(),
+	}
+}
+
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	if r == nil {
+		return nil
+	}
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames)
+	r.registry.MustRegister(vec)
+	return &Counter{vec}
+}
+
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	if r == nil {
+		return nil
+	}
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames)
+	r.registry.MustRegister(vec)
+	return &Gauge{vec}
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if r == nil {
+		return nil
+	}
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: sortedBuckets}, labelNames)
+	r.registry.MustRegister(vec)
+	return &Histogram{vec}
+}
+
+func (r *Registry) AddCollector(collector Collector) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors = append(r.collectors, collector)
+}
+
+// Gather runs collectors and gathers all metrics
+func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
+	r.mutex.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mutex.Unlock()
+	for _, collector := range collectors {
+		collector()
+	}
+	return r.registry.Gather()
+}
+
+// Handler serves all metrics in the format negotiated by the Prometheus handler
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{})
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if c == nil {
+		return
+	}
+	c.vec.WithLabelValues(labelValues...).Add(value)
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	g.vec.WithLabelValues(labelValues...).Set(value)
+}
+
+func (g *Gauge) Add(value float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	g.vec.WithLabelValues(labelValues...).Add(value)
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	if h == nil {
+		return
+	}
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
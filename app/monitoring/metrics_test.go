@@ -0,0 +1,54 @@
+package monitoring
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("test_requests_total", "Total number of requests.", "code")
+	gauge := registry.NewGauge("test_items", "Number of items.")
+	histogram := registry.NewHistogram("test_duration_seconds", "Duration.", []float64{1, 0.1}, "route")
+
+	counter.Inc("429")
+	counter.Add(2, "429")
+	counter.Inc("503")
+	registry.AddCollector(func() {
+		gauge.Set(5)
+	})
+	histogram.Observe(0.05, `a"b`)
+	histogram.Observe(0.5, `a"b`)
+	histogram.Observe(3, `a"b`)
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="a\"b",le="0.1"} 1
+test_duration_seconds_bucket{route="a\"b",le="1"} 2
+test_duration_seconds_bucket{route="a\"b",le="+Inf"} 3
+test_duration_seconds_sum{route="a\"b"} 3.55
+test_duration_seconds_count{route="a\"b"} 3
+# HELP test_items Number of items.
+# TYPE test_items gauge
+test_items 5
+# HELP test_requests_total Total number of requests.
+# TYPE test_requests_total counter
+test_requests_total{code="429"} 3
+test_requests_total{code="503"} 1
+`, w.Body.String())
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var registry *Registry
+	counter := registry.NewCounter("test_requests_total", "Total number of requests.")
+	require.Nil(t, counter)
+	counter.Inc()
+	registry.AddCollector(func() {})
+	pm := NewMetricsPerformanceMonitor(registry)
+	pm.Complete(pm.Start("test", ""))
+}
@@ -1,7 +1,9 @@
 package monitoring
 
 import (
+	"context"
 	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
 	"github.com/idena-network/idena-indexer-api/log"
 	"sort"
 	"sync"
@@ -57,12 +59,14 @@ type nameReport struct {
 	topMin          []*record
 }
 
-func NewPerformanceMonitor(interval time.Duration, log log.Logger) PerformanceMonitor {
+func NewPerformanceMonitor(interval time.Duration, lc *lifecycle.Manager, log log.Logger) PerformanceMonitor {
 	pm := &performanceMonitorImpl{
 		log:         log,
 		recordsById: make(map[uint32]*record),
 	}
-	go pm.loop(interval)
+	lc.Go(func(ctx context.Context) {
+		pm.loop(ctx, interval)
+	})
 	return pm
 }
 
@@ -96,9 +100,8 @@ func (pm *performanceMonitorImpl) Complete(id uint32) {
 	rec.finish = &finish
 }
 
-func (pm *performanceMonitorImpl) loop(interval time.Duration) {
-	for {
-		time.Sleep(interval)
+func (pm *performanceMonitorImpl) loop(ctx context.Context, interval time.Duration) {
+	for lifecycle.Sleep(ctx, interval) {
 		pm.report()
 	}
 }
@@ -0,0 +1,155 @@
+package monitoring
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+type metricsPerformanceMonitor struct {
+	duration   *Histogram
+	inFlight   *Gauge
+	mutex      sync.Mutex
+	counter    uint32
+	recordById map[uint32]metricsRecord
+}
+
+type metricsRecord struct {
+	name  string
+	start time.Time
+}
+
+// NewMetricsPerformanceMonitor observes durations of monitored calls in a histogram keyed by the name passed to Start
+func NewMetricsPerformanceMonitor(registry *Registry) PerformanceMonitor {
+	return &metricsPerformanceMonitor{
+		duration: registry.NewHistogram("idena_api_request_duration_seconds",
+			"Duration of API requests by route.", DefaultBuckets, "route"),
+		inFlight: registry.NewGauge("idena_api_requests_in_progress",
+			"Number of API requests being handled by route.", "route"),
+		recordById: make(map[uint32]metricsRecord),
+	}
+}
+
+func (pm *metricsPerformanceMonitor) Start(name string, details string) uint32 {
+	pm.mutex.Lock()
+	pm.counter++
+	id := pm.counter
+	pm.recordById[id] = metricsRecord{
+		name:  name,
+		start: time.Now(),
+	}
+	pm.mutex.Unlock()
+	pm.inFlight.Inc(name)
+	return id
+}
+
+func (pm *metricsPerformanceMonitor) Complete(id uint32) {
+	pm.mutex.Lock()
+	rec, ok := pm.recordById[id]
+	delete(pm.recordById, id)
+	pm.mutex.Unlock()
+	if !ok {
+		return
+	}
+	pm.inFlight.Dec(rec.name)
+	pm.duration.Observe(time.Since(rec.start).Seconds(), rec.name)
+}
+
+type compositePerformanceMonitor struct {
+	monitors []PerformanceMonitor
+	mutex    sync.Mutex
+	counter  uint32
+	idsById  map[uint32][]uint32
+}
+
+// NewCompositePerformanceMonitor fans calls out to several sinks
+func NewCompositePerformanceMonitor(monitors ...PerformanceMonitor) PerformanceMonitor {
+	if len(monitors) == 1 {
+		return monitors[0]
+	}
+	return &compositePerformanceMonitor{
+		monitors: monitors,
+		idsById:  make(map[uint32][]uint32),
+	}
+}
+
+func (pm *compositePerformanceMonitor) Start(name string, details string) uint32 {
+	ids := make([]uint32, len(pm.monitors))
+	for i, monitor := range pm.monitors {
+		ids[i] = monitor.Start(name, details)
+	}
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.counter++
+	pm.idsById[pm.counter] = ids
+	return pm.counter
+}
+
+func (pm *compositePerformanceMonitor) Complete(id uint32) {
+	pm.mutex.Lock()
+	ids, ok := pm.idsById[id]
+	delete(pm.idsById, id)
+	pm.mutex.Unlock()
+	if !ok {
+		return
+	}
+	for i, monitor := range pm.monitors {
+		monitor.Complete(ids[i])
+	}
+}
+
+type DbStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+type dbStatsCollector struct {
+	provider     DbStatsProvider
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+	closed       *prometheus.Desc
+}
+
+// RegisterDbStats exposes connection pool stats of the db on every scrape
+func RegisterDbStats(registry *Registry, provider DbStatsProvider) {
+	if registry == nil {
+		return
+	}
+	registry.registry.MustRegister(&dbStatsCollector{
+		provider:     provider,
+		maxOpen:      prometheus.NewDesc("idena_api_db_max_open_connections", "Maximum number of open connections to the database.", nil, nil),
+		open:         prometheus.NewDesc("idena_api_db_open_connections", "Number of established connections to the database.", nil, nil),
+		inUse:        prometheus.NewDesc("idena_api_db_in_use_connections", "Number of connections currently in use.", nil, nil),
+		idle:         prometheus.NewDesc("idena_api_db_idle_connections", "Number of idle connections.", nil, nil),
+		waitCount:    prometheus.NewDesc("idena_api_db_wait_count_total", "Total number of connections waited for.", nil, nil),
+		waitDuration: prometheus.NewDesc("idena_api_db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", nil, nil),
+		closed:       prometheus.NewDesc("idena_api_db_closed_connections_total", "Total number of closed connections by reason.", []string{"reason"}, nil),
+	})
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.closed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
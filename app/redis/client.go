@@ -0,0 +1,333 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned for nil bulk replies, e.g. by GET of a missing key
+var ErrNil = errors.New("redis: nil")
+
+var errClosed = errors.New("redis: client is closed")
+
+// Error is an error reply of the server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+type Options struct {
+	Address        string
+	Password       string
+	Db             int
+	MaxConnections int
+	Timeout        time.Duration
+}
+
+// Client is a minimal RESP client with a connection pool which is enough for the cache needs
+type Client struct {
+	options Options
+	idle    chan *conn
+	slots   chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+func NewClient(options Options) *Client {
+	if options.MaxConnections <= 0 {
+		options.MaxConnections = 10
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = time.Second * 3
+	}
+	return &Client{
+		options: options,
+		idle:    make(chan *conn, options.MaxConnections),
+		slots:   make(chan struct{}, options.MaxConnections),
+		done:    make(chan struct{}),
+	}
+}
+
+// Do sends the command and returns its reply: string for simple strings, int64 for integers,
+// []byte for bulk strings and []interface{} for arrays
+func (c *Client) Do(args ...string) (interface{}, error) {
+	cn, err := c.getConn()
+	if err != nil {
+		return nil, err
+	}
+	res, err := cn.do(c.options.Timeout, args...)
+	if err != nil {
+		if _, ok := err.(Error); !ok && err != ErrNil {
+			cn.close()
+			<-c.slots
+			return nil, err
+		}
+	}
+	c.putConn(cn)
+	return res, err
+}
+
+func (c *Client) getConn() (*conn, error) {
+	select {
+	case <-c.done:
+		return nil, errClosed
+	default:
+	}
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	case c.slots <- struct{}{}:
+	case <-time.After(c.options.Timeout):
+		return nil, errors.New("redis: timeout while waiting for connection")
+	}
+	cn, err := c.dial()
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return cn, nil
+}
+
+func (c *Client) putConn(cn *conn) {
+	select {
+	case <-c.done:
+		cn.close()
+		<-c.slots
+		return
+	default:
+	}
+	c.idle <- cn
+}
+
+func (c *Client) dial() (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", c.options.Address, c.options.Timeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to redis %v", c.options.Address)
+	}
+	cn := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+	if len(c.options.Password) > 0 {
+		if _, err := cn.do(c.options.Timeout, "AUTH", c.options.Password); err != nil {
+			cn.close()
+			return nil, errors.Wrap(err, "unable to authenticate to redis")
+		}
+	}
+	if c.options.Db > 0 {
+		if _, err := cn.do(c.options.Timeout, "SELECT", strconv.Itoa(c.options.Db)); err != nil {
+			cn.close()
+			return nil, errors.Wrap(err, "unable to select redis db")
+		}
+	}
+	return cn, nil
+}
+
+// Subscribe calls onMessage for every message published to the channel until the client is closed,
+// onSubscribed is called on every (re)subscription so that the caller can catch up with missed messages
+func (c *Client) Subscribe(channel string, onSubscribed func(), onMessage func(payload string), onError func(err error)) {
+	go func() {
+		for {
+			err := c.subscribe(channel, onSubscribed, onMessage)
+			select {
+			case <-c.done:
+				return
+			default:
+			}
+			onError(err)
+			select {
+			case <-c.done:
+				return
+			case <-time.After(time.Second * 5):
+			}
+		}
+	}()
+}
+
+func (c *Client) subscribe(channel string, onSubscribed func(), onMessage func(payload string)) error {
+	cn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-c.done:
+		case <-stop:
+		}
+		cn.close()
+	}()
+	if _, err := cn.do(c.options.Timeout, "SUBSCRIBE", channel); err != nil {
+		return errors.Wrapf(err, "unable to subscribe to %v", channel)
+	}
+	onSubscribed()
+	for {
+		if err := cn.netConn.SetReadDeadline(time.Time{}); err != nil {
+			return err
+		}
+		reply, err := cn.read()
+		if err != nil {
+			return err
+		}
+		message, ok := reply.([]interface{})
+		if !ok || len(message) != 3 {
+			continue
+		}
+		if kind, _ := message[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		payload, _ := message[2].([]byte)
+		onMessage(string(payload))
+	}
+}
+
+func (c *Client) Close() {
+	c.once.Do(func() {
+		close(c.done)
+		for {
+			select {
+			case cn := <-c.idle:
+				cn.close()
+			default:
+				return
+			}
+		}
+	})
+}
+
+func (cn *conn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	if err := cn.netConn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if err := cn.write(args); err != nil {
+		return nil, err
+	}
+	return cn.read()
+}
+
+func (cn *conn) write(args []string) error {
+	fmt.Fprintf(cn.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(cn.writer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return cn.writer.Flush()
+}
+
+func (cn *conn) read() (interface{}, error) {
+	return ReadReply(cn.reader)
+}
+
+func (cn *conn) close() {
+	cn.netConn.Close()
+}
+
+// ReadReply reads a RESP value, it is also used to read commands by the stand-in server
+func ReadReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "redis: invalid bulk length")
+		}
+		if size < 0 {
+			return nil, ErrNil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "redis: invalid array length")
+		}
+		if size < 0 {
+			return nil, ErrNil
+		}
+		res := make([]interface{}, size)
+		for i := range res {
+			item, err := ReadReply(reader)
+			if err != nil && err != ErrNil {
+				if _, ok := err.(Error); !ok {
+					return nil, err
+				}
+			}
+			res[i] = item
+		}
+		return res, nil
+	}
+	return nil, errors.Errorf("redis: unexpected reply %q", line)
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: invalid line terminator")
+	}
+	return line[:len(line)-2], nil
+}
+
+// Bytes converts a bulk string reply
+func Bytes(reply interface{}, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, errors.Errorf("redis: unexpected reply type %T", reply)
+}
+
+// Int64 converts an integer or a bulk string reply
+func Int64(reply interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	}
+	return 0, errors.Errorf("redis: unexpected reply type %T", reply)
+}
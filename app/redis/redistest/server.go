@@ -0,0 +1,316 @@
+// Package redistest provides an in-process Redis-compatible server supporting the subset of commands used by the api
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/redis"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	items    map[string]*item
+	subs     map[string]map[*client]struct{}
+	clients  map[*client]struct{}
+	wg       sync.WaitGroup
+}
+
+type item struct {
+	value     string
+	expiresAt time.Time
+}
+
+type client struct {
+	conn   net.Conn
+	writer *bufio.Writer
+	mutex  sync.Mutex
+}
+
+// NewServer starts the server on a random local port
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		items:    make(map[string]*item),
+		subs:     make(map[string]map[*client]struct{}),
+		clients:  make(map[*client]struct{}),
+	}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and drops all client connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mutex.Lock()
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+// Keys returns non-expired keys
+func (s *Server) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var res []string
+	for key := range s.items {
+		if s.get(key) != nil {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &client{
+			conn:   conn,
+			writer: bufio.NewWriter(conn),
+		}
+		s.mutex.Lock()
+		s.clients[c] = struct{}{}
+		s.mutex.Unlock()
+		s.wg.Add(1)
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c *client) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.clients, c)
+		for _, subs := range s.subs {
+			delete(subs, c)
+		}
+		s.mutex.Unlock()
+		c.conn.Close()
+	}()
+	reader := bufio.NewReader(c.conn)
+	for {
+		command, err := redis.ReadReply(reader)
+		if err != nil {
+			return
+		}
+		items, ok := command.([]interface{})
+		if !ok || len(items) == 0 {
+			c.write("-ERR invalid command\r\n")
+			continue
+		}
+		args := make([]string, len(items))
+		for i, item := range items {
+			bytes, _ := item.([]byte)
+			args[i] = string(bytes)
+		}
+		c.write(s.handle(c, strings.ToUpper(args[0]), args[1:]))
+	}
+}
+
+func (c *client) write(reply string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.writer.WriteString(reply)
+	c.writer.Flush()
+}
+
+func (s *Server) handle(c *client, command string, args []string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	switch command {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 1 {
+			return wrongArgs(command)
+		}
+		if it := s.get(args[0]); it != nil {
+			return bulk(it.value)
+		}
+		return "$-1\r\n"
+	case "SET":
+		return s.set(args)
+	case "DEL":
+		var count int
+		for _, key := range args {
+			if s.get(key) != nil {
+				delete(s.items, key)
+				count++
+			}
+		}
+		return integer(int64(count))
+	case "INCR":
+		if len(args) != 1 {
+			return wrongArgs(command)
+		}
+		var value int64
+		it := s.get(args[0])
+		if it != nil {
+			var err error
+			if value, err = strconv.ParseInt(it.value, 10, 64); err != nil {
+				return "-ERR value is not an integer or out of range\r\n"
+			}
+		} else {
+			it = &item{}
+			s.items[args[0]] = it
+		}
+		value++
+		it.value = strconv.FormatInt(value, 10)
+		return integer(value)
+	case "SCAN":
+		// The whole key space is returned at once with the final cursor
+		pattern := "*"
+		for i := 1; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		var keys []string
+		for key := range s.items {
+			if s.get(key) != nil && matchGlob(pattern, key) {
+				keys = append(keys, key)
+			}
+		}
+		var res strings.Builder
+		res.WriteString(fmt.Sprintf("*2\r\n%s*%d\r\n", bulk("0"), len(keys)))
+		for _, key := range keys {
+			res.WriteString(bulk(key))
+		}
+		return res.String()
+	case "FLUSHALL", "FLUSHDB":
+		s.items = make(map[string]*item)
+		return "+OK\r\n"
+	case "PUBLISH":
+		if len(args) != 2 {
+			return wrongArgs(command)
+		}
+		subs := s.subs[args[0]]
+		message := fmt.Sprintf("*3\r\n%s%s%s", bulk("message"), bulk(args[0]), bulk(args[1]))
+		for sub := range subs {
+			go sub.write(message)
+		}
+		return integer(int64(len(subs)))
+	case "SUBSCRIBE":
+		var res strings.Builder
+		for _, channel := range args {
+			if s.subs[channel] == nil {
+				s.subs[channel] = make(map[*client]struct{})
+			}
+			s.subs[channel][c] = struct{}{}
+			res.WriteString(fmt.Sprintf("*3\r\n%s%s%s", bulk("subscribe"), bulk(channel), integer(1)))
+		}
+		return res.String()
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", command)
+}
+
+func (s *Server) get(key string) *item {
+	it, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if !it.expiresAt.IsZero() && !time.Now().Before(it.expiresAt) {
+		delete(s.items, key)
+		return nil
+	}
+	return it
+}
+
+func (s *Server) set(args []string) string {
+	if len(args) < 2 {
+		return wrongArgs("SET")
+	}
+	it := &item{value: args[1]}
+	var nx bool
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return "-ERR syntax error\r\n"
+			}
+			value, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || value <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			unit := time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				unit = time.Millisecond
+			}
+			it.expiresAt = time.Now().Add(time.Duration(value) * unit)
+			i++
+		default:
+			return "-ERR syntax error\r\n"
+		}
+	}
+	if nx && s.get(args[0]) != nil {
+		return "$-1\r\n"
+	}
+	s.items[args[0]] = it
+	return "+OK\r\n"
+}
+
+// matchGlob supports '*', '?' and backslash escapes of glob-style patterns
+func matchGlob(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if matchGlob(pattern[1:], value[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(value) == 0 || value[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, value = pattern[1:], value[1:]
+	}
+	return len(value) == 0
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func integer(value int64) string {
+	return fmt.Sprintf(":%d\r\n", value)
+}
+
+func wrongArgs(command string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(command))
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/idena-network/idena-go/blockchain"
 	"github.com/idena-network/idena-go/common"
 	"github.com/idena-network/idena-indexer-api/app/db"
@@ -10,15 +11,15 @@ import (
 )
 
 type Contracts interface {
-	OracleVotingContracts(authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
-	OracleVotingContract(address, oracle string) (types.OracleVotingContract, error)
-	AddressContractTxBalanceUpdates(address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
-	ContractTxBalanceUpdates(contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
+	OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error)
+	OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error)
+	AddressContractTxBalanceUpdates(ctx context.Context, address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
+	ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error)
 }
 
 type ContractsMemPool interface {
-	GetOracleVotingContractDeploys(author string) ([]OracleVotingContract, error)
-	GetAddressContractTxs(address, contractAddress string) ([]Transaction, error)
+	GetOracleVotingContractDeploys(ctx context.Context, author string) ([]OracleVotingContract, error)
+	GetAddressContractTxs(ctx context.Context, address, contractAddress string) ([]Transaction, error)
 }
 
 type OracleVotingContract struct {
@@ -68,7 +69,7 @@ func NewContracts(dbAccessor db.Accessor, contractsMemPool ContractsMemPool) Con
 	}
 }
 
-func (c *contractsImpl) OracleVotingContracts(authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
+func (c *contractsImpl) OracleVotingContracts(ctx context.Context, authorAddress, oracleAddress string, states []string, all bool, sortBy *string, count uint64, continuationToken *string) ([]types.OracleVotingContract, *string, error) {
 	var res []types.OracleVotingContract
 
 	const pending = "Pending"
@@ -80,7 +81,7 @@ func (c *contractsImpl) OracleVotingContracts(authorAddress, oracleAddress strin
 		}
 	}
 	if len(authorAddress) > 0 && continuationToken == nil && includePending && all {
-		memPoolContracts, _ := c.contractsMemPool.GetOracleVotingContractDeploys(authorAddress)
+		memPoolContracts, _ := c.contractsMemPool.GetOracleVotingContractDeploys(ctx, authorAddress)
 		for _, memPoolContract := range memPoolContracts {
 			var minPayment, ownerDeposit, oracleRewardFund *decimal.Decimal
 			var refundRecipient string
@@ -125,14 +126,14 @@ func (c *contractsImpl) OracleVotingContracts(authorAddress, oracleAddress strin
 	var err error
 	if count > 0 {
 		var dbRes []types.OracleVotingContract
-		dbRes, nextContinuationToken, err = c.dbAccessor.OracleVotingContracts(authorAddress, oracleAddress, states, all, sortBy, count, continuationToken)
+		dbRes, nextContinuationToken, err = c.dbAccessor.OracleVotingContracts(ctx, authorAddress, oracleAddress, states, all, sortBy, count, continuationToken)
 		res = append(res, dbRes...)
 	}
 	return res, nextContinuationToken, err
 }
 
-func (c *contractsImpl) OracleVotingContract(address, oracle string) (types.OracleVotingContract, error) {
-	return c.dbAccessor.OracleVotingContract(address, oracle)
+func (c *contractsImpl) OracleVotingContract(ctx context.Context, address, oracle string) (types.OracleVotingContract, error) {
+	return c.dbAccessor.OracleVotingContract(ctx, address, oracle)
 }
 
 var contractTxTypes = map[uint16]string{
@@ -141,10 +142,10 @@ var contractTxTypes = map[uint16]string{
 	17: "TerminateContract",
 }
 
-func (c *contractsImpl) AddressContractTxBalanceUpdates(address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+func (c *contractsImpl) AddressContractTxBalanceUpdates(ctx context.Context, address string, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
 	var res []types.ContractTxBalanceUpdate
 	if continuationToken == nil {
-		memPoolTxs, _ := c.contractsMemPool.GetAddressContractTxs(address, contractAddress)
+		memPoolTxs, _ := c.contractsMemPool.GetAddressContractTxs(ctx, address, contractAddress)
 		for _, memPoolTx := range memPoolTxs {
 			bu := types.ContractTxBalanceUpdate{
 				Hash:            memPoolTx.Hash,
@@ -166,12 +167,12 @@ func (c *contractsImpl) AddressContractTxBalanceUpdates(address string, contract
 	var err error
 	if count > 0 {
 		var dbRes []types.ContractTxBalanceUpdate
-		dbRes, nextContinuationToken, err = c.dbAccessor.AddressContractTxBalanceUpdates(address, contractAddress, count, continuationToken)
+		dbRes, nextContinuationToken, err = c.dbAccessor.AddressContractTxBalanceUpdates(ctx, address, contractAddress, count, continuationToken)
 		res = append(res, dbRes...)
 	}
 	return res, nextContinuationToken, err
 }
 
-func (c *contractsImpl) ContractTxBalanceUpdates(contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
-	return c.dbAccessor.ContractTxBalanceUpdates(contractAddress, count, continuationToken)
+func (c *contractsImpl) ContractTxBalanceUpdates(ctx context.Context, contractAddress string, count uint64, continuationToken *string) ([]types.ContractTxBalanceUpdate, *string, error) {
+	return c.dbAccessor.ContractTxBalanceUpdates(ctx, contractAddress, count, continuationToken)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/idena-network/idena-indexer-api/app/db"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
@@ -21,5 +22,5 @@ func NewDynamicEndpointLoader(dbAccessor db.Accessor) DynamicEndpointLoader {
 }
 
 func (loader *loaderImpl) Load() ([]types.DynamicEndpoint, error) {
-	return loader.dbAccessor.DynamicEndpoints()
+	return loader.dbAccessor.DynamicEndpoints(context.Background())
 }
@@ -0,0 +1,102 @@
+package types
+
+import "encoding/json"
+
+// Tx types and balance update reasons having specific data, they match values read by the postgres accessor
+const (
+	activationTx   = "ActivationTx"
+	killTx         = "KillTx"
+	killInviteeTx  = "KillInviteeTx"
+	onlineStatusTx = "OnlineStatusTx"
+
+	txBalanceUpdateReason              = "Tx"
+	committeeRewardBalanceUpdateReason = "CommitteeReward"
+	contractBalanceUpdateReason        = "Contract"
+	epochRewardBalanceUpdateReason     = "EpochReward"
+)
+
+func newTxSpecificData(txType string) interface{} {
+	switch txType {
+	case activationTx:
+		return &ActivationTxSpecificData{}
+	case killTx:
+		return &KillTxSpecificData{}
+	case killInviteeTx:
+		return &KillInviteeTxSpecificData{}
+	case onlineStatusTx:
+		return &OnlineStatusTxSpecificData{}
+	}
+	return nil
+}
+
+func newBalanceUpdateSpecificData(reason string) interface{} {
+	switch reason {
+	case txBalanceUpdateReason:
+		return &TransactionBalanceUpdate{}
+	case committeeRewardBalanceUpdateReason:
+		return &CommitteeRewardBalanceUpdate{}
+	case epochRewardBalanceUpdateReason:
+		return &EpochRewardBalanceUpdate{}
+	case contractBalanceUpdateReason:
+		return &ContractBalanceUpdate{}
+	}
+	return nil
+}
+
+// unmarshalSpecificData decodes data into the value created for its kind, data of unknown kinds is decoded as is
+func unmarshalSpecificData(data json.RawMessage, value interface{}) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if value == nil {
+		err := json.Unmarshal(data, &value)
+		return value, err
+	}
+	err := json.Unmarshal(data, value)
+	return value, err
+}
+
+// UnmarshalJSON restores the concrete type of Data so that decoded values, e.g. shared by the redis cache, match read ones
+func (t *TransactionSummary) UnmarshalJSON(data []byte) error {
+	type plain TransactionSummary
+	aux := struct {
+		*plain
+		Data json.RawMessage `json:"data,omitempty"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	t.Data, err = unmarshalSpecificData(aux.Data, newTxSpecificData(t.Type))
+	return err
+}
+
+// UnmarshalJSON restores the concrete type of Data so that decoded values, e.g. shared by the redis cache, match read ones
+func (t *TransactionDetail) UnmarshalJSON(data []byte) error {
+	type plain TransactionDetail
+	aux := struct {
+		*plain
+		Data json.RawMessage `json:"data,omitempty"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	t.Data, err = unmarshalSpecificData(aux.Data, newTxSpecificData(t.Type))
+	return err
+}
+
+// UnmarshalJSON restores the concrete type of Data so that decoded values, e.g. shared by the redis cache, match read ones
+func (u *BalanceUpdate) UnmarshalJSON(data []byte) error {
+	type plain BalanceUpdate
+	aux := struct {
+		*plain
+		Data json.RawMessage `json:"data,omitempty"`
+	}{plain: (*plain)(u)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	u.Data, err = unmarshalSpecificData(aux.Data, newBalanceUpdateSpecificData(u.Reason))
+	return err
+}
@@ -16,10 +16,19 @@ func (t JSONTime) MarshalJSON() ([]byte, error) {
 	return b, nil
 }
 
+func (t *JSONTime) UnmarshalJSON(data []byte) error {
+	var v time.Time
+	if err := v.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*t = JSONTime(v)
+	return nil
+}
+
 type Entity struct {
-	NameOld  string `json:"Name" swaggerignore:"true"`  // todo deprecated
-	ValueOld string `json:"Value" swaggerignore:"true"` // todo deprecated
-	RefOld   string `json:"Ref" swaggerignore:"true"`   // todo deprecated
+	NameOld  string `json:"Name" swaggerignore:"true" deprecated:"true"`
+	ValueOld string `json:"Value" swaggerignore:"true" deprecated:"true"`
+	RefOld   string `json:"Ref" swaggerignore:"true" deprecated:"true"`
 	Name     string `json:"name" enums:"Address,Identity,Epoch,Block,Transaction,Flip"`
 	Value    string `json:"value"`
 	Ref      string `json:"ref"`
@@ -48,6 +57,44 @@ type AllCoins struct {
 	TotalStake   decimal.Decimal `json:"totalStake" swaggertype:"string"`
 } // @Name Coins
 
+type SupplyHistoryItem struct {
+	// Epoch is set for epoch intervals
+	Epoch *uint64 `json:"epoch,omitempty"`
+	// Timestamp is the start of the interval
+	Timestamp time.Time `json:"timestamp" example:"2020-01-01T00:00:00Z"`
+	// BlockHeight is the last block of the interval, supply values are taken after it
+	BlockHeight       uint64          `json:"blockHeight"`
+	TotalSupply       decimal.Decimal `json:"totalSupply" swaggertype:"string"`
+	CirculatingSupply decimal.Decimal `json:"circulatingSupply" swaggertype:"string"`
+	Staked            decimal.Decimal `json:"staked" swaggertype:"string"`
+	Minted            decimal.Decimal `json:"minted" swaggertype:"string"`
+	Burnt             decimal.Decimal `json:"burnt" swaggertype:"string"`
+	BurntByReason     BurntByReason   `json:"burntByReason"`
+} // @Name SupplyHistoryItem
+
+type BurntByReason struct {
+	Fees         decimal.Decimal `json:"fees" swaggertype:"string"`
+	BurnTxs      decimal.Decimal `json:"burnTxs" swaggertype:"string"`
+	Penalties    decimal.Decimal `json:"penalties" swaggertype:"string"`
+	KilledStakes decimal.Decimal `json:"killedStakes" swaggertype:"string"`
+	Other        decimal.Decimal `json:"other" swaggertype:"string"`
+} // @Name BurntByReason
+
+type StatsSeriesItem struct {
+	// Timestamp is the start of the interval
+	Timestamp       time.Time         `json:"timestamp" example:"2020-01-01T00:00:00Z"`
+	TxCount         uint64            `json:"txCount"`
+	TxCountsByType  map[string]uint64 `json:"txCountsByType"`
+	Fees            decimal.Decimal   `json:"fees" swaggertype:"string"`
+	Tips            decimal.Decimal   `json:"tips" swaggertype:"string"`
+	ActiveAddresses uint64            `json:"activeAddresses"`
+	// NewAddresses are active addresses without txs before the interval
+	NewAddresses    uint64          `json:"newAddresses"`
+	ContractDeploys uint64          `json:"contractDeploys"`
+	ContractCalls   uint64          `json:"contractCalls"`
+	AverageFeeRate  decimal.Decimal `json:"averageFeeRate" swaggertype:"string"`
+} // @Name StatsSeriesItem
+
 type RewardsSummary struct {
 	Epoch              uint64          `json:"epoch,omitempty"`
 	Total              decimal.Decimal `json:"total" swaggertype:"string"`
@@ -130,7 +177,7 @@ type FlipSummary struct {
 	Status         string `json:"status" enums:",NotQualified,Qualified,WeaklyQualified,QualifiedByNone"`
 	Answer         string `json:"answer" enums:",None,Left,Right"`
 	// Deprecated
-	WrongWords      bool          `json:"wrongWords"`
+	WrongWords      bool          `json:"wrongWords" deprecated:"true"`
 	WrongWordsVotes uint32        `json:"wrongWordsVotes"`
 	Timestamp       time.Time     `json:"timestamp" example:"2020-01-01T00:00:00Z"`
 	Size            uint32        `json:"size"`
@@ -221,7 +268,7 @@ type TransactionSummary struct {
 	Size      uint32           `json:"size,omitempty"`
 	Nonce     uint32           `json:"nonce,omitempty"`
 	// Deprecated
-	Transfer *decimal.Decimal `json:"transfer,omitempty" swaggerignore:"true"`
+	Transfer *decimal.Decimal `json:"transfer,omitempty" swaggerignore:"true" deprecated:"true"`
 	Data     interface{}      `json:"data,omitempty"`
 
 	TxReceipt *TxReceipt `json:"txReceipt,omitempty"`
@@ -249,7 +296,7 @@ type TransactionDetail struct {
 	Size        uint32          `json:"size"`
 	Nonce       uint32          `json:"nonce,omitempty"`
 	// Deprecated
-	Transfer *decimal.Decimal `json:"transfer,omitempty" swaggertype:"string"`
+	Transfer *decimal.Decimal `json:"transfer,omitempty" swaggertype:"string" deprecated:"true"`
 	Data     interface{}      `json:"data,omitempty"`
 
 	TxReceipt *TxReceipt `json:"txReceipt,omitempty"`
@@ -265,7 +312,7 @@ type KillInviteeTxSpecificData = ActivationTxSpecificData
 
 type OnlineStatusTxSpecificData struct {
 	// Deprecated
-	BecomeOnlineOld bool `json:"BecomeOnline"`
+	BecomeOnlineOld bool `json:"BecomeOnline" deprecated:"true"`
 	BecomeOnline    bool `json:"becomeOnline"`
 }
 
@@ -290,7 +337,7 @@ type Flip struct {
 	Status    string    `json:"status" enums:",NotQualified,Qualified,WeaklyQualified,QualifiedByNone"`
 	Answer    string    `json:"answer" enums:",None,Left,Right"`
 	// Deprecated
-	WrongWords      bool       `json:"wrongWords"`
+	WrongWords      bool       `json:"wrongWords" deprecated:"true"`
 	WrongWordsVotes uint32     `json:"wrongWordsVotes"`
 	TxHash          string     `json:"txHash"`
 	BlockHash       string     `json:"blockHash"`
@@ -307,11 +354,11 @@ type FlipContent struct {
 	RightOrder []uint16        `json:"rightOrder"`
 	Pics       []hexutil.Bytes `json:"pics" swaggertype:"array"`
 	// Deprecated
-	LeftOrderOld []uint16 `json:"LeftOrder" swaggerignore:"true"`
+	LeftOrderOld []uint16 `json:"LeftOrder" swaggerignore:"true" deprecated:"true"`
 	// Deprecated
-	RightOrderOld []uint16 `json:"RightOrder" swaggerignore:"true"`
+	RightOrderOld []uint16 `json:"RightOrder" swaggerignore:"true" deprecated:"true"`
 	// Deprecated
-	PicsOld []hexutil.Bytes `json:"Pics" swaggerignore:"true"`
+	PicsOld []hexutil.Bytes `json:"Pics" swaggerignore:"true" deprecated:"true"`
 } // @Name FlipContent
 
 type Answer struct {
@@ -319,10 +366,10 @@ type Answer struct {
 	Address    string `json:"address,omitempty"`
 	RespAnswer string `json:"respAnswer" enums:"None,Left,Right"`
 	// Deprecated
-	RespWrongWords bool   `json:"respWrongWords"`
+	RespWrongWords bool   `json:"respWrongWords" deprecated:"true"`
 	FlipAnswer     string `json:"flipAnswer" enums:"None,Left,Right"`
 	// Deprecated
-	FlipWrongWords bool    `json:"flipWrongWords"`
+	FlipWrongWords bool    `json:"flipWrongWords" deprecated:"true"`
 	FlipStatus     string  `json:"flipStatus" enums:"NotQualified,Qualified,WeaklyQualified,QualifiedByNone"`
 	Point          float32 `json:"point"`
 	RespGrade      byte    `json:"respGrade"`
@@ -369,6 +416,44 @@ type Balance struct {
 	Stake   decimal.Decimal `json:"stake" swaggertype:"string"`
 } // @Name Balance
 
+type BalanceAt struct {
+	Address     string          `json:"address"`
+	Balance     decimal.Decimal `json:"balance" swaggertype:"string"`
+	Stake       decimal.Decimal `json:"stake" swaggertype:"string"`
+	BlockHeight uint64          `json:"blockHeight,omitempty"`
+	Timestamp   *time.Time      `json:"timestamp,omitempty"`
+} // @Name BalanceAt
+
+type EpochDistribution struct {
+	Epoch       uint64 `json:"epoch"`
+	BlockHeight uint64 `json:"blockHeight"`
+	// Provisional is true for the current epoch whose distribution is calculated by the last block balances
+	Provisional  bool            `json:"provisional,omitempty"`
+	HoldersCount uint64          `json:"holdersCount"`
+	TotalBalance decimal.Decimal `json:"totalBalance" swaggertype:"string"`
+	TotalStake   decimal.Decimal `json:"totalStake" swaggertype:"string"`
+	BalanceGini  float64         `json:"balanceGini"`
+	StakeGini    float64         `json:"stakeGini"`
+	// StakeNakamotoCoefficient is the minimal number of addresses holding more than a half of the total stake
+	StakeNakamotoCoefficient uint64                      `json:"stakeNakamotoCoefficient"`
+	BalanceBuckets           []DistributionBucket        `json:"balanceBuckets"`
+	TopConcentrations        []DistributionConcentration `json:"topConcentrations"`
+	TopHolders               []Balance                   `json:"topHolders"`
+} // @Name EpochDistribution
+
+type DistributionBucket struct {
+	MinBalance   decimal.Decimal  `json:"minBalance" swaggertype:"string"`
+	MaxBalance   *decimal.Decimal `json:"maxBalance,omitempty" swaggertype:"string"`
+	HoldersCount uint64           `json:"holdersCount"`
+	TotalBalance decimal.Decimal  `json:"totalBalance" swaggertype:"string"`
+} // @Name DistributionBucket
+
+type DistributionConcentration struct {
+	Top          uint64  `json:"top"`
+	BalanceShare float64 `json:"balanceShare"`
+	StakeShare   float64 `json:"stakeShare"`
+} // @Name DistributionConcentration
+
 type Staking struct {
 	Weight             float64 `json:"weight"`
 	MinersWeight       float64 `json:"minersWeight"`
@@ -882,3 +967,43 @@ type PoolSizeHistoryItem struct {
 	ValidationSize uint64 `json:"validationSize"`
 	EndSize        uint64 `json:"endSize"`
 } // @Name PoolSizeHistoryItem
+
+type HealthReport struct {
+	Status           string           `json:"status" enums:"Ok,Fail"`
+	Timestamp        time.Time        `json:"timestamp" example:"2020-01-01T00:00:00Z"`
+	Db               *DbHealth        `json:"db,omitempty"`
+	LastBlock        *LastBlockHealth `json:"lastBlock,omitempty"`
+	Indexer          *ServiceHealth   `json:"indexer,omitempty"`
+	ChangeLog        *RefreshHealth   `json:"changeLog,omitempty"`
+	DynamicEndpoints *RefreshHealth   `json:"dynamicEndpoints,omitempty"`
+} // @Name HealthReport
+
+type DbHealth struct {
+	Status         string  `json:"status" enums:"Ok,Fail"`
+	PingLatencyMs  float64 `json:"pingLatencyMs"`
+	QueryLatencyMs float64 `json:"queryLatencyMs"`
+	Error          string  `json:"error,omitempty"`
+} // @Name DbHealth
+
+type LastBlockHealth struct {
+	Status    string     `json:"status" enums:"Ok,Fail"`
+	Height    uint64     `json:"height,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty" example:"2020-01-01T00:00:00Z"`
+	AgeSec    int64      `json:"ageSec"`
+	MaxAgeSec int64      `json:"maxAgeSec"`
+	Error     string     `json:"error,omitempty"`
+} // @Name LastBlockHealth
+
+type ServiceHealth struct {
+	Status    string  `json:"status" enums:"Ok,Fail"`
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+} // @Name ServiceHealth
+
+type RefreshHealth struct {
+	Status          string     `json:"status" enums:"Ok,Fail,Pending"`
+	LastRefreshTime *time.Time `json:"lastRefreshTime,omitempty" example:"2020-01-01T00:00:00Z"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty" example:"2020-01-01T00:00:00Z"`
+	Error           string     `json:"error,omitempty"`
+} // @Name RefreshHealth
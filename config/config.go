@@ -8,15 +8,29 @@ import (
 	"path/filepath"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+const (
+	CacheBackendLocal = "local"
+	CacheBackendRedis = "redis"
+)
+
 type Config struct {
-	Port                        int
-	Verbosity                   int
-	PostgresConnStr             string
-	ScriptsDir                  string
-	LatestHours                 int
-	ActiveAddressHours          int
-	MaxReqCount                 int
-	ReqTimeoutSec               int
+	Port               int
+	Verbosity          int
+	Storage            string
+	PostgresConnStr    string
+	FixturesPath       string
+	ScriptsDir         string
+	LatestHours        int
+	ActiveAddressHours int
+	MaxReqCount        int
+	ReqTimeoutSec      int
+	// HandlerTimeoutSec limits the time of handling a request after it left the queue, 0 means no limit
+	HandlerTimeoutSec           int
 	DefaultCacheMaxItemCount    int
 	DefaultCacheItemLifeTimeSec int
 	PerformanceMonitor          PerformanceMonitorConfig
@@ -30,8 +44,25 @@ type Config struct {
 	DynamicEndpointsTable       string
 	DynamicEndpointStatesTable  string
 	Cors                        bool
-	EmbeddedContractForkHeight  uint64
-	ContractSizeLimit           int
+	// LegacyErrors makes error responses have 200 status for old clients instead of the status of the error code
+	LegacyErrors bool
+	// DeprecationSunset is the RFC3339 date announced with the Sunset header of deprecated v1 routes and fields,
+	// empty means no Sunset header
+	DeprecationSunset          string
+	EmbeddedContractForkHeight uint64
+	ContractSizeLimit          int
+	WebSocket                  WebSocketConfig
+	EpochEvents                EpochEventsConfig
+	GraphQL                    GraphQLConfig
+	Metrics                    MetricsConfig
+	Health                     HealthConfig
+	ApiKeys                    ApiKeysConfig
+	RouteClasses               []RouteClassConfig
+	ClientIp                   ClientIpConfig
+	Cache                      CacheConfig
+	Invalidation               InvalidationConfig
+	Exports                    ExportsConfig
+	Server                     ServerConfig
 }
 
 type IndexerConfig struct {
@@ -45,6 +76,123 @@ type PerformanceMonitorConfig struct {
 	LogFileSize int
 }
 
+type WebSocketConfig struct {
+	Enabled                       bool
+	MaxConnectionsPerClient       int
+	MaxSubscriptionsPerConnection int
+	// AllowedOrigins are origins of browser pages allowed to open ws connections in addition to the api host,
+	// '*' allows any origin, requests without the Origin header are always allowed
+	AllowedOrigins []string
+}
+
+type EpochEventsConfig struct {
+	Enabled     bool
+	HistorySize int
+	// PollValidation enables polling of the db from the validation start to detect validationStarted,
+	// interimSummaryChanged and rewardsAvailable events, only newEpoch events are published otherwise
+	PollValidation bool
+}
+
+type GraphQLConfig struct {
+	Enabled       bool
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type MetricsConfig struct {
+	Enabled bool
+}
+
+type HealthConfig struct {
+	MaxBlockAgeSec  int
+	CheckTimeoutSec int
+	IndexerRequired bool
+}
+
+// RouteClassConfig sets a concurrency pool and a per-request cost for routes matching any of Paths,
+// '*' matches a single path segment and trailing '**' matches the rest of the path, e.g. "/api/epoch/*/identities",
+// configured classes take precedence over built-in classes of heavy routes such as /api/Balances/At
+type RouteClassConfig struct {
+	Name  string
+	Paths []string
+	// MaxConcurrency is the size of the class queue, 0 means the common queue is used
+	MaxConcurrency int
+	// Cost is the number of requests charged to the client limit, 0 means 1
+	Cost int
+	// TimeoutSec overrides HandlerTimeoutSec for the class routes, 0 means HandlerTimeoutSec is used
+	TimeoutSec int
+}
+
+// ServerConfig limits connections of the HTTP server, timeouts of 0 mean no limit
+type ServerConfig struct {
+	ReadHeaderTimeoutSec int
+	ReadTimeoutSec       int
+	// WriteTimeoutSec limits writing of whole responses including event streams and export downloads
+	WriteTimeoutSec int
+	IdleTimeoutSec  int
+	MaxHeaderBytes  int
+	// MaxBodyBytes limits bodies of requests, e.g. GraphQL queries, 0 means no limit
+	MaxBodyBytes int64
+	// ShutdownTimeoutSec limits draining of in-flight requests and then stopping of background loops on SIGTERM or SIGINT,
+	// each stage has its own timeout
+	ShutdownTimeoutSec int
+}
+
+type ClientIpConfig struct {
+	// TrustedProxies are addresses or CIDRs of proxies whose Forwarded, X-Forwarded-For and X-Real-IP headers are trusted.
+	// Forwarding headers were trusted from any sender before, now requests are limited by the peer address if it is empty,
+	// so deployments behind a proxy have to list it to keep limiting clients rather than the proxy
+	TrustedProxies []string
+	// AggregateIPv6 makes request limits count IPv6 clients by /64 networks
+	AggregateIPv6 bool
+}
+
+type CacheConfig struct {
+	// Backend is either local to keep db cache in the process memory or redis to share it between replicas
+	Backend string
+	Redis   RedisConfig
+}
+
+type InvalidationConfig struct {
+	// Channel is the postgres notification channel of indexed data changes, the cache is invalidated by polling
+	// if it is empty or listening to it fails, notifications do not evict address data which expires in a minute
+	// regardless of DefaultCacheItemLifeTimeSec
+	Channel string
+}
+
+type ExportsConfig struct {
+	// Dir keeps compressed export files and job states, api instances sharing the dir serve exports of each other,
+	// exports are disabled if it is empty
+	Dir       string
+	Workers   int
+	QueueSize int
+	// PageSize is the number of rows read from the db at once
+	PageSize int
+	// MaxRows truncates larger exports
+	MaxRows int
+	// ExpirySec is the lifetime of export results, it also limits the time an export may run
+	ExpirySec int
+	// MaxActiveJobsPerClient and JobsPerDay are quotas of anonymous clients and api keys whose tier sets no quota
+	MaxActiveJobsPerClient int
+	JobsPerDay             int
+}
+
+type RedisConfig struct {
+	Address        string
+	Password       string
+	Db             int
+	KeyPrefix      string
+	MaxConnections int
+	TimeoutSec     int
+}
+
+type ApiKeysConfig struct {
+	// File is the JSON file with tiers and keys, api keys are disabled if it is empty
+	File              string
+	AdminToken        string
+	ReloadIntervalSec int
+}
+
 type SwaggerConfig struct {
 	Enabled  bool
 	Host     string
@@ -70,12 +218,14 @@ func LoadConfig(configPath string) *Config {
 
 func newDefaultConfig() *Config {
 	return &Config{
+		Storage:                     StoragePostgres,
 		ScriptsDir:                  filepath.Join("resources", "scripts", "api"),
 		Verbosity:                   3,
 		LatestHours:                 24,
 		ActiveAddressHours:          24,
 		MaxReqCount:                 50,
 		ReqTimeoutSec:               60,
+		HandlerTimeoutSec:           30,
 		DefaultCacheMaxItemCount:    100,
 		DefaultCacheItemLifeTimeSec: 60,
 		ReqsPerMinuteLimit:          0,
@@ -85,5 +235,57 @@ func newDefaultConfig() *Config {
 		},
 		LogFileSize: 1024 * 100,
 		Cors:        true,
+		WebSocket: WebSocketConfig{
+			Enabled:                       false,
+			MaxConnectionsPerClient:       5,
+			MaxSubscriptionsPerConnection: 100,
+		},
+		EpochEvents: EpochEventsConfig{
+			Enabled:        false,
+			HistorySize:    100,
+			PollValidation: false,
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:       false,
+			MaxDepth:      6,
+			MaxComplexity: 30,
+		},
+		Metrics: MetricsConfig{
+			Enabled: false,
+		},
+		Health: HealthConfig{
+			MaxBlockAgeSec:  600,
+			CheckTimeoutSec: 5,
+			IndexerRequired: false,
+		},
+		Cache: CacheConfig{
+			Backend: CacheBackendLocal,
+			Redis: RedisConfig{
+				KeyPrefix:      "idena-api",
+				MaxConnections: 10,
+				TimeoutSec:     3,
+			},
+		},
+		ApiKeys: ApiKeysConfig{
+			ReloadIntervalSec: 60,
+		},
+		Exports: ExportsConfig{
+			Workers:                2,
+			QueueSize:              100,
+			PageSize:               1000,
+			MaxRows:                1000000,
+			ExpirySec:              60 * 60 * 24,
+			MaxActiveJobsPerClient: 2,
+			JobsPerDay:             10,
+		},
+		Server: ServerConfig{
+			ReadHeaderTimeoutSec: 10,
+			ReadTimeoutSec:       30,
+			WriteTimeoutSec:      0,
+			IdleTimeoutSec:       120,
+			MaxHeaderBytes:       1 << 20,
+			MaxBodyBytes:         1 << 20,
+			ShutdownTimeoutSec:   30,
+		},
 	}
 }
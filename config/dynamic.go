@@ -1,7 +1,9 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/pkg/errors"
 	"io/ioutil"
@@ -24,7 +26,7 @@ func (holder *DynamicConfigHolder) GetConfig() *DynamicConfig {
 	return holder.config
 }
 
-func NewDynamicConfigHolder(filePath string, logger log.Logger) *DynamicConfigHolder {
+func NewDynamicConfigHolder(filePath string, lc *lifecycle.Manager, logger log.Logger) *DynamicConfigHolder {
 	holder := &DynamicConfigHolder{
 		filePath: filePath,
 		logger:   logger,
@@ -36,7 +38,7 @@ func NewDynamicConfigHolder(filePath string, logger log.Logger) *DynamicConfigHo
 			panic(err)
 		}
 	}
-	go holder.updateLoop()
+	lc.Go(holder.updateLoop)
 	return holder
 }
 
@@ -54,9 +56,8 @@ func readDynamicConfig(configPath string) (*DynamicConfig, error) {
 	}
 }
 
-func (holder *DynamicConfigHolder) updateLoop() {
-	for {
-		time.Sleep(time.Minute)
+func (holder *DynamicConfigHolder) updateLoop(ctx context.Context) {
+	for lifecycle.Sleep(ctx, time.Minute) {
 		ok, err := holder.updateIfNeeded()
 		if err != nil {
 			holder.logger.Warn(err.Error())
@@ -76,6 +76,68 @@ var doc = `{
                 }
             }
         },
+        "/Address/{address}/Balance/At": {
+            "get": {
+                "tags": [
+                    "Address"
+                ],
+                "summary": "Returns the balance and stake after the block with the height or at the timestamp",
+                "operationId": "AddressBalanceAt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "block height",
+                        "name": "height",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "unix seconds or RFC3339 time, used instead of height",
+                        "name": "timestamp",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/BalanceAt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
         "/Address/{address}/Balance/Changes/Summary": {
             "get": {
                 "tags": [
@@ -811,6 +873,52 @@ var doc = `{
                 }
             }
         },
+        "/Admin/ApiKeys/Usage": {
+            "get": {
+                "tags": [
+                    "Admin"
+                ],
+                "operationId": "ApiKeysUsage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/apikeys.Usage"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Admin token is missing or wrong"
+                    },
+                    "404": {
+                        "description": "Api keys are not enabled"
+                    }
+                }
+            }
+        },
         "/Balances": {
             "get": {
                 "tags": [
@@ -879,6 +987,117 @@ var doc = `{
                 }
             }
         },
+        "/Balances/At": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Address"
+                ],
+                "summary": "Returns balances and stakes of up to 100 addresses after the block with the height",
+                "operationId": "AddressesBalanceAt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "block height",
+                        "name": "height",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "addresses",
+                        "name": "addresses",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/BalanceAt"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
+        "/Batch": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Batch"
+                ],
+                "summary": "Executes up to 20 API GET requests concurrently, every request counts against the client request limit",
+                "operationId": "Batch",
+                "parameters": [
+                    {
+                        "description": "relative API paths, e.g. /Epoch/Last",
+                        "name": "paths",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ResponsePage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    }
+                }
+            }
+        },
         "/Block/Last": {
             "get": {
                 "tags": [
@@ -919,7 +1138,7 @@ var doc = `{
                 }
             }
         },
-        "/Block/{id}": {
+        "/Block/{block}": {
             "get": {
                 "tags": [
                     "Block"
@@ -929,7 +1148,7 @@ var doc = `{
                     {
                         "type": "string",
                         "description": "block hash or height",
-                        "name": "id",
+                        "name": "block",
                         "in": "path",
                         "required": true
                     }
@@ -968,7 +1187,7 @@ var doc = `{
                 }
             }
         },
-        "/Block/{id}/Coins": {
+        "/Block/{block}/Coins": {
             "get": {
                 "tags": [
                     "Block"
@@ -978,7 +1197,7 @@ var doc = `{
                     {
                         "type": "string",
                         "description": "block hash or height",
-                        "name": "id",
+                        "name": "block",
                         "in": "path",
                         "required": true
                     }
@@ -1017,7 +1236,7 @@ var doc = `{
                 }
             }
         },
-        "/Block/{id}/Txs": {
+        "/Block/{block}/Txs": {
             "get": {
                 "tags": [
                     "Block"
@@ -1027,7 +1246,7 @@ var doc = `{
                     {
                         "type": "string",
                         "description": "block hash or height",
-                        "name": "id",
+                        "name": "block",
                         "in": "path",
                         "required": true
                     },
@@ -1094,7 +1313,7 @@ var doc = `{
                 }
             }
         },
-        "/Block/{id}/Txs/Count": {
+        "/Block/{block}/Txs/Count": {
             "get": {
                 "tags": [
                     "Block"
@@ -1104,7 +1323,7 @@ var doc = `{
                     {
                         "type": "string",
                         "description": "block hash or height",
-                        "name": "id",
+                        "name": "block",
                         "in": "path",
                         "required": true
                     }
@@ -1908,6 +2127,56 @@ var doc = `{
                 }
             }
         },
+        "/Epoch/{epoch}/Distribution": {
+            "get": {
+                "tags": [
+                    "Epochs"
+                ],
+                "summary": "Returns the distribution of balances and stakes at the end of the epoch excluding frozen balance addresses, the distribution of the current epoch is provisional",
+                "operationId": "EpochDistribution",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "epoch",
+                        "name": "epoch",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/EpochDistribution"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
         "/Epoch/{epoch}/FlipStatesSummary": {
             "get": {
                 "tags": [
@@ -3959,24 +4228,63 @@ var doc = `{
                 }
             }
         },
-        "/Flip/{hash}": {
+        "/Events/Epoch": {
             "get": {
+                "produces": [
+                    "text/event-stream"
+                ],
                 "tags": [
-                    "Flip"
+                    "Events"
                 ],
-                "operationId": "Flip",
+                "summary": "Server-sent events stream of epoch lifecycle: newEpoch, validationStarted, interimSummaryChanged, rewardsAvailable",
+                "operationId": "EpochEvents",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "flip hash",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
+                        "description": "id of the last received event to resume the stream from",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Event stream is not enabled"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/Exports": {
+            "post": {
+                "tags": [
+                    "Exports"
+                ],
+                "operationId": "CreateExport",
+                "parameters": [
+                    {
+                        "description": "route, filters and format (csv or ndjson) of the export",
+                        "name": "spec",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/exports.Spec"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "allOf": [
                                 {
@@ -3986,7 +4294,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/Flip"
+                                            "$ref": "#/definitions/api.exportJob"
                                         }
                                     }
                                 }
@@ -3997,10 +4305,7 @@ var doc = `{
                         "description": "Bad request"
                     },
                     "429": {
-                        "description": "Request number limit exceeded"
-                    },
-                    "500": {
-                        "description": "Internal server error"
+                        "description": "Export quota exceeded or too many exports in progress"
                     },
                     "503": {
                         "description": "Service unavailable"
@@ -4008,17 +4313,17 @@ var doc = `{
                 }
             }
         },
-        "/Flip/{hash}/Answers/Long": {
+        "/Exports/{id}": {
             "get": {
                 "tags": [
-                    "Flip"
+                    "Exports"
                 ],
-                "operationId": "FlipLongAnswers",
+                "operationId": "Export",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "flip hash",
-                        "name": "hash",
+                        "description": "export id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -4035,9 +4340,129 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/Answer"
+                                            "$ref": "#/definitions/api.exportJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Export not found"
+                    }
+                }
+            }
+        },
+        "/Exports/{id}/Download": {
+            "get": {
+                "tags": [
+                    "Exports"
+                ],
+                "operationId": "DownloadExport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "export id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "gzip compressed csv or ndjson",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Export not found"
+                    },
+                    "409": {
+                        "description": "Export is not completed"
+                    }
+                }
+            }
+        },
+        "/Flip/{cid}": {
+            "get": {
+                "tags": [
+                    "Flip"
+                ],
+                "operationId": "Flip",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "flip cid",
+                        "name": "cid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/Flip"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
+        "/Flip/{cid}/Answers/Long": {
+            "get": {
+                "tags": [
+                    "Flip"
+                ],
+                "operationId": "FlipLongAnswers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "flip cid",
+                        "name": "cid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/Answer"
                                             }
                                         }
                                     }
@@ -4060,7 +4485,7 @@ var doc = `{
                 }
             }
         },
-        "/Flip/{hash}/Answers/Short": {
+        "/Flip/{cid}/Answers/Short": {
             "get": {
                 "tags": [
                     "Flip"
@@ -4069,8 +4494,8 @@ var doc = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "flip hash",
-                        "name": "hash",
+                        "description": "flip cid",
+                        "name": "cid",
                         "in": "path",
                         "required": true
                     }
@@ -4112,7 +4537,7 @@ var doc = `{
                 }
             }
         },
-        "/Flip/{hash}/Content": {
+        "/Flip/{cid}/Content": {
             "get": {
                 "tags": [
                     "Flip"
@@ -4121,8 +4546,8 @@ var doc = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "flip hash",
-                        "name": "hash",
+                        "description": "flip cid",
+                        "name": "cid",
                         "in": "path",
                         "required": true
                     }
@@ -4161,6 +4586,123 @@ var doc = `{
                 }
             }
         },
+        "/GraphQL": {
+            "post": {
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "GraphQL queries over epochs, blocks, identities, addresses, flips, transactions, contracts, pools and tokens",
+                "operationId": "GraphQL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "GraphQL query, may be passed in the JSON body of POST request instead",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "operation to execute",
+                        "name": "operationName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON encoded variables",
+                        "name": "variables",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
+        "/Health/Live": {
+            "get": {
+                "tags": [
+                    "Health"
+                ],
+                "operationId": "HealthLive",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/Health/Ready": {
+            "get": {
+                "tags": [
+                    "Health"
+                ],
+                "operationId": "HealthReady",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service is not ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/HealthReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
         "/Identity/{address}": {
             "get": {
                 "tags": [
@@ -5941,19 +6483,37 @@ var doc = `{
                 }
             }
         },
-        "/TimeLockContract/{address}": {
+        "/Stats/Series": {
             "get": {
                 "tags": [
-                    "Contracts"
+                    "Stats"
                 ],
-                "operationId": "TimeLockContract",
+                "summary": "Returns network activity by intervals: tx counts by type, fees, active and new addresses, contract deploys and calls, average block fee rate",
+                "operationId": "StatsSeries",
                 "parameters": [
                     {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
                         "type": "string",
-                        "description": "contract address",
-                        "name": "address",
-                        "in": "path",
+                        "description": "interval length, weeks start on Monday",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items to take",
+                        "name": "limit",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "continuation token to get next page items",
+                        "name": "continuationToken",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -5962,13 +6522,16 @@ var doc = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/Response"
+                                    "$ref": "#/definitions/ResponsePage"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/types.TimeLockContract"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/StatsSeriesItem"
+                                            }
                                         }
                                     }
                                 }
@@ -5990,19 +6553,36 @@ var doc = `{
                 }
             }
         },
-        "/Token/{address}": {
+        "/Supply/History": {
             "get": {
                 "tags": [
-                    "Token"
+                    "Coins"
                 ],
-                "operationId": "Token",
+                "summary": "Returns total, circulating (excluding frozen balance addresses) and staked supply at the end of every interval with coins minted and burnt during it",
+                "operationId": "SupplyHistory",
                 "parameters": [
                     {
+                        "enum": [
+                            "epoch",
+                            "day"
+                        ],
                         "type": "string",
-                        "description": "address",
-                        "name": "address",
-                        "in": "path",
+                        "description": "interval length",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items to take",
+                        "name": "limit",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "continuation token to get next page items",
+                        "name": "continuationToken",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -6011,13 +6591,114 @@ var doc = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/Response"
+                                    "$ref": "#/definitions/ResponsePage"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/Token"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/SupplyHistoryItem"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
+        "/TimeLockContract/{address}": {
+            "get": {
+                "tags": [
+                    "Contracts"
+                ],
+                "operationId": "TimeLockContract",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/types.TimeLockContract"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "429": {
+                        "description": "Request number limit exceeded"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "503": {
+                        "description": "Service unavailable"
+                    }
+                }
+            }
+        },
+        "/Token/{address}": {
+            "get": {
+                "tags": [
+                    "Token"
+                ],
+                "operationId": "Token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/Token"
                                         }
                                     }
                                 }
@@ -6530,6 +7211,26 @@ var doc = `{
                 }
             }
         },
+        "BalanceAt": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "string"
+                },
+                "blockHeight": {
+                    "type": "integer"
+                },
+                "stake": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
         "BalanceUpdatesSummary": {
             "type": "object",
             "properties": {
@@ -6698,6 +7399,26 @@ var doc = `{
                 }
             }
         },
+        "BurntByReason": {
+            "type": "object",
+            "properties": {
+                "burnTxs": {
+                    "type": "string"
+                },
+                "fees": {
+                    "type": "string"
+                },
+                "killedStakes": {
+                    "type": "string"
+                },
+                "other": {
+                    "type": "string"
+                },
+                "penalties": {
+                    "type": "string"
+                }
+            }
+        },
         "Coins": {
             "type": "object",
             "properties": {
@@ -6770,6 +7491,27 @@ var doc = `{
                 }
             }
         },
+        "DbHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "pingLatencyMs": {
+                    "type": "number"
+                },
+                "queryLatencyMs": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Ok",
+                        "Fail"
+                    ]
+                }
+            }
+        },
         "DelegateeReward": {
             "type": "object",
             "properties": {
@@ -6924,6 +7666,37 @@ var doc = `{
                 }
             }
         },
+        "DistributionBucket": {
+            "type": "object",
+            "properties": {
+                "holdersCount": {
+                    "type": "integer"
+                },
+                "maxBalance": {
+                    "type": "string"
+                },
+                "minBalance": {
+                    "type": "string"
+                },
+                "totalBalance": {
+                    "type": "string"
+                }
+            }
+        },
+        "DistributionConcentration": {
+            "type": "object",
+            "properties": {
+                "balanceShare": {
+                    "type": "number"
+                },
+                "stakeShare": {
+                    "type": "number"
+                },
+                "top": {
+                    "type": "integer"
+                }
+            }
+        },
         "Entity": {
             "type": "object",
             "properties": {
@@ -6973,6 +7746,58 @@ var doc = `{
                 }
             }
         },
+        "EpochDistribution": {
+            "type": "object",
+            "properties": {
+                "balanceBuckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistributionBucket"
+                    }
+                },
+                "balanceGini": {
+                    "type": "number"
+                },
+                "blockHeight": {
+                    "type": "integer"
+                },
+                "epoch": {
+                    "type": "integer"
+                },
+                "holdersCount": {
+                    "type": "integer"
+                },
+                "provisional": {
+                    "description": "Provisional is true for the current epoch whose distribution is calculated by the last block balances",
+                    "type": "boolean"
+                },
+                "stakeGini": {
+                    "type": "number"
+                },
+                "stakeNakamotoCoefficient": {
+                    "description": "StakeNakamotoCoefficient is the minimal number of addresses holding more than a half of the total stake",
+                    "type": "integer"
+                },
+                "topConcentrations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistributionConcentration"
+                    }
+                },
+                "topHolders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Balance"
+                    }
+                },
+                "totalBalance": {
+                    "type": "string"
+                },
+                "totalStake": {
+                    "type": "string"
+                }
+            }
+        },
         "EpochIdentity": {
             "type": "object",
             "properties": {
@@ -7112,6 +7937,14 @@ var doc = `{
         "Error": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Code is one of not_found, invalid_argument, unauthorized, rate_limited, upstream_unavailable, timeout, conflict, internal",
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "message": {
                     "type": "string"
                 }
@@ -7373,6 +8206,42 @@ var doc = `{
                 }
             }
         },
+        "HealthReport": {
+            "type": "object",
+            "properties": {
+                "changeLog": {
+                    "type": "object",
+                    "$ref": "#/definitions/RefreshHealth"
+                },
+                "db": {
+                    "type": "object",
+                    "$ref": "#/definitions/DbHealth"
+                },
+                "dynamicEndpoints": {
+                    "type": "object",
+                    "$ref": "#/definitions/RefreshHealth"
+                },
+                "indexer": {
+                    "type": "object",
+                    "$ref": "#/definitions/ServiceHealth"
+                },
+                "lastBlock": {
+                    "type": "object",
+                    "$ref": "#/definitions/LastBlockHealth"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Ok",
+                        "Fail"
+                    ]
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                }
+            }
+        },
         "Identity": {
             "type": "object",
             "properties": {
@@ -7504,6 +8373,34 @@ var doc = `{
                 }
             }
         },
+        "LastBlockHealth": {
+            "type": "object",
+            "properties": {
+                "ageSec": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "maxAgeSec": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Ok",
+                        "Fail"
+                    ]
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                }
+            }
+        },
         "MinersHistoryItem": {
             "type": "object",
             "properties": {
@@ -7768,6 +8665,30 @@ var doc = `{
                 }
             }
         },
+        "RefreshHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "lastRefreshTime": {
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                },
+                "lastSuccessTime": {
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Ok",
+                        "Fail",
+                        "Pending"
+                    ]
+                }
+            }
+        },
         "ReportedFlipReward": {
             "type": "object",
             "properties": {
@@ -8162,6 +9083,27 @@ var doc = `{
                 }
             }
         },
+        "ServiceHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Ok",
+                        "Fail"
+                    ]
+                }
+            }
+        },
         "Staking": {
             "type": "object",
             "properties": {
@@ -8185,6 +9127,84 @@ var doc = `{
                 }
             }
         },
+        "StatsSeriesItem": {
+            "type": "object",
+            "properties": {
+                "activeAddresses": {
+                    "type": "integer"
+                },
+                "averageFeeRate": {
+                    "type": "string"
+                },
+                "contractCalls": {
+                    "type": "integer"
+                },
+                "contractDeploys": {
+                    "type": "integer"
+                },
+                "fees": {
+                    "type": "string"
+                },
+                "newAddresses": {
+                    "description": "NewAddresses are active addresses without txs before the interval",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "description": "Timestamp is the start of the interval",
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                },
+                "tips": {
+                    "type": "string"
+                },
+                "txCount": {
+                    "type": "integer"
+                },
+                "txCountsByType": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "SupplyHistoryItem": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "BlockHeight is the last block of the interval, supply values are taken after it",
+                    "type": "integer"
+                },
+                "burnt": {
+                    "type": "string"
+                },
+                "burntByReason": {
+                    "type": "object",
+                    "$ref": "#/definitions/BurntByReason"
+                },
+                "circulatingSupply": {
+                    "type": "string"
+                },
+                "epoch": {
+                    "description": "Epoch is set for epoch intervals",
+                    "type": "integer"
+                },
+                "minted": {
+                    "type": "string"
+                },
+                "staked": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Timestamp is the start of the interval",
+                    "type": "string",
+                    "example": "2020-01-01T00:00:00Z"
+                },
+                "totalSupply": {
+                    "type": "string"
+                }
+            }
+        },
         "Token": {
             "type": "object",
             "properties": {
@@ -8570,6 +9590,80 @@ var doc = `{
                 }
             }
         },
+        "api.exportJob": {
+            "type": "object",
+            "properties": {
+                "completedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "downloadUrl": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "spec": {
+                    "type": "object",
+                    "$ref": "#/definitions/exports.Spec"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "truncated": {
+                    "description": "Truncated is true if the export was stopped by the row limit",
+                    "type": "boolean"
+                }
+            }
+        },
+        "apikeys.Usage": {
+            "type": "object",
+            "properties": {
+                "lastRequestTime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rejectedRequests": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "tier": {
+                    "type": "string"
+                }
+            }
+        },
+        "exports.Spec": {
+            "type": "object",
+            "properties": {
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
         "types.Contract": {
             "type": "object",
             "properties": {
@@ -1,7 +1,9 @@
 package indexer
 
 import (
+	"context"
 	"github.com/idena-network/idena-go/common/hexutil"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
 	"github.com/idena-network/idena-indexer-api/app/service"
 	"github.com/idena-network/idena-indexer-api/app/types"
 	"github.com/idena-network/idena-indexer-api/log"
@@ -10,36 +12,36 @@ import (
 )
 
 type Api interface {
-	OnlineIdentitiesCount() (uint64, error)
-	OnlineIdentities(count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error)
-	OnlineIdentity(address string) (*types.OnlineIdentity, error)
-	OnlineCount() (uint64, error)
-	ValidatorsCount() (uint64, error)
-	Validators(count uint64, continuationToken *string) ([]types.Validator, *string, error)
-	OnlineValidatorsCount() (uint64, error)
-	OnlineValidators(count uint64, continuationToken *string) ([]types.Validator, *string, error)
-	ForkCommitteeCount() (uint64, error)
+	OnlineIdentitiesCount(ctx context.Context) (uint64, error)
+	OnlineIdentities(ctx context.Context, count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error)
+	OnlineIdentity(ctx context.Context, address string) (*types.OnlineIdentity, error)
+	OnlineCount(ctx context.Context) (uint64, error)
+	ValidatorsCount(ctx context.Context) (uint64, error)
+	Validators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error)
+	OnlineValidatorsCount(ctx context.Context) (uint64, error)
+	OnlineValidators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error)
+	ForkCommitteeCount(ctx context.Context) (uint64, error)
 
-	MemPoolTransaction(hash string) (*types.TransactionDetail, error)
-	MemPoolTransactionRaw(hash string) (*hexutil.Bytes, error)
-	MemPoolAddressTransactions(address string, count int) ([]*types.TransactionSummary, error)
-	MemPoolTransactions(count int) ([]*types.TransactionSummary, error)
-	MemPoolTransactionsCount() (int, error)
+	MemPoolTransaction(ctx context.Context, hash string) (*types.TransactionDetail, error)
+	MemPoolTransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error)
+	MemPoolAddressTransactions(ctx context.Context, address string, count int) ([]*types.TransactionSummary, error)
+	MemPoolTransactions(ctx context.Context, count int) ([]*types.TransactionSummary, error)
+	MemPoolTransactionsCount(ctx context.Context) (int, error)
 
-	MemPoolOracleVotingContractDeploys(author string) ([]service.OracleVotingContract, error)
-	MemPoolAddressContractTxs(address, contractAddress string) ([]service.Transaction, error)
+	MemPoolOracleVotingContractDeploys(ctx context.Context, author string) ([]service.OracleVotingContract, error)
+	MemPoolAddressContractTxs(ctx context.Context, address, contractAddress string) ([]service.Transaction, error)
 
-	UpgradeVoting() ([]*types.UpgradeVotes, error)
+	UpgradeVoting(ctx context.Context) ([]*types.UpgradeVotes, error)
 
-	Staking() (*types.Staking, error)
+	Staking(ctx context.Context) (*types.Staking, error)
 
-	IdentityWithProof(epoch uint64, address string) (*hexutil.Bytes, error)
+	IdentityWithProof(ctx context.Context, epoch uint64, address string) (*hexutil.Bytes, error)
 
-	MultisigContract(address string) (*types.MultisigContract, error)
+	MultisigContract(ctx context.Context, address string) (*types.MultisigContract, error)
 
-	Pool(address string) (*types.Pool, error)
+	Pool(ctx context.Context, address string) (*types.Pool, error)
 
-	VerifyContract(address string, data []byte) error
+	VerifyContract(ctx context.Context, address string, data []byte) error
 }
 
 func NewApi(client Client, logger log.Logger) Api {
@@ -55,169 +57,169 @@ type apiImpl struct {
 	logger log.Logger
 }
 
-func (api *apiImpl) OnlineIdentitiesCount() (uint64, error) {
+func (api *apiImpl) OnlineIdentitiesCount(ctx context.Context) (uint64, error) {
 	var res uint64
-	_, _, err := api.client.Get("api/OnlineIdentities/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/OnlineIdentities/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) OnlineIdentities(count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error) {
+func (api *apiImpl) OnlineIdentities(ctx context.Context, count uint64, continuationToken *string) ([]*types.OnlineIdentity, *string, error) {
 	var optional string
 	if continuationToken != nil {
 		optional = "&continuationToken=" + *continuationToken
 	}
 	var res []*types.OnlineIdentity
-	_, nextContinuationToken, err := api.client.Get("api/OnlineIdentities?limit="+strconv.Itoa(int(count))+optional, &res)
+	_, nextContinuationToken, err := api.client.Get(ctx, "api/OnlineIdentities?limit="+strconv.Itoa(int(count))+optional, &res)
 	if err != nil {
 		return nil, nil, api.handleError(err)
 	}
 	return res, nextContinuationToken, nil
 }
 
-func (api *apiImpl) OnlineIdentity(address string) (*types.OnlineIdentity, error) {
-	res, _, err := api.client.Get("api/OnlineIdentity/"+address, &types.OnlineIdentity{})
+func (api *apiImpl) OnlineIdentity(ctx context.Context, address string) (*types.OnlineIdentity, error) {
+	res, _, err := api.client.Get(ctx, "api/OnlineIdentity/"+address, &types.OnlineIdentity{})
 	if err != nil || res == nil {
 		return nil, api.handleError(err)
 	}
 	return res.(*types.OnlineIdentity), nil
 }
 
-func (api *apiImpl) OnlineCount() (uint64, error) {
+func (api *apiImpl) OnlineCount(ctx context.Context) (uint64, error) {
 	var res uint64
-	_, _, err := api.client.Get("api/OnlineMiners/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/OnlineMiners/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) ValidatorsCount() (uint64, error) {
+func (api *apiImpl) ValidatorsCount(ctx context.Context) (uint64, error) {
 	var res uint64
-	_, _, err := api.client.Get("api/Validators/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/Validators/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) Validators(count uint64, continuationToken *string) ([]types.Validator, *string, error) {
+func (api *apiImpl) Validators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error) {
 	var optional string
 	if continuationToken != nil {
 		optional = "&continuationToken=" + *continuationToken
 	}
 	var res []types.Validator
-	_, nextContinuationToken, err := api.client.Get("api/Validators?limit="+strconv.Itoa(int(count))+optional, &res)
+	_, nextContinuationToken, err := api.client.Get(ctx, "api/Validators?limit="+strconv.Itoa(int(count))+optional, &res)
 	if err != nil {
 		return nil, nil, api.handleError(err)
 	}
 	return res, nextContinuationToken, nil
 }
 
-func (api *apiImpl) OnlineValidatorsCount() (uint64, error) {
+func (api *apiImpl) OnlineValidatorsCount(ctx context.Context) (uint64, error) {
 	var res uint64
-	_, _, err := api.client.Get("api/OnlineValidators/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/OnlineValidators/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) OnlineValidators(count uint64, continuationToken *string) ([]types.Validator, *string, error) {
+func (api *apiImpl) OnlineValidators(ctx context.Context, count uint64, continuationToken *string) ([]types.Validator, *string, error) {
 	var optional string
 	if continuationToken != nil {
 		optional = "&continuationToken=" + *continuationToken
 	}
 	var res []types.Validator
-	_, nextContinuationToken, err := api.client.Get("api/OnlineValidators?limit="+strconv.Itoa(int(count))+optional, &res)
+	_, nextContinuationToken, err := api.client.Get(ctx, "api/OnlineValidators?limit="+strconv.Itoa(int(count))+optional, &res)
 	if err != nil {
 		return nil, nil, api.handleError(err)
 	}
 	return res, nextContinuationToken, nil
 }
 
-func (api *apiImpl) ForkCommitteeCount() (uint64, error) {
+func (api *apiImpl) ForkCommitteeCount(ctx context.Context) (uint64, error) {
 	var res uint64
-	_, _, err := api.client.Get("api/ForkCommittee/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/ForkCommittee/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) MemPoolTransaction(hash string) (*types.TransactionDetail, error) {
-	res, _, err := api.client.Get("api/MemPool/Transaction/"+hash, &types.TransactionDetail{})
+func (api *apiImpl) MemPoolTransaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
+	res, _, err := api.client.Get(ctx, "api/MemPool/Transaction/"+hash, &types.TransactionDetail{})
 	if err != nil || res == nil {
 		return nil, api.handleError(err)
 	}
 	return res.(*types.TransactionDetail), nil
 }
 
-func (api *apiImpl) MemPoolTransactionRaw(hash string) (*hexutil.Bytes, error) {
-	res, _, err := api.client.Get("api/MemPool/Transaction/"+hash+"/Raw", &hexutil.Bytes{})
+func (api *apiImpl) MemPoolTransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
+	res, _, err := api.client.Get(ctx, "api/MemPool/Transaction/"+hash+"/Raw", &hexutil.Bytes{})
 	if err != nil || res == nil {
 		return nil, api.handleError(err)
 	}
 	return res.(*hexutil.Bytes), nil
 }
 
-func (api *apiImpl) MemPoolAddressTransactions(address string, count int) ([]*types.TransactionSummary, error) {
+func (api *apiImpl) MemPoolAddressTransactions(ctx context.Context, address string, count int) ([]*types.TransactionSummary, error) {
 	var res []*types.TransactionSummary
-	_, _, err := api.client.Get("api/MemPool/Address/"+address+"/Transactions?limit="+strconv.Itoa(count), &res)
+	_, _, err := api.client.Get(ctx, "api/MemPool/Address/"+address+"/Transactions?limit="+strconv.Itoa(count), &res)
 	if err != nil {
 		return nil, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) MemPoolTransactions(count int) ([]*types.TransactionSummary, error) {
+func (api *apiImpl) MemPoolTransactions(ctx context.Context, count int) ([]*types.TransactionSummary, error) {
 	var res []*types.TransactionSummary
-	_, _, err := api.client.Get("api/MemPool/Transactions?limit="+strconv.Itoa(count), &res)
+	_, _, err := api.client.Get(ctx, "api/MemPool/Transactions?limit="+strconv.Itoa(count), &res)
 	if err != nil {
 		return nil, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) MemPoolTransactionsCount() (int, error) {
+func (api *apiImpl) MemPoolTransactionsCount(ctx context.Context) (int, error) {
 	var res int
-	_, _, err := api.client.Get("api/MemPool/Transactions/Count", &res)
+	_, _, err := api.client.Get(ctx, "api/MemPool/Transactions/Count", &res)
 	if err != nil {
 		return 0, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) MemPoolOracleVotingContractDeploys(author string) ([]service.OracleVotingContract, error) {
+func (api *apiImpl) MemPoolOracleVotingContractDeploys(ctx context.Context, author string) ([]service.OracleVotingContract, error) {
 	var res []service.OracleVotingContract
-	_, _, err := api.client.Get("api/MemPool/OracleVotingContractDeploys?author="+author, &res)
+	_, _, err := api.client.Get(ctx, "api/MemPool/OracleVotingContractDeploys?author="+author, &res)
 	if err != nil {
 		return nil, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) MemPoolAddressContractTxs(address, contractAddress string) ([]service.Transaction, error) {
+func (api *apiImpl) MemPoolAddressContractTxs(ctx context.Context, address, contractAddress string) ([]service.Transaction, error) {
 	var res []service.Transaction
-	_, _, err := api.client.Get("api/MemPool/Address/"+address+"/Contract/"+contractAddress+"/Txs", &res)
+	_, _, err := api.client.Get(ctx, "api/MemPool/Address/"+address+"/Contract/"+contractAddress+"/Txs", &res)
 	if err != nil {
 		return nil, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) UpgradeVoting() (res []*types.UpgradeVotes, err error) {
-	if _, _, err = api.client.Get("api/UpgradeVoting", &res); err != nil {
+func (api *apiImpl) UpgradeVoting(ctx context.Context) (res []*types.UpgradeVotes, err error) {
+	if _, _, err = api.client.Get(ctx, "api/UpgradeVoting", &res); err != nil {
 		return nil, api.handleError(err)
 	}
 	return res, nil
 }
 
-func (api *apiImpl) IdentityWithProof(epoch uint64, address string) (*hexutil.Bytes, error) {
+func (api *apiImpl) IdentityWithProof(ctx context.Context, epoch uint64, address string) (*hexutil.Bytes, error) {
 	var res hexutil.Bytes
-	if _, _, err := api.client.Get("api/Address/"+address+"/IdentityWithProof?epoch="+strconv.FormatUint(epoch, 10), &res); err != nil {
+	if _, _, err := api.client.Get(ctx, "api/Address/"+address+"/IdentityWithProof?epoch="+strconv.FormatUint(epoch, 10), &res); err != nil {
 		return nil, api.handleError(err)
 	}
 	if len(res) == 0 {
@@ -226,44 +228,50 @@ func (api *apiImpl) IdentityWithProof(epoch uint64, address string) (*hexutil.By
 	return &res, nil
 }
 
-func (api *apiImpl) Staking() (*types.Staking, error) {
+func (api *apiImpl) Staking(ctx context.Context) (*types.Staking, error) {
 	var res types.Staking
-	if _, _, err := api.client.Get("api/StakingV2", &res); err != nil {
+	if _, _, err := api.client.Get(ctx, "api/StakingV2", &res); err != nil {
 		return nil, api.handleError(err)
 	}
 	return &res, nil
 }
 
-func (api *apiImpl) MultisigContract(address string) (*types.MultisigContract, error) {
-	res, _, err := api.client.Get("api/Multisig/"+address, &types.MultisigContract{})
+func (api *apiImpl) MultisigContract(ctx context.Context, address string) (*types.MultisigContract, error) {
+	res, _, err := api.client.Get(ctx, "api/Multisig/"+address, &types.MultisigContract{})
 	if err != nil || res == nil {
 		return nil, api.handleError(err)
 	}
 	return res.(*types.MultisigContract), nil
 }
 
-func (api *apiImpl) Pool(address string) (*types.Pool, error) {
-	res, _, err := api.client.Get("api/Pool/"+address, &types.Pool{})
+func (api *apiImpl) Pool(ctx context.Context, address string) (*types.Pool, error) {
+	res, _, err := api.client.Get(ctx, "api/Pool/"+address, &types.Pool{})
 	if err != nil || res == nil {
 		return nil, api.handleError(err)
 	}
 	return res.(*types.Pool), nil
 }
 
-func (api *apiImpl) VerifyContract(address string, data []byte) error {
-	msgErr, err := api.client.Post("api/Contract/"+address+"/Verify", data)
+func (api *apiImpl) VerifyContract(ctx context.Context, address string, data []byte) error {
+	msgErr, err := api.client.Post(ctx, "api/Contract/"+address+"/Verify", data)
 	if msgErr != nil {
 		return msgErr
 	}
 	return api.handleError(err)
 }
 
-var indexerError = errors.New("unable to load indexer data")
+var (
+	indexerError        = apierrors.New(apierrors.UpstreamUnavailable, "unable to load indexer data")
+	indexerTimeoutError = apierrors.New(apierrors.Timeout, "indexer request timeout")
+)
 
 func (api *apiImpl) handleError(err error) error {
 	if err == nil {
 		return nil
 	}
 	api.logger.Error(err.Error())
+	if errors.Is(err, context.DeadlineExceeded) {
+		return indexerTimeoutError
+	}
 	return indexerError
 }
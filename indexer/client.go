@@ -1,8 +1,12 @@
 package indexer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/idena-network/idena-indexer-api/app/apierrors"
+	"github.com/idena-network/idena-indexer-api/app/lifecycle"
+	"github.com/idena-network/idena-indexer-api/app/monitoring"
 	"github.com/idena-network/idena-indexer-api/log"
 	"github.com/pkg/errors"
 	"github.com/valyala/fasthttp"
@@ -11,11 +15,11 @@ import (
 )
 
 type Client interface {
-	Get(query string, resultType interface{}) (interface{}, *string, error)
-	Post(url string, body []byte) (userErr, err error)
+	Get(ctx context.Context, query string, resultType interface{}) (interface{}, *string, error)
+	Post(ctx context.Context, url string, body []byte) (userErr, err error)
 }
 
-func NewClient(indexerUrl string, maxConnections int) Client {
+func NewClient(indexerUrl string, maxConnections int, metrics *monitoring.Registry, lc *lifecycle.Manager) Client {
 	log.Info(fmt.Sprintf("Initializing indexer client, url: %v, max connections: %v", indexerUrl, maxConnections))
 	res := &clientImpl{
 		indexerUrl: indexerUrl,
@@ -23,28 +27,49 @@ func NewClient(indexerUrl string, maxConnections int) Client {
 			MaxConnsPerHost:    maxConnections,
 			MaxConnWaitTimeout: time.Second * 30,
 		},
+		requests: metrics.NewCounter("idena_api_indexer_requests_total", "Total number of requests to the indexer.", "method"),
+		errors:   metrics.NewCounter("idena_api_indexer_errors_total", "Total number of failed requests to the indexer.", "method"),
 	}
+	lc.OnStop("indexer client", func() error {
+		res.pool.CloseIdleConnections()
+		return nil
+	})
 	return res
 }
 
 type clientImpl struct {
 	indexerUrl string
 	pool       *fasthttp.Client
+	requests   *monitoring.Counter
+	errors     *monitoring.Counter
 }
 
-func (client *clientImpl) Get(query string, resultType interface{}) (interface{}, *string, error) {
-	url := strings.Join([]string{client.indexerUrl, query}, "/")
-	statusCode, body, err := client.pool.Get(nil, url)
+func (client *clientImpl) Get(ctx context.Context, query string, resultType interface{}) (interface{}, *string, error) {
+	client.requests.Inc("get")
+	res, continuationToken, err := client.get(ctx, query, resultType)
 	if err != nil {
+		client.errors.Inc("get")
+	}
+	return res, continuationToken, err
+}
+
+func (client *clientImpl) get(ctx context.Context, query string, resultType interface{}) (interface{}, *string, error) {
+	url := strings.Join([]string{client.indexerUrl, query}, "/")
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(url)
+	httpResp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(httpResp)
+	if err := client.do(ctx, req, httpResp); err != nil {
 		return nil, nil, errors.Wrapf(err, "unable to send request %v", url)
 	}
-	if statusCode != fasthttp.StatusOK {
-		return nil, nil, errors.New(fmt.Sprintf("unable to send request %v, status code: %v", url, statusCode))
+	if httpResp.StatusCode() != fasthttp.StatusOK {
+		return nil, nil, errors.New(fmt.Sprintf("unable to send request %v, status code: %v", url, httpResp.StatusCode()))
 	}
 	resp := &response{
 		Result: resultType,
 	}
-	if err := json.Unmarshal(body, resp); err != nil {
+	if err := json.Unmarshal(httpResp.Body(), resp); err != nil {
 		return nil, nil, errors.Wrapf(err, "unable to parse response of %v", url)
 	}
 	if resp.Error != nil {
@@ -53,14 +78,23 @@ func (client *clientImpl) Get(query string, resultType interface{}) (interface{}
 	return resp.Result, resp.ContinuationToken, nil
 }
 
-func (client *clientImpl) Post(url string, body []byte) (userErr, err error) {
+func (client *clientImpl) Post(ctx context.Context, url string, body []byte) (userErr, err error) {
+	client.requests.Inc("post")
+	userErr, err = client.post(ctx, url, body)
+	if err != nil {
+		client.errors.Inc("post")
+	}
+	return userErr, err
+}
+
+func (client *clientImpl) post(ctx context.Context, url string, body []byte) (userErr, err error) {
 	url = strings.Join([]string{client.indexerUrl, url}, "/")
 	req := fasthttp.AcquireRequest()
 	req.SetRequestURI(url)
 	req.Header.SetMethod("POST")
 	req.SetBody(body)
 	resp := fasthttp.AcquireResponse()
-	if err := client.pool.Do(req, resp); err != nil {
+	if err := client.do(ctx, req, resp); err != nil {
 		return nil, errors.Wrapf(err, "unable to send request %v", url)
 	}
 	if resp.StatusCode() != fasthttp.StatusOK {
@@ -74,7 +108,7 @@ func (client *clientImpl) Post(url string, body []byte) (userErr, err error) {
 	}
 	if respBody.Error != nil {
 		if len(respBody.Error.UserMessage) > 0 {
-			return errors.New(respBody.Error.UserMessage), nil
+			return apierrors.New(apierrors.InvalidArgument, respBody.Error.UserMessage), nil
 		}
 		if len(respBody.Error.Message) > 0 {
 			return nil, errors.New(fmt.Sprintf("got error response from %v: %v", url, respBody.Error.Message))
@@ -83,6 +117,24 @@ func (client *clientImpl) Post(url string, body []byte) (userErr, err error) {
 	return nil, nil
 }
 
+// do sends the request until the context deadline as fasthttp requests can't be canceled otherwise
+func (client *clientImpl) do(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return client.pool.Do(req, resp)
+	}
+	if err := client.pool.DoDeadline(req, resp, deadline); err != nil {
+		if err == fasthttp.ErrTimeout {
+			return context.DeadlineExceeded
+		}
+		return err
+	}
+	return nil
+}
+
 type response struct {
 	Result            interface{} `json:"result,omitempty"`
 	Error             *respError  `json:"error,omitempty"`
@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"context"
 	"github.com/idena-network/idena-indexer-api/app/service"
 )
 
@@ -15,10 +16,10 @@ func NewContractsMemPool(api Api) *ContractsMemPool {
 	return res
 }
 
-func (memPool *ContractsMemPool) GetOracleVotingContractDeploys(author string) ([]service.OracleVotingContract, error) {
-	return memPool.api.MemPoolOracleVotingContractDeploys(author)
+func (memPool *ContractsMemPool) GetOracleVotingContractDeploys(ctx context.Context, author string) ([]service.OracleVotingContract, error) {
+	return memPool.api.MemPoolOracleVotingContractDeploys(ctx, author)
 }
 
-func (memPool *ContractsMemPool) GetAddressContractTxs(address, contractAddress string) ([]service.Transaction, error) {
-	return memPool.api.MemPoolAddressContractTxs(address, contractAddress)
+func (memPool *ContractsMemPool) GetAddressContractTxs(ctx context.Context, address, contractAddress string) ([]service.Transaction, error) {
+	return memPool.api.MemPoolAddressContractTxs(ctx, address, contractAddress)
 }
@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"context"
 	"github.com/idena-network/idena-go/common/hexutil"
 	"github.com/idena-network/idena-indexer-api/app/types"
 )
@@ -16,22 +17,22 @@ func NewMemPool(api Api) *MemPool {
 	return res
 }
 
-func (memPool *MemPool) GetTransaction(hash string) (*types.TransactionDetail, error) {
-	return memPool.api.MemPoolTransaction(hash)
+func (memPool *MemPool) GetTransaction(ctx context.Context, hash string) (*types.TransactionDetail, error) {
+	return memPool.api.MemPoolTransaction(ctx, hash)
 }
 
-func (memPool *MemPool) GetTransactionRaw(hash string) (*hexutil.Bytes, error) {
-	return memPool.api.MemPoolTransactionRaw(hash)
+func (memPool *MemPool) GetTransactionRaw(ctx context.Context, hash string) (*hexutil.Bytes, error) {
+	return memPool.api.MemPoolTransactionRaw(ctx, hash)
 }
 
-func (memPool *MemPool) GetAddressTransactions(address string, count int) ([]*types.TransactionSummary, error) {
-	return memPool.api.MemPoolAddressTransactions(address, count)
+func (memPool *MemPool) GetAddressTransactions(ctx context.Context, address string, count int) ([]*types.TransactionSummary, error) {
+	return memPool.api.MemPoolAddressTransactions(ctx, address, count)
 }
 
-func (memPool *MemPool) GetTransactions(count int) ([]*types.TransactionSummary, error) {
-	return memPool.api.MemPoolTransactions(count)
+func (memPool *MemPool) GetTransactions(ctx context.Context, count int) ([]*types.TransactionSummary, error) {
+	return memPool.api.MemPoolTransactions(ctx, count)
 }
 
-func (memPool *MemPool) GetTransactionsCount() (int, error) {
-	return memPool.api.MemPoolTransactionsCount()
+func (memPool *MemPool) GetTransactionsCount(ctx context.Context) (int, error) {
+	return memPool.api.MemPoolTransactionsCount(ctx)
 }
@@ -1,5 +1,7 @@
 package indexer
 
+import "context"
+
 type NetworkSizeLoader struct {
 	api Api
 }
@@ -12,5 +14,5 @@ func NewNetworkSizeLoader(api Api) *NetworkSizeLoader {
 }
 
 func (l *NetworkSizeLoader) Load() (uint64, error) {
-	return l.api.OnlineIdentitiesCount()
+	return l.api.OnlineIdentitiesCount(context.Background())
 }
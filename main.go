@@ -6,8 +6,10 @@ import (
 	"github.com/idena-network/idena-indexer-api/log"
 	"gopkg.in/urfave/cli.v1"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 	"time"
 )
 
@@ -30,9 +32,21 @@ func main() {
 		initLog(conf.Verbosity)
 		log.Info("Initializing app...")
 		apiApp := api.InitializeApp(conf, conf.MaxReqCount, time.Second*time.Duration(conf.ReqTimeoutSec), conf.ReqsPerMinuteLimit)
-		defer apiApp.Destroy()
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		stopped := make(chan struct{})
 		log.Info("Starting server...")
-		apiApp.Start(conf.Swagger)
+		go func() {
+			defer close(stopped)
+			apiApp.Start(conf.Swagger)
+		}()
+		select {
+		case sig := <-signals:
+			log.Info("Shutting down...", "signal", sig)
+		case <-stopped:
+		}
+		apiApp.Destroy()
+		log.Info("Stopped")
 		return nil
 	}
 
@@ -0,0 +1,17 @@
+WITH resolved AS (SELECT (CASE
+                              WHEN $3::bigint IS NULL THEN $2
+                              ELSE coalesce((SELECT max(height) FROM blocks WHERE timestamp <= $3), 0) END) height)
+SELECT a.address,
+       coalesce(bu.balance_old, b.balance, 0) balance,
+       coalesce(bu.stake_old, b.stake, 0)     stake,
+       r.height
+FROM resolved r,
+     addresses a
+         LEFT JOIN balances b ON b.address_id = a.id
+         LEFT JOIN LATERAL (SELECT balance_old, stake_old
+                            FROM balance_updates
+                            WHERE address_id = a.id
+                              AND block_height > (SELECT height FROM resolved)
+                            ORDER BY id
+                            LIMIT 1) bu ON true
+WHERE lower(a.address) = ANY ($1::text[])
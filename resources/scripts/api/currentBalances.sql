@@ -0,0 +1,5 @@
+SELECT a.address, b.balance, b.stake, (SELECT max(height) FROM blocks) block_height
+FROM balances b
+         JOIN addresses a ON a.id = b.address_id
+WHERE (b.balance > 0 OR b.stake > 0)
+  AND NOT lower(a.address) = any (coalesce($1::text[], '{}'))
@@ -0,0 +1,5 @@
+SELECT a.address, eb.balance, eb.stake, eb.block_height
+FROM api_epoch_balances eb
+         JOIN addresses a ON a.id = eb.address_id
+WHERE eb.epoch = $1
+  AND NOT lower(a.address) = any (coalesce($2::text[], '{}'))
@@ -0,0 +1,49 @@
+WITH buckets AS (SELECT b."timestamp" - (b."timestamp" + $2) % $1 bucket,
+                        min(b.height)                            first_height,
+                        max(b.height)                            last_height,
+                        avg(b.fee_rate)                          fee_rate
+                 FROM blocks b
+                 WHERE $4::bigint IS NULL
+                    OR b."timestamp" - (b."timestamp" + $2) % $1 <= $4
+                 GROUP BY 1
+                 ORDER BY 1 DESC
+                 LIMIT $3)
+SELECT bk.bucket,
+       coalesce(bk.fee_rate, 0)               fee_rate,
+       coalesce(txs.fees, 0)                  fees,
+       coalesce(txs.tips, 0)                  tips,
+       coalesce(types.names, '{}')            type_names,
+       coalesce(types.counts, '{}')           type_counts,
+       coalesce(addrs.active_count, 0)        active_addresses,
+       coalesce(addrs.new_count, 0)           new_addresses
+FROM buckets bk
+         LEFT JOIN LATERAL (SELECT sum(t.fee) fees, sum(t.tips) tips
+                            FROM transactions t
+                            WHERE t.block_height BETWEEN bk.first_height AND bk.last_height) txs ON true
+         LEFT JOIN LATERAL (SELECT array_agg(dtt.name) names, array_agg(tt.cnt) counts
+                            FROM (SELECT t.type, count(*) cnt
+                                  FROM transactions t
+                                  WHERE t.block_height BETWEEN bk.first_height AND bk.last_height
+                                  GROUP BY t.type) tt
+                                     JOIN dic_tx_types dtt ON dtt.id = tt.type) types ON true
+         LEFT JOIN LATERAL (SELECT count(*)                              active_count,
+                                   count(*) FILTER (WHERE NOT exists(SELECT 1
+                                                                     FROM transactions pt
+                                                                     WHERE pt.from = ta.address_id
+                                                                       AND pt.block_height < bk.first_height)
+                                       AND NOT exists(SELECT 1
+                                                      FROM transactions pt
+                                                      WHERE pt.to = ta.address_id
+                                                        AND pt.block_height < bk.first_height)) new_count
+                            FROM (SELECT t.from address_id
+                                  FROM transactions t
+                                  WHERE t.block_height BETWEEN bk.first_height AND bk.last_height
+                                    AND t.type != 1 -- not activation
+                                  UNION
+                                  SELECT t.to
+                                  FROM transactions t
+                                  WHERE t.block_height BETWEEN bk.first_height AND bk.last_height
+                                    AND t.to IS NOT NULL
+                                    AND t.type != 2 -- not invite
+                                 ) ta) addrs ON true
+ORDER BY bk.bucket DESC
\ No newline at end of file
@@ -0,0 +1,44 @@
+WITH buckets AS (SELECT (case when $1 = 'day' then b."timestamp" / 86400 else b.epoch end) bucket,
+                        min(b.height)                                                       first_height,
+                        max(b.height)                                                       last_height,
+                        min(b."timestamp")                                                  first_timestamp
+                 FROM blocks b
+                 WHERE $8::bigint IS NULL
+                    OR (case when $1 = 'day' then b."timestamp" / 86400 else b.epoch end) <= $8
+                 GROUP BY 1
+                 ORDER BY 1 DESC
+                 LIMIT $7)
+SELECT bk.bucket,
+       bk.first_timestamp,
+       bk.last_height,
+       c.total_balance,
+       c.total_stake,
+       coalesce(bs.minted, 0)         minted,
+       coalesce(bs.burnt, 0)          burnt,
+       coalesce(bc.fees, 0)           burnt_fees,
+       coalesce(bc.burn_tx, 0)        burnt_burn_tx,
+       coalesce(bc.penalties, 0)      burnt_penalties,
+       coalesce(bc.killed_stakes, 0)  burnt_killed_stakes,
+       coalesce(bc.other, 0)          burnt_other,
+       coalesce(frozen.balance, 0)    frozen_balance
+FROM buckets bk
+         JOIN coins c ON c.block_height = bk.last_height
+         LEFT JOIN LATERAL (SELECT sum(cc.minted) minted, sum(cc.burnt) burnt
+                            FROM coins cc
+                            WHERE cc.block_height BETWEEN bk.first_height AND bk.last_height) bs ON true
+         LEFT JOIN LATERAL (SELECT sum(bc.amount) FILTER (WHERE bc.reason = $3)                      fees,
+                                   sum(bc.amount) FILTER (WHERE bc.reason = $4)                      burn_tx,
+                                   sum(bc.amount) FILTER (WHERE bc.reason = $5)                      penalties,
+                                   sum(bc.amount) FILTER (WHERE bc.reason = $6)                      killed_stakes,
+                                   sum(bc.amount) FILTER (WHERE bc.reason NOT IN ($3, $4, $5, $6)) other
+                            FROM burnt_coins bc
+                            WHERE bc.block_height BETWEEN bk.first_height AND bk.last_height) bc ON true
+         LEFT JOIN LATERAL (SELECT sum(lu.balance_new) balance
+                            FROM (SELECT DISTINCT ON (bu.address_id) bu.balance_new
+                                  FROM balance_updates bu
+                                  WHERE bu.address_id IN (SELECT id
+                                                          FROM addresses
+                                                          WHERE lower(address) = any (coalesce($2::text[], '{}')))
+                                    AND bu.block_height <= bk.last_height
+                                  ORDER BY bu.address_id, bu.id DESC) lu) frozen ON true
+ORDER BY bk.bucket DESC
\ No newline at end of file
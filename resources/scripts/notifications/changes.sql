@@ -0,0 +1,151 @@
+-- Triggers to be created in the indexer db to notify api replicas about changes of indexed data.
+-- The channel name must match the api config value Invalidation.Channel.
+
+CREATE OR REPLACE FUNCTION notify_api_block_change() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('idena_api_changes',
+                      json_build_object('kind', 'block', 'height', NEW.height, 'epoch', NEW.epoch)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_api_epoch_change() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('idena_api_changes', json_build_object('kind', 'epoch', 'epoch', NEW.epoch)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Notifies about changes of rows referencing the contract by its address id
+CREATE OR REPLACE FUNCTION notify_api_contract_change() RETURNS trigger AS
+$$
+DECLARE
+    changed record;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed = OLD;
+    ELSE
+        changed = NEW;
+    END IF;
+    PERFORM pg_notify('idena_api_changes',
+                      json_build_object('kind', 'contract', 'address',
+                                        (SELECT lower(address) FROM addresses WHERE id = changed.contract_address_id))::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Notifies about changes of rows referencing the contract by its deploy tx id, the column name is the trigger argument
+CREATE OR REPLACE FUNCTION notify_api_contract_state_change() RETURNS trigger AS
+$$
+DECLARE
+    changed        record;
+    contract_tx_id bigint;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed = OLD;
+    ELSE
+        changed = NEW;
+    END IF;
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) USING changed INTO contract_tx_id;
+    PERFORM pg_notify('idena_api_changes',
+                      json_build_object('kind', 'contract', 'address',
+                                        (SELECT lower(a.address)
+                                         FROM contracts c
+                                                  JOIN addresses a ON a.id = c.contract_address_id
+                                         WHERE c.tx_id = contract_tx_id))::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS api_block_change ON blocks;
+CREATE TRIGGER api_block_change
+    AFTER INSERT
+    ON blocks
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_block_change();
+
+DROP TRIGGER IF EXISTS api_epoch_change ON epochs;
+CREATE TRIGGER api_epoch_change
+    AFTER INSERT
+    ON epochs
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_epoch_change();
+
+DROP TRIGGER IF EXISTS api_contract_change ON contract_tx_balance_updates;
+CREATE TRIGGER api_contract_change
+    AFTER INSERT
+    ON contract_tx_balance_updates
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_change();
+
+DROP TRIGGER IF EXISTS api_contract_deploy ON contracts;
+CREATE TRIGGER api_contract_deploy
+    AFTER INSERT
+    ON contracts
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_change();
+
+DROP TRIGGER IF EXISTS api_contract_verification_change ON contract_verifications;
+CREATE TRIGGER api_contract_verification_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON contract_verifications
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_change();
+
+DROP TRIGGER IF EXISTS api_token_change ON tokens;
+CREATE TRIGGER api_token_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON tokens
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_change();
+
+DROP TRIGGER IF EXISTS api_token_balance_change ON token_balances;
+CREATE TRIGGER api_token_balance_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON token_balances
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_change();
+
+DROP TRIGGER IF EXISTS api_time_lock_contract_termination ON time_lock_contract_terminations;
+CREATE TRIGGER api_time_lock_contract_termination
+    AFTER INSERT OR DELETE
+    ON time_lock_contract_terminations
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('tl_contract_tx_id');
+
+DROP TRIGGER IF EXISTS api_oracle_voting_contract_termination ON oracle_voting_contract_terminations;
+CREATE TRIGGER api_oracle_voting_contract_termination
+    AFTER INSERT OR DELETE
+    ON oracle_voting_contract_terminations
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('ov_contract_tx_id');
+
+DROP TRIGGER IF EXISTS api_oracle_lock_contract_termination ON oracle_lock_contract_terminations;
+CREATE TRIGGER api_oracle_lock_contract_termination
+    AFTER INSERT OR DELETE
+    ON oracle_lock_contract_terminations
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('ol_contract_tx_id');
+
+DROP TRIGGER IF EXISTS api_multisig_contract_termination ON multisig_contract_terminations;
+CREATE TRIGGER api_multisig_contract_termination
+    AFTER INSERT OR DELETE
+    ON multisig_contract_terminations
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('ms_contract_tx_id');
+
+DROP TRIGGER IF EXISTS api_refundable_oracle_lock_contract_termination ON refundable_oracle_lock_contract_terminations;
+CREATE TRIGGER api_refundable_oracle_lock_contract_termination
+    AFTER INSERT OR DELETE
+    ON refundable_oracle_lock_contract_terminations
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('ol_contract_tx_id');
+
+DROP TRIGGER IF EXISTS api_refundable_oracle_lock_contract_push ON refundable_oracle_lock_contract_call_pushes;
+CREATE TRIGGER api_refundable_oracle_lock_contract_push
+    AFTER INSERT OR UPDATE OR DELETE
+    ON refundable_oracle_lock_contract_call_pushes
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_api_contract_state_change('ol_contract_tx_id');
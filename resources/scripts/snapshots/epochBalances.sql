@@ -0,0 +1,101 @@
+-- Snapshots of holder balances at the end of epochs read by the api epoch distribution, to be created in the indexer db.
+-- A snapshot is stored when the next epoch is inserted, the last statement stores snapshots of already closed epochs.
+
+CREATE TABLE IF NOT EXISTS api_epoch_balances
+(
+    epoch        bigint  NOT NULL,
+    address_id   bigint  NOT NULL,
+    balance      numeric NOT NULL,
+    stake        numeric NOT NULL,
+    block_height bigint  NOT NULL,
+    CONSTRAINT api_epoch_balances_pkey PRIMARY KEY (epoch, address_id)
+);
+
+-- Stores balances after the last block of the epoch, the snapshot of the previous epoch is updated by balance updates
+-- of the epoch if it exists, all balance updates are scanned otherwise
+CREATE OR REPLACE FUNCTION api_store_epoch_balances(p_epoch bigint) RETURNS void AS
+$$
+DECLARE
+    l_height      bigint;
+    l_prev_height bigint;
+BEGIN
+    SELECT max(height) INTO l_height FROM blocks WHERE epoch = p_epoch;
+    IF l_height IS NULL THEN
+        RETURN;
+    END IF;
+    DELETE FROM api_epoch_balances WHERE epoch = p_epoch;
+
+    IF exists(SELECT 1 FROM api_epoch_balances WHERE epoch = p_epoch - 1) THEN
+        SELECT max(height) INTO l_prev_height FROM blocks WHERE epoch = p_epoch - 1;
+    END IF;
+
+    IF l_prev_height IS NULL THEN
+        INSERT INTO api_epoch_balances (epoch, address_id, balance, stake, block_height)
+        SELECT p_epoch, lu.address_id, lu.balance_new, lu.stake_new, l_height
+        FROM (SELECT DISTINCT ON (address_id) address_id, balance_new, stake_new
+              FROM balance_updates
+              WHERE block_height <= l_height
+              ORDER BY address_id, id DESC) lu
+        WHERE lu.balance_new > 0
+           OR lu.stake_new > 0;
+        RETURN;
+    END IF;
+
+    INSERT INTO api_epoch_balances (epoch, address_id, balance, stake, block_height)
+    SELECT p_epoch, lu.address_id, lu.balance_new, lu.stake_new, l_height
+    FROM (SELECT DISTINCT ON (address_id) address_id, balance_new, stake_new
+          FROM balance_updates
+          WHERE block_height > l_prev_height
+            AND block_height <= l_height
+          ORDER BY address_id, id DESC) lu
+    WHERE lu.balance_new > 0
+       OR lu.stake_new > 0;
+
+    INSERT INTO api_epoch_balances (epoch, address_id, balance, stake, block_height)
+    SELECT p_epoch, prev.address_id, prev.balance, prev.stake, l_height
+    FROM api_epoch_balances prev
+    WHERE prev.epoch = p_epoch - 1
+      AND NOT exists(SELECT 1
+                     FROM balance_updates bu
+                     WHERE bu.address_id = prev.address_id
+                       AND bu.block_height > l_prev_height
+                       AND bu.block_height <= l_height);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Snapshots the closed epoch once the next one is inserted and drops snapshots of epochs which are not closed anymore
+-- once epochs are deleted by the indexer reset
+CREATE OR REPLACE FUNCTION api_epoch_balances_on_epoch_change() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM api_epoch_balances WHERE epoch >= OLD.epoch - 1;
+        RETURN NULL;
+    END IF;
+    IF NEW.epoch > 0 THEN
+        PERFORM api_store_epoch_balances(NEW.epoch - 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS api_epoch_balances_change ON epochs;
+CREATE TRIGGER api_epoch_balances_change
+    AFTER INSERT OR DELETE
+    ON epochs
+    FOR EACH ROW
+EXECUTE PROCEDURE api_epoch_balances_on_epoch_change();
+
+DO
+$$
+    DECLARE
+        l_epoch bigint;
+    BEGIN
+        FOR l_epoch IN SELECT epoch FROM epochs WHERE epoch < (SELECT max(epoch) FROM epochs) ORDER BY epoch
+            LOOP
+                IF NOT exists(SELECT 1 FROM api_epoch_balances WHERE epoch = l_epoch) THEN
+                    PERFORM api_store_epoch_balances(l_epoch);
+                END IF;
+            END LOOP;
+    END
+$$;